@@ -51,7 +51,7 @@ func main() {
 	}
 
 	// Create and start server
-	server, err := api.NewServer(db, dataDir)
+	server, err := api.NewServer(db, dataDir, cfg.Worker, cfg.Benchmark)
 	if err != nil {
 		log.Fatalf("Failed to create server: %v", err)
 	}
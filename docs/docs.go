@@ -165,9 +165,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/backtests/{id}/metrics": {
+        "/api/v1/backtests/{id}/drawdowns": {
             "get": {
-                "description": "Get performance metrics for a specific backtest",
+                "description": "Get every drawdown episode of the marked-to-market equity curve with its peak, trough and recovery, the drawdown summary and the underwater curve, optionally downsampled to max_points evenly spaced points",
                 "consumes": [
                     "application/json"
                 ],
@@ -177,7 +177,7 @@ const docTemplate = `{
                 "tags": [
                     "backtests"
                 ],
-                "summary": "Get backtest metrics",
+                "summary": "Get backtest drawdowns",
                 "parameters": [
                     {
                         "type": "string",
@@ -185,13 +185,25 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of underwater points to return",
+                        "name": "max_points",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.MetricsResponse"
+                            "$ref": "#/definitions/dto.DrawdownsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
                     "404": {
@@ -203,9 +215,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/backtests/{id}/trades": {
+        "/api/v1/backtests/{id}/equity": {
             "get": {
-                "description": "Get all trades for a specific backtest",
+                "description": "Get the bar by bar equity curve of a backtest, optionally downsampled to max_points evenly spaced points",
                 "consumes": [
                     "application/json"
                 ],
@@ -215,7 +227,7 @@ const docTemplate = `{
                 "tags": [
                     "backtests"
                 ],
-                "summary": "Get backtest trades",
+                "summary": "Get backtest equity curve",
                 "parameters": [
                     {
                         "type": "string",
@@ -223,6 +235,12 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of points to return",
+                        "name": "max_points",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -232,6 +250,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/dto.ListResponse"
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -241,9 +265,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/health": {
+        "/api/v1/backtests/{id}/metrics": {
             "get": {
-                "description": "Check if the API is running",
+                "description": "Get performance metrics for a specific backtest",
                 "consumes": [
                     "application/json"
                 ],
@@ -251,170 +275,2241 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "health"
+                    "backtests"
+                ],
+                "summary": "Get backtest metrics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Backtest ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Health check",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/dto.MetricsResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "dto.BacktestResponse": {
-            "type": "object",
-            "properties": {
-                "created_at": {
-                    "type": "string",
-                    "example": "2025-01-15T10:30:00Z"
-                },
-                "end_date": {
-                    "type": "string",
-                    "example": "2024-12-31"
-                },
-                "id": {
-                    "type": "string",
-                    "example": "123e4567-e89b-12d3-a456-426614174000"
-                },
-                "initial_capital": {
-                    "type": "number",
-                    "example": 10000
-                },
-                "start_date": {
-                    "type": "string",
-                    "example": "2024-01-01"
-                },
-                "status": {
-                    "type": "string",
-                    "example": "completed"
-                },
-                "strategy_id": {
-                    "type": "string",
-                    "example": "SMA Crossover"
-                },
-                "symbol": {
-                    "type": "string",
-                    "example": "AAPL"
-                },
-                "updated_at": {
-                    "type": "string",
-                    "example": "2025-01-15T10:35:00Z"
+        },
+        "/api/v1/backtests/{id}/montecarlo": {
+            "post": {
+                "description": "Replay the closed trades of a completed backtest in randomized sequences: shuffled, resampled with replacement or with trades skipped at random. reports percentiles of final capital, max drawdown and sharpe and the probability of ruin. the same seed gives the same result",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "backtests"
+                ],
+                "summary": "Run a Monte Carlo analysis of a backtest",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Backtest ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Simulation settings",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MonteCarloRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MonteCarloResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
                 }
             }
         },
-        "dto.CreateBacktestRequest": {
-            "type": "object",
-            "required": [
-                "end_date",
-                "initial_capital",
-                "start_date",
-                "strategy_id",
-                "symbol"
-            ],
-            "properties": {
-                "end_date": {
-                    "type": "string",
-                    "example": "2024-12-31"
-                },
-                "initial_capital": {
-                    "type": "number",
-                    "example": 10000
-                },
-                "start_date": {
-                    "type": "string",
-                    "example": "2024-01-01"
-                },
-                "strategy_id": {
-                    "type": "string",
-                    "example": "buy_hold"
-                },
-                "symbol": {
-                    "type": "string",
-                    "example": "AAPL"
+        "/api/v1/backtests/{id}/returns": {
+            "get": {
+                "description": "Get the month by month or year by year returns of the equity curve as a matrix with a row per year, with the best and worst period, the share of positive periods and the year to date return",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "backtests"
+                ],
+                "summary": "Get backtest calendar returns",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Backtest ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "monthly or yearly, defaults to monthly",
+                        "name": "period",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReturnsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
                 }
             }
         },
-        "dto.ErrorResponse": {
-            "type": "object",
-            "properties": {
-                "error": {
-                    "type": "string",
-                    "example": "Invalid request"
-                },
-                "message": {
-                    "type": "string",
-                    "example": "strategy field is required"
+        "/api/v1/backtests/{id}/round-trips": {
+            "get": {
+                "description": "Get every exit of a backtest paired with the position it closed, with holding period and maximum adverse and favorable excursion",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "backtests"
+                ],
+                "summary": "Get backtest round trips",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Backtest ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only round trips of this symbol",
+                        "name": "symbol",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
                 }
             }
         },
-        "dto.ListResponse": {
-            "type": "object",
-            "properties": {
-                "items": {},
+        "/api/v1/backtests/{id}/trades": {
+            "get": {
+                "description": "Get all trades for a specific backtest",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "backtests"
+                ],
+                "summary": "Get backtest trades",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Backtest ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only trades of this symbol",
+                        "name": "symbol",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/optimizations": {
+            "get": {
+                "description": "Get the latest grid searches",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "optimizations"
+                ],
+                "summary": "List optimizations",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.ListResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "items": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/dto.OptimizationResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Expand the parameter grid, run one backtest per combination on the worker pool and rank them by the objective. combinations the strategy rejects are skipped",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "optimizations"
+                ],
+                "summary": "Create a parameter grid search",
+                "parameters": [
+                    {
+                        "description": "Backtest settings and parameter grid",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateOptimizationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OptimizationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/optimizations/{id}": {
+            "get": {
+                "description": "Get a grid search with the progress of its backtests",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "optimizations"
+                ],
+                "summary": "Get optimization by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Optimization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OptimizationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/optimizations/{id}/diagnostics": {
+            "get": {
+                "description": "Measure how much the best backtest of a grid search owes to the number of trials: the probabilistic and deflated Sharpe ratios of the best backtest and the probability of backtest overfitting from combinatorially symmetric cross-validation over the equity returns of every completed backtest",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "optimizations"
+                ],
+                "summary": "Get the overfitting diagnostics of an optimization",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Optimization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Even number of CSCV blocks, at most 16. defaults to the most the returns allow",
+                        "name": "partitions",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.DiagnosticsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/optimizations/{id}/results": {
+            "get": {
+                "description": "Get every backtest of a grid search ranked best first by the objective, backtests without metrics come last. objective overrides the one the search was created with",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "optimizations"
+                ],
+                "summary": "Get the ranked results of an optimization",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Optimization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "sharpe, total_return, max_drawdown or profit_factor",
+                        "name": "objective",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.ListResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "items": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/dto.OptimizationResultResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/strategies": {
+            "get": {
+                "description": "Get every registered strategy with its parameter schema, followed by the uploaded ones",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "strategies"
+                ],
+                "summary": "List strategies",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.ListResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "items": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/dto.StrategyResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Store user code as a strategy, backtests run it by passing the returned id as strategy_id. python strategies only run when the server has a jail configured for the interpreter",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "strategies"
+                ],
+                "summary": "Upload a strategy",
+                "parameters": [
+                    {
+                        "description": "Strategy code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateStrategyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.StrategyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/strategies/{id}": {
+            "get": {
+                "description": "Get an uploaded strategy including its code",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "strategies"
+                ],
+                "summary": "Get an uploaded strategy",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Strategy ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.StrategyResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/walk-forwards": {
+            "get": {
+                "description": "Get the latest walk-forward analyses without their windows",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "walk-forwards"
+                ],
+                "summary": "List walk-forward analyses",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.ListResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "items": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/dto.WalkForwardResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Split the date range into in-sample and out-of-sample windows, rolling or anchored. the grid is optimized on every in-sample window and the winner traded on the out-of-sample window after it. the out-of-sample trades are stitched into one backtest",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "walk-forwards"
+                ],
+                "summary": "Create a walk-forward analysis",
+                "parameters": [
+                    {
+                        "description": "Backtest settings, parameter grid and window lengths",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateWalkForwardRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.WalkForwardResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/walk-forwards/{id}": {
+            "get": {
+                "description": "Get a walk-forward analysis with the chosen parameters and efficiency of every window once it completed. the stitched trades, equity and metrics are under the backtest backtest_id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "walk-forwards"
+                ],
+                "summary": "Get walk-forward analysis by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Walk-forward ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.WalkForwardResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Check if the API is running",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Health check",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "dto.BacktestResponse": {
+            "type": "object",
+            "properties": {
+                "allow_short": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "benchmark": {
+                    "type": "string",
+                    "example": "SPY"
+                },
+                "borrow_rate": {
+                    "type": "number",
+                    "example": 0.02
+                },
+                "commission_model": {
+                    "type": "string",
+                    "example": "BPS"
+                },
+                "commission_rate": {
+                    "type": "number",
+                    "example": 10
+                },
+                "corporate_actions": {
+                    "type": "string",
+                    "example": "APPLY"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-01-15T10:30:00Z"
+                },
+                "end_date": {
+                    "type": "string",
+                    "example": "2024-12-31"
+                },
+                "fill_model": {
+                    "type": "string",
+                    "example": "NEXT_BAR_OPEN"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "initial_capital": {
+                    "type": "number",
+                    "example": 10000
+                },
+                "initial_margin": {
+                    "type": "number",
+                    "example": 0.5
+                },
+                "maintenance_margin": {
+                    "type": "number",
+                    "example": 0.3
+                },
+                "min_trade_notional": {
+                    "type": "number",
+                    "example": 100
+                },
+                "min_trade_shares": {
+                    "type": "number",
+                    "example": 1
+                },
+                "optimization_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "parameters": {
+                    "type": "object"
+                },
+                "rebalance_frequency": {
+                    "type": "string",
+                    "example": "MONTHLY"
+                },
+                "rebalance_threshold": {
+                    "type": "number",
+                    "example": 0.05
+                },
+                "reinvest_dividends": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "risk_free_rate": {
+                    "type": "number",
+                    "example": 0.04
+                },
+                "sizing_lookback": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "sizing_method": {
+                    "type": "string",
+                    "example": "PERCENT_EQUITY"
+                },
+                "sizing_value": {
+                    "type": "number",
+                    "example": 50
+                },
+                "slippage_model": {
+                    "type": "string",
+                    "example": "FIXED_BPS"
+                },
+                "slippage_rate": {
+                    "type": "number",
+                    "example": 5
+                },
+                "start_date": {
+                    "type": "string",
+                    "example": "2024-01-01"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "completed"
+                },
+                "strategy_id": {
+                    "type": "string",
+                    "example": "sma_crossover"
+                },
+                "symbols": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "AAPL",
+                        "MSFT"
+                    ]
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2025-01-15T10:35:00Z"
+                }
+            }
+        },
+        "dto.CreateBacktestRequest": {
+            "type": "object",
+            "required": [
+                "end_date",
+                "initial_capital",
+                "start_date",
+                "strategy_id",
+                "symbols"
+            ],
+            "properties": {
+                "allow_short": {
+                    "description": "short selling, margins are fractions of the short notional and default\nto common.DefaultInitialMargin and common.DefaultMaintenanceMargin",
+                    "type": "boolean",
+                    "example": true
+                },
+                "benchmark": {
+                    "description": "symbol bought and held to compare the returns with, its daily CSV\nhas to be in the data directory. defaults to the configured benchmark,\nor the first symbol traded without one",
+                    "type": "string",
+                    "maxLength": 10,
+                    "example": "SPY"
+                },
+                "borrow_rate": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 0.02
+                },
+                "commission_model": {
+                    "description": "trading costs, both default to none",
+                    "type": "string",
+                    "enum": [
+                        "none",
+                        "fixed",
+                        "per_share",
+                        "bps",
+                        "tiered"
+                    ],
+                    "example": "bps"
+                },
+                "commission_rate": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 10
+                },
+                "corporate_actions": {
+                    "description": "splits and dividends, defaults to apply which trades raw prices and pays\ndividends in cash. adjust trades back-adjusted prices instead",
+                    "type": "string",
+                    "enum": [
+                        "apply",
+                        "adjust",
+                        "ignore"
+                    ],
+                    "example": "apply"
+                },
+                "end_date": {
+                    "type": "string",
+                    "example": "2024-12-31"
+                },
+                "fill_model": {
+                    "type": "string",
+                    "enum": [
+                        "same_bar_close",
+                        "next_bar_open",
+                        "next_bar_vwap",
+                        "next_bar_close"
+                    ],
+                    "example": "next_bar_open"
+                },
+                "initial_capital": {
+                    "type": "number",
+                    "example": 10000
+                },
+                "initial_margin": {
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0,
+                    "example": 0.5
+                },
+                "maintenance_margin": {
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0,
+                    "example": 0.3
+                },
+                "min_trade_notional": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 100
+                },
+                "min_trade_shares": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 1
+                },
+                "parameters": {
+                    "type": "object"
+                },
+                "rebalance_frequency": {
+                    "description": "rebalancing of target weight strategies, defaults to monthly. drift\nrebalances once a weight is rebalance_threshold away from its target",
+                    "type": "string",
+                    "enum": [
+                        "daily",
+                        "weekly",
+                        "monthly",
+                        "drift"
+                    ],
+                    "example": "monthly"
+                },
+                "rebalance_threshold": {
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0,
+                    "example": 0.05
+                },
+                "reinvest_dividends": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "risk_free_rate": {
+                    "description": "annual risk-free rate as a fraction, subtracted from the returns by\nthe sharpe, sortino and omega ratios",
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 0.04
+                },
+                "sizing_lookback": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 20
+                },
+                "sizing_method": {
+                    "description": "position sizing for signals, defaults to all_in. the value is shares,\ndollars, percent of equity, percent risked per ATR, annual volatility\ntarget or Kelly fraction depending on the method",
+                    "type": "string",
+                    "enum": [
+                        "all_in",
+                        "fixed_shares",
+                        "fixed_notional",
+                        "percent_equity",
+                        "atr",
+                        "volatility_target",
+                        "kelly"
+                    ],
+                    "example": "percent_equity"
+                },
+                "sizing_value": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 50
+                },
+                "slippage_model": {
+                    "type": "string",
+                    "enum": [
+                        "none",
+                        "fixed_bps",
+                        "volatility"
+                    ],
+                    "example": "fixed_bps"
+                },
+                "slippage_rate": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 5
+                },
+                "start_date": {
+                    "type": "string",
+                    "example": "2024-01-01"
+                },
+                "strategy_id": {
+                    "type": "string",
+                    "example": "sma_crossover"
+                },
+                "symbol": {
+                    "type": "string",
+                    "maxLength": 10,
+                    "example": "AAPL"
+                },
+                "symbols": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "AAPL",
+                        "MSFT"
+                    ]
+                }
+            }
+        },
+        "dto.CreateOptimizationRequest": {
+            "type": "object",
+            "required": [
+                "end_date",
+                "grid",
+                "initial_capital",
+                "start_date",
+                "strategy_id",
+                "symbols"
+            ],
+            "properties": {
+                "allow_short": {
+                    "description": "short selling, margins are fractions of the short notional and default\nto common.DefaultInitialMargin and common.DefaultMaintenanceMargin",
+                    "type": "boolean",
+                    "example": true
+                },
+                "benchmark": {
+                    "description": "symbol bought and held to compare the returns with, its daily CSV\nhas to be in the data directory. defaults to the configured benchmark,\nor the first symbol traded without one",
+                    "type": "string",
+                    "maxLength": 10,
+                    "example": "SPY"
+                },
+                "borrow_rate": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 0.02
+                },
+                "commission_model": {
+                    "description": "trading costs, both default to none",
+                    "type": "string",
+                    "enum": [
+                        "none",
+                        "fixed",
+                        "per_share",
+                        "bps",
+                        "tiered"
+                    ],
+                    "example": "bps"
+                },
+                "commission_rate": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 10
+                },
+                "corporate_actions": {
+                    "description": "splits and dividends, defaults to apply which trades raw prices and pays\ndividends in cash. adjust trades back-adjusted prices instead",
+                    "type": "string",
+                    "enum": [
+                        "apply",
+                        "adjust",
+                        "ignore"
+                    ],
+                    "example": "apply"
+                },
+                "end_date": {
+                    "type": "string",
+                    "example": "2024-12-31"
+                },
+                "fill_model": {
+                    "type": "string",
+                    "enum": [
+                        "same_bar_close",
+                        "next_bar_open",
+                        "next_bar_vwap",
+                        "next_bar_close"
+                    ],
+                    "example": "next_bar_open"
+                },
+                "grid": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/dto.ParameterRange"
+                    }
+                },
+                "initial_capital": {
+                    "type": "number",
+                    "example": 10000
+                },
+                "initial_margin": {
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0,
+                    "example": 0.5
+                },
+                "maintenance_margin": {
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0,
+                    "example": 0.3
+                },
+                "min_trade_notional": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 100
+                },
+                "min_trade_shares": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 1
+                },
+                "objective": {
+                    "type": "string",
+                    "enum": [
+                        "sharpe",
+                        "total_return",
+                        "max_drawdown",
+                        "profit_factor"
+                    ],
+                    "example": "sharpe"
+                },
+                "parameters": {
+                    "type": "object"
+                },
+                "rebalance_frequency": {
+                    "description": "rebalancing of target weight strategies, defaults to monthly. drift\nrebalances once a weight is rebalance_threshold away from its target",
+                    "type": "string",
+                    "enum": [
+                        "daily",
+                        "weekly",
+                        "monthly",
+                        "drift"
+                    ],
+                    "example": "monthly"
+                },
+                "rebalance_threshold": {
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0,
+                    "example": 0.05
+                },
+                "reinvest_dividends": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "risk_free_rate": {
+                    "description": "annual risk-free rate as a fraction, subtracted from the returns by\nthe sharpe, sortino and omega ratios",
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 0.04
+                },
+                "sizing_lookback": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 20
+                },
+                "sizing_method": {
+                    "description": "position sizing for signals, defaults to all_in. the value is shares,\ndollars, percent of equity, percent risked per ATR, annual volatility\ntarget or Kelly fraction depending on the method",
+                    "type": "string",
+                    "enum": [
+                        "all_in",
+                        "fixed_shares",
+                        "fixed_notional",
+                        "percent_equity",
+                        "atr",
+                        "volatility_target",
+                        "kelly"
+                    ],
+                    "example": "percent_equity"
+                },
+                "sizing_value": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 50
+                },
+                "slippage_model": {
+                    "type": "string",
+                    "enum": [
+                        "none",
+                        "fixed_bps",
+                        "volatility"
+                    ],
+                    "example": "fixed_bps"
+                },
+                "slippage_rate": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 5
+                },
+                "start_date": {
+                    "type": "string",
+                    "example": "2024-01-01"
+                },
+                "strategy_id": {
+                    "type": "string",
+                    "example": "sma_crossover"
+                },
+                "symbol": {
+                    "type": "string",
+                    "maxLength": 10,
+                    "example": "AAPL"
+                },
+                "symbols": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "AAPL",
+                        "MSFT"
+                    ]
+                }
+            }
+        },
+        "dto.CreateStrategyRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "language",
+                "name"
+            ],
+            "properties": {
+                "code": {
+                    "description": "base64 encoded module for wasm, a YAML or JSON rule set for rules",
+                    "type": "string",
+                    "example": "def on_bar(ctx):\n    return 'BUY'"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Buys after three rising closes"
+                },
+                "language": {
+                    "type": "string",
+                    "enum": [
+                        "python",
+                        "go",
+                        "wasm",
+                        "rules"
+                    ],
+                    "example": "python"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Momentum"
+                }
+            }
+        },
+        "dto.CreateWalkForwardRequest": {
+            "type": "object",
+            "required": [
+                "end_date",
+                "grid",
+                "in_sample_days",
+                "initial_capital",
+                "out_of_sample_days",
+                "start_date",
+                "strategy_id",
+                "symbols"
+            ],
+            "properties": {
+                "allow_short": {
+                    "description": "short selling, margins are fractions of the short notional and default\nto common.DefaultInitialMargin and common.DefaultMaintenanceMargin",
+                    "type": "boolean",
+                    "example": true
+                },
+                "anchored": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "benchmark": {
+                    "description": "symbol bought and held to compare the returns with, its daily CSV\nhas to be in the data directory. defaults to the configured benchmark,\nor the first symbol traded without one",
+                    "type": "string",
+                    "maxLength": 10,
+                    "example": "SPY"
+                },
+                "borrow_rate": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 0.02
+                },
+                "commission_model": {
+                    "description": "trading costs, both default to none",
+                    "type": "string",
+                    "enum": [
+                        "none",
+                        "fixed",
+                        "per_share",
+                        "bps",
+                        "tiered"
+                    ],
+                    "example": "bps"
+                },
+                "commission_rate": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 10
+                },
+                "corporate_actions": {
+                    "description": "splits and dividends, defaults to apply which trades raw prices and pays\ndividends in cash. adjust trades back-adjusted prices instead",
+                    "type": "string",
+                    "enum": [
+                        "apply",
+                        "adjust",
+                        "ignore"
+                    ],
+                    "example": "apply"
+                },
+                "end_date": {
+                    "type": "string",
+                    "example": "2024-12-31"
+                },
+                "fill_model": {
+                    "type": "string",
+                    "enum": [
+                        "same_bar_close",
+                        "next_bar_open",
+                        "next_bar_vwap",
+                        "next_bar_close"
+                    ],
+                    "example": "next_bar_open"
+                },
+                "grid": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/dto.ParameterRange"
+                    }
+                },
+                "in_sample_days": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 365
+                },
+                "initial_capital": {
+                    "type": "number",
+                    "example": 10000
+                },
+                "initial_margin": {
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0,
+                    "example": 0.5
+                },
+                "maintenance_margin": {
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0,
+                    "example": 0.3
+                },
+                "min_trade_notional": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 100
+                },
+                "min_trade_shares": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 1
+                },
+                "objective": {
+                    "type": "string",
+                    "enum": [
+                        "sharpe",
+                        "total_return",
+                        "max_drawdown",
+                        "profit_factor"
+                    ],
+                    "example": "sharpe"
+                },
+                "out_of_sample_days": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 90
+                },
+                "parameters": {
+                    "type": "object"
+                },
+                "rebalance_frequency": {
+                    "description": "rebalancing of target weight strategies, defaults to monthly. drift\nrebalances once a weight is rebalance_threshold away from its target",
+                    "type": "string",
+                    "enum": [
+                        "daily",
+                        "weekly",
+                        "monthly",
+                        "drift"
+                    ],
+                    "example": "monthly"
+                },
+                "rebalance_threshold": {
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0,
+                    "example": 0.05
+                },
+                "reinvest_dividends": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "risk_free_rate": {
+                    "description": "annual risk-free rate as a fraction, subtracted from the returns by\nthe sharpe, sortino and omega ratios",
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 0.04
+                },
+                "sizing_lookback": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 20
+                },
+                "sizing_method": {
+                    "description": "position sizing for signals, defaults to all_in. the value is shares,\ndollars, percent of equity, percent risked per ATR, annual volatility\ntarget or Kelly fraction depending on the method",
+                    "type": "string",
+                    "enum": [
+                        "all_in",
+                        "fixed_shares",
+                        "fixed_notional",
+                        "percent_equity",
+                        "atr",
+                        "volatility_target",
+                        "kelly"
+                    ],
+                    "example": "percent_equity"
+                },
+                "sizing_value": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 50
+                },
+                "slippage_model": {
+                    "type": "string",
+                    "enum": [
+                        "none",
+                        "fixed_bps",
+                        "volatility"
+                    ],
+                    "example": "fixed_bps"
+                },
+                "slippage_rate": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 5
+                },
+                "start_date": {
+                    "type": "string",
+                    "example": "2024-01-01"
+                },
+                "strategy_id": {
+                    "type": "string",
+                    "example": "sma_crossover"
+                },
+                "symbol": {
+                    "type": "string",
+                    "maxLength": 10,
+                    "example": "AAPL"
+                },
+                "symbols": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "AAPL",
+                        "MSFT"
+                    ]
+                }
+            }
+        },
+        "dto.DiagnosticsResponse": {
+            "type": "object",
+            "properties": {
+                "best_backtest_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "best_parameters": {
+                    "type": "object"
+                },
+                "deflated_sharpe": {
+                    "type": "number",
+                    "example": 0.61
+                },
+                "degradation": {
+                    "type": "number",
+                    "example": -0.031
+                },
+                "expected_max_sharpe": {
+                    "type": "number",
+                    "example": 0.078
+                },
+                "mean_logit": {
+                    "type": "number",
+                    "example": 0.84
+                },
+                "optimization_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "pbo": {
+                    "type": "number",
+                    "example": 0.27
+                },
+                "pbo_partitions": {
+                    "type": "integer",
+                    "example": 16
+                },
+                "pbo_splits": {
+                    "type": "integer",
+                    "example": 12870
+                },
+                "probabilistic_sharpe": {
+                    "type": "number",
+                    "example": 0.97
+                },
+                "sharpe": {
+                    "type": "number",
+                    "example": 0.09
+                },
+                "sharpe_variance": {
+                    "type": "number",
+                    "example": 0.0012
+                },
+                "trials": {
+                    "type": "integer",
+                    "example": 48
+                }
+            }
+        },
+        "dto.DistributionResponse": {
+            "type": "object",
+            "properties": {
+                "max": {
+                    "type": "number",
+                    "example": 15200
+                },
+                "mean": {
+                    "type": "number",
+                    "example": 12450
+                },
+                "median": {
+                    "type": "number",
+                    "example": 12480
+                },
+                "min": {
+                    "type": "number",
+                    "example": 9800
+                },
+                "p25": {
+                    "type": "number",
+                    "example": 11900
+                },
+                "p5": {
+                    "type": "number",
+                    "example": 11050
+                },
+                "p75": {
+                    "type": "number",
+                    "example": 13010
+                },
+                "p95": {
+                    "type": "number",
+                    "example": 13800
+                },
+                "std_dev": {
+                    "type": "number",
+                    "example": 830
+                }
+            }
+        },
+        "dto.DrawdownResponse": {
+            "type": "object",
+            "properties": {
+                "depth": {
+                    "type": "number",
+                    "example": 15.5
+                },
+                "length_days": {
+                    "type": "integer",
+                    "example": 47
+                },
+                "peak_date": {
+                    "type": "string",
+                    "example": "2024-03-01T00:00:00Z"
+                },
+                "peak_equity": {
+                    "type": "number",
+                    "example": 11200
+                },
+                "recovery_date": {
+                    "type": "string",
+                    "example": "2024-04-17T00:00:00Z"
+                },
+                "trough_date": {
+                    "type": "string",
+                    "example": "2024-03-20T00:00:00Z"
+                },
+                "trough_equity": {
+                    "type": "number",
+                    "example": 9464
+                }
+            }
+        },
+        "dto.DrawdownsResponse": {
+            "type": "object",
+            "properties": {
+                "average_drawdown": {
+                    "type": "number",
+                    "example": 3.2
+                },
+                "backtest_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "drawdowns": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.DrawdownResponse"
+                    }
+                },
+                "max_drawdown": {
+                    "type": "number",
+                    "example": 15.5
+                },
+                "max_drawdown_duration": {
+                    "type": "integer",
+                    "example": 47
+                },
+                "time_under_water": {
+                    "type": "number",
+                    "example": 62.5
+                },
+                "ulcer_index": {
+                    "type": "number",
+                    "example": 4.12
+                },
+                "underwater": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.UnderwaterPointResponse"
+                    }
+                }
+            }
+        },
+        "dto.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "Invalid request"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "strategy field is required"
+                }
+            }
+        },
+        "dto.ListResponse": {
+            "type": "object",
+            "properties": {
+                "items": {},
                 "limit": {
                     "type": "integer",
-                    "example": 20
+                    "example": 20
+                },
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "dto.MetricsResponse": {
+            "type": "object",
+            "properties": {
+                "alpha": {
+                    "type": "number",
+                    "example": 4.35
+                },
+                "avg_bars_held": {
+                    "type": "number",
+                    "example": 12.5
+                },
+                "avg_mae": {
+                    "type": "number",
+                    "example": -3.1
+                },
+                "avg_mfe": {
+                    "type": "number",
+                    "example": 6.2
+                },
+                "backtest_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "benchmark_return": {
+                    "type": "number",
+                    "example": 18.2
+                },
+                "beta": {
+                    "type": "number",
+                    "example": 0.82
+                },
+                "calmar_ratio": {
+                    "type": "number",
+                    "example": 1.61
+                },
+                "correlation": {
+                    "type": "number",
+                    "example": 0.74
+                },
+                "down_capture": {
+                    "type": "number",
+                    "example": 76.5
+                },
+                "edge_ratio": {
+                    "type": "number",
+                    "example": 2
+                },
+                "excess_return": {
+                    "type": "number",
+                    "example": 6.8
+                },
+                "information_ratio": {
+                    "type": "number",
+                    "example": 0.58
+                },
+                "kurtosis": {
+                    "type": "number",
+                    "example": 1.87
+                },
+                "losing_trades": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "max_drawdown": {
+                    "type": "number",
+                    "example": 15.5
+                },
+                "max_drawdown_duration": {
+                    "type": "integer",
+                    "example": 47
+                },
+                "max_loss_streak": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "max_win_streak": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "omega_ratio": {
+                    "type": "number",
+                    "example": 1.35
+                },
+                "profit_factor": {
+                    "type": "number",
+                    "example": 4.33
+                },
+                "return_pct": {
+                    "type": "number",
+                    "example": 25
+                },
+                "sharpe_ratio": {
+                    "type": "number",
+                    "example": 1.82
+                },
+                "skewness": {
+                    "type": "number",
+                    "example": -0.21
+                },
+                "sortino_ratio": {
+                    "type": "number",
+                    "example": 2.41
+                },
+                "tail_ratio": {
+                    "type": "number",
+                    "example": 1.05
+                },
+                "total_return": {
+                    "type": "number",
+                    "example": 2500
+                },
+                "total_trades": {
+                    "type": "integer",
+                    "example": 8
+                },
+                "tracking_error": {
+                    "type": "number",
+                    "example": 9.6
+                },
+                "ulcer_index": {
+                    "type": "number",
+                    "example": 4.12
+                },
+                "up_capture": {
+                    "type": "number",
+                    "example": 91.2
+                },
+                "volatility": {
+                    "type": "number",
+                    "example": 18.4
+                },
+                "win_rate": {
+                    "type": "number",
+                    "example": 75
+                },
+                "winning_trades": {
+                    "type": "integer",
+                    "example": 6
+                }
+            }
+        },
+        "dto.MonteCarloRequest": {
+            "type": "object",
+            "properties": {
+                "method": {
+                    "type": "string",
+                    "enum": [
+                        "shuffle",
+                        "resample",
+                        "skip"
+                    ],
+                    "example": "shuffle"
+                },
+                "ruin_threshold": {
+                    "type": "number",
+                    "maximum": 1,
+                    "example": 0.5
+                },
+                "seed": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "simulations": {
+                    "type": "integer",
+                    "maximum": 10000,
+                    "minimum": 1,
+                    "example": 1000
+                },
+                "skip_probability": {
+                    "type": "number",
+                    "example": 0.1
+                }
+            }
+        },
+        "dto.MonteCarloResponse": {
+            "type": "object",
+            "properties": {
+                "backtest_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "final_capital": {
+                    "$ref": "#/definitions/dto.DistributionResponse"
+                },
+                "max_drawdown": {
+                    "$ref": "#/definitions/dto.DistributionResponse"
+                },
+                "method": {
+                    "type": "string",
+                    "example": "SHUFFLE"
+                },
+                "probability_of_ruin": {
+                    "type": "number",
+                    "example": 0.012
+                },
+                "ruin_threshold": {
+                    "type": "number",
+                    "example": 0.5
+                },
+                "seed": {
+                    "type": "integer",
+                    "example": 42
                 },
-                "page": {
+                "sharpe_ratio": {
+                    "$ref": "#/definitions/dto.DistributionResponse"
+                },
+                "simulations": {
+                    "type": "integer",
+                    "example": 1000
+                },
+                "trades": {
+                    "type": "integer",
+                    "example": 48
+                }
+            }
+        },
+        "dto.OptimizationResponse": {
+            "type": "object",
+            "properties": {
+                "combinations": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "completed": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "completed_at": {
+                    "type": "string",
+                    "example": "2025-01-15T10:35:00Z"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-01-15T10:30:00Z"
+                },
+                "end_date": {
+                    "type": "string",
+                    "example": "2024-12-31"
+                },
+                "error_message": {
+                    "type": "string",
+                    "example": "all 12 backtests failed"
+                },
+                "failed": {
                     "type": "integer",
                     "example": 1
                 },
-                "total": {
+                "grid": {
+                    "type": "object"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "objective": {
+                    "type": "string",
+                    "example": "SHARPE"
+                },
+                "skipped": {
                     "type": "integer",
-                    "example": 42
+                    "example": 3
+                },
+                "start_date": {
+                    "type": "string",
+                    "example": "2024-01-01"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "RUNNING"
+                },
+                "strategy_id": {
+                    "type": "string",
+                    "example": "sma_crossover"
+                },
+                "symbols": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "AAPL",
+                        "MSFT"
+                    ]
                 }
             }
         },
-        "dto.MetricsResponse": {
+        "dto.OptimizationResultResponse": {
             "type": "object",
             "properties": {
                 "backtest_id": {
                     "type": "string",
                     "example": "123e4567-e89b-12d3-a456-426614174000"
                 },
-                "losing_trades": {
+                "error": {
+                    "type": "string",
+                    "example": "strategy error on 2024-03-01"
+                },
+                "metrics": {
+                    "$ref": "#/definitions/dto.MetricsResponse"
+                },
+                "parameters": {
+                    "type": "object"
+                },
+                "rank": {
                     "type": "integer",
-                    "example": 2
+                    "example": 1
                 },
-                "max_drawdown": {
+                "score": {
                     "type": "number",
-                    "example": 15.5
+                    "example": 1.82
                 },
-                "profit_factor": {
+                "status": {
+                    "type": "string",
+                    "example": "COMPLETED"
+                }
+            }
+        },
+        "dto.ParameterRange": {
+            "type": "object",
+            "properties": {
+                "max": {
                     "type": "number",
-                    "example": 4.33
+                    "example": 50
                 },
-                "return_pct": {
+                "min": {
                     "type": "number",
-                    "example": 25
+                    "example": 10
                 },
-                "sharpe_ratio": {
+                "step": {
                     "type": "number",
-                    "example": 1.82
+                    "example": 10
                 },
-                "total_return": {
+                "values": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    },
+                    "example": [
+                        10,
+                        20,
+                        30
+                    ]
+                }
+            }
+        },
+        "dto.ParameterResponse": {
+            "type": "object",
+            "properties": {
+                "default": {
                     "type": "number",
-                    "example": 2500
+                    "example": 10
                 },
-                "total_trades": {
-                    "type": "integer",
-                    "example": 8
+                "description": {
+                    "type": "string",
+                    "example": "bars in the fast moving average"
                 },
-                "win_rate": {
+                "max": {
                     "type": "number",
-                    "example": 75
+                    "example": 200
                 },
-                "winning_trades": {
+                "min": {
+                    "type": "number",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "short_period"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "INT"
+                }
+            }
+        },
+        "dto.PeriodReturnResponse": {
+            "type": "object",
+            "properties": {
+                "period": {
+                    "type": "string",
+                    "example": "2024-03"
+                },
+                "return": {
+                    "type": "number",
+                    "example": 4.2
+                }
+            }
+        },
+        "dto.ReturnsResponse": {
+            "type": "object",
+            "properties": {
+                "backtest_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "best": {
+                    "$ref": "#/definitions/dto.PeriodReturnResponse"
+                },
+                "columns": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Jan",
+                        "Feb",
+                        "Mar"
+                    ]
+                },
+                "period": {
+                    "type": "string",
+                    "example": "monthly"
+                },
+                "positive_pct": {
+                    "type": "number",
+                    "example": 58.33
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ReturnsRowResponse"
+                    }
+                },
+                "worst": {
+                    "$ref": "#/definitions/dto.PeriodReturnResponse"
+                },
+                "year_to_date": {
+                    "type": "number",
+                    "example": 6.1
+                }
+            }
+        },
+        "dto.ReturnsRowResponse": {
+            "type": "object",
+            "properties": {
+                "total": {
+                    "type": "number",
+                    "example": 14
+                },
+                "values": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "year": {
                     "type": "integer",
-                    "example": 6
+                    "example": 2024
+                }
+            }
+        },
+        "dto.StrategyResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-01-15T10:30:00Z"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Goes long when the fast moving average crosses above the slow one"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "sma_crossover"
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "SIGNAL"
+                },
+                "language": {
+                    "description": "set for uploaded strategies only",
+                    "type": "string",
+                    "example": "PYTHON"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "SMA Crossover"
+                },
+                "parameters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ParameterResponse"
+                    }
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 1
                 }
             }
         },
@@ -427,6 +2522,159 @@ const docTemplate = `{
                     "example": "Backtest created successfully"
                 }
             }
+        },
+        "dto.UnderwaterPointResponse": {
+            "type": "object",
+            "properties": {
+                "drawdown": {
+                    "type": "number",
+                    "example": 15.5
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2024-03-20T00:00:00Z"
+                }
+            }
+        },
+        "dto.WalkForwardResponse": {
+            "type": "object",
+            "properties": {
+                "anchored": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "backtest_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "combinations": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "completed_at": {
+                    "type": "string",
+                    "example": "2025-01-15T10:35:00Z"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-01-15T10:30:00Z"
+                },
+                "efficiency": {
+                    "type": "number",
+                    "example": 0.62
+                },
+                "end_date": {
+                    "type": "string",
+                    "example": "2024-12-31"
+                },
+                "error_message": {
+                    "type": "string",
+                    "example": "window 3: no parameter combination completed in sample"
+                },
+                "grid": {
+                    "type": "object"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "in_sample_days": {
+                    "type": "integer",
+                    "example": 365
+                },
+                "objective": {
+                    "type": "string",
+                    "example": "SHARPE"
+                },
+                "out_of_sample_days": {
+                    "type": "integer",
+                    "example": 90
+                },
+                "start_date": {
+                    "type": "string",
+                    "example": "2020-01-01"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "COMPLETED"
+                },
+                "strategy_id": {
+                    "type": "string",
+                    "example": "sma_crossover"
+                },
+                "symbols": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "AAPL",
+                        "MSFT"
+                    ]
+                },
+                "windows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.WalkForwardWindowResponse"
+                    }
+                }
+            }
+        },
+        "dto.WalkForwardWindowResponse": {
+            "type": "object",
+            "properties": {
+                "efficiency": {
+                    "type": "number",
+                    "example": 0.62
+                },
+                "evaluated": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "in_sample_end": {
+                    "type": "string",
+                    "example": "2020-12-31"
+                },
+                "in_sample_return": {
+                    "type": "number",
+                    "example": 24.5
+                },
+                "in_sample_score": {
+                    "type": "number",
+                    "example": 1.9
+                },
+                "in_sample_start": {
+                    "type": "string",
+                    "example": "2020-01-01"
+                },
+                "index": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "out_of_sample_end": {
+                    "type": "string",
+                    "example": "2021-03-31"
+                },
+                "out_of_sample_return": {
+                    "type": "number",
+                    "example": 15.2
+                },
+                "out_of_sample_score": {
+                    "type": "number",
+                    "example": 1.1
+                },
+                "out_of_sample_start": {
+                    "type": "string",
+                    "example": "2020-12-31"
+                },
+                "out_of_sample_trades": {
+                    "type": "integer",
+                    "example": 8
+                },
+                "parameters": {
+                    "type": "object"
+                }
+            }
         }
     }
 }`
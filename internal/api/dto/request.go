@@ -2,15 +2,101 @@ package dto
 
 import (
 	"fmt"
+	"strings"
 	"time"
+
+	"github.com/wreckitral/distributed-backtesting-platform/internal/common"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
 )
 
 type CreateBacktestRequest struct {
-	StrategyID     string  `json:"strategy_id" binding:"required" example:"buy_hold"`
-	Symbol         string  `json:"symbol" binding:"required" example:"AAPL"`
-	StartDate      string  `json:"start_date" binding:"required" example:"2024-01-01"`
-	EndDate        string  `json:"end_date" binding:"required" example:"2024-12-31"`
-	InitialCapital float64 `json:"initial_capital" binding:"required,gt=0" example:"10000"`
+	StrategyID     string         `json:"strategy_id" binding:"required" example:"sma_crossover"`
+	Parameters     map[string]any `json:"parameters,omitempty" swaggertype:"object"`
+	Symbol         string         `json:"symbol,omitempty" binding:"required_without=Symbols,omitempty,max=10" example:"AAPL"`
+	Symbols        []string       `json:"symbols,omitempty" binding:"required_without=Symbol,omitempty,dive,required,max=10" example:"AAPL,MSFT"`
+	StartDate      string         `json:"start_date" binding:"required" example:"2024-01-01"`
+	EndDate        string         `json:"end_date" binding:"required" example:"2024-12-31"`
+	InitialCapital float64        `json:"initial_capital" binding:"required,gt=0" example:"10000"`
+	FillModel      string         `json:"fill_model,omitempty" binding:"omitempty,oneof=same_bar_close next_bar_open next_bar_vwap next_bar_close" example:"next_bar_open"`
+
+	// trading costs, both default to none
+	CommissionModel string  `json:"commission_model,omitempty" binding:"omitempty,oneof=none fixed per_share bps tiered" example:"bps"`
+	CommissionRate  float64 `json:"commission_rate,omitempty" binding:"gte=0" example:"10"`
+	SlippageModel   string  `json:"slippage_model,omitempty" binding:"omitempty,oneof=none fixed_bps volatility" example:"fixed_bps"`
+	SlippageRate    float64 `json:"slippage_rate,omitempty" binding:"gte=0" example:"5"`
+
+	// short selling, margins are fractions of the short notional and default
+	// to common.DefaultInitialMargin and common.DefaultMaintenanceMargin
+	AllowShort        bool    `json:"allow_short,omitempty" example:"true"`
+	InitialMargin     float64 `json:"initial_margin,omitempty" binding:"gte=0,lte=1" example:"0.5"`
+	MaintenanceMargin float64 `json:"maintenance_margin,omitempty" binding:"gte=0,lte=1" example:"0.3"`
+	BorrowRate        float64 `json:"borrow_rate,omitempty" binding:"gte=0" example:"0.02"`
+
+	// position sizing for signals, defaults to all_in. the value is shares,
+	// dollars, percent of equity, percent risked per ATR, annual volatility
+	// target or Kelly fraction depending on the method
+	SizingMethod   string  `json:"sizing_method,omitempty" binding:"omitempty,oneof=all_in fixed_shares fixed_notional percent_equity atr volatility_target kelly" example:"percent_equity"`
+	SizingValue    float64 `json:"sizing_value,omitempty" binding:"gte=0" example:"50"`
+	SizingLookback int     `json:"sizing_lookback,omitempty" binding:"gte=0" example:"20"`
+
+	// rebalancing of target weight strategies, defaults to monthly. drift
+	// rebalances once a weight is rebalance_threshold away from its target
+	RebalanceFrequency string  `json:"rebalance_frequency,omitempty" binding:"omitempty,oneof=daily weekly monthly drift" example:"monthly"`
+	RebalanceThreshold float64 `json:"rebalance_threshold,omitempty" binding:"gte=0,lte=1" example:"0.05"`
+	MinTradeShares     float64 `json:"min_trade_shares,omitempty" binding:"gte=0" example:"1"`
+	MinTradeNotional   float64 `json:"min_trade_notional,omitempty" binding:"gte=0" example:"100"`
+
+	// splits and dividends, defaults to apply which trades raw prices and pays
+	// dividends in cash. adjust trades back-adjusted prices instead
+	CorporateActions  string `json:"corporate_actions,omitempty" binding:"omitempty,oneof=apply adjust ignore" example:"apply"`
+	ReinvestDividends bool   `json:"reinvest_dividends,omitempty" example:"false"`
+
+	// annual risk-free rate as a fraction, subtracted from the returns by
+	// the sharpe, sortino and omega ratios
+	RiskFreeRate float64 `json:"risk_free_rate,omitempty" binding:"gte=0,lt=1" example:"0.04"`
+
+	// symbol bought and held to compare the returns with, its daily CSV
+	// has to be in the data directory. defaults to the configured benchmark,
+	// or the first symbol traded without one
+	Benchmark string `json:"benchmark,omitempty" binding:"omitempty,max=10" example:"SPY"`
+}
+
+// ParseBenchmark picks the benchmark of the request, falling back to the
+// configured one and then to buying and holding the first symbol
+func ParseBenchmark(req CreateBacktestRequest, fallback string, symbols []string) string {
+	if benchmark := strings.ToUpper(strings.TrimSpace(req.Benchmark)); benchmark != "" {
+		return benchmark
+	}
+	if fallback != "" || len(symbols) == 0 {
+		return fallback
+	}
+	return symbols[0]
+}
+
+// ParseSymbols merges symbol and symbols into the backtest universe, keeping
+// the order they were given in and dropping duplicates
+func ParseSymbols(req CreateBacktestRequest) ([]string, error) {
+	all := req.Symbols
+	if req.Symbol != "" {
+		all = append([]string{req.Symbol}, all...)
+	}
+
+	seen := make(map[string]bool, len(all))
+	symbols := make([]string, 0, len(all))
+	for _, symbol := range all {
+		symbol = strings.ToUpper(strings.TrimSpace(symbol))
+		if symbol == "" || seen[symbol] {
+			continue
+		}
+		seen[symbol] = true
+		symbols = append(symbols, symbol)
+	}
+
+	if len(symbols) == 0 {
+		return nil, fmt.Errorf("at least one symbol is required")
+	}
+
+	return symbols, nil
 }
 
 func ParseBacktestDates(startDateStr, endDateStr string) (time.Time, time.Time, error) {
@@ -32,3 +118,254 @@ func ParseBacktestDates(startDateStr, endDateStr string) (time.Time, time.Time,
 
 	return startDate, endDate, nil
 }
+
+// ParseFillModel maps the request fill model, empty means next bar open
+func ParseFillModel(s string) (domain.FillModel, error) {
+	switch s {
+	case "", "next_bar_open":
+		return domain.FillModelNextBarOpen, nil
+	case "same_bar_close":
+		return domain.FillModelSameBarClose, nil
+	case "next_bar_vwap":
+		return domain.FillModelNextBarVWAP, nil
+	case "next_bar_close":
+		return domain.FillModelNextBarClose, nil
+	default:
+		return domain.FillModelNextBarOpen, fmt.Errorf("unknown fill_model: %s", s)
+	}
+}
+
+// ParseCostSettings maps the request cost models, an omitted model charges
+// nothing
+func ParseCostSettings(req CreateBacktestRequest) (domain.CostSettings, error) {
+	costs := domain.CostSettings{
+		CommissionRate: req.CommissionRate,
+		SlippageRate:   req.SlippageRate,
+	}
+
+	switch req.CommissionModel {
+	case "", "none":
+		costs.CommissionModel = domain.CommissionModelNone
+	case "fixed":
+		costs.CommissionModel = domain.CommissionModelFixed
+	case "per_share":
+		costs.CommissionModel = domain.CommissionModelPerShare
+	case "bps":
+		costs.CommissionModel = domain.CommissionModelBps
+	case "tiered":
+		costs.CommissionModel = domain.CommissionModelTiered
+	default:
+		return costs, fmt.Errorf("unknown commission_model: %s", req.CommissionModel)
+	}
+
+	switch req.SlippageModel {
+	case "", "none":
+		costs.SlippageModel = domain.SlippageModelNone
+	case "fixed_bps":
+		costs.SlippageModel = domain.SlippageModelFixedBps
+	case "volatility":
+		costs.SlippageModel = domain.SlippageModelVolatility
+	default:
+		return costs, fmt.Errorf("unknown slippage_model: %s", req.SlippageModel)
+	}
+
+	return costs, nil
+}
+
+// ParseMarginSettings maps the request short selling settings, filling in the
+// default margins when they are not given
+func ParseMarginSettings(req CreateBacktestRequest) (domain.MarginSettings, error) {
+	margin := domain.MarginSettings{
+		AllowShort:        req.AllowShort,
+		InitialMargin:     req.InitialMargin,
+		MaintenanceMargin: req.MaintenanceMargin,
+		BorrowRate:        req.BorrowRate,
+	}
+
+	if margin.InitialMargin == 0 {
+		margin.InitialMargin = common.DefaultInitialMargin
+	}
+	if margin.MaintenanceMargin == 0 {
+		margin.MaintenanceMargin = common.DefaultMaintenanceMargin
+	}
+
+	if margin.MaintenanceMargin > margin.InitialMargin {
+		return margin, fmt.Errorf("maintenance_margin cannot exceed initial_margin")
+	}
+
+	return margin, nil
+}
+
+// ParseSizingSettings maps the request position sizing, every method except
+// all_in needs a positive sizing_value
+func ParseSizingSettings(req CreateBacktestRequest) (domain.SizingSettings, error) {
+	sizing := domain.SizingSettings{
+		Value:    req.SizingValue,
+		Lookback: req.SizingLookback,
+	}
+
+	switch req.SizingMethod {
+	case "", "all_in":
+		sizing.Method = domain.SizingMethodAllIn
+		return sizing, nil
+	case "fixed_shares":
+		sizing.Method = domain.SizingMethodFixedShares
+	case "fixed_notional":
+		sizing.Method = domain.SizingMethodFixedNotional
+	case "percent_equity":
+		sizing.Method = domain.SizingMethodPercentEquity
+	case "atr":
+		sizing.Method = domain.SizingMethodATR
+	case "volatility_target":
+		sizing.Method = domain.SizingMethodVolatilityTarget
+	case "kelly":
+		sizing.Method = domain.SizingMethodKelly
+	default:
+		return sizing, fmt.Errorf("unknown sizing_method: %s", req.SizingMethod)
+	}
+
+	if sizing.Value <= 0 {
+		return sizing, fmt.Errorf("sizing_value must be positive for %s", req.SizingMethod)
+	}
+
+	return sizing, nil
+}
+
+// ParseRebalanceSettings maps the request rebalance schedule, drift needs a
+// positive rebalance_threshold
+func ParseRebalanceSettings(req CreateBacktestRequest) (domain.RebalanceSettings, error) {
+	rebalance := domain.RebalanceSettings{
+		DriftThreshold: req.RebalanceThreshold,
+		MinShares:      req.MinTradeShares,
+		MinNotional:    req.MinTradeNotional,
+	}
+
+	switch req.RebalanceFrequency {
+	case "", "monthly":
+		rebalance.Frequency = domain.RebalanceFrequencyMonthly
+	case "weekly":
+		rebalance.Frequency = domain.RebalanceFrequencyWeekly
+	case "daily":
+		rebalance.Frequency = domain.RebalanceFrequencyDaily
+	case "drift":
+		rebalance.Frequency = domain.RebalanceFrequencyDrift
+		if rebalance.DriftThreshold <= 0 {
+			return rebalance, fmt.Errorf("rebalance_threshold must be positive for drift")
+		}
+	default:
+		return rebalance, fmt.Errorf("unknown rebalance_frequency: %s", req.RebalanceFrequency)
+	}
+
+	return rebalance, nil
+}
+
+// ParseCorporateActionSettings maps the request corporate action handling,
+// dividends can only be reinvested when they are paid
+func ParseCorporateActionSettings(req CreateBacktestRequest) (domain.CorporateActionSettings, error) {
+	actions := domain.CorporateActionSettings{ReinvestDividends: req.ReinvestDividends}
+
+	switch req.CorporateActions {
+	case "", "apply":
+		actions.Mode = domain.CorporateActionModeApply
+	case "adjust":
+		actions.Mode = domain.CorporateActionModeAdjust
+	case "ignore":
+		actions.Mode = domain.CorporateActionModeIgnore
+	default:
+		return actions, fmt.Errorf("unknown corporate_actions: %s", req.CorporateActions)
+	}
+
+	if actions.ReinvestDividends && actions.Mode != domain.CorporateActionModeApply {
+		return actions, fmt.Errorf("reinvest_dividends requires corporate_actions apply")
+	}
+
+	return actions, nil
+}
+
+type CreateStrategyRequest struct {
+	Name        string `json:"name" binding:"required,max=255" example:"Momentum"`
+	Description string `json:"description,omitempty" example:"Buys after three rising closes"`
+	Language    string `json:"language" binding:"required,oneof=python go wasm rules" example:"python"`
+	Code        string `json:"code" binding:"required" example:"def on_bar(ctx):\n    return 'BUY'"` // base64 encoded module for wasm, a YAML or JSON rule set for rules
+}
+
+// ParseStrategy maps the request to a strategy. the code itself is checked by
+// the sandbox before the strategy is stored
+func ParseStrategy(req CreateStrategyRequest) (*domain.Strategy, error) {
+	s := &domain.Strategy{
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Code:        req.Code,
+	}
+
+	switch req.Language {
+	case "python":
+		s.Language = domain.StrategyLanguagePython
+	case "go":
+		s.Language = domain.StrategyLanguageGo
+	case "wasm":
+		s.Language = domain.StrategyLanguageWasm
+	case "rules":
+		s.Language = domain.StrategyLanguageRules
+	default:
+		return nil, fmt.Errorf("unknown language: %s", req.Language)
+	}
+
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// ParameterRange is the values one parameter takes in a grid search, either
+// an explicit list of values or a min, max and step
+type ParameterRange struct {
+	Values []any    `json:"values,omitempty" swaggertype:"array,number" example:"10,20,30"`
+	Min    *float64 `json:"min,omitempty" example:"10"`
+	Max    *float64 `json:"max,omitempty" example:"50"`
+	Step   *float64 `json:"step,omitempty" example:"10"`
+}
+
+// CreateOptimizationRequest runs the backtest settings once for every
+// combination of the grid. Parameters holds the values that stay fixed
+type CreateOptimizationRequest struct {
+	CreateBacktestRequest
+	Grid      map[string]ParameterRange `json:"grid" binding:"required"`
+	Objective string                    `json:"objective,omitempty" binding:"omitempty,oneof=sharpe total_return max_drawdown profit_factor" example:"sharpe"`
+}
+
+// ParseObjective maps the request objective, empty means sharpe
+func ParseObjective(s string) (domain.OptimizationObjective, error) {
+	switch s {
+	case "", "sharpe":
+		return domain.OptimizationObjectiveSharpe, nil
+	case "total_return":
+		return domain.OptimizationObjectiveTotalReturn, nil
+	case "max_drawdown":
+		return domain.OptimizationObjectiveMaxDrawdown, nil
+	case "profit_factor":
+		return domain.OptimizationObjectiveProfitFactor, nil
+	default:
+		return 0, fmt.Errorf("unknown objective: %s", s)
+	}
+}
+
+// CreateWalkForwardRequest optimizes the grid on every in-sample window and
+// trades the winner on the out-of-sample window after it
+type CreateWalkForwardRequest struct {
+	CreateOptimizationRequest
+	InSampleDays    int  `json:"in_sample_days" binding:"required,min=1" example:"365"`
+	OutOfSampleDays int  `json:"out_of_sample_days" binding:"required,min=1" example:"90"`
+	Anchored        bool `json:"anchored,omitempty" example:"false"`
+}
+
+// MonteCarloRequest configures a Monte Carlo analysis of the trades of a
+// completed backtest, every field is optional
+type MonteCarloRequest struct {
+	Simulations     int     `json:"simulations,omitempty" binding:"omitempty,min=1,max=10000" example:"1000"`
+	Method          string  `json:"method,omitempty" binding:"omitempty,oneof=shuffle resample skip" example:"shuffle"`
+	SkipProbability float64 `json:"skip_probability,omitempty" binding:"omitempty,gt=0,lt=1" example:"0.1"`
+	RuinThreshold   float64 `json:"ruin_threshold,omitempty" binding:"omitempty,gt=0,lte=1" example:"0.5"`
+	Seed            uint64  `json:"seed,omitempty" example:"42"`
+}
@@ -1,22 +1,49 @@
 package dto
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/diagnostics"
 	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/metrics"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/optimize"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/strategy"
 )
 
 type BacktestResponse struct {
-	ID             uuid.UUID `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
-	StrategyID     string    `json:"strategy_id" example:"SMA Crossover"`
-	Symbol         string    `json:"symbol" example:"AAPL"`
-	StartDate      string    `json:"start_date" example:"2024-01-01"`
-	EndDate        string    `json:"end_date" example:"2024-12-31"`
-	InitialCapital float64   `json:"initial_capital" example:"10000"`
-	Status         string    `json:"status" example:"completed"`
-	CreatedAt      time.Time `json:"created_at" example:"2025-01-15T10:30:00Z"`
-	UpdatedAt      time.Time `json:"updated_at" example:"2025-01-15T10:35:00Z"`
+	ID             uuid.UUID      `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	StrategyID     string         `json:"strategy_id" example:"sma_crossover"`
+	Parameters     map[string]any `json:"parameters" swaggertype:"object"`
+	Symbols        []string       `json:"symbols" example:"AAPL,MSFT"`
+	StartDate      string         `json:"start_date" example:"2024-01-01"`
+	EndDate        string         `json:"end_date" example:"2024-12-31"`
+	InitialCapital float64        `json:"initial_capital" example:"10000"`
+	FillModel      string         `json:"fill_model" example:"NEXT_BAR_OPEN"`
+	Commission     string         `json:"commission_model" example:"BPS"`
+	CommissionRate float64        `json:"commission_rate" example:"10"`
+	Slippage       string         `json:"slippage_model" example:"FIXED_BPS"`
+	SlippageRate   float64        `json:"slippage_rate" example:"5"`
+	AllowShort     bool           `json:"allow_short" example:"true"`
+	InitialMargin  float64        `json:"initial_margin" example:"0.5"`
+	Maintenance    float64        `json:"maintenance_margin" example:"0.3"`
+	BorrowRate     float64        `json:"borrow_rate" example:"0.02"`
+	SizingMethod   string         `json:"sizing_method" example:"PERCENT_EQUITY"`
+	SizingValue    float64        `json:"sizing_value" example:"50"`
+	SizingLookback int            `json:"sizing_lookback" example:"20"`
+	Rebalance      string         `json:"rebalance_frequency" example:"MONTHLY"`
+	RebalanceDrift float64        `json:"rebalance_threshold" example:"0.05"`
+	MinShares      float64        `json:"min_trade_shares" example:"1"`
+	MinNotional    float64        `json:"min_trade_notional" example:"100"`
+	Actions        string         `json:"corporate_actions" example:"APPLY"`
+	Reinvest       bool           `json:"reinvest_dividends" example:"false"`
+	RiskFreeRate   float64        `json:"risk_free_rate" example:"0.04"`
+	Benchmark      string         `json:"benchmark" example:"SPY"`
+	Status         string         `json:"status" example:"completed"`
+	OptimizationID *uuid.UUID     `json:"optimization_id,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"`
+	CreatedAt      time.Time      `json:"created_at" example:"2025-01-15T10:30:00Z"`
+	UpdatedAt      time.Time      `json:"updated_at" example:"2025-01-15T10:35:00Z"`
 }
 
 type MetricsResponse struct {
@@ -30,16 +57,124 @@ type MetricsResponse struct {
 	SharpeRatio   float64   `json:"sharpe_ratio" example:"1.82"`
 	MaxDrawdown   float64   `json:"max_drawdown" example:"15.50"`
 	ProfitFactor  float64   `json:"profit_factor" example:"4.33"`
+	Volatility    float64   `json:"volatility" example:"18.40"`
+	SortinoRatio  float64   `json:"sortino_ratio" example:"2.41"`
+	CalmarRatio   float64   `json:"calmar_ratio" example:"1.61"`
+	OmegaRatio    float64   `json:"omega_ratio" example:"1.35"`
+	Skewness      float64   `json:"skewness" example:"-0.21"`
+	Kurtosis      float64   `json:"kurtosis" example:"1.87"`
+	TailRatio     float64   `json:"tail_ratio" example:"1.05"`
+	DrawdownDays  int       `json:"max_drawdown_duration" example:"47"`
+	UlcerIndex    float64   `json:"ulcer_index" example:"4.12"`
+	AvgBarsHeld   float64   `json:"avg_bars_held" example:"12.5"`
+	WinStreak     int       `json:"max_win_streak" example:"4"`
+	LossStreak    int       `json:"max_loss_streak" example:"2"`
+	AvgMAE        float64   `json:"avg_mae" example:"-3.10"`
+	AvgMFE        float64   `json:"avg_mfe" example:"6.20"`
+	EdgeRatio     float64   `json:"edge_ratio" example:"2.00"`
+	BenchReturn   float64   `json:"benchmark_return" example:"18.20"`
+	ExcessReturn  float64   `json:"excess_return" example:"6.80"`
+	Alpha         float64   `json:"alpha" example:"4.35"`
+	Beta          float64   `json:"beta" example:"0.82"`
+	Correlation   float64   `json:"correlation" example:"0.74"`
+	TrackingError float64   `json:"tracking_error" example:"9.60"`
+	InfoRatio     float64   `json:"information_ratio" example:"0.58"`
+	UpCapture     float64   `json:"up_capture" example:"91.20"`
+	DownCapture   float64   `json:"down_capture" example:"76.50"`
+}
+
+type DrawdownResponse struct {
+	PeakDate     time.Time  `json:"peak_date" example:"2024-03-01T00:00:00Z"`
+	TroughDate   time.Time  `json:"trough_date" example:"2024-03-20T00:00:00Z"`
+	RecoveryDate *time.Time `json:"recovery_date,omitempty" example:"2024-04-17T00:00:00Z"`
+	PeakEquity   float64    `json:"peak_equity" example:"11200.00"`
+	TroughEquity float64    `json:"trough_equity" example:"9464.00"`
+	Depth        float64    `json:"depth" example:"15.50"`
+	LengthDays   int        `json:"length_days" example:"47"`
+}
+
+type UnderwaterPointResponse struct {
+	Timestamp time.Time `json:"timestamp" example:"2024-03-20T00:00:00Z"`
+	Drawdown  float64   `json:"drawdown" example:"15.50"`
+}
+
+// DrawdownsResponse is the drawdown analysis of a backtest, depths and
+// drawdowns are percent below the running peak
+type DrawdownsResponse struct {
+	BacktestID      uuid.UUID                 `json:"backtest_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	MaxDrawdown     float64                   `json:"max_drawdown" example:"15.50"`
+	MaxDuration     int                       `json:"max_drawdown_duration" example:"47"`
+	AverageDrawdown float64                   `json:"average_drawdown" example:"3.20"`
+	TimeUnderWater  float64                   `json:"time_under_water" example:"62.50"`
+	UlcerIndex      float64                   `json:"ulcer_index" example:"4.12"`
+	Drawdowns       []DrawdownResponse        `json:"drawdowns"`
+	Underwater      []UnderwaterPointResponse `json:"underwater"`
 }
 
 type TradeResponse struct {
-	ID        uuid.UUID `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
-	Symbol    string    `json:"symbol" example:"AAPL"`
-	Direction string    `json:"direction" example:"BUY"`
-	Quantity  float64   `json:"quantity" example:"54.79"`
-	Price     float64   `json:"price" example:"182.50"`
-	PnL       float64   `json:"pnl" example:"1000.00"`
-	Timestamp time.Time `json:"timestamp" example:"2024-01-15T09:30:00Z"`
+	ID         uuid.UUID `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Symbol     string    `json:"symbol" example:"AAPL"`
+	Direction  string    `json:"direction" example:"BUY"`
+	Quantity   float64   `json:"quantity" example:"54.79"`
+	Price      float64   `json:"price" example:"182.50"`
+	Commission float64   `json:"commission" example:"10.00"`
+	Slippage   float64   `json:"slippage" example:"5.00"`
+	BorrowFee  float64   `json:"borrow_fee" example:"0.00"`
+	Dividends  float64   `json:"dividends" example:"12.50"`
+	PnL        float64   `json:"pnl" example:"1000.00"`
+	Timestamp  time.Time `json:"timestamp" example:"2024-01-15T09:30:00Z"`
+}
+
+// RoundTripResponse is the part of a position closed by one exit, return,
+// mae and mfe are percent of the entry price
+type RoundTripResponse struct {
+	Symbol     string    `json:"symbol" example:"AAPL"`
+	Side       string    `json:"side" example:"LONG"`
+	EntryTime  time.Time `json:"entry_time" example:"2024-01-15T09:30:00Z"`
+	ExitTime   time.Time `json:"exit_time" example:"2024-02-01T09:30:00Z"`
+	EntryPrice float64   `json:"entry_price" example:"182.50"`
+	ExitPrice  float64   `json:"exit_price" example:"191.20"`
+	Quantity   float64   `json:"quantity" example:"54.79"`
+	PnL        float64   `json:"pnl" example:"466.67"`
+	ReturnPct  float64   `json:"return_pct" example:"4.67"`
+	BarsHeld   int       `json:"bars_held" example:"12"`
+	MAE        float64   `json:"mae" example:"-2.30"`
+	MFE        float64   `json:"mfe" example:"6.10"`
+}
+
+type PeriodReturnResponse struct {
+	Period string  `json:"period" example:"2024-03"`
+	Return float64 `json:"return" example:"4.20"`
+}
+
+// ReturnsRowResponse is one year of the returns matrix, values line up with
+// the columns and are null for periods the backtest did not cover
+type ReturnsRowResponse struct {
+	Year   int        `json:"year" example:"2024"`
+	Values []*float64 `json:"values"`
+	Total  float64    `json:"total" example:"14.00"`
+}
+
+// ReturnsResponse is the calendar returns of a backtest as a year by period
+// matrix for heatmaps, returns are percent
+type ReturnsResponse struct {
+	BacktestID uuid.UUID             `json:"backtest_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Period     string                `json:"period" example:"monthly"`
+	Columns    []string              `json:"columns" example:"Jan,Feb,Mar"`
+	Rows       []ReturnsRowResponse  `json:"rows"`
+	Best       *PeriodReturnResponse `json:"best,omitempty"`
+	Worst      *PeriodReturnResponse `json:"worst,omitempty"`
+	Positive   float64               `json:"positive_pct" example:"58.33"`
+	YearToDate float64               `json:"year_to_date" example:"6.10"`
+}
+
+type EquityPointResponse struct {
+	Timestamp     time.Time `json:"timestamp" example:"2024-01-15T00:00:00Z"`
+	Equity        float64   `json:"equity" example:"10250.00"`
+	Cash          float64   `json:"cash" example:"250.00"`
+	PositionValue float64   `json:"position_value" example:"10000.00"`
+	GrossExposure float64   `json:"gross_exposure" example:"0.98"`
+	NetExposure   float64   `json:"net_exposure" example:"0.98"`
 }
 
 type ErrorResponse struct {
@@ -63,11 +198,33 @@ func FromDomainBacktest(b *domain.Backtest) BacktestResponse {
 	return BacktestResponse{
 		ID:             b.ID,
 		StrategyID:     b.StrategyID,
-		Symbol:         b.Symbol,
+		Parameters:     b.Parameters,
+		Symbols:        b.Symbols,
 		StartDate:      b.StartDate.Format("2006-01-02"),
 		EndDate:        b.EndDate.Format("2006-01-02"),
 		InitialCapital: b.InitialCapital,
+		FillModel:      b.FillModel.String(),
+		Commission:     b.Costs.CommissionModel.String(),
+		CommissionRate: b.Costs.CommissionRate,
+		Slippage:       b.Costs.SlippageModel.String(),
+		SlippageRate:   b.Costs.SlippageRate,
+		AllowShort:     b.Margin.AllowShort,
+		InitialMargin:  b.Margin.InitialMargin,
+		Maintenance:    b.Margin.MaintenanceMargin,
+		BorrowRate:     b.Margin.BorrowRate,
+		SizingMethod:   b.Sizing.Method.String(),
+		SizingValue:    b.Sizing.Value,
+		SizingLookback: b.Sizing.Lookback,
+		Rebalance:      b.Rebalance.Frequency.String(),
+		RebalanceDrift: b.Rebalance.DriftThreshold,
+		MinShares:      b.Rebalance.MinShares,
+		MinNotional:    b.Rebalance.MinNotional,
+		Actions:        b.Actions.Mode.String(),
+		Reinvest:       b.Actions.ReinvestDividends,
+		RiskFreeRate:   b.RiskFreeRate,
+		Benchmark:      b.Benchmark,
 		Status:         b.Status.String(),
+		OptimizationID: b.OptimizationID,
 		CreatedAt:      b.CreatedAt,
 		UpdatedAt:      b.UpdatedAt,
 	}
@@ -75,13 +232,17 @@ func FromDomainBacktest(b *domain.Backtest) BacktestResponse {
 
 func FromDomainTrade(t *domain.Trade) TradeResponse {
 	return TradeResponse{
-		ID:        t.ID,
-		Symbol:    t.Symbol,
-		Direction: t.Direction.String(),
-		Quantity:  t.Quantity,
-		Price:     t.Price,
-		PnL:       t.PnL,
-		Timestamp: t.Timestamp,
+		ID:         t.ID,
+		Symbol:     t.Symbol,
+		Direction:  t.Direction.String(),
+		Quantity:   t.Quantity,
+		Price:      t.Price,
+		Commission: t.Commission,
+		Slippage:   t.Slippage,
+		BorrowFee:  t.BorrowFee,
+		Dividends:  t.Dividends,
+		PnL:        t.PnL,
+		Timestamp:  t.Timestamp,
 	}
 }
 
@@ -97,5 +258,451 @@ func FromDomainMetrics(m *domain.Metrics) MetricsResponse {
 		SharpeRatio:   m.SharpeRatio,
 		MaxDrawdown:   m.MaxDrawdown * 100, // Convert to percentage if stored as decimal
 		ProfitFactor:  m.ProfitFactor,
+		Volatility:    m.Volatility,
+		SortinoRatio:  m.SortinoRatio,
+		CalmarRatio:   m.CalmarRatio,
+		OmegaRatio:    m.OmegaRatio,
+		Skewness:      m.Skewness,
+		Kurtosis:      m.Kurtosis,
+		TailRatio:     m.TailRatio,
+		DrawdownDays:  m.MaxDrawdownDuration,
+		UlcerIndex:    m.UlcerIndex,
+		AvgBarsHeld:   m.AvgBarsHeld,
+		WinStreak:     m.MaxWinStreak,
+		LossStreak:    m.MaxLossStreak,
+		AvgMAE:        m.AvgMAE,
+		AvgMFE:        m.AvgMFE,
+		EdgeRatio:     m.EdgeRatio,
+		BenchReturn:   m.BenchmarkReturn,
+		ExcessReturn:  m.ExcessReturn,
+		Alpha:         m.Alpha,
+		Beta:          m.Beta,
+		Correlation:   m.Correlation,
+		TrackingError: m.TrackingError,
+		InfoRatio:     m.InformationRatio,
+		UpCapture:     m.UpCapture,
+		DownCapture:   m.DownCapture,
+	}
+}
+
+func FromDrawdowns(m *domain.Metrics, drawdowns []domain.Drawdown, underwater []metrics.UnderwaterPoint) DrawdownsResponse {
+	response := DrawdownsResponse{
+		BacktestID:      m.BacktestID,
+		MaxDrawdown:     m.MaxDrawdown,
+		MaxDuration:     m.MaxDrawdownDuration,
+		AverageDrawdown: m.AvgDrawdown,
+		TimeUnderWater:  m.TimeUnderWater,
+		UlcerIndex:      m.UlcerIndex,
+		Drawdowns:       make([]DrawdownResponse, len(drawdowns)),
+		Underwater:      make([]UnderwaterPointResponse, len(underwater)),
+	}
+
+	for i, d := range drawdowns {
+		response.Drawdowns[i] = DrawdownResponse{
+			PeakDate:     d.PeakDate,
+			TroughDate:   d.TroughDate,
+			RecoveryDate: d.RecoveryDate,
+			PeakEquity:   d.PeakEquity,
+			TroughEquity: d.TroughEquity,
+			Depth:        d.Depth,
+			LengthDays:   d.Length,
+		}
+	}
+	for i, p := range underwater {
+		response.Underwater[i] = UnderwaterPointResponse{Timestamp: p.Timestamp, Drawdown: p.Drawdown}
+	}
+
+	return response
+}
+
+func FromDomainRoundTrip(r *domain.RoundTrip) RoundTripResponse {
+	return RoundTripResponse{
+		Symbol:     r.Symbol,
+		Side:       r.Side.String(),
+		EntryTime:  r.EntryTime,
+		ExitTime:   r.ExitTime,
+		EntryPrice: r.EntryPrice,
+		ExitPrice:  r.ExitPrice,
+		Quantity:   r.Quantity,
+		PnL:        r.PnL,
+		ReturnPct:  r.ReturnPct,
+		BarsHeld:   r.BarsHeld,
+		MAE:        r.MAE,
+		MFE:        r.MFE,
+	}
+}
+
+// FromCalendarReturns lays out monthly or yearly returns as a matrix with a
+// row per year, yearly returns have a single column
+func FromCalendarReturns(backtestID uuid.UUID, period string, returns, yearly []metrics.PeriodReturn, yearToDate float64) ReturnsResponse {
+	response := ReturnsResponse{
+		BacktestID: backtestID,
+		Period:     period,
+		Columns:    []string{"Year"},
+		Rows:       make([]ReturnsRowResponse, 0, len(yearly)),
+		YearToDate: yearToDate,
+	}
+	monthly := period == "monthly"
+	if monthly {
+		response.Columns = make([]string, 12)
+		for m := range response.Columns {
+			response.Columns[m] = time.Month(m + 1).String()[:3]
+		}
+	}
+
+	rows := make(map[int]int, len(yearly))
+	for _, y := range yearly {
+		rows[y.Year] = len(response.Rows)
+		response.Rows = append(response.Rows, ReturnsRowResponse{
+			Year:   y.Year,
+			Values: make([]*float64, len(response.Columns)),
+			Total:  y.Return,
+		})
+	}
+	for _, r := range returns {
+		column := 0
+		if monthly {
+			column = int(r.Month) - 1
+		}
+		value := r.Return
+		response.Rows[rows[r.Year]].Values[column] = &value
+	}
+
+	if len(returns) > 0 {
+		summary := metrics.SummarizeReturns(returns)
+		best, worst := periodReturnResponse(summary.Best), periodReturnResponse(summary.Worst)
+		response.Best, response.Worst = &best, &worst
+		response.Positive = summary.Positive
+	}
+
+	return response
+}
+
+func periodReturnResponse(r metrics.PeriodReturn) PeriodReturnResponse {
+	label := fmt.Sprintf("%d", r.Year)
+	if r.Month != 0 {
+		label = fmt.Sprintf("%d-%02d", r.Year, int(r.Month))
+	}
+	return PeriodReturnResponse{Period: label, Return: r.Return}
+}
+
+func FromDomainEquityPoint(p *domain.EquityCurve) EquityPointResponse {
+	return EquityPointResponse{
+		Timestamp:     p.Timestamp,
+		Equity:        p.Equity,
+		Cash:          p.Cash,
+		PositionValue: p.PositionValue,
+		GrossExposure: p.GrossExposure,
+		NetExposure:   p.NetExposure,
+	}
+}
+
+type StrategyResponse struct {
+	ID          string              `json:"id" example:"sma_crossover"`
+	Name        string              `json:"name" example:"SMA Crossover"`
+	Description string              `json:"description" example:"Goes long when the fast moving average crosses above the slow one"`
+	Kind        string              `json:"kind" example:"SIGNAL"`
+	Parameters  []ParameterResponse `json:"parameters"`
+
+	// set for uploaded strategies only
+	Language  string     `json:"language,omitempty" example:"PYTHON"`
+	Code      string     `json:"code,omitempty"`
+	Version   int        `json:"version,omitempty" example:"1"`
+	CreatedAt *time.Time `json:"created_at,omitempty" example:"2025-01-15T10:30:00Z"`
+}
+
+type ParameterResponse struct {
+	Name        string   `json:"name" example:"short_period"`
+	Type        string   `json:"type" example:"INT"`
+	Description string   `json:"description" example:"bars in the fast moving average"`
+	Default     any      `json:"default" swaggertype:"number" example:"10"`
+	Min         *float64 `json:"min,omitempty" example:"1"`
+	Max         *float64 `json:"max,omitempty" example:"200"`
+}
+
+func FromStrategyDefinition(def strategy.Definition) StrategyResponse {
+	params := make([]ParameterResponse, len(def.Params))
+	for i, spec := range def.Params {
+		params[i] = ParameterResponse{
+			Name:        spec.Name,
+			Type:        spec.Type.String(),
+			Description: spec.Description,
+			Default:     spec.Default,
+			Min:         spec.Min,
+			Max:         spec.Max,
+		}
+	}
+
+	return StrategyResponse{
+		ID:          def.ID,
+		Name:        def.Name,
+		Description: def.Description,
+		Kind:        def.Kind(),
+		Parameters:  params,
+	}
+}
+
+// FromDomainStrategy converts an uploaded strategy, its parameters are free
+// form so no schema is listed
+func FromDomainStrategy(s *domain.Strategy, includeCode bool) StrategyResponse {
+	resp := StrategyResponse{
+		ID:          s.ID.String(),
+		Name:        s.Name,
+		Description: s.Description,
+		Kind:        "SIGNAL",
+		Parameters:  []ParameterResponse{},
+		Language:    s.Language.String(),
+		Version:     s.Version,
+		CreatedAt:   &s.CreatedAt,
+	}
+	if includeCode {
+		resp.Code = s.Code
+	}
+
+	return resp
+}
+
+type OptimizationResponse struct {
+	ID           uuid.UUID        `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	StrategyID   string           `json:"strategy_id" example:"sma_crossover"`
+	Symbols      []string         `json:"symbols" example:"AAPL,MSFT"`
+	StartDate    string           `json:"start_date" example:"2024-01-01"`
+	EndDate      string           `json:"end_date" example:"2024-12-31"`
+	Grid         map[string][]any `json:"grid" swaggertype:"object"`
+	Objective    string           `json:"objective" example:"SHARPE"`
+	Status       string           `json:"status" example:"RUNNING"`
+	Combinations int              `json:"combinations" example:"12"`
+	Skipped      int              `json:"skipped" example:"3"`
+	Completed    int              `json:"completed" example:"7"`
+	Failed       int              `json:"failed" example:"1"`
+	ErrorMessage string           `json:"error_message,omitempty" example:"all 12 backtests failed"`
+	CreatedAt    time.Time        `json:"created_at" example:"2025-01-15T10:30:00Z"`
+	CompletedAt  *time.Time       `json:"completed_at,omitempty" example:"2025-01-15T10:35:00Z"`
+}
+
+// OptimizationResultResponse is one row of the ranked table, Rank is 0 and
+// Metrics empty for backtests that did not complete
+type OptimizationResultResponse struct {
+	Rank       int              `json:"rank" example:"1"`
+	BacktestID uuid.UUID        `json:"backtest_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Parameters map[string]any   `json:"parameters" swaggertype:"object"`
+	Status     string           `json:"status" example:"COMPLETED"`
+	Score      float64          `json:"score" example:"1.82"`
+	Metrics    *MetricsResponse `json:"metrics,omitempty"`
+	Error      string           `json:"error,omitempty" example:"strategy error on 2024-03-01"`
+}
+
+// FromDomainOptimization converts the optimization with the progress of its
+// child backtests
+func FromDomainOptimization(o *domain.Optimization, children []*domain.Backtest) OptimizationResponse {
+	response := OptimizationResponse{
+		ID:           o.ID,
+		StrategyID:   o.StrategyID,
+		Symbols:      o.Symbols,
+		StartDate:    o.StartDate.Format("2006-01-02"),
+		EndDate:      o.EndDate.Format("2006-01-02"),
+		Grid:         o.Grid,
+		Objective:    o.Objective.String(),
+		Status:       o.Status.String(),
+		Combinations: o.Combinations,
+		Skipped:      o.Skipped,
+		ErrorMessage: o.ErrorMessage,
+		CreatedAt:    o.CreatedAt,
+		CompletedAt:  o.CompletedAt,
+	}
+
+	for _, child := range children {
+		switch child.Status {
+		case domain.BacktestStatusCompleted:
+			response.Completed++
+		case domain.BacktestStatusFailed:
+			response.Failed++
+		}
+	}
+
+	return response
+}
+
+func FromRankedBacktest(r optimize.Ranked) OptimizationResultResponse {
+	response := OptimizationResultResponse{
+		Rank:       r.Rank,
+		BacktestID: r.Backtest.ID,
+		Parameters: r.Backtest.Parameters,
+		Status:     r.Backtest.Status.String(),
+		Score:      r.Score,
+		Error:      r.Backtest.ErrorMessage,
+	}
+
+	if r.Metrics != nil {
+		metrics := FromDomainMetrics(r.Metrics)
+		response.Metrics = &metrics
+	}
+
+	return response
+}
+
+type WalkForwardResponse struct {
+	ID              uuid.UUID                   `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	StrategyID      string                      `json:"strategy_id" example:"sma_crossover"`
+	Symbols         []string                    `json:"symbols" example:"AAPL,MSFT"`
+	StartDate       string                      `json:"start_date" example:"2020-01-01"`
+	EndDate         string                      `json:"end_date" example:"2024-12-31"`
+	InSampleDays    int                         `json:"in_sample_days" example:"365"`
+	OutOfSampleDays int                         `json:"out_of_sample_days" example:"90"`
+	Anchored        bool                        `json:"anchored" example:"false"`
+	Grid            map[string][]any            `json:"grid" swaggertype:"object"`
+	Objective       string                      `json:"objective" example:"SHARPE"`
+	Status          string                      `json:"status" example:"COMPLETED"`
+	Combinations    int                         `json:"combinations" example:"12"`
+	BacktestID      *uuid.UUID                  `json:"backtest_id,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Efficiency      float64                     `json:"efficiency" example:"0.62"`
+	Windows         []WalkForwardWindowResponse `json:"windows,omitempty"`
+	ErrorMessage    string                      `json:"error_message,omitempty" example:"window 3: no parameter combination completed in sample"`
+	CreatedAt       time.Time                   `json:"created_at" example:"2025-01-15T10:30:00Z"`
+	CompletedAt     *time.Time                  `json:"completed_at,omitempty" example:"2025-01-15T10:35:00Z"`
+}
+
+// WalkForwardWindowResponse is the parameter report of one window, end
+// dates are exclusive and returns annualized percentages
+type WalkForwardWindowResponse struct {
+	Index             int            `json:"index" example:"1"`
+	InSampleStart     string         `json:"in_sample_start" example:"2020-01-01"`
+	InSampleEnd       string         `json:"in_sample_end" example:"2020-12-31"`
+	OutOfSampleStart  string         `json:"out_of_sample_start" example:"2020-12-31"`
+	OutOfSampleEnd    string         `json:"out_of_sample_end" example:"2021-03-31"`
+	Parameters        map[string]any `json:"parameters" swaggertype:"object"`
+	Evaluated         int            `json:"evaluated" example:"12"`
+	InSampleScore     float64        `json:"in_sample_score" example:"1.9"`
+	OutOfSampleScore  float64        `json:"out_of_sample_score" example:"1.1"`
+	InSampleReturn    float64        `json:"in_sample_return" example:"24.5"`
+	OutOfSampleReturn float64        `json:"out_of_sample_return" example:"15.2"`
+	OutOfSampleTrades int            `json:"out_of_sample_trades" example:"8"`
+	Efficiency        float64        `json:"efficiency" example:"0.62"`
+}
+
+func FromDomainWalkForward(w *domain.WalkForward, windows []domain.WalkForwardWindow) WalkForwardResponse {
+	response := WalkForwardResponse{
+		ID:              w.ID,
+		StrategyID:      w.StrategyID,
+		Symbols:         w.Symbols,
+		StartDate:       w.StartDate.Format("2006-01-02"),
+		EndDate:         w.EndDate.Format("2006-01-02"),
+		InSampleDays:    w.InSampleDays,
+		OutOfSampleDays: w.OutOfSampleDays,
+		Anchored:        w.Anchored,
+		Grid:            w.Grid,
+		Objective:       w.Objective.String(),
+		Status:          w.Status.String(),
+		Combinations:    w.Combinations,
+		BacktestID:      w.BacktestID,
+		Efficiency:      w.Efficiency,
+		ErrorMessage:    w.ErrorMessage,
+		CreatedAt:       w.CreatedAt,
+		CompletedAt:     w.CompletedAt,
+	}
+
+	for _, window := range windows {
+		response.Windows = append(response.Windows, WalkForwardWindowResponse{
+			Index:             window.Index,
+			InSampleStart:     window.InSampleStart.Format("2006-01-02"),
+			InSampleEnd:       window.InSampleEnd.Format("2006-01-02"),
+			OutOfSampleStart:  window.OutOfSampleStart.Format("2006-01-02"),
+			OutOfSampleEnd:    window.OutOfSampleEnd.Format("2006-01-02"),
+			Parameters:        window.Parameters,
+			Evaluated:         window.Evaluated,
+			InSampleScore:     window.InSampleScore,
+			OutOfSampleScore:  window.OutOfSampleScore,
+			InSampleReturn:    window.InSampleReturn,
+			OutOfSampleReturn: window.OutOfSampleReturn,
+			OutOfSampleTrades: window.OutOfSampleTrades,
+			Efficiency:        window.Efficiency,
+		})
+	}
+
+	return response
+}
+
+type DistributionResponse struct {
+	Mean   float64 `json:"mean" example:"12450.00"`
+	StdDev float64 `json:"std_dev" example:"830.00"`
+	Min    float64 `json:"min" example:"9800.00"`
+	P5     float64 `json:"p5" example:"11050.00"`
+	P25    float64 `json:"p25" example:"11900.00"`
+	Median float64 `json:"median" example:"12480.00"`
+	P75    float64 `json:"p75" example:"13010.00"`
+	P95    float64 `json:"p95" example:"13800.00"`
+	Max    float64 `json:"max" example:"15200.00"`
+}
+
+// MonteCarloResponse is the spread of final capital, max drawdown and
+// sharpe over the simulations
+type MonteCarloResponse struct {
+	BacktestID        uuid.UUID            `json:"backtest_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Method            string               `json:"method" example:"SHUFFLE"`
+	Simulations       int                  `json:"simulations" example:"1000"`
+	Trades            int                  `json:"trades" example:"48"`
+	Seed              uint64               `json:"seed" example:"42"`
+	FinalCapital      DistributionResponse `json:"final_capital"`
+	MaxDrawdown       DistributionResponse `json:"max_drawdown"`
+	SharpeRatio       DistributionResponse `json:"sharpe_ratio"`
+	RuinThreshold     float64              `json:"ruin_threshold" example:"0.5"`
+	ProbabilityOfRuin float64              `json:"probability_of_ruin" example:"0.012"`
+}
+
+func FromMonteCarloResult(backtestID uuid.UUID, cfg metrics.MonteCarloConfig, r *metrics.MonteCarloResult) MonteCarloResponse {
+	return MonteCarloResponse{
+		BacktestID:        backtestID,
+		Method:            cfg.Method.String(),
+		Simulations:       r.Simulations,
+		Trades:            r.Trades,
+		Seed:              cfg.Seed,
+		FinalCapital:      fromDistribution(r.FinalCapital),
+		MaxDrawdown:       fromDistribution(r.MaxDrawdown),
+		SharpeRatio:       fromDistribution(r.SharpeRatio),
+		RuinThreshold:     cfg.RuinThreshold,
+		ProbabilityOfRuin: r.ProbabilityOfRuin,
+	}
+}
+
+func fromDistribution(d metrics.Distribution) DistributionResponse {
+	return DistributionResponse(d)
+}
+
+// DiagnosticsResponse is the overfitting diagnostics of a grid search,
+// Sharpe ratios are per bar
+type DiagnosticsResponse struct {
+	OptimizationID      uuid.UUID      `json:"optimization_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Trials              int            `json:"trials" example:"48"`
+	BestBacktestID      uuid.UUID      `json:"best_backtest_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	BestParameters      map[string]any `json:"best_parameters" swaggertype:"object"`
+	Sharpe              float64        `json:"sharpe" example:"0.09"`
+	SharpeVariance      float64        `json:"sharpe_variance" example:"0.0012"`
+	ExpectedMaxSharpe   float64        `json:"expected_max_sharpe" example:"0.078"`
+	ProbabilisticSharpe float64        `json:"probabilistic_sharpe" example:"0.97"`
+	DeflatedSharpe      float64        `json:"deflated_sharpe" example:"0.61"`
+	PBO                 float64        `json:"pbo" example:"0.27"`
+	PBOSplits           int            `json:"pbo_splits" example:"12870"`
+	PBOPartitions       int            `json:"pbo_partitions" example:"16"`
+	MeanLogit           float64        `json:"mean_logit" example:"0.84"`
+	Degradation         float64        `json:"degradation" example:"-0.031"`
+}
+
+func FromDiagnosticsReport(optimizationID uuid.UUID, trials []*domain.Backtest, r *diagnostics.Report) DiagnosticsResponse {
+	best := trials[r.Best]
+	return DiagnosticsResponse{
+		OptimizationID:      optimizationID,
+		Trials:              r.Trials,
+		BestBacktestID:      best.ID,
+		BestParameters:      best.Parameters,
+		Sharpe:              r.Sharpe,
+		SharpeVariance:      r.SharpeVariance,
+		ExpectedMaxSharpe:   r.ExpectedMaxSharpe,
+		ProbabilisticSharpe: r.ProbabilisticSharpe,
+		DeflatedSharpe:      r.DeflatedSharpe,
+		PBO:                 r.PBO.Probability,
+		PBOSplits:           r.PBO.Splits,
+		PBOPartitions:       r.PBO.Partitions,
+		MeanLogit:           r.PBO.MeanLogit,
+		Degradation:         r.PBO.Degradation,
 	}
 }
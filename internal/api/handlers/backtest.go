@@ -2,9 +2,12 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"math"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -14,16 +17,27 @@ import (
 	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
 	"github.com/wreckitral/distributed-backtesting-platform/internal/marketdata"
 	"github.com/wreckitral/distributed-backtesting-platform/internal/metrics"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/optimize"
 	"github.com/wreckitral/distributed-backtesting-platform/internal/repository"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/sandbox"
 	"github.com/wreckitral/distributed-backtesting-platform/internal/strategy"
 )
 
 type BacktestHandler struct {
-	backtestRepo repository.BacktestRepository
-	tradeRepo    repository.TradeRepository
-	metricsRepo  repository.MetricsRepository
-	provider     marketdata.Provider
-	validate     *validator.Validate
+	backtestRepo  repository.BacktestRepository
+	tradeRepo     repository.TradeRepository
+	metricsRepo   repository.MetricsRepository
+	equityRepo    repository.EquityRepository
+	drawdownRepo  repository.DrawdownRepository
+	roundTripRepo repository.RoundTripRepository
+	provider      marketdata.Provider
+	actions       marketdata.CorporateActionSource
+	registry      *strategy.Registry
+	strategyRepo  repository.StrategyRepository
+	loader        *sandbox.Loader
+	benchmark     string        // compared with when a request names none, empty for the first symbol
+	workers       chan struct{} // one token per backtest allowed to run at once
+	validate      *validator.Validate
 }
 
 // NewBacktestHandler creates a new backtest handler
@@ -31,106 +45,276 @@ func NewBacktestHandler(
 	backtestRepo repository.BacktestRepository,
 	tradeRepo repository.TradeRepository,
 	metricsRepo repository.MetricsRepository,
+	equityRepo repository.EquityRepository,
+	drawdownRepo repository.DrawdownRepository,
+	roundTripRepo repository.RoundTripRepository,
 	provider marketdata.Provider,
+	actions marketdata.CorporateActionSource,
+	registry *strategy.Registry,
+	strategyRepo repository.StrategyRepository,
+	loader *sandbox.Loader,
+	benchmark string,
+	workers int,
 ) *BacktestHandler {
 	return &BacktestHandler{
-		backtestRepo: backtestRepo,
-		tradeRepo:    tradeRepo,
-		metricsRepo:  metricsRepo,
-		provider:     provider,
-		validate:     validator.New(),
+		backtestRepo:  backtestRepo,
+		tradeRepo:     tradeRepo,
+		metricsRepo:   metricsRepo,
+		equityRepo:    equityRepo,
+		drawdownRepo:  drawdownRepo,
+		roundTripRepo: roundTripRepo,
+		provider:      provider,
+		actions:       actions,
+		registry:      registry,
+		strategyRepo:  strategyRepo,
+		loader:        loader,
+		benchmark:     benchmark,
+		workers:       make(chan struct{}, max(workers, 1)),
+		validate:      validator.New(),
 	}
 }
 
+// buildStrategy looks up a registered strategy and creates it from the raw
+// parameters, one of the returned strategies is nil. ids that are not
+// registered are loaded as uploaded strategies and get the raw parameters
+func (h *BacktestHandler) buildStrategy(ctx context.Context, id string, raw map[string]any) (strategy.Strategy, strategy.WeightStrategy, error) {
+	def, ok := h.registry.Get(id)
+	if !ok {
+		uploaded, err := h.uploadedStrategy(ctx, id)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		strat, err := h.loader.Load(uploaded, raw)
+		return strat, nil, err
+	}
+
+	params, err := def.Validate(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return def.Build(params)
+}
+
+// registeredParams validates raw parameters against the schema of a
+// registered strategy and builds it once so constructor errors surface
+// before anything runs
+func registeredParams(def strategy.Definition, raw map[string]any) (map[string]any, error) {
+	validated, err := def.Validate(raw)
+	if err == nil {
+		_, _, err = def.Build(validated)
+	}
+	return validated, err
+}
+
+// combinations expands the grid over the fixed parameters and validates
+// every combination against the strategy schema. combinations the strategy
+// rejects, like a short period above the long one, are skipped and counted
+func (h *BacktestHandler) combinations(ctx context.Context, strategyID string, fixed map[string]any, grid map[string][]any) ([]map[string]any, int, error) {
+	expanded, err := optimize.Expand(grid, fixed)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	def, ok := h.registry.Get(strategyID)
+	if !ok {
+		// uploaded strategies check their own parameters when they run
+		if _, err := h.uploadedStrategy(ctx, strategyID); err != nil {
+			return nil, 0, err
+		}
+		return expanded, 0, nil
+	}
+
+	valid := make([]map[string]any, 0, len(expanded))
+	var firstErr error
+	for _, raw := range expanded {
+		params, err := registeredParams(def, raw)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%v: %w", raw, err)
+			}
+			continue
+		}
+		valid = append(valid, params)
+	}
+
+	if len(valid) == 0 {
+		return nil, 0, fmt.Errorf("no valid parameter combination, first error: %w", firstErr)
+	}
+
+	return valid, len(expanded) - len(valid), nil
+}
+
+func (h *BacktestHandler) uploadedStrategy(ctx context.Context, id string) (*domain.Strategy, error) {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("unknown strategy: %s", id)
+	}
+
+	uploaded, err := h.strategyRepo.GetByID(ctx, parsed)
+	if err != nil {
+		return nil, fmt.Errorf("unknown strategy %s: %w", id, err)
+	}
+
+	return uploaded, nil
+}
+
+// runQueued waits for a free worker and then runs the backtest, so at most
+// the worker pool size of backtests execute at once
+func (h *BacktestHandler) runQueued(backtest *domain.Backtest) {
+	h.workers <- struct{}{}
+	defer func() { <-h.workers }()
+
+	h.executeBacktest(backtest)
+}
+
+// simulateQueued waits for a free worker and then simulates the backtest
+func (h *BacktestHandler) simulateQueued(ctx context.Context, backtest *domain.Backtest) (*strategy.Result, error) {
+	h.workers <- struct{}{}
+	defer func() { <-h.workers }()
+
+	return h.simulate(ctx, backtest)
+}
+
+// simulate builds the strategy and runs it over the settings of the
+// backtest without saving anything
+func (h *BacktestHandler) simulate(ctx context.Context, backtest *domain.Backtest) (*strategy.Result, error) {
+	strat, weights, err := h.buildStrategy(ctx, backtest.StrategyID, backtest.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	// uploaded strategies hold an interpreter process until closed
+	if closer, ok := strat.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	// execute the strategy
+	opts := []strategy.ExecutorOption{
+		strategy.WithFillModel(strategy.NewFillModel(backtest.FillModel)),
+		strategy.WithCommission(strategy.NewCommissionModel(backtest.Costs.CommissionModel, backtest.Costs.CommissionRate)),
+		strategy.WithSlippage(strategy.NewSlippageModel(backtest.Costs.SlippageModel, backtest.Costs.SlippageRate)),
+		strategy.WithSizer(strategy.NewSizer(backtest.Sizing)),
+	}
+
+	provider := h.provider
+	switch backtest.Actions.Mode {
+	case domain.CorporateActionModeApply:
+		opts = append(opts, strategy.WithCorporateActions(h.actions, backtest.Actions.ReinvestDividends))
+	case domain.CorporateActionModeAdjust:
+		provider = marketdata.NewAdjustedProvider(h.provider, h.actions)
+	}
+
+	if backtest.Margin.AllowShort {
+		opts = append(opts, strategy.WithShortSelling(strategy.MarginConfig{
+			InitialMargin:     backtest.Margin.InitialMargin,
+			MaintenanceMargin: backtest.Margin.MaintenanceMargin,
+			BorrowRate:        backtest.Margin.BorrowRate,
+		}))
+	}
+	executor := strategy.NewExecutor(strat, provider, backtest.InitialCapital, opts...)
+	if weights != nil {
+		opts = append(opts, strategy.WithRebalance(strategy.NewRebalanceConfig(backtest.Rebalance)))
+		executor = strategy.NewWeightExecutor(weights, provider, backtest.InitialCapital, opts...)
+	}
+	return executor.SimulatePortfolio(ctx, backtest.Symbols, backtest.StartDate, backtest.EndDate)
+}
+
 func (h *BacktestHandler) executeBacktest(backtest *domain.Backtest) {
 	ctx := context.Background()
 
 	backtest.Status = domain.BacktestStatusRunning
 	h.backtestRepo.Update(ctx, backtest)
 
-	var strat strategy.Strategy
-	switch backtest.StrategyID {
-	case "buy_hold":
-		strat = strategy.NewBuyHold()
-	case "sma_crossover":
-		strat = strategy.NewSMACrossover(10, 30)
-	case "sma_crossover_20_50":
-		strat = strategy.NewSMACrossover(20, 50)
-	default:
+	result, err := h.simulate(ctx, backtest)
+	if err != nil {
 		backtest.Status = domain.BacktestStatusFailed
-		backtest.ErrorMessage = fmt.Sprintf("Unknown strategy: %s", backtest.StrategyID)
+		backtest.ErrorMessage = err.Error()
 		h.backtestRepo.Update(ctx, backtest)
 		return
 	}
-
-	// execute the strategy
-	executor := strategy.NewExecutor(strat, h.provider, backtest.InitialCapital)
-	trades, err := executor.Run(ctx, backtest.Symbol, backtest.StartDate, backtest.EndDate)
-	if err != nil {
+	if err := h.saveResult(ctx, backtest, result); err != nil {
 		backtest.Status = domain.BacktestStatusFailed
 		backtest.ErrorMessage = err.Error()
 		h.backtestRepo.Update(ctx, backtest)
 		return
 	}
 
+	// update backtest status
+	now := time.Now()
+	backtest.Status = domain.BacktestStatusCompleted
+	backtest.CompletedAt = &now
+	backtest.ErrorMessage = ""
+	h.backtestRepo.Update(ctx, backtest)
+}
+
+// saveResult stores the trades, equity curve and metrics of a finished run
+// under the backtest
+func (h *BacktestHandler) saveResult(ctx context.Context, backtest *domain.Backtest, result *strategy.Result) error {
+	trades := result.Trades
+
 	// save trades
 	for i := range trades {
 		trades[i].BacktestID = backtest.ID
 		if err := h.tradeRepo.Create(ctx, &trades[i]); err != nil {
-			backtest.Status = domain.BacktestStatusFailed
-			backtest.ErrorMessage = fmt.Sprintf("Failed to save trade: %v", err)
-			h.backtestRepo.Update(ctx, backtest)
-			return
+			return fmt.Errorf("Failed to save trade: %w", err)
 		}
 	}
 
+	// save the equity curve
+	if err := h.equityRepo.CreateBatch(ctx, backtest.ID, result.EquityCurve); err != nil {
+		return fmt.Errorf("Failed to save equity curve: %w", err)
+	}
+
+	// buy and hold the benchmark with splits and dividends folded into the
+	// prices, whatever the backtest does with corporate actions
+	var benchmark []domain.Bar
+	if backtest.Benchmark != "" {
+		bars, err := marketdata.NewAdjustedProvider(h.provider, h.actions).
+			GetBars(ctx, backtest.Benchmark, backtest.StartDate, backtest.EndDate)
+		if err != nil {
+			return fmt.Errorf("Failed to load benchmark: %w", err)
+		}
+		benchmark = bars
+	}
+
 	// calculate metrics
-	calculator := metrics.NewCalculator(backtest.InitialCapital)
-	results, err := calculator.Calculate(trades, backtest.StartDate, backtest.EndDate)
+	calculator := metrics.NewCalculator(
+		backtest.InitialCapital,
+		metrics.WithRiskFreeRate(backtest.RiskFreeRate),
+		metrics.WithBars(result.Bars),
+		metrics.WithBenchmark(benchmark),
+	)
+	results, err := calculator.CalculateWithEquity(trades, result.EquityCurve, backtest.StartDate, backtest.EndDate)
 	if err != nil {
-		backtest.Status = domain.BacktestStatusFailed
-		backtest.ErrorMessage = fmt.Sprintf("Failed to calculate metrics: %v", err)
-		h.backtestRepo.Update(ctx, backtest)
-		return
+		return fmt.Errorf("Failed to calculate metrics: %w", err)
 	}
 
-	// calculate annualized return: ((1 + return_rate)^(365/days) - 1) * 100
-	annualizedReturn := 0.0
-	if results.Duration > 0 {
-		returnRate := results.ReturnPct / 100.0
-		annualizedReturn = (math.Pow(1+returnRate, 365.0/float64(results.Duration)) - 1) * 100
+	// save metrics
+	metricsEntity := results.ToDomain(backtest.ID)
+	if err := h.metricsRepo.Create(ctx, metricsEntity); err != nil {
+		return fmt.Errorf("Failed to save metrics: %w", err)
 	}
 
-	// save metrics
-	metricsEntity := &domain.Metrics{
-		ID:               uuid.New(),
-		BacktestID:       backtest.ID,
-		TotalReturn:      results.TotalReturn,
-		AnnualizedReturn: annualizedReturn,
-		SharpeRatio:      results.SharpeRatio,
-		MaxDrawdown:      results.MaxDrawdown,
-		WinRate:          results.WinRate,
-		TotalTrades:      results.TotalTrades,
-		WinningTrades:    results.WinningTrades,
-		LosingTrades:     results.LosingTrades,
-		ProfitFactor:     results.ProfitFactor(),
-		AvgWin:           results.AverageWin,
-		AvgLoss:          results.AverageLoss,
+	// save the drawdown episodes
+	drawdowns := make([]domain.Drawdown, len(results.Drawdowns))
+	for i, d := range results.Drawdowns {
+		drawdowns[i] = d.ToDomain(backtest.ID, i)
+	}
+	if err := h.drawdownRepo.CreateBatch(ctx, drawdowns); err != nil {
+		return fmt.Errorf("Failed to save drawdowns: %w", err)
 	}
 
-	if err := h.metricsRepo.Create(ctx, metricsEntity); err != nil {
-		backtest.Status = domain.BacktestStatusFailed
-		backtest.ErrorMessage = fmt.Sprintf("Failed to save metrics: %v", err)
-		h.backtestRepo.Update(ctx, backtest)
-		return
+	// save the round trips
+	trips := make([]domain.RoundTrip, len(results.RoundTrips))
+	for i, trip := range results.RoundTrips {
+		trips[i] = trip.ToDomain(backtest.ID, i)
+	}
+	if err := h.roundTripRepo.CreateBatch(ctx, trips); err != nil {
+		return fmt.Errorf("Failed to save round trips: %w", err)
 	}
 
-	// update backtest status
-	now := time.Now()
-	backtest.Status = domain.BacktestStatusCompleted
-	backtest.CompletedAt = &now
-	backtest.ErrorMessage = ""
-	h.backtestRepo.Update(ctx, backtest)
+	return nil
 }
 
 // CreateBacktest godoc
@@ -166,29 +350,36 @@ func (h *BacktestHandler) CreateBacktest(c *gin.Context) {
 		return
 	}
 
-	// parse dates
-	startDate, endDate, err := dto.ParseBacktestDates(req.StartDate, req.StartDate)
-	if err != nil {
+	ctx := context.Background()
+
+	// uploaded strategies take free form parameters, they are only checked
+	// by the user code once the backtest runs
+	params := req.Parameters
+	if def, ok := h.registry.Get(req.StrategyID); ok {
+		validated, err := registeredParams(def, req.Parameters)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Invalid strategy parameters",
+				Message: err.Error(),
+			})
+			return
+		}
+		params = validated
+	} else if _, err := h.uploadedStrategy(ctx, req.StrategyID); err != nil {
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid date format",
-			Message: "Use YYYY-MM-DD format",
+			Error:   "Unknown strategy",
+			Message: fmt.Sprintf("no strategy registered or uploaded as %s", req.StrategyID),
 		})
 		return
 	}
 
-	// create backtest domain object
-	backtest := &domain.Backtest{
-		ID:             uuid.New(),
-		StrategyID:     req.StrategyID,
-		Symbol:         req.Symbol,
-		StartDate:      startDate,
-		EndDate:        endDate,
-		InitialCapital: req.InitialCapital,
-		Status:         domain.BacktestStatusPending,
+	backtest, ok := h.newBacktest(c, req)
+	if !ok {
+		return
 	}
+	backtest.Parameters = params
 
 	// save to database
-	ctx := context.Background()
 	if err := h.backtestRepo.Create(ctx, backtest); err != nil {
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Error:   "Failed to create backtest",
@@ -198,11 +389,107 @@ func (h *BacktestHandler) CreateBacktest(c *gin.Context) {
 	}
 
 	// execute backtest asynchronously
-	go h.executeBacktest(backtest)
+	go h.runQueued(backtest)
 
 	c.JSON(http.StatusCreated, dto.FromDomainBacktest(backtest))
 }
 
+// newBacktest parses the settings shared by every backtest request into a
+// pending backtest without strategy parameters. on invalid settings it
+// writes the error response and returns false
+func (h *BacktestHandler) newBacktest(c *gin.Context, req dto.CreateBacktestRequest) (*domain.Backtest, bool) {
+	symbols, err := dto.ParseSymbols(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid symbols",
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+
+	// parse dates
+	startDate, endDate, err := dto.ParseBacktestDates(req.StartDate, req.EndDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid date format",
+			Message: "Use YYYY-MM-DD format",
+		})
+		return nil, false
+	}
+
+	fillModel, err := dto.ParseFillModel(req.FillModel)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid fill model",
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+
+	costs, err := dto.ParseCostSettings(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid cost model",
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+
+	margin, err := dto.ParseMarginSettings(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid margin settings",
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+
+	sizing, err := dto.ParseSizingSettings(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid position sizing",
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+
+	rebalance, err := dto.ParseRebalanceSettings(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid rebalance settings",
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+
+	actions, err := dto.ParseCorporateActionSettings(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid corporate action settings",
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+
+	return &domain.Backtest{
+		ID:             uuid.New(),
+		StrategyID:     req.StrategyID,
+		Symbols:        symbols,
+		StartDate:      startDate,
+		EndDate:        endDate,
+		InitialCapital: req.InitialCapital,
+		FillModel:      fillModel,
+		Costs:          costs,
+		Margin:         margin,
+		Sizing:         sizing,
+		Rebalance:      rebalance,
+		Actions:        actions,
+		RiskFreeRate:   req.RiskFreeRate,
+		Benchmark:      dto.ParseBenchmark(req, h.benchmark, symbols),
+		Status:         domain.BacktestStatusPending,
+	}, true
+}
+
 // GetBacktest godoc
 //
 //	@Summary		Get backtest by ID
@@ -303,6 +590,124 @@ func (h *BacktestHandler) GetBacktestMetrics(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// RunMonteCarlo godoc
+//
+//	@Summary		Run a Monte Carlo analysis of a backtest
+//	@Description	Replay the closed trades of a completed backtest in randomized sequences: shuffled, resampled with replacement or with trades skipped at random. reports percentiles of final capital, max drawdown and sharpe and the probability of ruin. the same seed gives the same result
+//	@Tags			backtests
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string					true	"Backtest ID"
+//	@Param			request	body		dto.MonteCarloRequest	false	"Simulation settings"
+//	@Success		200		{object}	dto.MonteCarloResponse
+//	@Failure		400		{object}	dto.ErrorResponse
+//	@Failure		404		{object}	dto.ErrorResponse
+//	@Failure		409		{object}	dto.ErrorResponse
+//	@Router			/api/v1/backtests/{id}/montecarlo [post]
+func (h *BacktestHandler) RunMonteCarlo(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid backtest ID",
+		})
+		return
+	}
+
+	// the body is optional, an empty one runs the defaults
+	var req dto.MonteCarloRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	cfg, err := monteCarloConfig(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid simulation settings",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	backtest, err := h.backtestRepo.GetByID(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: "Backtest not found",
+		})
+		return
+	}
+	if backtest.Status != domain.BacktestStatusCompleted {
+		c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Error:   "Backtest not completed",
+			Message: fmt.Sprintf("backtest is %s", backtest.Status),
+		})
+		return
+	}
+
+	stored, err := h.tradeRepo.GetByBacktestID(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to fetch trades",
+		})
+		return
+	}
+
+	trades := make([]domain.Trade, len(stored))
+	for i, trade := range stored {
+		trades[i] = *trade
+	}
+
+	calculator := metrics.NewCalculator(backtest.InitialCapital)
+	result, err := calculator.MonteCarlo(trades, backtest.StartDate, backtest.EndDate, cfg)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Monte Carlo analysis failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromMonteCarloResult(id, cfg, result))
+}
+
+// monteCarloConfig maps the request with the defaults filled in: 1000
+// shuffled simulations, a 10% skip probability and ruin at half the capital
+func monteCarloConfig(req dto.MonteCarloRequest) (metrics.MonteCarloConfig, error) {
+	cfg := metrics.MonteCarloConfig{
+		Simulations:     req.Simulations,
+		SkipProbability: req.SkipProbability,
+		RuinThreshold:   req.RuinThreshold,
+		Seed:            req.Seed,
+	}
+
+	switch req.Method {
+	case "", "shuffle":
+		cfg.Method = metrics.MonteCarloShuffle
+	case "resample":
+		cfg.Method = metrics.MonteCarloResample
+	case "skip":
+		cfg.Method = metrics.MonteCarloSkip
+	default:
+		return cfg, fmt.Errorf("unknown method: %s", req.Method)
+	}
+
+	if cfg.Simulations == 0 {
+		cfg.Simulations = 1000
+	}
+	if cfg.SkipProbability == 0 && cfg.Method == metrics.MonteCarloSkip {
+		cfg.SkipProbability = 0.1
+	}
+	if cfg.RuinThreshold == 0 {
+		cfg.RuinThreshold = 0.5
+	}
+
+	return cfg, nil
+}
+
 // GetBacktestTrades godoc
 //
 //	@Summary		Get backtest trades
@@ -310,9 +715,10 @@ func (h *BacktestHandler) GetBacktestMetrics(c *gin.Context) {
 //	@Tags			backtests
 //	@Accept			json
 //	@Produce		json
-//	@Param			id	path		string	true	"Backtest ID"
-//	@Success		200	{object}	dto.ListResponse
-//	@Failure		404	{object}	dto.ErrorResponse
+//	@Param			id		path		string	true	"Backtest ID"
+//	@Param			symbol	query		string	false	"Only trades of this symbol"
+//	@Success		200		{object}	dto.ListResponse
+//	@Failure		404		{object}	dto.ErrorResponse
 //	@Router			/api/v1/backtests/{id}/trades [get]
 func (h *BacktestHandler) GetBacktestTrades(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
@@ -332,9 +738,13 @@ func (h *BacktestHandler) GetBacktestTrades(c *gin.Context) {
 		return
 	}
 
-	responses := make([]dto.TradeResponse, len(trades))
-	for i, t := range trades {
-		responses[i] = dto.FromDomainTrade(t)
+	symbol := strings.ToUpper(c.Query("symbol"))
+
+	responses := make([]dto.TradeResponse, 0, len(trades))
+	for _, t := range trades {
+		if symbol == "" || t.Symbol == symbol {
+			responses = append(responses, dto.FromDomainTrade(t))
+		}
 	}
 
 	c.JSON(http.StatusOK, dto.ListResponse{
@@ -345,6 +755,246 @@ func (h *BacktestHandler) GetBacktestTrades(c *gin.Context) {
 	})
 }
 
+// GetBacktestReturns godoc
+//
+//	@Summary		Get backtest calendar returns
+//	@Description	Get the month by month or year by year returns of the equity curve as a matrix with a row per year, with the best and worst period, the share of positive periods and the year to date return
+//	@Tags			backtests
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string	true	"Backtest ID"
+//	@Param			period	query		string	false	"monthly or yearly, defaults to monthly"
+//	@Success		200		{object}	dto.ReturnsResponse
+//	@Failure		400		{object}	dto.ErrorResponse
+//	@Failure		404		{object}	dto.ErrorResponse
+//	@Router			/api/v1/backtests/{id}/returns [get]
+func (h *BacktestHandler) GetBacktestReturns(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid backtest ID",
+		})
+		return
+	}
+
+	period := c.DefaultQuery("period", "monthly")
+	if period != "monthly" && period != "yearly" {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid period",
+			Message: "period must be monthly or yearly",
+		})
+		return
+	}
+
+	ctx := context.Background()
+	if _, err := h.backtestRepo.GetByID(ctx, id); err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: "Backtest not found",
+		})
+		return
+	}
+
+	points, err := h.equityRepo.GetByBacktestID(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to fetch equity curve",
+		})
+		return
+	}
+
+	yearly := metrics.YearlyReturns(points)
+	returns := yearly
+	if period == "monthly" {
+		returns = metrics.MonthlyReturns(points)
+	}
+
+	c.JSON(http.StatusOK, dto.FromCalendarReturns(id, period, returns, yearly, metrics.YearToDate(points)))
+}
+
+// GetBacktestRoundTrips godoc
+//
+//	@Summary		Get backtest round trips
+//	@Description	Get every exit of a backtest paired with the position it closed, with holding period and maximum adverse and favorable excursion
+//	@Tags			backtests
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string	true	"Backtest ID"
+//	@Param			symbol	query		string	false	"Only round trips of this symbol"
+//	@Success		200		{object}	dto.ListResponse
+//	@Failure		404		{object}	dto.ErrorResponse
+//	@Router			/api/v1/backtests/{id}/round-trips [get]
+func (h *BacktestHandler) GetBacktestRoundTrips(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid backtest ID",
+		})
+		return
+	}
+
+	ctx := context.Background()
+	if _, err := h.backtestRepo.GetByID(ctx, id); err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: "Backtest not found",
+		})
+		return
+	}
+
+	trips, err := h.roundTripRepo.GetByBacktestID(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to fetch round trips",
+		})
+		return
+	}
+
+	symbol := strings.ToUpper(c.Query("symbol"))
+
+	responses := make([]dto.RoundTripResponse, 0, len(trips))
+	for i := range trips {
+		if symbol == "" || trips[i].Symbol == symbol {
+			responses = append(responses, dto.FromDomainRoundTrip(&trips[i]))
+		}
+	}
+
+	c.JSON(http.StatusOK, dto.ListResponse{
+		Items: responses,
+		Total: len(responses),
+		Page:  1,
+		Limit: len(responses),
+	})
+}
+
+// GetBacktestEquity godoc
+//
+//	@Summary		Get backtest equity curve
+//	@Description	Get the bar by bar equity curve of a backtest, optionally downsampled to max_points evenly spaced points
+//	@Tags			backtests
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string	true	"Backtest ID"
+//	@Param			max_points	query		int		false	"Maximum number of points to return"
+//	@Success		200			{object}	dto.ListResponse
+//	@Failure		400			{object}	dto.ErrorResponse
+//	@Failure		404			{object}	dto.ErrorResponse
+//	@Router			/api/v1/backtests/{id}/equity [get]
+func (h *BacktestHandler) GetBacktestEquity(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid backtest ID",
+		})
+		return
+	}
+
+	maxPoints := 0
+	if raw := c.Query("max_points"); raw != "" {
+		maxPoints, err = strconv.Atoi(raw)
+		if err != nil || maxPoints < 0 {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Invalid max_points",
+				Message: "max_points must be a non-negative integer",
+			})
+			return
+		}
+	}
+
+	ctx := context.Background()
+	if _, err := h.backtestRepo.GetByID(ctx, id); err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: "Backtest not found",
+		})
+		return
+	}
+
+	points, err := h.equityRepo.GetByBacktestID(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to fetch equity curve",
+		})
+		return
+	}
+
+	sampled := domain.DownsampleEquityCurve(points, maxPoints)
+
+	responses := make([]dto.EquityPointResponse, len(sampled))
+	for i := range sampled {
+		responses[i] = dto.FromDomainEquityPoint(&sampled[i])
+	}
+
+	c.JSON(http.StatusOK, dto.ListResponse{
+		Items: responses,
+		Total: len(points),
+		Page:  1,
+		Limit: len(responses),
+	})
+}
+
+// GetBacktestDrawdowns godoc
+//
+//	@Summary		Get backtest drawdowns
+//	@Description	Get every drawdown episode of the marked-to-market equity curve with its peak, trough and recovery, the drawdown summary and the underwater curve, optionally downsampled to max_points evenly spaced points
+//	@Tags			backtests
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string	true	"Backtest ID"
+//	@Param			max_points	query		int		false	"Maximum number of underwater points to return"
+//	@Success		200			{object}	dto.DrawdownsResponse
+//	@Failure		400			{object}	dto.ErrorResponse
+//	@Failure		404			{object}	dto.ErrorResponse
+//	@Router			/api/v1/backtests/{id}/drawdowns [get]
+func (h *BacktestHandler) GetBacktestDrawdowns(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid backtest ID",
+		})
+		return
+	}
+
+	maxPoints := 0
+	if raw := c.Query("max_points"); raw != "" {
+		maxPoints, err = strconv.Atoi(raw)
+		if err != nil || maxPoints < 0 {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Invalid max_points",
+				Message: "max_points must be a non-negative integer",
+			})
+			return
+		}
+	}
+
+	ctx := context.Background()
+	metricsEntity, err := h.metricsRepo.GetByBacktestID(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: "Metrics not found",
+		})
+		return
+	}
+
+	drawdowns, err := h.drawdownRepo.GetByBacktestID(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to fetch drawdowns",
+		})
+		return
+	}
+
+	points, err := h.equityRepo.GetByBacktestID(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to fetch equity curve",
+		})
+		return
+	}
+
+	// the running peak needs every point, sample after
+	underwater := domain.Downsample(metrics.Underwater(points), maxPoints)
+
+	c.JSON(http.StatusOK, dto.FromDrawdowns(metricsEntity, drawdowns, underwater))
+}
+
 // DeleteBacktest godoc
 //
 //	@Summary		Delete a backtest
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/api/dto"
+)
+
+func TestNewBacktestDates(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		end     string
+		wantOK  bool
+		wantEnd time.Time
+	}{
+		{"end date honored", "2024-06-30", true, time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)},
+		{"end before start", "2023-12-31", false, time.Time{}},
+		{"invalid end date", "30/06/2024", false, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			backtest, ok := (&BacktestHandler{}).newBacktest(c, dto.CreateBacktestRequest{
+				StrategyID:     "sma_crossover",
+				Symbol:         "AAPL",
+				StartDate:      "2024-01-01",
+				EndDate:        tt.end,
+				InitialCapital: 10000,
+			})
+			if ok != tt.wantOK {
+				t.Fatalf("Expected ok %v, got %v with status %d", tt.wantOK, ok, w.Code)
+			}
+			if !ok {
+				if w.Code != http.StatusBadRequest {
+					t.Errorf("Expected status 400, got %d", w.Code)
+				}
+				return
+			}
+			if !backtest.EndDate.Equal(tt.wantEnd) {
+				t.Errorf("Expected end date %v, got %v", tt.wantEnd, backtest.EndDate)
+			}
+		})
+	}
+}
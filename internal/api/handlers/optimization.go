@@ -0,0 +1,438 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/api/dto"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/diagnostics"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/optimize"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/repository"
+)
+
+// OptimizationHandler runs grid searches as a parent optimization with one
+// child backtest per parameter combination
+type OptimizationHandler struct {
+	optimizationRepo repository.OptimizationRepository
+	backtestRepo     repository.BacktestRepository
+	metricsRepo      repository.MetricsRepository
+	equityRepo       repository.EquityRepository
+	backtests        *BacktestHandler
+}
+
+// NewOptimizationHandler creates a new optimization handler, the child
+// backtests run on the worker pool of backtests
+func NewOptimizationHandler(
+	optimizationRepo repository.OptimizationRepository,
+	backtestRepo repository.BacktestRepository,
+	metricsRepo repository.MetricsRepository,
+	equityRepo repository.EquityRepository,
+	backtests *BacktestHandler,
+) *OptimizationHandler {
+	return &OptimizationHandler{
+		optimizationRepo: optimizationRepo,
+		backtestRepo:     backtestRepo,
+		metricsRepo:      metricsRepo,
+		equityRepo:       equityRepo,
+		backtests:        backtests,
+	}
+}
+
+// runOptimization runs the children on the worker pool and completes the
+// parent once all of them finished. it only fails when every child failed
+func (h *OptimizationHandler) runOptimization(optimization *domain.Optimization, children []*domain.Backtest) {
+	ctx := context.Background()
+
+	optimization.Status = domain.BacktestStatusRunning
+	h.optimizationRepo.Update(ctx, optimization)
+
+	var wg sync.WaitGroup
+	for _, child := range children {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h.backtests.runQueued(child)
+		}()
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, child := range children {
+		if child.Status == domain.BacktestStatusFailed {
+			failed++
+		}
+	}
+
+	now := time.Now()
+	optimization.CompletedAt = &now
+	optimization.Status = domain.BacktestStatusCompleted
+	if failed == len(children) {
+		optimization.Status = domain.BacktestStatusFailed
+		optimization.ErrorMessage = fmt.Sprintf("all %d backtests failed", failed)
+	}
+
+	if err := h.optimizationRepo.Update(ctx, optimization); err != nil {
+		log.Printf("Failed to complete optimization %s: %v", optimization.ID, err)
+	}
+}
+
+// CreateOptimization godoc
+//
+//	@Summary		Create a parameter grid search
+//	@Description	Expand the parameter grid, run one backtest per combination on the worker pool and rank them by the objective. combinations the strategy rejects are skipped
+//	@Tags			optimizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.CreateOptimizationRequest	true	"Backtest settings and parameter grid"
+//	@Success		201		{object}	dto.OptimizationResponse
+//	@Failure		400		{object}	dto.ErrorResponse
+//	@Failure		500		{object}	dto.ErrorResponse
+//	@Router			/api/v1/optimizations [post]
+func (h *OptimizationHandler) CreateOptimization(c *gin.Context) {
+	var req dto.CreateOptimizationRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	objective, err := dto.ParseObjective(req.Objective)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid objective",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	grid, err := parseGrid(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid parameter grid",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	template, ok := h.backtests.newBacktest(c, req.CreateBacktestRequest)
+	if !ok {
+		return
+	}
+
+	ctx := context.Background()
+
+	combinations, skipped, err := h.backtests.combinations(ctx, req.StrategyID, req.Parameters, grid)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid parameter grid",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	optimization := &domain.Optimization{
+		StrategyID:   req.StrategyID,
+		Symbols:      template.Symbols,
+		StartDate:    template.StartDate,
+		EndDate:      template.EndDate,
+		Grid:         grid,
+		Objective:    objective,
+		Status:       domain.BacktestStatusPending,
+		Combinations: len(combinations),
+		Skipped:      skipped,
+	}
+
+	if err := h.optimizationRepo.Create(ctx, optimization); err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to create optimization",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	children := make([]*domain.Backtest, len(combinations))
+	for i, params := range combinations {
+		child := *template
+		child.ID = uuid.New()
+		child.Parameters = params
+		child.OptimizationID = &optimization.ID
+		child.Status = domain.BacktestStatusQueued
+
+		if err := h.backtestRepo.Create(ctx, &child); err != nil {
+			optimization.Status = domain.BacktestStatusFailed
+			optimization.ErrorMessage = fmt.Sprintf("Failed to create backtest: %v", err)
+			h.optimizationRepo.Update(ctx, optimization)
+
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+				Error:   "Failed to create optimization",
+				Message: err.Error(),
+			})
+			return
+		}
+		children[i] = &child
+	}
+
+	go h.runOptimization(optimization, children)
+
+	c.JSON(http.StatusCreated, dto.FromDomainOptimization(optimization, nil))
+}
+
+// parseGrid expands the parameter ranges of the request
+func parseGrid(req dto.CreateOptimizationRequest) (map[string][]any, error) {
+	ranges := make(map[string]optimize.Range, len(req.Grid))
+	for name, r := range req.Grid {
+		ranges[name] = optimize.Range(r)
+	}
+	return optimize.Grid(ranges)
+}
+
+// GetOptimization godoc
+//
+//	@Summary		Get optimization by ID
+//	@Description	Get a grid search with the progress of its backtests
+//	@Tags			optimizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Optimization ID"
+//	@Success		200	{object}	dto.OptimizationResponse
+//	@Failure		400	{object}	dto.ErrorResponse
+//	@Failure		404	{object}	dto.ErrorResponse
+//	@Router			/api/v1/optimizations/{id} [get]
+func (h *OptimizationHandler) GetOptimization(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid optimization ID",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	optimization, err := h.optimizationRepo.GetByID(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: "Optimization not found",
+		})
+		return
+	}
+
+	children, err := h.backtestRepo.ListByOptimization(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to fetch backtests",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromDomainOptimization(optimization, children))
+}
+
+// ListOptimizations godoc
+//
+//	@Summary		List optimizations
+//	@Description	Get the latest grid searches
+//	@Tags			optimizations
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	dto.ListResponse{items=[]dto.OptimizationResponse}
+//	@Failure		500	{object}	dto.ErrorResponse
+//	@Router			/api/v1/optimizations [get]
+func (h *OptimizationHandler) ListOptimizations(c *gin.Context) {
+	ctx := context.Background()
+	optimizations, err := h.optimizationRepo.List(ctx, 100, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to fetch optimizations",
+		})
+		return
+	}
+
+	responses := make([]dto.OptimizationResponse, len(optimizations))
+	for i, o := range optimizations {
+		responses[i] = dto.FromDomainOptimization(o, nil)
+	}
+
+	c.JSON(http.StatusOK, dto.ListResponse{
+		Items: responses,
+		Total: len(responses),
+		Page:  1,
+		Limit: 100,
+	})
+}
+
+// GetOptimizationResults godoc
+//
+//	@Summary		Get the ranked results of an optimization
+//	@Description	Get every backtest of a grid search ranked best first by the objective, backtests without metrics come last. objective overrides the one the search was created with
+//	@Tags			optimizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string	true	"Optimization ID"
+//	@Param			objective	query		string	false	"sharpe, total_return, max_drawdown or profit_factor"
+//	@Success		200			{object}	dto.ListResponse{items=[]dto.OptimizationResultResponse}
+//	@Failure		400			{object}	dto.ErrorResponse
+//	@Failure		404			{object}	dto.ErrorResponse
+//	@Router			/api/v1/optimizations/{id}/results [get]
+func (h *OptimizationHandler) GetOptimizationResults(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid optimization ID",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	optimization, err := h.optimizationRepo.GetByID(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: "Optimization not found",
+		})
+		return
+	}
+
+	objective := optimization.Objective
+	if raw := c.Query("objective"); raw != "" {
+		objective, err = dto.ParseObjective(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Invalid objective",
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+
+	children, err := h.backtestRepo.ListByOptimization(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to fetch backtests",
+		})
+		return
+	}
+
+	entries := make([]optimize.Entry, len(children))
+	for i, child := range children {
+		entries[i] = optimize.Entry{Backtest: child}
+		if child.Status != domain.BacktestStatusCompleted {
+			continue
+		}
+		if metrics, err := h.metricsRepo.GetByBacktestID(ctx, child.ID); err == nil {
+			entries[i].Metrics = metrics
+		}
+	}
+
+	ranked := optimize.Rank(entries, objective)
+	responses := make([]dto.OptimizationResultResponse, len(ranked))
+	for i, r := range ranked {
+		responses[i] = dto.FromRankedBacktest(r)
+	}
+
+	c.JSON(http.StatusOK, dto.ListResponse{
+		Items: responses,
+		Total: len(responses),
+		Page:  1,
+		Limit: len(responses),
+	})
+}
+
+// GetOptimizationDiagnostics godoc
+//
+//	@Summary		Get the overfitting diagnostics of an optimization
+//	@Description	Measure how much the best backtest of a grid search owes to the number of trials: the probabilistic and deflated Sharpe ratios of the best backtest and the probability of backtest overfitting from combinatorially symmetric cross-validation over the equity returns of every completed backtest
+//	@Tags			optimizations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string	true	"Optimization ID"
+//	@Param			partitions	query		int		false	"Even number of CSCV blocks, at most 16. defaults to the most the returns allow"
+//	@Success		200			{object}	dto.DiagnosticsResponse
+//	@Failure		400			{object}	dto.ErrorResponse
+//	@Failure		404			{object}	dto.ErrorResponse
+//	@Failure		422			{object}	dto.ErrorResponse
+//	@Router			/api/v1/optimizations/{id}/diagnostics [get]
+func (h *OptimizationHandler) GetOptimizationDiagnostics(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid optimization ID",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	partitions := 0
+	if raw := c.Query("partitions"); raw != "" {
+		partitions, err = strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Invalid partitions",
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+
+	ctx := context.Background()
+	if _, err := h.optimizationRepo.GetByID(ctx, id); err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: "Optimization not found",
+		})
+		return
+	}
+
+	children, err := h.backtestRepo.ListByOptimization(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to fetch backtests",
+		})
+		return
+	}
+
+	var trials []*domain.Backtest
+	var series [][]float64
+	for _, child := range children {
+		if child.Status != domain.BacktestStatusCompleted {
+			continue
+		}
+
+		curve, err := h.equityRepo.GetByBacktestID(ctx, child.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+				Error: "Failed to fetch equity curves",
+			})
+			return
+		}
+
+		trials = append(trials, child)
+		series = append(series, diagnostics.Returns(curve))
+	}
+
+	// the most blocks that still leave two returns in each
+	if partitions == 0 && len(series) > 0 {
+		partitions = min(diagnostics.MaxPartitions, len(series[0])/2/2*2)
+	}
+
+	report, err := diagnostics.Analyze(series, partitions)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, dto.ErrorResponse{
+			Error:   "Not enough data for diagnostics",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromDiagnosticsReport(id, trials, report))
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/api/dto"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/repository"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/sandbox"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/strategy"
+)
+
+type StrategyHandler struct {
+	registry     *strategy.Registry
+	strategyRepo repository.StrategyRepository
+}
+
+// NewStrategyHandler creates a new strategy handler
+func NewStrategyHandler(registry *strategy.Registry, strategyRepo repository.StrategyRepository) *StrategyHandler {
+	return &StrategyHandler{
+		registry:     registry,
+		strategyRepo: strategyRepo,
+	}
+}
+
+// ListStrategies godoc
+//
+//	@Summary		List strategies
+//	@Description	Get every registered strategy with its parameter schema, followed by the uploaded ones
+//	@Tags			strategies
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	dto.ListResponse{items=[]dto.StrategyResponse}
+//	@Failure		500	{object}	dto.ErrorResponse
+//	@Router			/api/v1/strategies [get]
+func (h *StrategyHandler) ListStrategies(c *gin.Context) {
+	defs := h.registry.List()
+
+	ctx := context.Background()
+	uploaded, err := h.strategyRepo.List(ctx, 100, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to fetch strategies",
+		})
+		return
+	}
+
+	responses := make([]dto.StrategyResponse, 0, len(defs)+len(uploaded))
+	for _, def := range defs {
+		responses = append(responses, dto.FromStrategyDefinition(def))
+	}
+	for _, s := range uploaded {
+		responses = append(responses, dto.FromDomainStrategy(s, false))
+	}
+
+	c.JSON(http.StatusOK, dto.ListResponse{
+		Items: responses,
+		Total: len(responses),
+		Page:  1,
+		Limit: len(responses),
+	})
+}
+
+// CreateStrategy godoc
+//
+//	@Summary		Upload a strategy
+//	@Description	Store user code as a strategy, backtests run it by passing the returned id as strategy_id. python strategies only run when the server has a jail configured for the interpreter
+//	@Tags			strategies
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.CreateStrategyRequest	true	"Strategy code"
+//	@Success		201		{object}	dto.StrategyResponse
+//	@Failure		400		{object}	dto.ErrorResponse
+//	@Failure		500		{object}	dto.ErrorResponse
+//	@Router			/api/v1/strategies [post]
+func (h *StrategyHandler) CreateStrategy(c *gin.Context) {
+	var req dto.CreateStrategyRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	s, err := dto.ParseStrategy(req)
+	if err == nil {
+		err = sandbox.Check(s)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid strategy",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	if err := h.strategyRepo.Create(ctx, s); err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to create strategy",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.FromDomainStrategy(s, true))
+}
+
+// GetStrategy godoc
+//
+//	@Summary		Get an uploaded strategy
+//	@Description	Get an uploaded strategy including its code
+//	@Tags			strategies
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Strategy ID"
+//	@Success		200	{object}	dto.StrategyResponse
+//	@Failure		404	{object}	dto.ErrorResponse
+//	@Router			/api/v1/strategies/{id} [get]
+func (h *StrategyHandler) GetStrategy(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid strategy ID",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	s, err := h.strategyRepo.GetByID(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: "Strategy not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromDomainStrategy(s, true))
+}
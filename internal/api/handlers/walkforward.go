@@ -0,0 +1,311 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/api/dto"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/optimize"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/repository"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/strategy"
+)
+
+// WalkForwardHandler runs walk-forward analyses. the in-sample runs are only
+// scored, the stitched out-of-sample result is saved as a regular backtest
+type WalkForwardHandler struct {
+	walkForwardRepo repository.WalkForwardRepository
+	backtestRepo    repository.BacktestRepository
+	backtests       *BacktestHandler
+}
+
+// NewWalkForwardHandler creates a new walk-forward handler, every run goes
+// through the worker pool of backtests
+func NewWalkForwardHandler(
+	walkForwardRepo repository.WalkForwardRepository,
+	backtestRepo repository.BacktestRepository,
+	backtests *BacktestHandler,
+) *WalkForwardHandler {
+	return &WalkForwardHandler{
+		walkForwardRepo: walkForwardRepo,
+		backtestRepo:    backtestRepo,
+		backtests:       backtests,
+	}
+}
+
+// runWalkForward executes the windows and saves the stitched trades under
+// the combined backtest. template holds the settings shared by every run
+func (h *WalkForwardHandler) runWalkForward(walkForward *domain.WalkForward, combined, template *domain.Backtest, wf *optimize.WalkForward) {
+	ctx := context.Background()
+
+	walkForward.Status = domain.BacktestStatusRunning
+	h.walkForwardRepo.Update(ctx, walkForward)
+	combined.Status = domain.BacktestStatusRunning
+	h.backtestRepo.Update(ctx, combined)
+
+	wf.Run = func(ctx context.Context, params map[string]any, start, end time.Time, capital float64) (*strategy.Result, error) {
+		run := *template
+		run.Parameters = params
+		run.StartDate = start
+		run.EndDate = end
+		run.InitialCapital = capital
+		return h.backtests.simulateQueued(ctx, &run)
+	}
+
+	err := h.execute(ctx, walkForward, combined, wf)
+
+	now := time.Now()
+	walkForward.CompletedAt = &now
+	if err != nil {
+		walkForward.Status = domain.BacktestStatusFailed
+		walkForward.ErrorMessage = err.Error()
+		combined.Status = domain.BacktestStatusFailed
+		combined.ErrorMessage = err.Error()
+	} else {
+		walkForward.Status = domain.BacktestStatusCompleted
+		combined.Status = domain.BacktestStatusCompleted
+		combined.CompletedAt = &now
+	}
+
+	h.backtestRepo.Update(ctx, combined)
+	if err := h.walkForwardRepo.Update(ctx, walkForward); err != nil {
+		log.Printf("Failed to complete walk-forward %s: %v", walkForward.ID, err)
+	}
+}
+
+func (h *WalkForwardHandler) execute(ctx context.Context, walkForward *domain.WalkForward, combined *domain.Backtest, wf *optimize.WalkForward) error {
+	result, err := wf.Execute(ctx)
+	if err != nil {
+		return err
+	}
+
+	stitched := &strategy.Result{Trades: result.Trades, EquityCurve: result.EquityCurve, Bars: result.Bars}
+	if err := h.backtests.saveResult(ctx, combined, stitched); err != nil {
+		return err
+	}
+
+	windows := make([]domain.WalkForwardWindow, len(result.Windows))
+	for i, w := range result.Windows {
+		windows[i] = domain.WalkForwardWindow{
+			WalkForwardID:     walkForward.ID,
+			Index:             i + 1,
+			InSampleStart:     w.InStart,
+			InSampleEnd:       w.InEnd,
+			OutOfSampleStart:  w.OutStart,
+			OutOfSampleEnd:    w.OutEnd,
+			Parameters:        w.Parameters,
+			Evaluated:         w.Evaluated,
+			InSampleScore:     w.InSampleScore,
+			OutOfSampleScore:  w.OutOfSampleScore,
+			InSampleReturn:    w.InSample.AnnualizedReturn,
+			OutOfSampleReturn: w.OutOfSample.AnnualizedReturn,
+			OutOfSampleTrades: w.OutOfSample.TotalTrades,
+			Efficiency:        w.Efficiency,
+		}
+	}
+	if err := h.walkForwardRepo.CreateWindows(ctx, windows); err != nil {
+		return fmt.Errorf("Failed to save windows: %w", err)
+	}
+
+	walkForward.Efficiency = result.Efficiency
+	return nil
+}
+
+// CreateWalkForward godoc
+//
+//	@Summary		Create a walk-forward analysis
+//	@Description	Split the date range into in-sample and out-of-sample windows, rolling or anchored. the grid is optimized on every in-sample window and the winner traded on the out-of-sample window after it. the out-of-sample trades are stitched into one backtest
+//	@Tags			walk-forwards
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.CreateWalkForwardRequest	true	"Backtest settings, parameter grid and window lengths"
+//	@Success		201		{object}	dto.WalkForwardResponse
+//	@Failure		400		{object}	dto.ErrorResponse
+//	@Failure		500		{object}	dto.ErrorResponse
+//	@Router			/api/v1/walk-forwards [post]
+func (h *WalkForwardHandler) CreateWalkForward(c *gin.Context) {
+	var req dto.CreateWalkForwardRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	objective, err := dto.ParseObjective(req.Objective)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid objective",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	grid, err := parseGrid(req.CreateOptimizationRequest)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid parameter grid",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	template, ok := h.backtests.newBacktest(c, req.CreateBacktestRequest)
+	if !ok {
+		return
+	}
+
+	windows, err := optimize.Windows(template.StartDate, template.EndDate, req.InSampleDays, req.OutOfSampleDays, req.Anchored)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid windows",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+
+	combinations, _, err := h.backtests.combinations(ctx, req.StrategyID, req.Parameters, grid)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid parameter grid",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// the stitched result covers the out-of-sample windows with the fixed
+	// parameters, the searched ones change from window to window
+	combined := *template
+	combined.ID = uuid.New()
+	combined.StartDate = windows[0].OutStart
+	combined.Parameters = req.Parameters
+	if combined.Parameters == nil {
+		combined.Parameters = map[string]any{}
+	}
+
+	if err := h.backtestRepo.Create(ctx, &combined); err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to create walk-forward",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	walkForward := &domain.WalkForward{
+		StrategyID:      req.StrategyID,
+		Symbols:         template.Symbols,
+		StartDate:       template.StartDate,
+		EndDate:         template.EndDate,
+		InSampleDays:    req.InSampleDays,
+		OutOfSampleDays: req.OutOfSampleDays,
+		Anchored:        req.Anchored,
+		Grid:            grid,
+		Objective:       objective,
+		Status:          domain.BacktestStatusPending,
+		Combinations:    len(combinations),
+		BacktestID:      &combined.ID,
+	}
+
+	if err := h.walkForwardRepo.Create(ctx, walkForward); err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to create walk-forward",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	wf := &optimize.WalkForward{
+		Windows:      windows,
+		Combinations: combinations,
+		Objective:    objective,
+		Capital:      template.InitialCapital,
+		RiskFreeRate: template.RiskFreeRate,
+	}
+	go h.runWalkForward(walkForward, &combined, template, wf)
+
+	c.JSON(http.StatusCreated, dto.FromDomainWalkForward(walkForward, nil))
+}
+
+// GetWalkForward godoc
+//
+//	@Summary		Get walk-forward analysis by ID
+//	@Description	Get a walk-forward analysis with the chosen parameters and efficiency of every window once it completed. the stitched trades, equity and metrics are under the backtest backtest_id
+//	@Tags			walk-forwards
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Walk-forward ID"
+//	@Success		200	{object}	dto.WalkForwardResponse
+//	@Failure		400	{object}	dto.ErrorResponse
+//	@Failure		404	{object}	dto.ErrorResponse
+//	@Router			/api/v1/walk-forwards/{id} [get]
+func (h *WalkForwardHandler) GetWalkForward(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid walk-forward ID",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx := context.Background()
+	walkForward, err := h.walkForwardRepo.GetByID(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: "Walk-forward not found",
+		})
+		return
+	}
+
+	windows, err := h.walkForwardRepo.ListWindows(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to fetch windows",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromDomainWalkForward(walkForward, windows))
+}
+
+// ListWalkForwards godoc
+//
+//	@Summary		List walk-forward analyses
+//	@Description	Get the latest walk-forward analyses without their windows
+//	@Tags			walk-forwards
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	dto.ListResponse{items=[]dto.WalkForwardResponse}
+//	@Failure		500	{object}	dto.ErrorResponse
+//	@Router			/api/v1/walk-forwards [get]
+func (h *WalkForwardHandler) ListWalkForwards(c *gin.Context) {
+	ctx := context.Background()
+	walkForwards, err := h.walkForwardRepo.List(ctx, 100, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error: "Failed to fetch walk-forwards",
+		})
+		return
+	}
+
+	responses := make([]dto.WalkForwardResponse, len(walkForwards))
+	for i, w := range walkForwards {
+		responses[i] = dto.FromDomainWalkForward(w, nil)
+	}
+
+	c.JSON(http.StatusOK, dto.ListResponse{
+		Items: responses,
+		Total: len(responses),
+		Page:  1,
+		Limit: 100,
+	})
+}
@@ -9,8 +9,11 @@ import (
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"github.com/wreckitral/distributed-backtesting-platform/internal/api/handlers"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/config"
 	"github.com/wreckitral/distributed-backtesting-platform/internal/marketdata"
 	"github.com/wreckitral/distributed-backtesting-platform/internal/repository/postgres"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/sandbox"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/strategy"
 )
 
 type Server struct {
@@ -18,7 +21,7 @@ type Server struct {
 	db     *sql.DB
 }
 
-func NewServer(db *sql.DB, dataDir string) (*Server, error) {
+func NewServer(db *sql.DB, dataDir string, worker config.Worker, benchmark string) (*Server, error) {
 	// Set Gin mode (release/debug)
 	gin.SetMode(gin.ReleaseMode)
 
@@ -34,7 +37,12 @@ func NewServer(db *sql.DB, dataDir string) (*Server, error) {
 	backtestRepo := postgres.NewBacktestRepository(db)
 	tradeRepo := postgres.NewTradeRepository(db)
 	metricsRepo := postgres.NewMetricsRepository(db)
-	// strategyRepo := postgres.NewStrategyRepository(db) // TODO: Will be used in Day 7 for strategy listing
+	equityRepo := postgres.NewEquityRepository(db)
+	drawdownRepo := postgres.NewDrawdownRepository(db)
+	roundTripRepo := postgres.NewRoundTripRepository(db)
+	strategyRepo := postgres.NewStrategyRepository(db)
+	optimizationRepo := postgres.NewOptimizationRepository(db)
+	walkForwardRepo := postgres.NewWalkForwardRepository(db)
 
 	// Initialize market data provider
 	provider, err := marketdata.NewCSVProvider(dataDir)
@@ -42,17 +50,56 @@ func NewServer(db *sql.DB, dataDir string) (*Server, error) {
 		return nil, fmt.Errorf("failed to create market data provider: %w", err)
 	}
 
+	actions, err := marketdata.NewCSVCorporateActions(dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create corporate actions source: %w", err)
+	}
+
+	registry := strategy.DefaultRegistry()
+	python := sandbox.PythonConfig{
+		Path:        worker.PythonPath,
+		CallTimeout: worker.PythonTimeout,
+		MemoryLimit: int64(worker.PythonMemoryMB) << 20,
+		Unjailed:    worker.PythonUnjailed,
+	}
+	if worker.PythonJailRoot != "" {
+		python.Jail = &sandbox.Jail{
+			Root: worker.PythonJailRoot,
+			UID:  worker.PythonJailUID,
+			GID:  worker.PythonJailGID,
+		}
+	}
+	loader := sandbox.NewLoader(python, sandbox.GoConfig{
+		CallTimeout: worker.GoTimeout,
+	}, sandbox.WasmConfig{
+		CallTimeout: worker.WasmTimeout,
+		Fuel:        int64(worker.WasmFuel),
+		MemoryLimit: uint32(worker.WasmMemoryMB) << 20,
+	})
+
 	// Initialize handlers
 	healthHandler := handlers.NewHealthHandler()
 	backtestHandler := handlers.NewBacktestHandler(
 		backtestRepo,
 		tradeRepo,
 		metricsRepo,
+		equityRepo,
+		drawdownRepo,
+		roundTripRepo,
 		provider,
+		actions,
+		registry,
+		strategyRepo,
+		loader,
+		benchmark,
+		worker.WorkerPoolSize,
 	)
+	strategyHandler := handlers.NewStrategyHandler(registry, strategyRepo)
+	optimizationHandler := handlers.NewOptimizationHandler(optimizationRepo, backtestRepo, metricsRepo, equityRepo, backtestHandler)
+	walkForwardHandler := handlers.NewWalkForwardHandler(walkForwardRepo, backtestRepo, backtestHandler)
 
 	// Register routes
-	registerRoutes(router, healthHandler, backtestHandler)
+	registerRoutes(router, healthHandler, backtestHandler, strategyHandler, optimizationHandler, walkForwardHandler)
 
 	return &Server{
 		router: router,
@@ -64,6 +111,9 @@ func registerRoutes(
 	router *gin.Engine,
 	healthHandler *handlers.HealthHandler,
 	backtestHandler *handlers.BacktestHandler,
+	strategyHandler *handlers.StrategyHandler,
+	optimizationHandler *handlers.OptimizationHandler,
+	walkForwardHandler *handlers.WalkForwardHandler,
 ) {
 	// Health check
 	router.GET("/health", healthHandler.GetHealth)
@@ -82,14 +132,39 @@ func registerRoutes(
 			backtests.GET("/:id", backtestHandler.GetBacktest)
 			backtests.GET("/:id/metrics", backtestHandler.GetBacktestMetrics)
 			backtests.GET("/:id/trades", backtestHandler.GetBacktestTrades)
+			backtests.GET("/:id/equity", backtestHandler.GetBacktestEquity)
+			backtests.GET("/:id/drawdowns", backtestHandler.GetBacktestDrawdowns)
+			backtests.GET("/:id/round-trips", backtestHandler.GetBacktestRoundTrips)
+			backtests.GET("/:id/returns", backtestHandler.GetBacktestReturns)
+			backtests.POST("/:id/montecarlo", backtestHandler.RunMonteCarlo)
 			backtests.DELETE("/:id", backtestHandler.DeleteBacktest)
 		}
 
-		// TODO: Day 7 - Strategy routes
-		// strategies := v1.Group("/strategies")
-		// {
-		//     strategies.GET("", strategyHandler.ListStrategies)
-		// }
+		// Strategy routes
+		strategies := v1.Group("/strategies")
+		{
+			strategies.GET("", strategyHandler.ListStrategies)
+			strategies.POST("", strategyHandler.CreateStrategy)
+			strategies.GET("/:id", strategyHandler.GetStrategy)
+		}
+
+		// Optimization routes
+		optimizations := v1.Group("/optimizations")
+		{
+			optimizations.POST("", optimizationHandler.CreateOptimization)
+			optimizations.GET("", optimizationHandler.ListOptimizations)
+			optimizations.GET("/:id", optimizationHandler.GetOptimization)
+			optimizations.GET("/:id/results", optimizationHandler.GetOptimizationResults)
+			optimizations.GET("/:id/diagnostics", optimizationHandler.GetOptimizationDiagnostics)
+		}
+
+		// Walk-forward routes
+		walkForwards := v1.Group("/walk-forwards")
+		{
+			walkForwards.POST("", walkForwardHandler.CreateWalkForward)
+			walkForwards.GET("", walkForwardHandler.ListWalkForwards)
+			walkForwards.GET("/:id", walkForwardHandler.GetWalkForward)
+		}
 
 		// TODO: Day 7 - Symbol routes
 		// symbols := v1.Group("/symbols")
@@ -9,9 +9,11 @@ var (
 )
 
 const (
-	DefaultCommission     = 0.001 // 0.1%
-	DefaultInitialCapital = 100000.0
-	MaxBacktestDuration   = 10 * 365 // 10 years in days
+	DefaultCommission        = 0.001 // 0.1%
+	DefaultInitialCapital    = 100000.0
+	MaxBacktestDuration      = 10 * 365 // 10 years in days
+	DefaultInitialMargin     = 0.5      // Reg T, 50% of short notional
+	DefaultMaintenanceMargin = 0.3      // 30% of short notional
 )
 
 type Timeframe string
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -13,6 +14,7 @@ type Config struct {
 	Database         Database
 	OrchestratorPort string
 	Worker           Worker
+	Benchmark        string // symbol backtests are compared with by default, empty for the first traded symbol
 	LogLevel         string
 	Environment      string
 }
@@ -34,6 +36,25 @@ type Database struct {
 type Worker struct {
 	WorkerPoolSize int
 	PythonPath     string
+
+	// limits for each Python strategy process
+	PythonTimeout  time.Duration
+	PythonMemoryMB int
+
+	// jail for each Python strategy process, Python strategies only run
+	// without one when PythonUnjailed is set
+	PythonJailRoot string
+	PythonJailUID  int
+	PythonJailGID  int
+	PythonUnjailed bool
+
+	// limit for each call into an interpreted Go strategy
+	GoTimeout time.Duration
+
+	// limits for each WebAssembly strategy instance
+	WasmTimeout  time.Duration
+	WasmFuel     int // instructions per call
+	WasmMemoryMB int
 }
 
 func Load() (*Config, error) {
@@ -57,7 +78,18 @@ func Load() (*Config, error) {
 		Worker: Worker{
 			WorkerPoolSize: getEnvAsInt("WORKER_POOL_SIZE", 4),
 			PythonPath:     os.Getenv("PYTHON_PATH"),
+			PythonTimeout:  getEnvAsDuration("PYTHON_TIMEOUT", 5*time.Second),
+			PythonMemoryMB: getEnvAsInt("PYTHON_MEMORY_MB", 512),
+			PythonJailRoot: os.Getenv("PYTHON_JAIL_ROOT"),
+			PythonJailUID:  getEnvAsInt("PYTHON_JAIL_UID", 65534),
+			PythonJailGID:  getEnvAsInt("PYTHON_JAIL_GID", 65534),
+			PythonUnjailed: getEnvAsBool("PYTHON_UNJAILED", false),
+			GoTimeout:      getEnvAsDuration("GO_STRATEGY_TIMEOUT", 5*time.Second),
+			WasmTimeout:    getEnvAsDuration("WASM_TIMEOUT", time.Second),
+			WasmFuel:       getEnvAsInt("WASM_FUEL", 100_000_000),
+			WasmMemoryMB:   getEnvAsInt("WASM_MEMORY_MB", 64),
 		},
+		Benchmark:   strings.ToUpper(strings.TrimSpace(os.Getenv("BENCHMARK"))),
 		LogLevel:    os.Getenv("LOG_LEVEL"),
 		Environment: os.Getenv("ENVIRONMENT"),
 	}
@@ -102,6 +134,27 @@ func (c *Config) Validate() error {
 	if c.Worker.WorkerPoolSize < 1 {
 		return fmt.Errorf("WORKER_POOL_SIZE must be at least 1")
 	}
+	if c.Worker.PythonTimeout <= 0 {
+		return fmt.Errorf("PYTHON_TIMEOUT must be positive")
+	}
+	if c.Worker.GoTimeout <= 0 {
+		return fmt.Errorf("GO_STRATEGY_TIMEOUT must be positive")
+	}
+	if c.Worker.WasmTimeout <= 0 {
+		return fmt.Errorf("WASM_TIMEOUT must be positive")
+	}
+	if c.Worker.WasmFuel < 1 {
+		return fmt.Errorf("WASM_FUEL must be positive")
+	}
+	if c.Worker.WasmMemoryMB < 1 || c.Worker.WasmMemoryMB > 4095 {
+		return fmt.Errorf("WASM_MEMORY_MB must be between 1 and 4095")
+	}
+	if c.Worker.PythonMemoryMB < 0 {
+		return fmt.Errorf("PYTHON_MEMORY_MB cannot be negative")
+	}
+	if c.Worker.PythonJailRoot != "" && (c.Worker.PythonJailUID < 1 || c.Worker.PythonJailGID < 1) {
+		return fmt.Errorf("PYTHON_JAIL_UID and PYTHON_JAIL_GID must not be root")
+	}
 
 	validLogLevels := []string{"debug", "info", "warn", "error"}
 	if !contains(validLogLevels, c.LogLevel) {
@@ -123,6 +176,18 @@ func getEnvAsInt(key string, defaultVal int) int {
 	return val
 }
 
+func getEnvAsBool(key string, defaultVal bool) bool {
+	valStr := os.Getenv(key)
+	if valStr == "" {
+		return defaultVal
+	}
+	val, err := strconv.ParseBool(valStr)
+	if err != nil {
+		return defaultVal
+	}
+	return val
+}
+
 func getEnvAsDuration(key string, defaultVal time.Duration) time.Duration {
 	valStr := os.Getenv(key)
 	if valStr == "" {
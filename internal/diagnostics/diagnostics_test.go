@@ -0,0 +1,205 @@
+package diagnostics
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+)
+
+// reference values computed independently with python's statistics.NormalDist
+var returns = []float64{0.01, -0.005, 0.02, 0.003, -0.012, 0.008, 0.015, -0.002, 0.004, 0.011, -0.007, 0.006}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestMoments(t *testing.T) {
+	m := ComputeMoments(returns)
+
+	if m.N != 12 || !near(m.Mean, 0.00425) || !near(m.StdDev, 0.009019654464926396) {
+		t.Errorf("Expected mean 0.00425 and std dev 0.0090197, got %+v", m)
+	}
+	if !near(m.Skewness, -0.11894638734468847) || !near(m.Kurtosis, 2.1765030223243773) {
+		t.Errorf("Expected skew -0.11895 and kurtosis 2.1765, got %v and %v", m.Skewness, m.Kurtosis)
+	}
+	if !near(m.Sharpe(), 0.4711932166056298) {
+		t.Errorf("Expected sharpe 0.47119, got %v", m.Sharpe())
+	}
+}
+
+func TestProbabilisticSharpe(t *testing.T) {
+	tests := []struct {
+		benchmark float64
+		want      float64
+	}{
+		{0, 0.9300000107664361},
+		{0.1, 0.8775016789199019},
+	}
+	for _, tt := range tests {
+		got, err := ProbabilisticSharpe(returns, tt.benchmark)
+		if err != nil {
+			t.Fatalf("PSR failed: %v", err)
+		}
+		if !near(got, tt.want) {
+			t.Errorf("PSR against %v: expected %v, got %v", tt.benchmark, tt.want, got)
+		}
+	}
+
+	if _, err := ProbabilisticSharpe([]float64{0.01}, 0); err == nil {
+		t.Error("Expected an error for a single return")
+	}
+	if _, err := ProbabilisticSharpe([]float64{0.01, 0.01, 0.01}, 0); err == nil {
+		t.Error("Expected an error for returns without variance")
+	}
+}
+
+func TestDeflatedSharpe(t *testing.T) {
+	if got := ExpectedMaxSharpe(100, 0.25); !near(got, 1.2653014466008423) {
+		t.Errorf("Expected max sharpe of 100 trials 1.26530, got %v", got)
+	}
+	if got := ExpectedMaxSharpe(1, 0.25); got != 0 {
+		t.Errorf("Expected no inflation for a single trial, got %v", got)
+	}
+
+	got, err := DeflatedSharpe(returns, 10, 0.04)
+	if err != nil {
+		t.Fatalf("DSR failed: %v", err)
+	}
+	if !near(got, 0.6877396330948313) {
+		t.Errorf("Expected DSR 0.68774, got %v", got)
+	}
+
+	// a single trial has nothing to deflate
+	single, _ := DeflatedSharpe(returns, 1, 0.04)
+	psr, _ := ProbabilisticSharpe(returns, 0)
+	if single != psr {
+		t.Errorf("Expected DSR of one trial to equal PSR %v, got %v", psr, single)
+	}
+}
+
+func noise(rng *rand.Rand, n int, drift float64) []float64 {
+	s := make([]float64, n)
+	for i := range s {
+		s[i] = drift + rng.NormFloat64()*0.01
+	}
+	return s
+}
+
+func TestPBO(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	t.Run("skill", func(t *testing.T) {
+		series := [][]float64{noise(rng, 800, 0.005)}
+		for range 9 {
+			series = append(series, noise(rng, 800, 0))
+		}
+
+		result, err := PBO(series, 8)
+		if err != nil {
+			t.Fatalf("PBO failed: %v", err)
+		}
+		if result.Splits != 70 || len(result.Logits) != 70 {
+			t.Errorf("Expected C(8, 4) = 70 splits, got %d", result.Splits)
+		}
+		if result.Probability != 0 || result.MeanLogit <= 0 {
+			t.Errorf("Expected no overfitting for a real edge, got PBO %v mean logit %v", result.Probability, result.MeanLogit)
+		}
+	})
+
+	t.Run("luck", func(t *testing.T) {
+		var series [][]float64
+		for range 50 {
+			series = append(series, noise(rng, 1000, 0))
+		}
+
+		result, err := PBO(series, 10)
+		if err != nil {
+			t.Fatalf("PBO failed: %v", err)
+		}
+		if result.Probability < 0.25 || result.Probability > 0.75 {
+			t.Errorf("Expected PBO near 0.5 for pure noise, got %v", result.Probability)
+		}
+		if result.Degradation >= 0 {
+			t.Errorf("Expected the in-sample best to degrade out of sample, got %v", result.Degradation)
+		}
+	})
+
+	t.Run("regime change", func(t *testing.T) {
+		// each series only works in its own half, so whatever wins in sample
+		// is the wrong pick out of sample
+		first := append(noise(rng, 200, 0.01), noise(rng, 200, -0.01)...)
+		second := append(noise(rng, 200, -0.01), noise(rng, 200, 0.01)...)
+
+		result, err := PBO([][]float64{first, second}, 2)
+		if err != nil {
+			t.Fatalf("PBO failed: %v", err)
+		}
+		if result.Probability != 1 {
+			t.Errorf("Expected PBO 1, got %v", result.Probability)
+		}
+	})
+
+	errorCases := []struct {
+		name       string
+		series     [][]float64
+		partitions int
+		want       string
+	}{
+		{"one series", [][]float64{returns}, 2, "at least 2 series"},
+		{"odd partitions", [][]float64{returns, returns}, 3, "even number"},
+		{"too many partitions", [][]float64{returns, returns}, 18, "even number"},
+		{"unequal lengths", [][]float64{returns, returns[1:]}, 2, "series 1 has 11 returns"},
+		{"too short", [][]float64{returns, returns}, 8, "need at least 16 returns"},
+	}
+	for _, tt := range errorCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := PBO(tt.series, tt.partitions)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	var series [][]float64
+	for range 20 {
+		series = append(series, noise(rng, 500, 0.0005))
+	}
+
+	report, err := Analyze(series, 10)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	if report.Trials != 20 || report.PBO == nil {
+		t.Fatalf("Expected 20 trials with a PBO, got %+v", report)
+	}
+	for i, s := range series {
+		if sr := ComputeMoments(s).Sharpe(); sr > report.Sharpe {
+			t.Errorf("Series %d has sharpe %v above the best %v", i, sr, report.Sharpe)
+		}
+	}
+	// the more trials, the more the best sharpe is deflated
+	if report.DeflatedSharpe >= report.ProbabilisticSharpe {
+		t.Errorf("Expected DSR %v below PSR %v", report.DeflatedSharpe, report.ProbabilisticSharpe)
+	}
+	if report.ExpectedMaxSharpe <= 0 {
+		t.Errorf("Expected a positive expected max sharpe, got %v", report.ExpectedMaxSharpe)
+	}
+}
+
+func TestReturns(t *testing.T) {
+	curve := []domain.EquityCurve{{Equity: 100}, {Equity: 110}, {Equity: 99}}
+	got := Returns(curve)
+	if len(got) != 2 || !near(got[0], 0.1) || !near(got[1], -0.1) {
+		t.Errorf("Expected returns [0.1 -0.1], got %v", got)
+	}
+	if Returns(curve[:1]) != nil {
+		t.Error("Expected no returns for a single point")
+	}
+}
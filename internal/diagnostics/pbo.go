@@ -0,0 +1,143 @@
+package diagnostics
+
+import (
+	"fmt"
+	"math"
+)
+
+// MaxPartitions bounds CSCV to C(16, 8) = 12870 splits
+const MaxPartitions = 16
+
+// PBOResult is the outcome of combinatorially symmetric cross-validation
+type PBOResult struct {
+	Probability  float64   // share of splits where the in-sample best ranks at or below the out-of-sample median
+	Splits       int       // train and test combinations evaluated
+	Logits       []float64 // relative out-of-sample rank of the in-sample best per split, as a logit
+	MeanLogit    float64
+	Degradation  float64 // mean out-of-sample minus in-sample sharpe of the in-sample best
+	Partitions   int
+	Observations int // returns of every series used, the remainder after partitioning is dropped
+}
+
+// blockStats are the sums needed to get the sharpe of any union of blocks
+type blockStats struct {
+	n, sum, sumSq float64
+}
+
+func (b blockStats) add(o blockStats) blockStats {
+	return blockStats{b.n + o.n, b.sum + o.sum, b.sumSq + o.sumSq}
+}
+
+func (b blockStats) sharpe() float64 {
+	if b.n == 0 {
+		return 0
+	}
+	mean := b.sum / b.n
+	variance := b.sumSq/b.n - mean*mean
+	if variance <= 0 {
+		return 0
+	}
+	return mean / math.Sqrt(variance)
+}
+
+// PBO estimates the probability of backtest overfitting of a set of related
+// return series, one per trial, all of the same length and aligned in time.
+// the rows are cut into partitions blocks, every half of the blocks is used
+// once to pick the best trial by Sharpe and the other half to rank it
+func PBO(series [][]float64, partitions int) (*PBOResult, error) {
+	if len(series) < 2 {
+		return nil, fmt.Errorf("need at least 2 series, have %d", len(series))
+	}
+	if partitions < 2 || partitions > MaxPartitions || partitions%2 != 0 {
+		return nil, fmt.Errorf("partitions must be an even number between 2 and %d, got %d", MaxPartitions, partitions)
+	}
+
+	length := len(series[0])
+	for i, s := range series {
+		if len(s) != length {
+			return nil, fmt.Errorf("series %d has %d returns, series 0 has %d", i, len(s), length)
+		}
+	}
+	blockSize := length / partitions
+	if blockSize < 2 {
+		return nil, fmt.Errorf("need at least %d returns for %d partitions, have %d", 2*partitions, partitions, length)
+	}
+
+	// stats[n][b] are the sums of series n over block b
+	stats := make([][]blockStats, len(series))
+	for n, s := range series {
+		stats[n] = make([]blockStats, partitions)
+		for b := range partitions {
+			for _, r := range s[b*blockSize : (b+1)*blockSize] {
+				stats[n][b] = stats[n][b].add(blockStats{1, r, r * r})
+			}
+		}
+	}
+
+	result := &PBOResult{Partitions: partitions, Observations: blockSize * partitions}
+	train := make([]float64, len(series))
+	test := make([]float64, len(series))
+	below := 0
+
+	forEachHalf(partitions, func(inTrain []bool) {
+		for n := range series {
+			var in, out blockStats
+			for b, isTrain := range inTrain {
+				if isTrain {
+					in = in.add(stats[n][b])
+				} else {
+					out = out.add(stats[n][b])
+				}
+			}
+			train[n], test[n] = in.sharpe(), out.sharpe()
+		}
+
+		best := 0
+		for n := range train {
+			if train[n] > train[best] {
+				best = n
+			}
+		}
+
+		// relative rank of the in-sample best out of sample, in (0, 1)
+		rank := 0
+		for n := range test {
+			if test[n] <= test[best] {
+				rank++
+			}
+		}
+		omega := float64(rank) / float64(len(series)+1)
+		logit := math.Log(omega / (1 - omega))
+
+		result.Logits = append(result.Logits, logit)
+		result.MeanLogit += logit
+		result.Degradation += test[best] - train[best]
+		if logit <= 0 {
+			below++
+		}
+	})
+
+	result.Splits = len(result.Logits)
+	result.Probability = float64(below) / float64(result.Splits)
+	result.MeanLogit /= float64(result.Splits)
+	result.Degradation /= float64(result.Splits)
+	return result, nil
+}
+
+// forEachHalf calls fn with every way to pick half of n blocks for training
+func forEachHalf(n int, fn func(inTrain []bool)) {
+	inTrain := make([]bool, n)
+	var pick func(start, left int)
+	pick = func(start, left int) {
+		if left == 0 {
+			fn(inTrain)
+			return
+		}
+		for i := start; i <= n-left; i++ {
+			inTrain[i] = true
+			pick(i+1, left-1)
+			inTrain[i] = false
+		}
+	}
+	pick(0, n/2)
+}
@@ -0,0 +1,58 @@
+package diagnostics
+
+import "fmt"
+
+// Report is the overfitting diagnostics of a parameter sweep, Sharpe ratios
+// are per period
+type Report struct {
+	Trials              int
+	Best                int // index of the series with the highest Sharpe
+	Sharpe              float64
+	SharpeVariance      float64 // across all trials
+	ExpectedMaxSharpe   float64 // best Sharpe expected from luck alone
+	ProbabilisticSharpe float64 // of the best against zero
+	DeflatedSharpe      float64 // of the best against the expected maximum
+	PBO                 *PBOResult
+}
+
+// Analyze runs every diagnostic on the return series of the trials of a
+// sweep, see PBO for the requirements on the series
+func Analyze(series [][]float64, partitions int) (*Report, error) {
+	if len(series) < 2 {
+		return nil, fmt.Errorf("need at least 2 trials, have %d", len(series))
+	}
+
+	report := &Report{Trials: len(series)}
+	sharpes := make([]float64, len(series))
+	mean := 0.0
+	for i, s := range series {
+		sharpes[i] = ComputeMoments(s).Sharpe()
+		mean += sharpes[i]
+		if sharpes[i] > sharpes[report.Best] {
+			report.Best = i
+		}
+	}
+	mean /= float64(len(series))
+
+	for _, sr := range sharpes {
+		report.SharpeVariance += (sr - mean) * (sr - mean)
+	}
+	report.SharpeVariance /= float64(len(series) - 1)
+
+	best := series[report.Best]
+	report.Sharpe = sharpes[report.Best]
+	report.ExpectedMaxSharpe = ExpectedMaxSharpe(len(series), report.SharpeVariance)
+
+	var err error
+	if report.ProbabilisticSharpe, err = ProbabilisticSharpe(best, 0); err != nil {
+		return nil, fmt.Errorf("best trial: %w", err)
+	}
+	if report.DeflatedSharpe, err = DeflatedSharpe(best, len(series), report.SharpeVariance); err != nil {
+		return nil, fmt.Errorf("best trial: %w", err)
+	}
+	if report.PBO, err = PBO(series, partitions); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
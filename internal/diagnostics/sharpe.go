@@ -0,0 +1,134 @@
+// Package diagnostics measures how much of a backtest result is likely due
+// to overfitting: the probabilistic and deflated Sharpe ratios of Bailey and
+// López de Prado and the probability of backtest overfitting estimated with
+// combinatorially symmetric cross-validation
+package diagnostics
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+)
+
+// eulerGamma is the Euler-Mascheroni constant
+const eulerGamma = 0.5772156649015329
+
+// Moments of a return series, kurtosis is not excess so a normal
+// distribution has 3
+type Moments struct {
+	N        int
+	Mean     float64
+	StdDev   float64
+	Skewness float64
+	Kurtosis float64
+}
+
+// ComputeMoments returns the population moments of the returns
+func ComputeMoments(returns []float64) Moments {
+	m := Moments{N: len(returns)}
+	if m.N == 0 {
+		return m
+	}
+
+	for _, r := range returns {
+		m.Mean += r
+	}
+	m.Mean /= float64(m.N)
+
+	var m2, m3, m4 float64
+	for _, r := range returns {
+		d := r - m.Mean
+		m2 += d * d
+		m3 += d * d * d
+		m4 += d * d * d * d
+	}
+	m2 /= float64(m.N)
+	m3 /= float64(m.N)
+	m4 /= float64(m.N)
+
+	m.StdDev = math.Sqrt(m2)
+	if m2 > 0 {
+		m.Skewness = m3 / math.Pow(m2, 1.5)
+		m.Kurtosis = m4 / (m2 * m2)
+	}
+	return m
+}
+
+// Sharpe is the per-period Sharpe ratio of the moments, not annualized.
+// it is 0 for a series without variance
+func (m Moments) Sharpe() float64 {
+	if m.StdDev == 0 {
+		return 0
+	}
+	return m.Mean / m.StdDev
+}
+
+// Returns converts an equity curve into per-bar simple returns
+func Returns(curve []domain.EquityCurve) []float64 {
+	if len(curve) < 2 {
+		return nil
+	}
+
+	returns := make([]float64, 0, len(curve)-1)
+	for i := 1; i < len(curve); i++ {
+		prev := curve[i-1].Equity
+		if prev == 0 {
+			returns = append(returns, 0)
+			continue
+		}
+		returns = append(returns, curve[i].Equity/prev-1)
+	}
+	return returns
+}
+
+// ProbabilisticSharpe is the probability that the true Sharpe ratio of the
+// returns is above benchmark, given their length, skew and kurtosis. both
+// Sharpe ratios are per period
+func ProbabilisticSharpe(returns []float64, benchmark float64) (float64, error) {
+	m := ComputeMoments(returns)
+	if m.N < 2 {
+		return 0, fmt.Errorf("need at least 2 returns, have %d", m.N)
+	}
+	if m.StdDev == 0 {
+		return 0, fmt.Errorf("returns have no variance")
+	}
+
+	sr := m.Sharpe()
+	variance := 1 - m.Skewness*sr + (m.Kurtosis-1)/4*sr*sr
+	if variance <= 0 {
+		return 0, fmt.Errorf("sharpe ratio estimate has no variance")
+	}
+
+	return normCDF((sr - benchmark) * math.Sqrt(float64(m.N-1)) / math.Sqrt(variance)), nil
+}
+
+// ExpectedMaxSharpe is the Sharpe ratio the best of trials independent
+// strategies without skill is expected to reach, given the variance of
+// the Sharpe ratios across the trials
+func ExpectedMaxSharpe(trials int, variance float64) float64 {
+	if trials <= 1 || variance <= 0 {
+		return 0
+	}
+
+	n := float64(trials)
+	return math.Sqrt(variance) * ((1-eulerGamma)*normInv(1-1/n) + eulerGamma*normInv(1-1/(n*math.E)))
+}
+
+// DeflatedSharpe is the probabilistic Sharpe ratio of the returns against
+// the expected maximum Sharpe of the trials, the probability that the best
+// of the sweep has skill rather than luck
+func DeflatedSharpe(returns []float64, trials int, variance float64) (float64, error) {
+	if trials < 1 {
+		return 0, fmt.Errorf("need at least 1 trial, got %d", trials)
+	}
+	return ProbabilisticSharpe(returns, ExpectedMaxSharpe(trials, variance))
+}
+
+func normCDF(x float64) float64 {
+	return 0.5 * math.Erfc(-x/math.Sqrt2)
+}
+
+func normInv(p float64) float64 {
+	return math.Sqrt2 * math.Erfinv(2*p-1)
+}
@@ -9,11 +9,21 @@ import (
 type Backtest struct {
 	ID             uuid.UUID
 	StrategyID     string
+	Parameters     map[string]any // strategy parameters with defaults filled in
 	Status         BacktestStatus
-	Symbol         string
+	Symbols        []string // universe traded from one shared cash account
 	StartDate      time.Time
 	EndDate        time.Time
 	InitialCapital float64
+	FillModel      FillModel
+	Costs          CostSettings
+	Margin         MarginSettings
+	Sizing         SizingSettings
+	Rebalance      RebalanceSettings
+	Actions        CorporateActionSettings
+	RiskFreeRate   float64    // annual, as a fraction, for sharpe and sortino
+	Benchmark      string     // symbol bought and held to compare the returns with
+	OptimizationID *uuid.UUID // parent grid search, nil for standalone backtests
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
 	CompletedAt    *time.Time
@@ -46,3 +56,28 @@ func (b BacktestStatus) String() string {
 		return "UNKNOWN"
 	}
 }
+
+// FillModel controls at which bar and price a strategy signal gets filled
+type FillModel int
+
+const (
+	FillModelNextBarOpen FillModel = iota
+	FillModelSameBarClose
+	FillModelNextBarVWAP
+	FillModelNextBarClose
+)
+
+func (f FillModel) String() string {
+	switch f {
+	case FillModelNextBarOpen:
+		return "NEXT_BAR_OPEN"
+	case FillModelSameBarClose:
+		return "SAME_BAR_CLOSE"
+	case FillModelNextBarVWAP:
+		return "NEXT_BAR_VWAP"
+	case FillModelNextBarClose:
+		return "NEXT_BAR_CLOSE"
+	default:
+		return "UNKNOWN"
+	}
+}
@@ -20,3 +20,43 @@ func TestBacktestStatusString(t *testing.T) {
 		}
 	}
 }
+
+func TestFillModelString(t *testing.T) {
+	tests := []struct {
+		model    FillModel
+		expected string
+	}{
+		{FillModelNextBarOpen, "NEXT_BAR_OPEN"},
+		{FillModelSameBarClose, "SAME_BAR_CLOSE"},
+		{FillModelNextBarVWAP, "NEXT_BAR_VWAP"},
+		{FillModelNextBarClose, "NEXT_BAR_CLOSE"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.model.String(); got != tt.expected {
+			t.Errorf("FillModel.String() = %v, want %v", got, tt.expected)
+		}
+	}
+}
+
+func TestDownsampleEquityCurve(t *testing.T) {
+	points := make([]EquityCurve, 10)
+	for i := range points {
+		points[i].Equity = float64(i)
+	}
+
+	sampled := DownsampleEquityCurve(points, 4)
+	expected := []float64{0, 3, 6, 9}
+	if len(sampled) != len(expected) {
+		t.Fatalf("Expected %d points, got %d", len(expected), len(sampled))
+	}
+	for i, want := range expected {
+		if sampled[i].Equity != want {
+			t.Errorf("Point %d: expected equity %.0f, got %.0f", i, want, sampled[i].Equity)
+		}
+	}
+
+	if got := DownsampleEquityCurve(points, 0); len(got) != len(points) {
+		t.Errorf("Expected all %d points without a limit, got %d", len(points), len(got))
+	}
+}
@@ -0,0 +1,67 @@
+package domain
+
+// CostSettings describes the trading costs applied to every fill of a backtest.
+// the meaning of each rate depends on the selected model
+type CostSettings struct {
+	CommissionModel CommissionModel
+	CommissionRate  float64
+	SlippageModel   SlippageModel
+	SlippageRate    float64
+}
+
+type CommissionModel int
+
+const (
+	CommissionModelNone     CommissionModel = iota
+	CommissionModelFixed                    // rate is a flat fee per trade
+	CommissionModelPerShare                 // rate is a fee per share
+	CommissionModelBps                      // rate is basis points of notional
+	CommissionModelTiered                   // built-in broker schedule, rate unused
+)
+
+func (c CommissionModel) String() string {
+	switch c {
+	case CommissionModelNone:
+		return "NONE"
+	case CommissionModelFixed:
+		return "FIXED"
+	case CommissionModelPerShare:
+		return "PER_SHARE"
+	case CommissionModelBps:
+		return "BPS"
+	case CommissionModelTiered:
+		return "TIERED"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+type SlippageModel int
+
+const (
+	SlippageModelNone       SlippageModel = iota
+	SlippageModelFixedBps                 // rate is basis points of price
+	SlippageModelVolatility               // rate is a multiple of recent daily volatility
+)
+
+func (s SlippageModel) String() string {
+	switch s {
+	case SlippageModelNone:
+		return "NONE"
+	case SlippageModelFixedBps:
+		return "FIXED_BPS"
+	case SlippageModelVolatility:
+		return "VOLATILITY"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// MarginSettings controls short selling, margins are fractions of the short
+// notional and the borrow rate is annual
+type MarginSettings struct {
+	AllowShort        bool
+	InitialMargin     float64
+	MaintenanceMargin float64
+	BorrowRate        float64
+}
@@ -36,6 +36,11 @@ func (b Bar) TypicalPrice() float64 {
 	return (b.High + b.Low + b.Close) / 3
 }
 
+// AveragePrice is the OHLC average, a rough VWAP approximation for the bar
+func (b Bar) AveragePrice() float64 {
+	return (b.Open + b.High + b.Low + b.Close) / 4
+}
+
 type ErrInvalidMarketData struct {
 	Reason string
 }
@@ -43,3 +48,57 @@ type ErrInvalidMarketData struct {
 func (e ErrInvalidMarketData) Error() string {
 	return "invalid market data: " + e.Reason
 }
+
+// CorporateAction is a split or cash dividend taking effect at the open of ExDate
+type CorporateAction struct {
+	Symbol string
+	ExDate time.Time
+	Type   CorporateActionType
+	Ratio  float64 // new shares per old share of a split, 2 for a 2-for-1
+	Amount float64 // cash paid per share of a dividend
+}
+
+type CorporateActionType int
+
+const (
+	CorporateActionSplit CorporateActionType = iota
+	CorporateActionDividend
+)
+
+func (c CorporateActionType) String() string {
+	switch c {
+	case CorporateActionSplit:
+		return "SPLIT"
+	case CorporateActionDividend:
+		return "DIVIDEND"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// CorporateActionSettings controls how a backtest treats splits and dividends
+type CorporateActionSettings struct {
+	Mode              CorporateActionMode
+	ReinvestDividends bool // buy more shares with dividends, only for CorporateActionModeApply
+}
+
+type CorporateActionMode int
+
+const (
+	CorporateActionModeApply  CorporateActionMode = iota // raw prices, positions are split and dividends paid in cash
+	CorporateActionModeAdjust                            // back-adjusted prices, no events during the run
+	CorporateActionModeIgnore                            // raw prices and no events
+)
+
+func (c CorporateActionMode) String() string {
+	switch c {
+	case CorporateActionModeApply:
+		return "APPLY"
+	case CorporateActionModeAdjust:
+		return "ADJUST"
+	case CorporateActionModeIgnore:
+		return "IGNORE"
+	default:
+		return "UNKNOWN"
+	}
+}
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"math"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,8 +13,18 @@ type Metrics struct {
 	TotalReturn         float64
 	AnnualizedReturn    float64
 	SharpeRatio         float64
+	Volatility          float64 // annualized, in percent
+	SortinoRatio        float64
+	CalmarRatio         float64
+	OmegaRatio          float64
+	Skewness            float64
+	Kurtosis            float64 // excess
+	TailRatio           float64
 	MaxDrawdown         float64
-	MaxDrawdownDuration int
+	MaxDrawdownDuration int     // days
+	AvgDrawdown         float64 // mean depth of the drawdowns
+	TimeUnderWater      float64 // percent of the curve below its running peak
+	UlcerIndex          float64
 	WinRate             float64
 	TotalTrades         int
 	WinningTrades       int
@@ -23,9 +34,69 @@ type Metrics struct {
 	AvgLoss             float64
 	LargestWin          float64
 	LargestLoss         float64
+	AvgBarsHeld         float64
+	MaxWinStreak        int
+	MaxLossStreak       int
+	AvgMAE              float64 // percent of the entry price
+	AvgMFE              float64 // percent of the entry price
+	EdgeRatio           float64
+	BenchmarkReturn     float64 // percent
+	ExcessReturn        float64 // percent
+	Alpha               float64 // annualized, in percent
+	Beta                float64
+	Correlation         float64
+	TrackingError       float64 // annualized, in percent
+	InformationRatio    float64
+	UpCapture           float64 // percent
+	DownCapture         float64 // percent
 }
 
+// Drawdown is one episode of a backtest equity curve below a previous peak,
+// depth is in percent and length in days
+type Drawdown struct {
+	BacktestID   uuid.UUID
+	Index        int // order of the episode in the backtest
+	PeakDate     time.Time
+	TroughDate   time.Time
+	RecoveryDate *time.Time // nil when the backtest ended below the peak
+	PeakEquity   float64
+	TroughEquity float64
+	Depth        float64
+	Length       int
+}
+
+// EquityCurve is one mark to market point of a backtest account, taken at
+// the close of every bar. exposures are fractions of equity
 type EquityCurve struct {
-	Timestamp time.Time
-	Equity    float64
+	BacktestID    uuid.UUID
+	Timestamp     time.Time
+	Equity        float64
+	Cash          float64
+	PositionValue float64 // signed market value of open positions
+	GrossExposure float64 // long plus short value over equity
+	NetExposure   float64 // long minus short value over equity
+}
+
+// DownsampleEquityCurve keeps at most maxPoints evenly spaced points, always
+// including the first and last one. zero or negative maxPoints keeps everything
+func DownsampleEquityCurve(points []EquityCurve, maxPoints int) []EquityCurve {
+	return Downsample(points, maxPoints)
+}
+
+// Downsample keeps at most maxPoints evenly spaced elements of any series,
+// see DownsampleEquityCurve
+func Downsample[T any](points []T, maxPoints int) []T {
+	if maxPoints <= 0 || len(points) <= maxPoints {
+		return points
+	}
+	if maxPoints == 1 {
+		return points[len(points)-1:]
+	}
+
+	sampled := make([]T, maxPoints)
+	step := float64(len(points)-1) / float64(maxPoints-1)
+	for i := range sampled {
+		sampled[i] = points[int(math.Round(float64(i)*step))]
+	}
+	return sampled
 }
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Optimization is a grid search over strategy parameters. it is the parent
+// job of one child backtest per combination, the children carry the shared
+// settings and their own parameters
+type Optimization struct {
+	ID           uuid.UUID
+	StrategyID   string
+	Symbols      []string
+	StartDate    time.Time
+	EndDate      time.Time
+	Grid         map[string][]any // candidate values of every searched parameter
+	Objective    OptimizationObjective
+	Status       BacktestStatus
+	Combinations int // backtests run, one per valid combination
+	Skipped      int // combinations the strategy rejected
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	CompletedAt  *time.Time
+	ErrorMessage string
+}
+
+// OptimizationObjective is the metric child backtests are ranked by
+type OptimizationObjective int
+
+const (
+	OptimizationObjectiveSharpe OptimizationObjective = iota
+	OptimizationObjectiveTotalReturn
+	OptimizationObjectiveMaxDrawdown
+	OptimizationObjectiveProfitFactor
+)
+
+func (o OptimizationObjective) String() string {
+	switch o {
+	case OptimizationObjectiveSharpe:
+		return "SHARPE"
+	case OptimizationObjectiveTotalReturn:
+		return "TOTAL_RETURN"
+	case OptimizationObjectiveMaxDrawdown:
+		return "MAX_DRAWDOWN"
+	case OptimizationObjectiveProfitFactor:
+		return "PROFIT_FACTOR"
+	default:
+		return "UNKNOWN"
+	}
+}
@@ -0,0 +1,34 @@
+package domain
+
+// RebalanceSettings controls when a target weight strategy trades and which
+// trades are too small to bother with
+type RebalanceSettings struct {
+	Frequency      RebalanceFrequency
+	DriftThreshold float64 // fraction of equity a weight may drift before trading
+	MinShares      float64
+	MinNotional    float64
+}
+
+type RebalanceFrequency int
+
+const (
+	RebalanceFrequencyMonthly RebalanceFrequency = iota
+	RebalanceFrequencyWeekly
+	RebalanceFrequencyDaily
+	RebalanceFrequencyDrift // every bar, only once a weight drifts past the threshold
+)
+
+func (r RebalanceFrequency) String() string {
+	switch r {
+	case RebalanceFrequencyMonthly:
+		return "MONTHLY"
+	case RebalanceFrequencyWeekly:
+		return "WEEKLY"
+	case RebalanceFrequencyDaily:
+		return "DAILY"
+	case RebalanceFrequencyDrift:
+		return "DRIFT"
+	default:
+		return "UNKNOWN"
+	}
+}
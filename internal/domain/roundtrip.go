@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RoundTrip is the part of a position closed by one exit of a backtest.
+// returns and excursions are in percent of the entry price
+type RoundTrip struct {
+	BacktestID uuid.UUID
+	Index      int // order of the exit in the backtest
+	Symbol     string
+	Side       PositionSide
+	EntryTime  time.Time
+	ExitTime   time.Time
+	EntryPrice float64
+	ExitPrice  float64
+	Quantity   float64
+	PnL        float64
+	ReturnPct  float64
+	BarsHeld   int
+	MAE        float64
+	MFE        float64
+}
+
+// PositionSide is whether a round trip was long or short
+type PositionSide int
+
+const (
+	PositionSideLong PositionSide = iota
+	PositionSideShort
+)
+
+func (ps PositionSide) String() string {
+	switch ps {
+	case PositionSideLong:
+		return "LONG"
+	case PositionSideShort:
+		return "SHORT"
+	default:
+		return "UNKNOWN"
+	}
+}
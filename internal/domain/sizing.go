@@ -0,0 +1,43 @@
+package domain
+
+// SizingSettings describes how many shares an unsized order trades. the
+// meaning of Value depends on the method, Lookback is the number of bars or
+// trades used by the adaptive methods
+type SizingSettings struct {
+	Method   SizingMethod
+	Value    float64
+	Lookback int
+}
+
+type SizingMethod int
+
+const (
+	SizingMethodAllIn            SizingMethod = iota // everything cash or margin allows, value unused
+	SizingMethodFixedShares                          // value is a number of shares
+	SizingMethodFixedNotional                        // value is a dollar amount
+	SizingMethodPercentEquity                        // value is a percent of equity
+	SizingMethodATR                                  // value is the percent of equity risked per ATR
+	SizingMethodVolatilityTarget                     // value is the annualized volatility to target
+	SizingMethodKelly                                // value is the fraction of the Kelly bet
+)
+
+func (s SizingMethod) String() string {
+	switch s {
+	case SizingMethodAllIn:
+		return "ALL_IN"
+	case SizingMethodFixedShares:
+		return "FIXED_SHARES"
+	case SizingMethodFixedNotional:
+		return "FIXED_NOTIONAL"
+	case SizingMethodPercentEquity:
+		return "PERCENT_EQUITY"
+	case SizingMethodATR:
+		return "ATR"
+	case SizingMethodVolatilityTarget:
+		return "VOLATILITY_TARGET"
+	case SizingMethodKelly:
+		return "KELLY"
+	default:
+		return "UNKNOWN"
+	}
+}
@@ -22,6 +22,8 @@ type StrategyLanguage int
 const (
 	StrategyLanguageGo StrategyLanguage = iota
 	StrategyLanguagePython
+	StrategyLanguageWasm  // a WebAssembly module stored base64 encoded in Code
+	StrategyLanguageRules // a declarative rule set, see the rules package
 )
 
 func (sl StrategyLanguage) String() string {
@@ -30,6 +32,10 @@ func (sl StrategyLanguage) String() string {
 		return "GO"
 	case StrategyLanguagePython:
 		return "PYTHON"
+	case StrategyLanguageWasm:
+		return "WASM"
+	case StrategyLanguageRules:
+		return "RULES"
 	default:
 		return "UNKNOWN"
 	}
@@ -42,7 +48,9 @@ func (s Strategy) Validate() error {
 	if s.Code == "" {
 		return ErrInvalidStrategy{Reason: "code cannot be empty"}
 	}
-	if s.Language != StrategyLanguagePython && s.Language != StrategyLanguageGo {
+	switch s.Language {
+	case StrategyLanguagePython, StrategyLanguageGo, StrategyLanguageWasm, StrategyLanguageRules:
+	default:
 		return ErrInvalidStrategy{Reason: "unsupported language"}
 	}
 
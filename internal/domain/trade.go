@@ -14,6 +14,9 @@ type Trade struct {
 	Quantity      float64
 	Price         float64
 	Commission    float64
+	Slippage      float64 // cost of the fill price moving against us
+	BorrowFee     float64 // stock borrow fees of the short shares being covered
+	Dividends     float64 // dividends received by the closed shares, negative when paid on a short
 	Timestamp     time.Time
 	PnL           float64
 	CumulativePnL float64
@@ -22,8 +25,10 @@ type Trade struct {
 type TradeDirection int
 
 const (
-	TradeDirectionBuy = iota
-	TradeDirectionSell
+	TradeDirectionBuy   = iota // opens or adds to a long position
+	TradeDirectionSell         // reduces a long position
+	TradeDirectionShort        // opens or adds to a short position
+	TradeDirectionCover        // buys back a short position
 )
 
 func (td TradeDirection) String() string {
@@ -32,6 +37,10 @@ func (td TradeDirection) String() string {
 		return "BUY"
 	case TradeDirectionSell:
 		return "SELL"
+	case TradeDirectionShort:
+		return "SHORT"
+	case TradeDirectionCover:
+		return "COVER"
 	default:
 		return "UNKNOWN"
 	}
@@ -44,3 +53,8 @@ func (t Trade) Value() float64 {
 func (t Trade) TotalCost() float64 {
 	return t.Value() + t.Commission
 }
+
+// IsClosing reports whether the trade realizes P&L by reducing a position
+func (t Trade) IsClosing() bool {
+	return t.Direction == TradeDirectionSell || t.Direction == TradeDirectionCover
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WalkForward is a walk-forward analysis: the grid is optimized on every
+// in-sample window and the winner traded on the out-of-sample window after
+// it. the out-of-sample trades are stitched into the backtest BacktestID
+type WalkForward struct {
+	ID              uuid.UUID
+	StrategyID      string
+	Symbols         []string
+	StartDate       time.Time
+	EndDate         time.Time
+	InSampleDays    int
+	OutOfSampleDays int
+	Anchored        bool             // in-sample windows all start at StartDate instead of rolling
+	Grid            map[string][]any // candidate values of every searched parameter
+	Objective       OptimizationObjective
+	Status          BacktestStatus
+	Combinations    int        // combinations run in every in-sample window
+	BacktestID      *uuid.UUID // stitched out-of-sample result
+	Efficiency      float64    // annualized out-of-sample return over the mean in-sample one
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	CompletedAt     *time.Time
+	ErrorMessage    string
+}
+
+// WalkForwardWindow is the report of one window of a walk-forward analysis.
+// returns are annualized percentages
+type WalkForwardWindow struct {
+	WalkForwardID     uuid.UUID
+	Index             int
+	InSampleStart     time.Time
+	InSampleEnd       time.Time
+	OutOfSampleStart  time.Time
+	OutOfSampleEnd    time.Time
+	Parameters        map[string]any // winner of the in-sample search
+	Evaluated         int            // combinations that completed in sample
+	InSampleScore     float64
+	OutOfSampleScore  float64
+	InSampleReturn    float64
+	OutOfSampleReturn float64
+	OutOfSampleTrades int
+	Efficiency        float64
+}
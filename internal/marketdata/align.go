@@ -0,0 +1,63 @@
+package marketdata
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+)
+
+// TimeSlice holds the bars of every symbol that traded at one timestamp
+type TimeSlice struct {
+	Timestamp time.Time
+	Bars      map[string]domain.Bar
+}
+
+// Align merges per symbol bar series into one timeline. a symbol without a
+// bar at some timestamp is simply missing from that slice
+func Align(series map[string][]domain.Bar) []TimeSlice {
+	byTime := make(map[int64]*TimeSlice)
+
+	for symbol, bars := range series {
+		for _, bar := range bars {
+			key := bar.Timestamp.UnixNano()
+			slice, ok := byTime[key]
+			if !ok {
+				slice = &TimeSlice{Timestamp: bar.Timestamp, Bars: make(map[string]domain.Bar)}
+				byTime[key] = slice
+			}
+			slice.Bars[symbol] = bar
+		}
+	}
+
+	slices := make([]TimeSlice, 0, len(byTime))
+	for _, slice := range byTime {
+		slices = append(slices, *slice)
+	}
+	sort.Slice(slices, func(i, j int) bool {
+		return slices[i].Timestamp.Before(slices[j].Timestamp)
+	})
+
+	return slices
+}
+
+// GetAlignedBars loads bars for every symbol and aligns them on time, it fails
+// when any symbol has no data in the range
+func GetAlignedBars(ctx context.Context, provider Provider, symbols []string, start, end time.Time) ([]TimeSlice, error) {
+	series := make(map[string][]domain.Bar, len(symbols))
+
+	for _, symbol := range symbols {
+		bars, err := provider.GetBars(ctx, symbol, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get bars for %s: %w", symbol, err)
+		}
+		if len(bars) == 0 {
+			return nil, fmt.Errorf("no bars found for %s between %s and %s", symbol, start, end)
+		}
+		series[symbol] = bars
+	}
+
+	return Align(series), nil
+}
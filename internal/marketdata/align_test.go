@@ -0,0 +1,49 @@
+package marketdata
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+)
+
+func TestAlign(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	series := map[string][]domain.Bar{
+		"AAPL": {
+			{Symbol: "AAPL", Timestamp: day(2), Close: 100},
+			{Symbol: "AAPL", Timestamp: day(3), Close: 101},
+			{Symbol: "AAPL", Timestamp: day(4), Close: 102},
+		},
+		"MSFT": {
+			{Symbol: "MSFT", Timestamp: day(3), Close: 300},
+			{Symbol: "MSFT", Timestamp: day(4), Close: 301},
+			{Symbol: "MSFT", Timestamp: day(5), Close: 302},
+		},
+	}
+
+	slices := Align(series)
+	if len(slices) != 4 {
+		t.Fatalf("Expected 4 timestamps, got %d", len(slices))
+	}
+
+	expected := []int{1, 2, 2, 1}
+	for i, slice := range slices {
+		if !slice.Timestamp.Equal(day(i + 2)) {
+			t.Errorf("Slice %d: expected %s, got %s", i, day(i+2), slice.Timestamp)
+		}
+		if len(slice.Bars) != expected[i] {
+			t.Errorf("Slice %d: expected %d bars, got %d", i, expected[i], len(slice.Bars))
+		}
+	}
+
+	if _, ok := slices[0].Bars["MSFT"]; ok {
+		t.Error("Expected MSFT to be missing before its first bar")
+	}
+	if slices[3].Bars["MSFT"].Close != 302 {
+		t.Errorf("Expected MSFT close 302 on the last slice, got %.2f", slices[3].Bars["MSFT"].Close)
+	}
+}
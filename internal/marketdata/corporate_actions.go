@@ -0,0 +1,255 @@
+package marketdata
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+)
+
+// CorporateActionSource serves the splits and dividends of a symbol with an
+// ex-date in [start, end), sorted by ex-date
+type CorporateActionSource interface {
+	GetActions(ctx context.Context, symbol string, start, end time.Time) ([]domain.CorporateAction, error)
+}
+
+// CSVCorporateActions reads SYMBOL_actions.csv files next to the bar data. a
+// symbol without a file has no corporate actions
+type CSVCorporateActions struct {
+	dataDir string
+	cache   map[string][]domain.CorporateAction
+	mu      sync.RWMutex
+}
+
+func NewCSVCorporateActions(dataDir string) (*CSVCorporateActions, error) {
+	if _, err := os.Stat(dataDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("data directory does not exist: %s", dataDir)
+	}
+
+	return &CSVCorporateActions{
+		dataDir: dataDir,
+		cache:   make(map[string][]domain.CorporateAction),
+	}, nil
+}
+
+func (s *CSVCorporateActions) GetActions(ctx context.Context, symbol string, start, end time.Time) ([]domain.CorporateAction, error) {
+	s.mu.RLock()
+	actions, exists := s.cache[symbol]
+	s.mu.RUnlock()
+
+	if !exists {
+		loaded, err := s.loadCSV(symbol)
+		if err != nil {
+			return nil, err
+		}
+
+		s.mu.Lock()
+		s.cache[symbol] = loaded
+		s.mu.Unlock()
+
+		actions = loaded
+	}
+
+	filtered := []domain.CorporateAction{}
+	for _, action := range actions {
+		if !action.ExDate.Before(start) && action.ExDate.Before(end) {
+			filtered = append(filtered, action)
+		}
+	}
+
+	return filtered, nil
+}
+
+func (s *CSVCorporateActions) loadCSV(symbol string) ([]domain.CorporateAction, error) {
+	filename := filepath.Join(s.dataDir, symbol+"_actions.csv")
+
+	file, err := os.Open(filename)
+	if os.IsNotExist(err) {
+		return []domain.CorporateAction{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open corporate actions %s: %w", symbol, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+
+	if _, err := reader.Read(); err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	actions := []domain.CorporateAction{}
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading CSV: %w", err)
+		}
+
+		action, err := parseActionRow(symbol, row)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing row: %w", err)
+		}
+
+		actions = append(actions, action)
+	}
+
+	sort.SliceStable(actions, func(i, j int) bool {
+		return actions[i].ExDate.Before(actions[j].ExDate)
+	})
+
+	return actions, nil
+}
+
+func parseActionRow(symbol string, row []string) (domain.CorporateAction, error) {
+	// CSV format: Date,Type,Value
+	// value is the split ratio or the dividend per share
+
+	if len(row) != 3 {
+		return domain.CorporateAction{}, fmt.Errorf("expected 3 columns, got %d", len(row))
+	}
+
+	exDate, err := time.Parse("2006-01-02", row[0])
+	if err != nil {
+		return domain.CorporateAction{}, fmt.Errorf("invalid date format: %w", err)
+	}
+
+	value, err := strconv.ParseFloat(row[2], 64)
+	if err != nil {
+		return domain.CorporateAction{}, fmt.Errorf("invalid value: %w", err)
+	}
+
+	action := domain.CorporateAction{Symbol: symbol, ExDate: exDate}
+
+	switch strings.ToUpper(strings.TrimSpace(row[1])) {
+	case "SPLIT":
+		if value <= 0 {
+			return action, fmt.Errorf("split ratio must be positive, got %f", value)
+		}
+		action.Type = domain.CorporateActionSplit
+		action.Ratio = value
+	case "DIVIDEND":
+		if value < 0 {
+			return action, fmt.Errorf("dividend cannot be negative, got %f", value)
+		}
+		action.Type = domain.CorporateActionDividend
+		action.Amount = value
+	default:
+		return action, fmt.Errorf("unknown action type: %s", row[1])
+	}
+
+	return action, nil
+}
+
+// ExDateReached reports whether a bar at timestamp trades on or after the
+// ex-date, comparing calendar days in the time zone of the bar
+func ExDateReached(timestamp, exDate time.Time) bool {
+	year, month, day := exDate.Date()
+	return !timestamp.Before(time.Date(year, month, day, 0, 0, 0, 0, timestamp.Location()))
+}
+
+// AdjustBars back-adjusts bars for the actions so the series has no gaps at
+// ex-dates. prices before a split are divided by the ratio and volumes
+// multiplied by it, prices before a dividend are scaled by one minus the
+// dividend over the last close before the ex-date
+func AdjustBars(bars []domain.Bar, actions []domain.CorporateAction) []domain.Bar {
+	adjusted := make([]domain.Bar, len(bars))
+	copy(adjusted, bars)
+
+	sorted := make([]domain.CorporateAction, len(actions))
+	copy(sorted, actions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ExDate.Before(sorted[j].ExDate)
+	})
+
+	priceFactor, volumeFactor := 1.0, 1.0
+	next := len(sorted) - 1
+
+	for i := len(adjusted) - 1; i >= 0; i-- {
+		// every action going ex after this bar adjusts it and all before it
+		for next >= 0 && !ExDateReached(bars[i].Timestamp, sorted[next].ExDate) {
+			action := sorted[next]
+			switch action.Type {
+			case domain.CorporateActionSplit:
+				priceFactor /= action.Ratio
+				volumeFactor *= action.Ratio
+			case domain.CorporateActionDividend:
+				if bars[i].Close > action.Amount {
+					priceFactor *= 1 - action.Amount/bars[i].Close
+				}
+			}
+			next--
+		}
+
+		adjusted[i].Open *= priceFactor
+		adjusted[i].High *= priceFactor
+		adjusted[i].Low *= priceFactor
+		adjusted[i].Close *= priceFactor
+		adjusted[i].Volume = int64(float64(adjusted[i].Volume) * volumeFactor)
+	}
+
+	return adjusted
+}
+
+// AdjustedProvider serves back-adjusted bars from another provider, the
+// adjustment is relative to the latest data so it covers actions after end
+type AdjustedProvider struct {
+	provider Provider
+	actions  CorporateActionSource
+}
+
+func NewAdjustedProvider(provider Provider, actions CorporateActionSource) *AdjustedProvider {
+	return &AdjustedProvider{
+		provider: provider,
+		actions:  actions,
+	}
+}
+
+func (p *AdjustedProvider) GetBars(ctx context.Context, symbol string, start, end time.Time) ([]domain.Bar, error) {
+	allBars, err := p.provider.GetBars(ctx, symbol, time.Time{}, time.Now().AddDate(100, 0, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	actions, err := p.actions.GetActions(ctx, symbol, time.Time{}, time.Now().AddDate(100, 0, 0))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get corporate actions for %s: %w", symbol, err)
+	}
+
+	filtered := []domain.Bar{}
+	for _, bar := range AdjustBars(allBars, actions) {
+		if !bar.Timestamp.Before(start) && bar.Timestamp.Before(end) {
+			filtered = append(filtered, bar)
+		}
+	}
+
+	return filtered, nil
+}
+
+func (p *AdjustedProvider) GetLatestBar(ctx context.Context, symbol string) (domain.Bar, error) {
+	allBars, err := p.GetBars(ctx, symbol, time.Time{}, time.Now().AddDate(100, 0, 0))
+	if err != nil {
+		return domain.Bar{}, err
+	}
+
+	if len(allBars) == 0 {
+		return domain.Bar{}, fmt.Errorf("no bars found for symbol %s", symbol)
+	}
+
+	return allBars[len(allBars)-1], nil
+}
+
+func (p *AdjustedProvider) ListSymbols(ctx context.Context) ([]string, error) {
+	return p.provider.ListSymbols(ctx)
+}
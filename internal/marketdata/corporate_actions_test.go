@@ -0,0 +1,83 @@
+package marketdata
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+)
+
+func TestCSVCorporateActions(t *testing.T) {
+	dir := t.TempDir()
+	csv := "Date,Type,Value\n2024-06-10,split,4\n2024-02-09,DIVIDEND,0.24\n"
+	if err := os.WriteFile(filepath.Join(dir, "AAPL_actions.csv"), []byte(csv), 0o644); err != nil {
+		t.Fatalf("Failed to write actions: %v", err)
+	}
+
+	source, err := NewCSVCorporateActions(dir)
+	if err != nil {
+		t.Fatalf("Failed to create source: %v", err)
+	}
+
+	ctx := context.Background()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	actions, err := source.GetActions(ctx, "AAPL", start, end)
+	if err != nil {
+		t.Fatalf("GetActions failed: %v", err)
+	}
+	if len(actions) != 2 {
+		t.Fatalf("Expected 2 actions, got %d", len(actions))
+	}
+
+	if actions[0].Type != domain.CorporateActionDividend || actions[0].Amount != 0.24 {
+		t.Errorf("Expected the dividend first, got %+v", actions[0])
+	}
+	if actions[1].Type != domain.CorporateActionSplit || actions[1].Ratio != 4 {
+		t.Errorf("Expected the split second, got %+v", actions[1])
+	}
+
+	actions, err = source.GetActions(ctx, "MSFT", start, end)
+	if err != nil {
+		t.Fatalf("Expected no error for a symbol without actions, got: %v", err)
+	}
+	if len(actions) != 0 {
+		t.Errorf("Expected no actions for MSFT, got %d", len(actions))
+	}
+}
+
+func TestAdjustBars(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	bars := []domain.Bar{
+		{Timestamp: day(2), Open: 200, High: 200, Low: 200, Close: 200, Volume: 100},
+		{Timestamp: day(3), Open: 100, High: 100, Low: 100, Close: 100, Volume: 200},
+		{Timestamp: day(4), Open: 99, High: 99, Low: 99, Close: 99, Volume: 200},
+	}
+	actions := []domain.CorporateAction{
+		{ExDate: day(3), Type: domain.CorporateActionSplit, Ratio: 2},
+		{ExDate: day(4), Type: domain.CorporateActionDividend, Amount: 1},
+	}
+
+	adjusted := AdjustBars(bars, actions)
+
+	expected := []float64{99, 99, 99}
+	for i, bar := range adjusted {
+		if math.Abs(bar.Close-expected[i]) > 1e-9 {
+			t.Errorf("Bar %d: expected close %.2f, got %.4f", i, expected[i], bar.Close)
+		}
+	}
+	if adjusted[0].Volume != 200 {
+		t.Errorf("Expected the split to double volume, got %d", adjusted[0].Volume)
+	}
+	if bars[0].Close != 200 {
+		t.Error("Expected the input bars to be left untouched")
+	}
+}
@@ -0,0 +1,109 @@
+package metrics
+
+import (
+	"math"
+
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+)
+
+// alignBenchmark pairs every point of the equity curve with the last
+// benchmark close at or before it. points before the first benchmark bar
+// are dropped from both curves so their returns line up
+func alignBenchmark(curve []domain.EquityCurve, bars []domain.Bar) ([]domain.EquityCurve, []domain.EquityCurve) {
+	var strategy, benchmark []domain.EquityCurve
+	next := 0
+	for _, point := range curve {
+		for next < len(bars) && !bars[next].Timestamp.After(point.Timestamp) {
+			next++
+		}
+		if next == 0 {
+			continue
+		}
+
+		strategy = append(strategy, point)
+		benchmark = append(benchmark, domain.EquityCurve{Timestamp: point.Timestamp, Equity: bars[next-1].Close})
+	}
+	return strategy, benchmark
+}
+
+// calculateBenchmark compares the returns of the equity curve with buying
+// and holding the benchmark over the same bars. curves that overlap the
+// benchmark on fewer than three points leave the metrics at zero
+func (c *Calculator) calculateBenchmark(curve []domain.EquityCurve, m *Metrics) {
+	strategyCurve, benchmarkCurve := alignBenchmark(curve, c.benchmark)
+	if len(strategyCurve) < 3 {
+		return
+	}
+
+	m.BenchmarkReturn = curveReturn(benchmarkCurve)
+	m.ExcessReturn = curveReturn(strategyCurve) - m.BenchmarkReturn
+
+	series := NewReturnSeries(strategyCurve)
+	strategy, benchmark := series.Returns, NewReturnSeries(benchmarkCurve).Returns
+	if len(strategy) < 2 {
+		return
+	}
+
+	periods := series.PeriodsPerYear
+	riskFree := math.Pow(1+c.riskFreeRate, 1/periods) - 1
+	n := float64(len(strategy))
+
+	var meanS, meanB, meanActive float64
+	var upS, upB, downS, downB float64
+	for i := range strategy {
+		meanS += strategy[i]
+		meanB += benchmark[i]
+		meanActive += strategy[i] - benchmark[i]
+
+		if benchmark[i] > 0 {
+			upS += strategy[i]
+			upB += benchmark[i]
+		} else if benchmark[i] < 0 {
+			downS += strategy[i]
+			downB += benchmark[i]
+		}
+	}
+	meanS, meanB, meanActive = meanS/n, meanB/n, meanActive/n
+
+	var varS, varB, cov, varActive float64
+	for i := range strategy {
+		ds, db := strategy[i]-meanS, benchmark[i]-meanB
+		da := strategy[i] - benchmark[i] - meanActive
+		varS += ds * ds
+		varB += db * db
+		cov += ds * db
+		varActive += da * da
+	}
+	varS, varB, cov, varActive = varS/(n-1), varB/(n-1), cov/(n-1), varActive/(n-1)
+
+	if varB > 0 {
+		m.Beta = cov / varB
+	}
+	m.Alpha = ((meanS - riskFree) - m.Beta*(meanB-riskFree)) * periods * 100
+	if varS > 0 && varB > 0 {
+		m.Correlation = cov / math.Sqrt(varS*varB)
+	}
+
+	trackingError := math.Sqrt(varActive)
+	m.TrackingError = trackingError * math.Sqrt(periods) * 100
+	if trackingError > 0 {
+		m.InformationRatio = meanActive / trackingError * math.Sqrt(periods)
+	}
+
+	// capture compares the summed returns, the counts of the periods cancel out
+	if upB > 0 {
+		m.UpCapture = upS / upB * 100
+	}
+	if downB < 0 {
+		m.DownCapture = downS / downB * 100
+	}
+}
+
+// curveReturn is the return from the first to the last point of the curve (%)
+func curveReturn(curve []domain.EquityCurve) float64 {
+	first := curve[0].Equity
+	if first <= 0 {
+		return 0
+	}
+	return (curve[len(curve)-1].Equity/first - 1) * 100
+}
@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+)
+
+func benchmarkBars(start time.Time, closes ...float64) []domain.Bar {
+	bars := make([]domain.Bar, len(closes))
+	for i, c := range closes {
+		bars[i] = domain.Bar{Symbol: "SPY", Timestamp: start.AddDate(0, 0, i), Close: c}
+	}
+	return bars
+}
+
+// reference values computed independently in python with the statistics module
+func TestCalculateBenchmark(t *testing.T) {
+	curve := equityCurve(riskStart, 24*time.Hour,
+		10000, 10100, 10050, 10200, 10150, 10300, 10250, 10400, 10350, 10500, 10300)
+	bars := benchmarkBars(riskStart, 400, 404, 401, 405, 406, 410, 407, 409, 404, 412, 410)
+
+	m := &Metrics{}
+	NewCalculator(10000, WithRiskFreeRate(0.04), WithBenchmark(bars)).calculateBenchmark(curve, m)
+
+	got := map[string][2]float64{
+		"benchmark return":  {m.BenchmarkReturn, 2.5},
+		"excess return":     {m.ExcessReturn, 0.5},
+		"alpha":             {m.Alpha, 15.887104828981274},
+		"beta":              {m.Beta, 0.9481157417472922},
+		"correlation":       {m.Correlation, 0.7953451133238394},
+		"tracking error":    {m.TrackingError, 11.735344186127602},
+		"information ratio": {m.InformationRatio, 1.0904688967525216},
+		"up capture":        {m.UpCapture, 112.13111082767877},
+		"down capture":      {m.DownCapture, 105.7760186289855},
+	}
+	for name, v := range got {
+		if !closeTo(v[0], v[1]) {
+			t.Errorf("Expected %s %v, got %v", name, v[1], v[0])
+		}
+	}
+}
+
+func TestAlignBenchmark(t *testing.T) {
+	curve := equityCurve(riskStart, 24*time.Hour, 100, 101, 102, 103, 104)
+	// the benchmark starts a day late and misses the fourth day
+	bars := benchmarkBars(riskStart.AddDate(0, 0, 1), 50, 51)
+	bars = append(bars, domain.Bar{Timestamp: riskStart.AddDate(0, 0, 4), Close: 53})
+
+	strategy, benchmark := alignBenchmark(curve, bars)
+	if len(strategy) != 4 || strategy[0].Equity != 101 {
+		t.Fatalf("Expected the curve from the first benchmark bar, got %+v", strategy)
+	}
+
+	want := []float64{50, 51, 51, 53}
+	for i, w := range want {
+		if benchmark[i].Equity != w || !benchmark[i].Timestamp.Equal(strategy[i].Timestamp) {
+			t.Errorf("Point %d: expected %v at %v, got %+v", i, w, strategy[i].Timestamp, benchmark[i])
+		}
+	}
+}
+
+func TestCalculateBenchmarkWithoutBars(t *testing.T) {
+	curve := equityCurve(riskStart, 24*time.Hour, 100, 101, 102, 103)
+
+	m := &Metrics{}
+	NewCalculator(100).calculateBenchmark(curve, m)
+	if m.Beta != 0 || m.BenchmarkReturn != 0 || m.ExcessReturn != 0 {
+		t.Errorf("Expected no benchmark metrics without bars, got %+v", m)
+	}
+}
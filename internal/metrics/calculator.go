@@ -10,13 +10,47 @@ import (
 // computes performance metrics from trades
 type Calculator struct {
 	initialCapital float64
+	riskFreeRate   float64                 // annual, as a fraction
+	bars           map[string][]domain.Bar // bars traded per symbol, for round trip excursions
+	benchmark      []domain.Bar            // bars of the benchmark in time order
+}
+
+// CalculatorOption configures a Calculator
+type CalculatorOption func(*Calculator)
+
+// WithRiskFreeRate sets the annual risk-free rate, as a fraction, that sharpe,
+// sortino and omega measure excess returns against. the default is 0
+func WithRiskFreeRate(rate float64) CalculatorOption {
+	return func(c *Calculator) {
+		c.riskFreeRate = rate
+	}
+}
+
+// WithBars gives the calculator the bars the trades were made on, so round
+// trips measure their excursions over every bar held
+func WithBars(bars map[string][]domain.Bar) CalculatorOption {
+	return func(c *Calculator) {
+		c.bars = bars
+	}
+}
+
+// WithBenchmark sets the bars of the benchmark the equity curve is compared
+// with, buying and holding it over the same span
+func WithBenchmark(bars []domain.Bar) CalculatorOption {
+	return func(c *Calculator) {
+		c.benchmark = bars
+	}
 }
 
 // NewCalculator creates a new metrics calculator
-func NewCalculator(initialCapital float64) *Calculator {
-	return &Calculator{
+func NewCalculator(initialCapital float64, opts ...CalculatorOption) *Calculator {
+	c := &Calculator{
 		initialCapital: initialCapital,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // computes all metrics from a list of trades
@@ -41,12 +75,35 @@ func (c *Calculator) Calculate(trades []domain.Trade, startDate, endDate time.Ti
 	// drawdown
 	c.calculateDrawdown(trades, m)
 
+	// round trips, also averages the P&L per trade
+	c.calculateRoundTrips(trades, m)
+
 	// sharpe ratio
 	c.calculateSharpe(trades, m)
 
 	return m, nil
 }
 
+// CalculateWithEquity computes the trade metrics, the returns, the
+// drawdowns, the time-series risk metrics and the benchmark comparison of
+// the marked-to-market equity curve, which replace the trade based returns,
+// drawdown and sharpe ratio
+func (c *Calculator) CalculateWithEquity(trades []domain.Trade, curve []domain.EquityCurve, startDate, endDate time.Time) (*Metrics, error) {
+	m, err := c.Calculate(trades, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(curve) > 0 {
+		// the last point also values the positions still open
+		c.setFinalCapital(curve[len(curve)-1].Equity, m)
+	}
+	c.calculateEquityDrawdown(curve, m)
+	c.calculateRisk(curve, m)
+	c.calculateBenchmark(curve, m)
+	return m, nil
+}
+
 // emptyMetrics returns metrics for a backtest with no trades
 func (c *Calculator) emptyMetrics(startDate, endDate time.Time) *Metrics {
 	return &Metrics{
@@ -66,8 +123,21 @@ func (c *Calculator) calculateTradeStats(trades []domain.Trade, m *Metrics) {
 	m.TotalTrades = len(trades)
 
 	for _, trade := range trades {
-		// only count P&L from sell trades
-		if trade.Direction == domain.TradeDirectionSell {
+		m.TotalCommission += trade.Commission
+		m.TotalSlippage += trade.Slippage
+		m.TotalBorrowFees += trade.BorrowFee
+		m.TotalDividends += trade.Dividends
+
+		// only count P&L from trades that close a position
+		if trade.IsClosing() {
+			if trade.Direction == domain.TradeDirectionCover {
+				m.ShortTrades++
+				m.ShortPnL += trade.PnL
+			} else {
+				m.LongTrades++
+				m.LongPnL += trade.PnL
+			}
+
 			if trade.PnL > 0 {
 				m.WinningTrades++
 				m.GrossProfit += trade.PnL
@@ -82,17 +152,11 @@ func (c *Calculator) calculateTradeStats(trades []domain.Trade, m *Metrics) {
 	if m.TotalTrades > 0 {
 		totalPnL := 0.0
 		for _, t := range trades {
-			if t.Direction == domain.TradeDirectionSell {
+			if t.IsClosing() {
 				totalPnL += t.PnL
 			}
 		}
 		m.NetProfit = totalPnL
-
-		// average per closed trade (buy+sell = 1 round trip)
-		roundTrips := len(trades) / 2
-		if roundTrips > 0 {
-			m.AverageTrade = totalPnL / float64(roundTrips)
-		}
 	}
 
 	if m.WinningTrades > 0 {
@@ -111,16 +175,23 @@ func (c *Calculator) calculateTradeStats(trades []domain.Trade, m *Metrics) {
 // calculateReturns computes return metrics
 func (c *Calculator) calculateReturns(trades []domain.Trade, m *Metrics) {
 	// final capital = initial + all realized P&L
-	m.FinalCapital = c.initialCapital
+	finalCapital := c.initialCapital
 
 	for _, trade := range trades {
-		if trade.Direction == domain.TradeDirectionSell {
-			m.FinalCapital += trade.PnL
+		if trade.IsClosing() {
+			finalCapital += trade.PnL
 		}
 	}
 
+	c.setFinalCapital(finalCapital, m)
+}
+
+// setFinalCapital sets the final capital and the returns it implies
+func (c *Calculator) setFinalCapital(finalCapital float64, m *Metrics) {
+	m.FinalCapital = finalCapital
 	m.TotalReturn = m.FinalCapital - c.initialCapital
 
+	m.ReturnPct = 0
 	if c.initialCapital > 0 {
 		m.ReturnPct = (m.TotalReturn / c.initialCapital) * 100
 	}
@@ -140,7 +211,7 @@ func (c *Calculator) calculateDrawdown(trades []domain.Trade, m *Metrics) {
 
 	for _, trade := range trades {
 		// update equity after each trade
-		if trade.Direction == domain.TradeDirectionSell {
+		if trade.IsClosing() {
 			equity += trade.PnL
 		}
 
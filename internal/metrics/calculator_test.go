@@ -218,3 +218,113 @@ func TestCalculateDrawdown(t *testing.T) {
 	t.Logf("Max Drawdown: %.2f%% ($%.2f)", metrics.MaxDrawdown, metrics.MaxDrawdownAmt)
 	t.Logf("Final Capital: $%.2f", metrics.FinalCapital)
 }
+
+// TestCalculateCosts tests that trading costs are totaled
+func TestCalculateCosts(t *testing.T) {
+	calculator := NewCalculator(10000.0)
+
+	trades := []domain.Trade{
+		{ID: uuid.New(), Direction: domain.TradeDirectionBuy, Quantity: 100, Price: 100, Commission: 10, Slippage: 5},
+		{ID: uuid.New(), Direction: domain.TradeDirectionSell, Quantity: 100, Price: 110, Commission: 11, Slippage: 5, PnL: 974},
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	metrics, err := calculator.Calculate(trades, start, end)
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	if metrics.TotalCommission != 21 {
+		t.Errorf("Expected total commission 21, got %.2f", metrics.TotalCommission)
+	}
+
+	if metrics.TotalSlippage != 10 {
+		t.Errorf("Expected total slippage 10, got %.2f", metrics.TotalSlippage)
+	}
+
+	if metrics.FinalCapital != 10974 {
+		t.Errorf("Expected final capital 10974, got %.2f", metrics.FinalCapital)
+	}
+}
+
+func TestCalculateShortTrades(t *testing.T) {
+	calculator := NewCalculator(10000.0)
+
+	trades := []domain.Trade{
+		{ID: uuid.New(), Direction: domain.TradeDirectionBuy, Quantity: 100, Price: 100},
+		{ID: uuid.New(), Direction: domain.TradeDirectionSell, Quantity: 100, Price: 95, PnL: -500},
+		{ID: uuid.New(), Direction: domain.TradeDirectionShort, Quantity: 100, Price: 95},
+		{ID: uuid.New(), Direction: domain.TradeDirectionCover, Quantity: 100, Price: 80, BorrowFee: 10, PnL: 1490},
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	metrics, err := calculator.Calculate(trades, start, end)
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	if metrics.LongTrades != 1 || metrics.ShortTrades != 1 {
+		t.Errorf("Expected 1 long and 1 short trade, got %d and %d", metrics.LongTrades, metrics.ShortTrades)
+	}
+
+	if metrics.LongPnL != -500 || metrics.ShortPnL != 1490 {
+		t.Errorf("Expected long P&L -500 and short P&L 1490, got %.2f and %.2f", metrics.LongPnL, metrics.ShortPnL)
+	}
+
+	if metrics.WinningTrades != 1 || metrics.LosingTrades != 1 {
+		t.Errorf("Expected 1 winner and 1 loser, got %d and %d", metrics.WinningTrades, metrics.LosingTrades)
+	}
+
+	if metrics.FinalCapital != 10990 {
+		t.Errorf("Expected final capital 10990, got %.2f", metrics.FinalCapital)
+	}
+
+	if metrics.TotalBorrowFees != 10 {
+		t.Errorf("Expected total borrow fees 10, got %.2f", metrics.TotalBorrowFees)
+	}
+}
+
+func TestCalculateWithEquityOpenPosition(t *testing.T) {
+	calculator := NewCalculator(10000.0)
+
+	// one closed round trip and a long still open, worth 1200 more at the end
+	trades := []domain.Trade{
+		{ID: uuid.New(), Direction: domain.TradeDirectionBuy, Quantity: 100, Price: 100},
+		{ID: uuid.New(), Direction: domain.TradeDirectionSell, Quantity: 100, Price: 105, PnL: 500},
+		{ID: uuid.New(), Direction: domain.TradeDirectionBuy, Quantity: 100, Price: 100},
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	curve := []domain.EquityCurve{
+		{Timestamp: start, Equity: 10000},
+		{Timestamp: start.AddDate(0, 6, 0), Equity: 10500},
+		{Timestamp: end, Equity: 11700},
+	}
+
+	metrics, err := calculator.CalculateWithEquity(trades, curve, start, end)
+	if err != nil {
+		t.Fatalf("CalculateWithEquity failed: %v", err)
+	}
+
+	if metrics.FinalCapital != 11700 || metrics.TotalReturn != 1700 || metrics.ReturnPct != 17 {
+		t.Errorf("Expected the curve to end at 11700 for a 17%% return, got %.2f, %.2f and %.2f%%",
+			metrics.FinalCapital, metrics.TotalReturn, metrics.ReturnPct)
+	}
+	if metrics.NetProfit != 500 {
+		t.Errorf("Expected the realized net profit to stay 500, got %.2f", metrics.NetProfit)
+	}
+
+	// without a curve only the closed trade counts
+	metrics, err = calculator.CalculateWithEquity(trades, nil, start, end)
+	if err != nil {
+		t.Fatalf("CalculateWithEquity failed: %v", err)
+	}
+	if metrics.FinalCapital != 10500 {
+		t.Errorf("Expected final capital 10500 without a curve, got %.2f", metrics.FinalCapital)
+	}
+}
@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+)
+
+// PeriodReturn is the return of one calendar month or year of an equity curve
+type PeriodReturn struct {
+	Year   int
+	Month  time.Month // 0 for a whole year
+	Return float64    // (%)
+}
+
+// ReturnsSummary describes a set of calendar returns
+type ReturnsSummary struct {
+	Best     PeriodReturn
+	Worst    PeriodReturn
+	Positive float64 // share of periods with a positive return (%)
+}
+
+// MonthlyReturns splits the equity curve into calendar months. each month is
+// measured from the last point of the month before, the first one from the
+// start of the curve
+func MonthlyReturns(curve []domain.EquityCurve) []PeriodReturn {
+	return periodReturns(curve, func(t time.Time) PeriodReturn {
+		return PeriodReturn{Year: t.Year(), Month: t.Month()}
+	})
+}
+
+// YearlyReturns splits the equity curve into calendar years like MonthlyReturns
+func YearlyReturns(curve []domain.EquityCurve) []PeriodReturn {
+	return periodReturns(curve, func(t time.Time) PeriodReturn {
+		return PeriodReturn{Year: t.Year()}
+	})
+}
+
+// YearToDate is the return of the last calendar year of the curve up to its
+// last point (%)
+func YearToDate(curve []domain.EquityCurve) float64 {
+	yearly := YearlyReturns(curve)
+	if len(yearly) == 0 {
+		return 0
+	}
+	return yearly[len(yearly)-1].Return
+}
+
+func periodReturns(curve []domain.EquityCurve, period func(time.Time) PeriodReturn) []PeriodReturn {
+	if len(curve) == 0 {
+		return nil
+	}
+
+	var returns []PeriodReturn
+	base := curve[0].Equity
+	for i, point := range curve {
+		current := period(point.Timestamp)
+		if i+1 < len(curve) && period(curve[i+1].Timestamp) == current {
+			continue
+		}
+
+		// last point of the period
+		if base > 0 {
+			current.Return = (point.Equity/base - 1) * 100
+		}
+		returns = append(returns, current)
+		base = point.Equity
+	}
+	return returns
+}
+
+// SummarizeReturns finds the best and worst period and how many were
+// positive, the first of equal periods wins
+func SummarizeReturns(returns []PeriodReturn) ReturnsSummary {
+	var summary ReturnsSummary
+	if len(returns) == 0 {
+		return summary
+	}
+
+	summary.Best, summary.Worst = returns[0], returns[0]
+	positive := 0
+	for _, r := range returns {
+		if r.Return > summary.Best.Return {
+			summary.Best = r
+		}
+		if r.Return < summary.Worst.Return {
+			summary.Worst = r
+		}
+		if r.Return > 0 {
+			positive++
+		}
+	}
+	summary.Positive = float64(positive) / float64(len(returns)) * 100
+	return summary
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+)
+
+func point(year int, month time.Month, day int, equity float64) domain.EquityCurve {
+	return domain.EquityCurve{Timestamp: time.Date(year, month, day, 0, 0, 0, 0, time.UTC), Equity: equity}
+}
+
+func TestCalendarReturns(t *testing.T) {
+	curve := []domain.EquityCurve{
+		point(2023, time.November, 15, 1000),
+		point(2023, time.November, 30, 1100),
+		point(2023, time.December, 29, 1045),
+		point(2024, time.January, 2, 1100),
+		point(2024, time.January, 31, 1254),
+		point(2024, time.March, 1, 1254),
+		point(2024, time.March, 28, 1191.3),
+	}
+
+	monthly := MonthlyReturns(curve)
+	want := []PeriodReturn{
+		{2023, time.November, 10},
+		{2023, time.December, -5},
+		{2024, time.January, 20},
+		{2024, time.March, -5},
+	}
+	if len(monthly) != len(want) {
+		t.Fatalf("Expected %d months, got %v", len(want), monthly)
+	}
+	for i, w := range want {
+		if monthly[i].Year != w.Year || monthly[i].Month != w.Month || !closeTo(monthly[i].Return, w.Return) {
+			t.Errorf("Month %d: expected %+v, got %+v", i, w, monthly[i])
+		}
+	}
+
+	yearly := YearlyReturns(curve)
+	if len(yearly) != 2 || !closeTo(yearly[0].Return, 4.5) || !closeTo(yearly[1].Return, 14) {
+		t.Errorf("Expected 2023 up 4.5%% and 2024 up 14%%, got %+v", yearly)
+	}
+	if ytd := YearToDate(curve); !closeTo(ytd, 14) {
+		t.Errorf("Expected year to date 14%%, got %v", ytd)
+	}
+
+	summary := SummarizeReturns(monthly)
+	if summary.Best.Month != time.January || summary.Worst.Month != time.December || summary.Positive != 50 {
+		t.Errorf("Expected best january, worst december and half the months positive, got %+v", summary)
+	}
+}
+
+func TestCalendarReturnsEmpty(t *testing.T) {
+	if MonthlyReturns(nil) != nil || YearToDate(nil) != 0 {
+		t.Error("Expected no returns for an empty curve")
+	}
+	if summary := SummarizeReturns(nil); summary.Positive != 0 {
+		t.Errorf("Expected an empty summary, got %+v", summary)
+	}
+}
@@ -0,0 +1,160 @@
+package metrics
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+)
+
+// Drawdown is one episode of the equity curve below a previous peak, from
+// the peak until the equity gets back to it
+type Drawdown struct {
+	PeakDate     time.Time
+	TroughDate   time.Time
+	RecoveryDate *time.Time // nil when the curve ends below the peak
+	PeakEquity   float64
+	TroughEquity float64
+	Depth        float64 // peak to trough decline (%)
+	Length       int     // days from the peak to the recovery, or to the end of the curve
+}
+
+// Recovered reports whether the equity got back to the peak
+func (d Drawdown) Recovered() bool {
+	return d.RecoveryDate != nil
+}
+
+// ToDomain converts the episode into the drawdown stored for a backtest
+func (d Drawdown) ToDomain(backtestID uuid.UUID, index int) domain.Drawdown {
+	return domain.Drawdown{
+		BacktestID:   backtestID,
+		Index:        index,
+		PeakDate:     d.PeakDate,
+		TroughDate:   d.TroughDate,
+		RecoveryDate: d.RecoveryDate,
+		PeakEquity:   d.PeakEquity,
+		TroughEquity: d.TroughEquity,
+		Depth:        d.Depth,
+		Length:       d.Length,
+	}
+}
+
+// UnderwaterPoint is how far the equity is below its running peak
+type UnderwaterPoint struct {
+	Timestamp time.Time
+	Drawdown  float64 // decline from the running peak (%), 0 at a new high
+}
+
+// DrawdownAnalysis summarizes every drawdown of an equity curve
+type DrawdownAnalysis struct {
+	Drawdowns       []Drawdown // in the order they started
+	MaxDrawdown     float64    // deepest decline (%)
+	MaxDrawdownAmt  float64    // deepest decline in dollars
+	MaxDuration     int        // longest episode in days
+	AverageDrawdown float64    // mean depth of the episodes (%)
+	TimeUnderWater  float64    // share of the curve below its running peak (%)
+	UlcerIndex      float64    // root mean square of the underwater curve (%)
+}
+
+// Underwater returns the decline from the running peak at every point of
+// the equity curve
+func Underwater(curve []domain.EquityCurve) []UnderwaterPoint {
+	underwater := make([]UnderwaterPoint, len(curve))
+	peak := 0.0
+	for i, point := range curve {
+		peak = math.Max(peak, point.Equity)
+		underwater[i].Timestamp = point.Timestamp
+		if peak > 0 {
+			underwater[i].Drawdown = (peak - point.Equity) / peak * 100
+		}
+	}
+	return underwater
+}
+
+// AnalyzeDrawdowns splits the equity curve into drawdown episodes. an episode
+// starts at the last high before the equity drops and ends at the first
+// point back at or above that high
+func AnalyzeDrawdowns(curve []domain.EquityCurve) *DrawdownAnalysis {
+	analysis := &DrawdownAnalysis{}
+	if len(curve) == 0 {
+		return analysis
+	}
+
+	var current *Drawdown
+	peak, peakDate := curve[0].Equity, curve[0].Timestamp
+	underwater, sumSquares := 0, 0.0
+
+	for _, point := range curve {
+		if point.Equity >= peak {
+			if current != nil {
+				recovery := point.Timestamp
+				current.RecoveryDate = &recovery
+				current.Length = days(current.PeakDate, recovery)
+				analysis.Drawdowns = append(analysis.Drawdowns, *current)
+				current = nil
+			}
+			peak, peakDate = point.Equity, point.Timestamp
+			continue
+		}
+
+		depth := 0.0
+		if peak > 0 {
+			depth = (peak - point.Equity) / peak * 100
+		}
+		underwater++
+		sumSquares += depth * depth
+
+		if current == nil {
+			current = &Drawdown{PeakDate: peakDate, PeakEquity: peak, TroughEquity: math.Inf(1)}
+		}
+		if point.Equity < current.TroughEquity {
+			current.TroughDate = point.Timestamp
+			current.TroughEquity = point.Equity
+			current.Depth = depth
+		}
+		if depth > analysis.MaxDrawdown {
+			analysis.MaxDrawdown = depth
+			analysis.MaxDrawdownAmt = peak - point.Equity
+		}
+	}
+
+	if current != nil {
+		current.Length = days(current.PeakDate, curve[len(curve)-1].Timestamp)
+		analysis.Drawdowns = append(analysis.Drawdowns, *current)
+	}
+
+	for _, d := range analysis.Drawdowns {
+		analysis.AverageDrawdown += d.Depth
+		analysis.MaxDuration = max(analysis.MaxDuration, d.Length)
+	}
+	if n := len(analysis.Drawdowns); n > 0 {
+		analysis.AverageDrawdown /= float64(n)
+	}
+
+	analysis.TimeUnderWater = float64(underwater) / float64(len(curve)) * 100
+	analysis.UlcerIndex = math.Sqrt(sumSquares / float64(len(curve)))
+	return analysis
+}
+
+func days(from, to time.Time) int {
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// calculateEquityDrawdown replaces the drawdown of the realized P&L with the
+// drawdown of the marked-to-market equity curve, which also sees losses of
+// positions that are still open
+func (c *Calculator) calculateEquityDrawdown(curve []domain.EquityCurve, m *Metrics) {
+	if len(curve) == 0 {
+		return
+	}
+
+	analysis := AnalyzeDrawdowns(curve)
+	m.MaxDrawdown = analysis.MaxDrawdown
+	m.MaxDrawdownAmt = analysis.MaxDrawdownAmt
+	m.MaxDrawdownDuration = analysis.MaxDuration
+	m.AverageDrawdown = analysis.AverageDrawdown
+	m.TimeUnderWater = analysis.TimeUnderWater
+	m.UlcerIndex = analysis.UlcerIndex
+	m.Drawdowns = analysis.Drawdowns
+}
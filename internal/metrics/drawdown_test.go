@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+)
+
+func TestAnalyzeDrawdowns(t *testing.T) {
+	day := 24 * time.Hour
+	// two episodes: 100 -> 80 recovered on day 4, then 120 -> 90 still open
+	curve := equityCurve(riskStart, day, 100, 90, 80, 95, 100, 120, 90, 108)
+
+	analysis := AnalyzeDrawdowns(curve)
+	if len(analysis.Drawdowns) != 2 {
+		t.Fatalf("Expected 2 drawdowns, got %d", len(analysis.Drawdowns))
+	}
+
+	first := analysis.Drawdowns[0]
+	if !first.PeakDate.Equal(riskStart) || !first.TroughDate.Equal(riskStart.Add(2*day)) {
+		t.Errorf("Expected peak on day 0 and trough on day 2, got %v and %v", first.PeakDate, first.TroughDate)
+	}
+	if !first.Recovered() || !first.RecoveryDate.Equal(riskStart.Add(4*day)) || first.Length != 4 {
+		t.Errorf("Expected recovery on day 4 after 4 days, got %v after %d", first.RecoveryDate, first.Length)
+	}
+	if !closeTo(first.Depth, 20) || first.PeakEquity != 100 || first.TroughEquity != 80 {
+		t.Errorf("Expected a 20%% drawdown from 100 to 80, got %+v", first)
+	}
+
+	second := analysis.Drawdowns[1]
+	if second.Recovered() || second.Length != 2 || !closeTo(second.Depth, 25) {
+		t.Errorf("Expected an open 25%% drawdown of 2 days, got %+v", second)
+	}
+
+	if !closeTo(analysis.MaxDrawdown, 25) || analysis.MaxDrawdownAmt != 30 || analysis.MaxDuration != 4 {
+		t.Errorf("Expected max drawdown 25%% of 30 lasting 4 days, got %v%% of %v lasting %d",
+			analysis.MaxDrawdown, analysis.MaxDrawdownAmt, analysis.MaxDuration)
+	}
+	if !closeTo(analysis.AverageDrawdown, 22.5) {
+		t.Errorf("Expected average drawdown 22.5, got %v", analysis.AverageDrawdown)
+	}
+	if !closeTo(analysis.TimeUnderWater, 62.5) {
+		t.Errorf("Expected 5 of 8 points under water, got %v", analysis.TimeUnderWater)
+	}
+
+	// underwater depths are 0 10 20 5 0 0 25 10
+	want := math.Sqrt((100 + 400 + 25 + 625 + 100) / 8.0)
+	if !closeTo(analysis.UlcerIndex, want) {
+		t.Errorf("Expected ulcer index %v, got %v", want, analysis.UlcerIndex)
+	}
+}
+
+func TestAnalyzeDrawdownsNoDecline(t *testing.T) {
+	analysis := AnalyzeDrawdowns(equityCurve(riskStart, 24*time.Hour, 100, 100, 110, 120))
+	if len(analysis.Drawdowns) != 0 || analysis.MaxDrawdown != 0 || analysis.UlcerIndex != 0 || analysis.TimeUnderWater != 0 {
+		t.Errorf("Expected no drawdowns for a rising curve, got %+v", analysis)
+	}
+
+	if empty := AnalyzeDrawdowns(nil); len(empty.Drawdowns) != 0 {
+		t.Errorf("Expected no drawdowns for an empty curve, got %+v", empty)
+	}
+}
+
+func TestUnderwater(t *testing.T) {
+	underwater := Underwater(equityCurve(riskStart, 24*time.Hour, 100, 90, 120, 60))
+	want := []float64{0, 10, 0, 50}
+
+	for i, p := range underwater {
+		if !closeTo(p.Drawdown, want[i]) {
+			t.Errorf("Point %d: expected %v, got %v", i, want[i], p.Drawdown)
+		}
+	}
+}
+
+// open positions losing value count towards the drawdown even when nothing
+// was sold at a loss
+func TestCalculateWithEquityDrawdown(t *testing.T) {
+	trades := []domain.Trade{
+		{Symbol: "AAPL", Direction: domain.TradeDirectionBuy, Quantity: 100, Price: 100, Timestamp: riskStart},
+	}
+	curve := equityCurve(riskStart, 24*time.Hour, 10000, 9000, 8500, 9500)
+
+	m, err := NewCalculator(10000).CalculateWithEquity(trades, curve, riskStart, riskStart.Add(3*24*time.Hour))
+	if err != nil {
+		t.Fatalf("CalculateWithEquity failed: %v", err)
+	}
+
+	if !closeTo(m.MaxDrawdown, 15) || m.MaxDrawdownAmt != 1500 {
+		t.Errorf("Expected a 15%% drawdown of 1500, got %v%% of %v", m.MaxDrawdown, m.MaxDrawdownAmt)
+	}
+	if m.MaxDrawdownDuration != 3 || len(m.Drawdowns) != 1 {
+		t.Errorf("Expected one open drawdown of 3 days, got %d lasting %d", len(m.Drawdowns), m.MaxDrawdownDuration)
+	}
+
+	stored := m.ToDomain(uuid.New())
+	if stored.MaxDrawdownDuration != 3 || !closeTo(stored.UlcerIndex, m.UlcerIndex) {
+		t.Errorf("Expected the drawdown metrics to be stored, got %+v", stored)
+	}
+}
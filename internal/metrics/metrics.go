@@ -1,11 +1,17 @@
 package metrics
 
-import "time"
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+)
 
 type Metrics struct {
 	// basic returns
 	InitialCapital float64 // starting cash
-	FinalCapital   float64 // ending cash
+	FinalCapital   float64 // ending cash, or account value with an equity curve
 	TotalReturn    float64 // Total return in dollars
 	ReturnPct      float64 // Total return in percentage
 
@@ -19,14 +25,64 @@ type Metrics struct {
 	GrossProfit  float64 // total profit from winning trades
 	GrossLoss    float64 // total loss from losing trades
 	NetProfit    float64 // gross profit, gross loss
-	AverageTrade float64 // average P&L per trade
+	AverageTrade float64 // average P&L per round trip
 	AverageWin   float64 // average profit on winning trades
 	AverageLoss  float64 // average loss on losing trades
 
+	// trading costs and dividend income, already included in the P&L above
+	TotalCommission float64 // commission paid on all fills
+	TotalSlippage   float64 // cost of fill prices moving against us
+	TotalBorrowFees float64 // stock borrow fees paid on covered shorts
+	TotalDividends  float64 // dividends received on closed shares, net of those paid on shorts
+
+	// long/short breakdown of closed trades
+	LongTrades  int     // number of closed long trades
+	ShortTrades int     // number of covered short trades
+	LongPnL     float64 // realized P&L of long trades
+	ShortPnL    float64 // realized P&L of short trades
+
+	// round trips, see BuildRoundTrips
+	RoundTrips        []RoundTrip // one per exit, in order
+	AverageBarsHeld   float64     // bars from entry to exit
+	AverageWinnerBars float64     // bars held by winning round trips
+	AverageLoserBars  float64     // bars held by losing round trips
+	MaxWinStreak      int         // most winning round trips in a row
+	MaxLossStreak     int         // most losing round trips in a row
+	AverageMAE        float64     // mean maximum adverse excursion (%)
+	AverageMFE        float64     // mean maximum favorable excursion (%)
+	EdgeRatio         float64     // mean favorable over mean adverse excursion
+
 	// risk metrics
 	MaxDrawdown    float64 // largest peak-to-trough decline (%)
 	MaxDrawdownAmt float64 // largest decline in dollars
-	SharpeRatio    float64 // risk-adjusted return
+	SharpeRatio    float64 // annualized excess return over volatility
+
+	// drawdowns of the equity curve, see CalculateWithEquity
+	MaxDrawdownDuration int        // longest drawdown in days, peak to recovery
+	AverageDrawdown     float64    // mean depth of the drawdowns (%)
+	TimeUnderWater      float64    // share of the curve below its running peak (%)
+	UlcerIndex          float64    // root mean square decline from the running peak (%)
+	Drawdowns           []Drawdown // every drawdown episode in order
+
+	// time-series risk of the equity curve returns, see CalculateWithEquity
+	Volatility   float64 // annualized standard deviation of returns (%)
+	SortinoRatio float64 // annualized excess return over downside deviation
+	CalmarRatio  float64 // compound annual growth over max drawdown of the curve
+	OmegaRatio   float64 // gains over losses around the risk-free rate, 0 without losses
+	Skewness     float64 // of the returns
+	Kurtosis     float64 // excess kurtosis of the returns, 0 for a normal distribution
+	TailRatio    float64 // 95th percentile return over the absolute 5th percentile
+
+	// against buying and holding the benchmark, see WithBenchmark
+	BenchmarkReturn  float64 // of the benchmark over the curve (%)
+	ExcessReturn     float64 // curve return minus benchmark return (%)
+	Alpha            float64 // annualized return not explained by beta (%)
+	Beta             float64 // sensitivity of the returns to the benchmark returns
+	Correlation      float64 // of the returns with the benchmark returns
+	TrackingError    float64 // annualized standard deviation of the active returns (%)
+	InformationRatio float64 // annualized active return over tracking error
+	UpCapture        float64 // share of the benchmark gains captured (%)
+	DownCapture      float64 // share of the benchmark losses taken (%)
 
 	// time
 	StartDate time.Time // backtest start date
@@ -53,3 +109,43 @@ func (m *Metrics) Expectancy() float64 {
 	lossProb := float64(m.LosingTrades) / float64(m.TotalTrades)
 	return (winProb * m.AverageWin) - (lossProb * m.AverageLoss)
 }
+
+// annualized return in percentage: ((1 + return_rate)^(365/days) - 1) * 100
+func (m *Metrics) AnnualizedReturn() float64 {
+	if m.Duration <= 0 {
+		return 0
+	}
+
+	returnRate := m.ReturnPct / 100.0
+	return (math.Pow(1+returnRate, 365.0/float64(m.Duration)) - 1) * 100
+}
+
+// ToDomain converts the results into the metrics stored for a backtest
+func (m *Metrics) ToDomain(backtestID uuid.UUID) *domain.Metrics {
+	return &domain.Metrics{
+		ID:                  uuid.New(),
+		BacktestID:          backtestID,
+		TotalReturn:         m.TotalReturn,
+		AnnualizedReturn:    m.AnnualizedReturn(),
+		SharpeRatio:         m.SharpeRatio,
+		Volatility:          m.Volatility,
+		SortinoRatio:        m.SortinoRatio,
+		CalmarRatio:         m.CalmarRatio,
+		OmegaRatio:          m.OmegaRatio,
+		Skewness:            m.Skewness,
+		Kurtosis:            m.Kurtosis,
+		TailRatio:           m.TailRatio,
+		MaxDrawdown:         m.MaxDrawdown,
+		MaxDrawdownDuration: m.MaxDrawdownDuration,
+		AvgDrawdown:         m.AverageDrawdown,
+		TimeUnderWater:      m.TimeUnderWater,
+		UlcerIndex:          m.UlcerIndex,
+		WinRate:             m.WinRate,
+		TotalTrades:         m.TotalTrades,
+		WinningTrades:       m.WinningTrades,
+		LosingTrades:        m.LosingTrades,
+		ProfitFactor:        m.ProfitFactor(),
+		AvgWin:              m.AverageWin,
+		AvgLoss:             m.AverageLoss,
+	}
+}
@@ -0,0 +1,199 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"time"
+
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+)
+
+// MaxSimulations bounds the work of a single Monte Carlo request
+const MaxSimulations = 10000
+
+// MonteCarloMethod is how a simulation reorders the closed trades of a backtest
+type MonteCarloMethod int
+
+const (
+	MonteCarloShuffle  MonteCarloMethod = iota // same trades in a random order
+	MonteCarloResample                         // as many trades drawn with replacement
+	MonteCarloSkip                             // trades in order, each one dropped at random
+)
+
+func (m MonteCarloMethod) String() string {
+	switch m {
+	case MonteCarloShuffle:
+		return "SHUFFLE"
+	case MonteCarloResample:
+		return "RESAMPLE"
+	case MonteCarloSkip:
+		return "SKIP"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// MonteCarloConfig configures a Monte Carlo analysis, the same seed always
+// gives the same result
+type MonteCarloConfig struct {
+	Simulations     int
+	Method          MonteCarloMethod
+	SkipProbability float64 // chance of dropping each trade with MonteCarloSkip
+	RuinThreshold   float64 // fraction of the initial capital lost that counts as ruin
+	Seed            uint64
+}
+
+// Distribution summarizes one metric over all simulations
+type Distribution struct {
+	Mean   float64
+	StdDev float64
+	Min    float64
+	P5     float64
+	P25    float64
+	Median float64
+	P75    float64
+	P95    float64
+	Max    float64
+}
+
+// MonteCarloResult is the spread of outcomes the trades of a backtest could
+// have produced
+type MonteCarloResult struct {
+	Simulations       int
+	Trades            int // closed trades every simulation draws from
+	FinalCapital      Distribution
+	MaxDrawdown       Distribution
+	SharpeRatio       Distribution
+	ProbabilityOfRuin float64 // share of simulations where equity hit the ruin level
+}
+
+// MonteCarlo replays the closed trades of a backtest in randomized sequences
+// and collects the final capital, max drawdown and sharpe of every sequence.
+// only closing trades carry P&L so opening trades are left out
+func (c *Calculator) MonteCarlo(trades []domain.Trade, startDate, endDate time.Time, cfg MonteCarloConfig) (*MonteCarloResult, error) {
+	if cfg.Simulations <= 0 || cfg.Simulations > MaxSimulations {
+		return nil, fmt.Errorf("simulations must be between 1 and %d, got %d", MaxSimulations, cfg.Simulations)
+	}
+	if cfg.SkipProbability < 0 || cfg.SkipProbability >= 1 {
+		return nil, fmt.Errorf("skip probability must be in [0, 1), got %v", cfg.SkipProbability)
+	}
+	if cfg.RuinThreshold <= 0 || cfg.RuinThreshold > 1 {
+		return nil, fmt.Errorf("ruin threshold must be in (0, 1], got %v", cfg.RuinThreshold)
+	}
+
+	var closed []domain.Trade
+	for _, trade := range trades {
+		if trade.IsClosing() {
+			closed = append(closed, trade)
+		}
+	}
+	if len(closed) == 0 {
+		return nil, fmt.Errorf("backtest has no closed trades to simulate")
+	}
+
+	rng := rand.New(rand.NewPCG(cfg.Seed, cfg.Seed))
+	ruinLevel := c.initialCapital * (1 - cfg.RuinThreshold)
+
+	finals := make([]float64, cfg.Simulations)
+	drawdowns := make([]float64, cfg.Simulations)
+	sharpes := make([]float64, cfg.Simulations)
+	ruined := 0
+
+	sequence := make([]domain.Trade, 0, len(closed))
+	for i := range cfg.Simulations {
+		sequence = sample(rng, closed, sequence[:0], cfg)
+
+		m, err := c.Calculate(sequence, startDate, endDate)
+		if err != nil {
+			return nil, err
+		}
+		finals[i] = m.FinalCapital
+		drawdowns[i] = m.MaxDrawdown
+		sharpes[i] = m.SharpeRatio
+
+		if lowestEquity(c.initialCapital, sequence) <= ruinLevel {
+			ruined++
+		}
+	}
+
+	return &MonteCarloResult{
+		Simulations:       cfg.Simulations,
+		Trades:            len(closed),
+		FinalCapital:      distribution(finals),
+		MaxDrawdown:       distribution(drawdowns),
+		SharpeRatio:       distribution(sharpes),
+		ProbabilityOfRuin: float64(ruined) / float64(cfg.Simulations),
+	}, nil
+}
+
+// sample appends one randomized sequence of the closed trades to dst
+func sample(rng *rand.Rand, closed, dst []domain.Trade, cfg MonteCarloConfig) []domain.Trade {
+	switch cfg.Method {
+	case MonteCarloResample:
+		for range closed {
+			dst = append(dst, closed[rng.IntN(len(closed))])
+		}
+	case MonteCarloSkip:
+		for _, trade := range closed {
+			if rng.Float64() >= cfg.SkipProbability {
+				dst = append(dst, trade)
+			}
+		}
+	default:
+		dst = append(dst, closed...)
+		rng.Shuffle(len(dst), func(i, j int) { dst[i], dst[j] = dst[j], dst[i] })
+	}
+	return dst
+}
+
+// lowestEquity is the lowest point the account reaches over the sequence
+func lowestEquity(capital float64, trades []domain.Trade) float64 {
+	equity, lowest := capital, capital
+	for _, trade := range trades {
+		equity += trade.PnL
+		lowest = math.Min(lowest, equity)
+	}
+	return lowest
+}
+
+// distribution summarizes the values, percentiles interpolate linearly
+// between the closest ranks
+func distribution(values []float64) Distribution {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	mean := 0.0
+	for _, v := range sorted {
+		mean += v
+	}
+	mean /= float64(len(sorted))
+
+	variance := 0.0
+	for _, v := range sorted {
+		variance += (v - mean) * (v - mean)
+	}
+	variance /= float64(len(sorted))
+
+	return Distribution{
+		Mean:   mean,
+		StdDev: math.Sqrt(variance),
+		Min:    sorted[0],
+		P5:     percentile(sorted, 5),
+		P25:    percentile(sorted, 25),
+		Median: percentile(sorted, 50),
+		P75:    percentile(sorted, 75),
+		P95:    percentile(sorted, 95),
+		Max:    sorted[len(sorted)-1],
+	}
+}
+
+// percentile of sorted values, p in [0, 100]
+func percentile(sorted []float64, p float64) float64 {
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	weight := rank - float64(lower)
+	return sorted[lower]*(1-weight) + sorted[upper]*weight
+}
@@ -0,0 +1,136 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+)
+
+// closedTrades builds a buy and a sell per P&L, only the sells count
+func closedTrades(pnls ...float64) []domain.Trade {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var trades []domain.Trade
+	for i, pnl := range pnls {
+		day := start.AddDate(0, 0, 2*i)
+		trades = append(trades,
+			domain.Trade{Symbol: "TEST", Direction: domain.TradeDirectionBuy, Quantity: 10, Price: 100, Timestamp: day},
+			domain.Trade{Symbol: "TEST", Direction: domain.TradeDirectionSell, Quantity: 10, Price: 100 + pnl/10, Timestamp: day.AddDate(0, 0, 1), PnL: pnl},
+		)
+	}
+	return trades
+}
+
+func TestMonteCarlo(t *testing.T) {
+	calculator := NewCalculator(10000)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	trades := closedTrades(500, -300, 800, -1200, 400, 200, -100, 600)
+
+	cfg := MonteCarloConfig{Simulations: 500, Method: MonteCarloShuffle, RuinThreshold: 0.5, Seed: 42}
+
+	t.Run("shuffle keeps the totals", func(t *testing.T) {
+		result, err := calculator.MonteCarlo(trades, start, end, cfg)
+		if err != nil {
+			t.Fatalf("Monte Carlo failed: %v", err)
+		}
+
+		if result.Trades != 8 || result.Simulations != 500 {
+			t.Errorf("Expected 500 simulations of 8 trades, got %d of %d", result.Simulations, result.Trades)
+		}
+		// the order changes the path but not where it ends
+		if result.FinalCapital.Min != 10900 || result.FinalCapital.Max != 10900 {
+			t.Errorf("Expected every final capital at 10900, got %v to %v", result.FinalCapital.Min, result.FinalCapital.Max)
+		}
+		if result.MaxDrawdown.Min >= result.MaxDrawdown.Max {
+			t.Errorf("Expected the drawdown to depend on the order, got %v to %v", result.MaxDrawdown.Min, result.MaxDrawdown.Max)
+		}
+		if result.ProbabilityOfRuin != 0 {
+			t.Errorf("Expected no ruin, got %v", result.ProbabilityOfRuin)
+		}
+	})
+
+	t.Run("same seed same result", func(t *testing.T) {
+		resample := cfg
+		resample.Method = MonteCarloResample
+
+		a, err := calculator.MonteCarlo(trades, start, end, resample)
+		if err != nil {
+			t.Fatalf("Monte Carlo failed: %v", err)
+		}
+		b, _ := calculator.MonteCarlo(trades, start, end, resample)
+		if *a != *b {
+			t.Errorf("Expected identical results for the same seed")
+		}
+
+		resample.Seed = 7
+		c, _ := calculator.MonteCarlo(trades, start, end, resample)
+		if *a == *c {
+			t.Errorf("Expected a different seed to change the result")
+		}
+		if a.FinalCapital.Min >= a.FinalCapital.Max {
+			t.Errorf("Expected resampling to spread the final capital")
+		}
+	})
+
+	t.Run("skip nothing", func(t *testing.T) {
+		skip := cfg
+		skip.Method = MonteCarloSkip
+
+		result, err := calculator.MonteCarlo(trades, start, end, skip)
+		if err != nil {
+			t.Fatalf("Monte Carlo failed: %v", err)
+		}
+		original, _ := calculator.Calculate(trades, start, end)
+		if result.MaxDrawdown.Min != original.MaxDrawdown || result.MaxDrawdown.Max != original.MaxDrawdown {
+			t.Errorf("Expected the original drawdown %v every time, got %+v", original.MaxDrawdown, result.MaxDrawdown)
+		}
+	})
+
+	t.Run("ruin", func(t *testing.T) {
+		losing := closedTrades(-2000, -2000, -2000, 1000, 500)
+		result, err := calculator.MonteCarlo(losing, start, end, cfg)
+		if err != nil {
+			t.Fatalf("Monte Carlo failed: %v", err)
+		}
+		// equity always ends at 5500, it dips to 4000 or below unless a
+		// win comes before the last loss
+		if result.ProbabilityOfRuin <= 0 || result.ProbabilityOfRuin >= 1 {
+			t.Errorf("Expected some simulations to be ruined, got %v", result.ProbabilityOfRuin)
+		}
+	})
+
+	errorCases := []struct {
+		name   string
+		trades []domain.Trade
+		cfg    MonteCarloConfig
+	}{
+		{"no simulations", trades, MonteCarloConfig{RuinThreshold: 0.5}},
+		{"too many simulations", trades, MonteCarloConfig{Simulations: MaxSimulations + 1, RuinThreshold: 0.5}},
+		{"skip everything", trades, MonteCarloConfig{Simulations: 1, SkipProbability: 1, RuinThreshold: 0.5}},
+		{"no ruin threshold", trades, MonteCarloConfig{Simulations: 1}},
+		{"no closed trades", closedTrades(), MonteCarloConfig{Simulations: 1, RuinThreshold: 0.5}},
+	}
+	for _, tt := range errorCases {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := calculator.MonteCarlo(tt.trades, start, end, tt.cfg); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+func TestDistribution(t *testing.T) {
+	d := distribution([]float64{5, 1, 4, 2, 3})
+
+	want := Distribution{Mean: 3, StdDev: math.Sqrt2, Min: 1, P5: 1.2, P25: 2, Median: 3, P75: 4, P95: 4.8, Max: 5}
+	got := []float64{d.Mean, d.StdDev, d.Min, d.P5, d.P25, d.Median, d.P75, d.P95, d.Max}
+	expected := []float64{want.Mean, want.StdDev, want.Min, want.P5, want.P25, want.Median, want.P75, want.P95, want.Max}
+	for i := range got {
+		if math.Abs(got[i]-expected[i]) > 1e-9 {
+			t.Errorf("Expected %+v, got %+v", want, d)
+			break
+		}
+	}
+}
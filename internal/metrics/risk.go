@@ -0,0 +1,165 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+)
+
+// ReturnSeries is the periodic simple returns of an equity curve and how
+// many such periods make a year
+type ReturnSeries struct {
+	Returns        []float64
+	PeriodsPerYear float64
+}
+
+// NewReturnSeries derives returns from a marked-to-market equity curve.
+// intraday curves are collapsed to the last point of every day first so the
+// returns are at most daily. the annualization factor follows from the
+// median spacing of the points: 252 for daily bars, 52 weekly, 12 monthly,
+// 4 quarterly and 1 for anything slower
+func NewReturnSeries(curve []domain.EquityCurve) ReturnSeries {
+	if len(curve) < 2 {
+		return ReturnSeries{PeriodsPerYear: 252}
+	}
+
+	spacing := medianSpacing(curve)
+	if spacing < 20*time.Hour {
+		curve = lastPerDay(curve)
+		spacing = 24 * time.Hour
+	}
+
+	series := ReturnSeries{PeriodsPerYear: periodsPerYear(spacing)}
+	for i := 1; i < len(curve); i++ {
+		prev := curve[i-1].Equity
+		if prev <= 0 {
+			series.Returns = append(series.Returns, 0)
+			continue
+		}
+		series.Returns = append(series.Returns, curve[i].Equity/prev-1)
+	}
+	return series
+}
+
+func medianSpacing(curve []domain.EquityCurve) time.Duration {
+	gaps := make([]time.Duration, 0, len(curve)-1)
+	for i := 1; i < len(curve); i++ {
+		gaps = append(gaps, curve[i].Timestamp.Sub(curve[i-1].Timestamp))
+	}
+	sort.Slice(gaps, func(i, j int) bool { return gaps[i] < gaps[j] })
+	return gaps[len(gaps)/2]
+}
+
+// lastPerDay keeps the closing point of every calendar day
+func lastPerDay(curve []domain.EquityCurve) []domain.EquityCurve {
+	var daily []domain.EquityCurve
+	for i, point := range curve {
+		if i+1 < len(curve) && sameDay(point.Timestamp, curve[i+1].Timestamp) {
+			continue
+		}
+		daily = append(daily, point)
+	}
+	return daily
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.In(a.Location()).Date()
+	return ay == by && am == bm && ad == bd
+}
+
+func periodsPerYear(spacing time.Duration) float64 {
+	days := spacing.Hours() / 24
+	switch {
+	case days <= 4:
+		return 252
+	case days <= 10:
+		return 52
+	case days <= 45:
+		return 12
+	case days <= 120:
+		return 4
+	default:
+		return 1
+	}
+}
+
+// calculateRisk computes the time-series risk metrics of the equity curve,
+// curves with fewer than three points leave them at zero
+func (c *Calculator) calculateRisk(curve []domain.EquityCurve, m *Metrics) {
+	m.SharpeRatio = 0
+
+	series := NewReturnSeries(curve)
+	returns := series.Returns
+	if len(returns) < 2 {
+		return
+	}
+
+	periods := series.PeriodsPerYear
+	riskFree := math.Pow(1+c.riskFreeRate, 1/periods) - 1
+	n := float64(len(returns))
+
+	mean := 0.0
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= n
+
+	// sample variance for the ratios, population moments for the shape
+	var m2, m3, m4, downside, gains, losses float64
+	for _, r := range returns {
+		d := r - mean
+		m2 += d * d
+		m3 += d * d * d
+		m4 += d * d * d * d
+
+		excess := r - riskFree
+		if excess < 0 {
+			downside += excess * excess
+			losses -= excess
+		} else {
+			gains += excess
+		}
+	}
+	stdDev := math.Sqrt(m2 / (n - 1))
+	downsideDev := math.Sqrt(downside / n)
+
+	m.Volatility = stdDev * math.Sqrt(periods) * 100
+	if stdDev > 0 {
+		m.SharpeRatio = (mean - riskFree) / stdDev * math.Sqrt(periods)
+	}
+	if downsideDev > 0 {
+		m.SortinoRatio = (mean - riskFree) / downsideDev * math.Sqrt(periods)
+	}
+	if losses > 0 {
+		m.OmegaRatio = gains / losses
+	}
+
+	if variance := m2 / n; variance > 0 {
+		m.Skewness = (m3 / n) / math.Pow(variance, 1.5)
+		m.Kurtosis = (m4/n)/(variance*variance) - 3
+	}
+
+	sorted := append([]float64(nil), returns...)
+	sort.Float64s(sorted)
+	if p5 := percentile(sorted, 5); p5 != 0 {
+		m.TailRatio = math.Abs(percentile(sorted, 95)) / math.Abs(p5)
+	}
+
+	if drawdown := AnalyzeDrawdowns(curve).MaxDrawdown / 100; drawdown > 0 {
+		m.CalmarRatio = compoundAnnualGrowth(curve) / drawdown
+	}
+}
+
+// compoundAnnualGrowth is the yearly growth rate from the first to the last
+// point of the curve, as a fraction
+func compoundAnnualGrowth(curve []domain.EquityCurve) float64 {
+	first, last := curve[0], curve[len(curve)-1]
+	years := last.Timestamp.Sub(first.Timestamp).Hours() / 24 / 365.25
+	if years <= 0 || first.Equity <= 0 || last.Equity <= 0 {
+		return 0
+	}
+	return math.Pow(last.Equity/first.Equity, 1/years) - 1
+}
@@ -0,0 +1,149 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+)
+
+func equityCurve(start time.Time, step time.Duration, equity ...float64) []domain.EquityCurve {
+	curve := make([]domain.EquityCurve, len(equity))
+	for i, e := range equity {
+		curve[i] = domain.EquityCurve{Timestamp: start.Add(time.Duration(i) * step), Equity: e}
+	}
+	return curve
+}
+
+var riskStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// reference values computed independently in python with the statistics module
+func TestCalculateRisk(t *testing.T) {
+	curve := equityCurve(riskStart, 24*time.Hour,
+		10000, 10100, 10050, 10200, 10150, 10300, 10250, 10400, 10350, 10500, 10300)
+
+	tests := []struct {
+		name     string
+		riskFree float64
+		want     Metrics
+	}{
+		{"no risk-free rate", 0, Metrics{SharpeRatio: 3.9499970516258727}},
+		{"risk-free rate", 0.04, Metrics{
+			Volatility:   19.31052694407289,
+			SharpeRatio:  3.7468758963254603,
+			SortinoRatio: 6.647275537532145,
+			CalmarRatio:  102.039298229975,
+			OmegaRatio:   1.7298343205203077,
+			Skewness:     -0.39139433393527073,
+			Kurtosis:     -1.1394792470577657,
+			TailRatio:    1.169655462744254,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &Metrics{}
+			NewCalculator(10000, WithRiskFreeRate(tt.riskFree)).calculateRisk(curve, m)
+
+			if !closeTo(m.SharpeRatio, tt.want.SharpeRatio) {
+				t.Errorf("Expected sharpe %v, got %v", tt.want.SharpeRatio, m.SharpeRatio)
+			}
+			if tt.riskFree == 0 {
+				return
+			}
+
+			got := map[string][2]float64{
+				"volatility": {m.Volatility, tt.want.Volatility},
+				"sortino":    {m.SortinoRatio, tt.want.SortinoRatio},
+				"calmar":     {m.CalmarRatio, tt.want.CalmarRatio},
+				"omega":      {m.OmegaRatio, tt.want.OmegaRatio},
+				"skewness":   {m.Skewness, tt.want.Skewness},
+				"kurtosis":   {m.Kurtosis, tt.want.Kurtosis},
+				"tail ratio": {m.TailRatio, tt.want.TailRatio},
+			}
+			for name, v := range got {
+				if !closeTo(v[0], v[1]) {
+					t.Errorf("Expected %s %v, got %v", name, v[1], v[0])
+				}
+			}
+		})
+	}
+}
+
+func closeTo(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9*math.Max(1, math.Abs(b))
+}
+
+func TestCalculateRiskShortCurve(t *testing.T) {
+	m := &Metrics{SharpeRatio: 1.5}
+	NewCalculator(10000).calculateRisk(equityCurve(riskStart, 24*time.Hour, 10000, 10100), m)
+
+	if m.SharpeRatio != 0 || m.Volatility != 0 || m.SortinoRatio != 0 {
+		t.Errorf("Expected zero risk metrics for a single return, got %+v", m)
+	}
+}
+
+func TestReturnSeries(t *testing.T) {
+	tests := []struct {
+		name    string
+		step    time.Duration
+		periods float64
+		returns int
+	}{
+		{"daily", 24 * time.Hour, 252, 9},
+		{"weekly", 7 * 24 * time.Hour, 52, 9},
+		{"monthly", 30 * 24 * time.Hour, 12, 9},
+		{"quarterly", 91 * 24 * time.Hour, 4, 9},
+		{"yearly", 365 * 24 * time.Hour, 1, 9},
+		// ten hourly bars on one day collapse to a single point
+		{"intraday", time.Hour, 252, 0},
+		// six hour bars over two and a half days leave three daily points
+		{"intraday over days", 6 * time.Hour, 252, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			curve := equityCurve(riskStart, tt.step, 100, 101, 102, 103, 104, 105, 106, 107, 108, 109)
+			series := NewReturnSeries(curve)
+
+			if series.PeriodsPerYear != tt.periods {
+				t.Errorf("Expected %v periods per year, got %v", tt.periods, series.PeriodsPerYear)
+			}
+			if len(series.Returns) != tt.returns {
+				t.Errorf("Expected %d returns, got %d", tt.returns, len(series.Returns))
+			}
+		})
+	}
+
+	// daily closes are the last bar of each day
+	curve := equityCurve(riskStart, 12*time.Hour, 100, 90, 50, 99, 108.9)
+	series := NewReturnSeries(curve)
+	if len(series.Returns) != 2 || !closeTo(series.Returns[0], 0.1) || !closeTo(series.Returns[1], 0.1) {
+		t.Errorf("Expected daily returns [0.1 0.1], got %v", series.Returns)
+	}
+}
+
+// the sharpe ratio of the equity curve does not depend on how many trades
+// produced it
+func TestCalculateWithEquity(t *testing.T) {
+	curve := equityCurve(riskStart, 24*time.Hour, 10000, 10100, 10050, 10200, 10150, 10300)
+	trades := []domain.Trade{
+		{Symbol: "AAPL", Direction: domain.TradeDirectionBuy, Quantity: 10, Price: 100, Timestamp: riskStart},
+		{Symbol: "AAPL", Direction: domain.TradeDirectionSell, Quantity: 10, Price: 130, PnL: 300, Timestamp: riskStart.Add(5 * 24 * time.Hour)},
+	}
+
+	m, err := NewCalculator(10000).CalculateWithEquity(trades, curve, riskStart, riskStart.Add(5*24*time.Hour))
+	if err != nil {
+		t.Fatalf("CalculateWithEquity failed: %v", err)
+	}
+
+	want := &Metrics{}
+	NewCalculator(10000).calculateRisk(curve, want)
+	if m.SharpeRatio != want.SharpeRatio || m.SharpeRatio <= 0 {
+		t.Errorf("Expected the curve sharpe %v, got %v", want.SharpeRatio, m.SharpeRatio)
+	}
+	if m.TotalTrades != 2 || m.WinningTrades != 1 {
+		t.Errorf("Expected the trade metrics to be kept, got %d trades %d winners", m.TotalTrades, m.WinningTrades)
+	}
+}
@@ -0,0 +1,189 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+)
+
+// RoundTrip is the part of a position closed by one exit. positions are kept
+// at average cost like the executor does, so a partial exit is a round trip
+// of its own and adding to a position moves the entry price of every later
+// exit. excursions are measured from the entry price over the bars the
+// position was held
+type RoundTrip struct {
+	Symbol     string
+	Short      bool
+	EntryTime  time.Time // when the position was opened from flat
+	ExitTime   time.Time
+	EntryPrice float64 // average cost of the position at the exit
+	ExitPrice  float64
+	Quantity   float64
+	PnL        float64 // realized by the exit, after costs
+	ReturnPct  float64 // P&L over the entry value (%)
+	BarsHeld   int     // bars from the entry up to the exit bar
+	MAE        float64 // maximum adverse excursion (%), 0 or negative
+	MFE        float64 // maximum favorable excursion (%), 0 or positive
+}
+
+// Duration is how long the position was held
+func (r RoundTrip) Duration() time.Duration {
+	return r.ExitTime.Sub(r.EntryTime)
+}
+
+// ToDomain converts the round trip into the one stored for a backtest
+func (r RoundTrip) ToDomain(backtestID uuid.UUID, index int) domain.RoundTrip {
+	side := domain.PositionSideLong
+	if r.Short {
+		side = domain.PositionSideShort
+	}
+
+	return domain.RoundTrip{
+		BacktestID: backtestID,
+		Index:      index,
+		Symbol:     r.Symbol,
+		Side:       side,
+		EntryTime:  r.EntryTime,
+		ExitTime:   r.ExitTime,
+		EntryPrice: r.EntryPrice,
+		ExitPrice:  r.ExitPrice,
+		Quantity:   r.Quantity,
+		PnL:        r.PnL,
+		ReturnPct:  r.ReturnPct,
+		BarsHeld:   r.BarsHeld,
+		MAE:        r.MAE,
+		MFE:        r.MFE,
+	}
+}
+
+// openPosition is a position rebuilt from the trades
+type openPosition struct {
+	shares     float64 // always positive
+	entryPrice float64
+	entryTime  time.Time
+	short      bool
+}
+
+// BuildRoundTrips pairs the exits of the trades with the positions they
+// close, in the order of the exits. bars are the bars of every symbol in
+// time order, without them the excursions only see the entry and exit price
+func BuildRoundTrips(trades []domain.Trade, bars map[string][]domain.Bar) []RoundTrip {
+	positions := make(map[string]*openPosition)
+	var trips []RoundTrip
+
+	for _, trade := range trades {
+		position := positions[trade.Symbol]
+
+		if !trade.IsClosing() {
+			short := trade.Direction == domain.TradeDirectionShort
+			if position == nil || position.shares == 0 {
+				position = &openPosition{entryTime: trade.Timestamp, short: short}
+				positions[trade.Symbol] = position
+			}
+			total := position.shares + trade.Quantity
+			position.entryPrice = (position.shares*position.entryPrice + trade.Quantity*trade.Price) / total
+			position.shares = total
+			continue
+		}
+
+		// an exit without a known entry has nothing to measure against
+		if position == nil || position.shares == 0 {
+			continue
+		}
+
+		trip := RoundTrip{
+			Symbol:     trade.Symbol,
+			Short:      position.short,
+			EntryTime:  position.entryTime,
+			ExitTime:   trade.Timestamp,
+			EntryPrice: position.entryPrice,
+			ExitPrice:  trade.Price,
+			Quantity:   trade.Quantity,
+			PnL:        trade.PnL,
+		}
+		if value := trip.EntryPrice * trip.Quantity; value > 0 {
+			trip.ReturnPct = trip.PnL / value * 100
+		}
+		trip.measure(bars[trade.Symbol])
+		trips = append(trips, trip)
+
+		position.shares = math.Max(position.shares-trade.Quantity, 0)
+	}
+
+	return trips
+}
+
+// measure counts the bars held and finds the excursions over the bars from
+// the entry up to, not including, the exit bar and the exit price itself
+func (r *RoundTrip) measure(bars []domain.Bar) {
+	first := sort.Search(len(bars), func(i int) bool { return !bars[i].Timestamp.Before(r.EntryTime) })
+	last := sort.Search(len(bars), func(i int) bool { return !bars[i].Timestamp.Before(r.ExitTime) })
+	r.BarsHeld = max(last-first, 0)
+
+	low, high := math.Min(r.EntryPrice, r.ExitPrice), math.Max(r.EntryPrice, r.ExitPrice)
+	for _, bar := range bars[first:max(last, first)] {
+		low = math.Min(low, bar.Low)
+		high = math.Max(high, bar.High)
+	}
+
+	if r.EntryPrice <= 0 {
+		return
+	}
+	if r.Short {
+		r.MAE = (r.EntryPrice - high) / r.EntryPrice * 100
+		r.MFE = (r.EntryPrice - low) / r.EntryPrice * 100
+	} else {
+		r.MAE = (low - r.EntryPrice) / r.EntryPrice * 100
+		r.MFE = (high - r.EntryPrice) / r.EntryPrice * 100
+	}
+}
+
+// calculateRoundTrips computes the holding period and excursion statistics
+// of the round trips
+func (c *Calculator) calculateRoundTrips(trades []domain.Trade, m *Metrics) {
+	trips := BuildRoundTrips(trades, c.bars)
+	m.RoundTrips = trips
+	if len(trips) == 0 {
+		return
+	}
+
+	var bars, winnerBars, loserBars, winners, losers, winStreak, lossStreak int
+	for _, trip := range trips {
+		bars += trip.BarsHeld
+		m.AverageMAE += trip.MAE
+		m.AverageMFE += trip.MFE
+
+		switch {
+		case trip.PnL > 0:
+			winners++
+			winnerBars += trip.BarsHeld
+			winStreak, lossStreak = winStreak+1, 0
+		case trip.PnL < 0:
+			losers++
+			loserBars += trip.BarsHeld
+			winStreak, lossStreak = 0, lossStreak+1
+		default:
+			winStreak, lossStreak = 0, 0
+		}
+		m.MaxWinStreak = max(m.MaxWinStreak, winStreak)
+		m.MaxLossStreak = max(m.MaxLossStreak, lossStreak)
+	}
+
+	n := float64(len(trips))
+	m.AverageBarsHeld = float64(bars) / n
+	m.AverageMAE /= n
+	m.AverageMFE /= n
+	if winners > 0 {
+		m.AverageWinnerBars = float64(winnerBars) / float64(winners)
+	}
+	if losers > 0 {
+		m.AverageLoserBars = float64(loserBars) / float64(losers)
+	}
+	if m.AverageMAE < 0 {
+		m.EdgeRatio = m.AverageMFE / -m.AverageMAE
+	}
+	m.AverageTrade = m.NetProfit / n
+}
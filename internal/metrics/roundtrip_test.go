@@ -0,0 +1,148 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+)
+
+func bar(day int, low, high float64) domain.Bar {
+	return domain.Bar{
+		Symbol:    "AAPL",
+		Timestamp: riskStart.AddDate(0, 0, day),
+		Open:      (low + high) / 2,
+		High:      high,
+		Low:       low,
+		Close:     (low + high) / 2,
+	}
+}
+
+func trade(day int, direction domain.TradeDirection, quantity, price, pnl float64) domain.Trade {
+	return domain.Trade{
+		Symbol:    "AAPL",
+		Direction: direction,
+		Quantity:  quantity,
+		Price:     price,
+		PnL:       pnl,
+		Timestamp: riskStart.AddDate(0, 0, day),
+	}
+}
+
+func TestBuildRoundTrips(t *testing.T) {
+	bars := map[string][]domain.Bar{"AAPL": {
+		bar(0, 98, 101), bar(1, 95, 104), bar(2, 99, 110), bar(3, 105, 112), bar(4, 90, 100),
+	}}
+
+	t.Run("partial exits", func(t *testing.T) {
+		trades := []domain.Trade{
+			trade(0, domain.TradeDirectionBuy, 10, 100, 0),
+			trade(2, domain.TradeDirectionSell, 4, 108, 32),
+			trade(4, domain.TradeDirectionSell, 6, 95, -30),
+		}
+
+		trips := BuildRoundTrips(trades, bars)
+		if len(trips) != 2 {
+			t.Fatalf("Expected a round trip per exit, got %d", len(trips))
+		}
+
+		first, second := trips[0], trips[1]
+		if first.Quantity != 4 || !first.EntryTime.Equal(riskStart) || first.BarsHeld != 2 {
+			t.Errorf("Expected 4 shares held 2 bars from day 0, got %+v", first)
+		}
+		if !closeTo(first.ReturnPct, 8) || !closeTo(first.MAE, -5) || !closeTo(first.MFE, 8) {
+			t.Errorf("Expected return 8%%, mae -5%% and mfe 8%%, got %v %v %v", first.ReturnPct, first.MAE, first.MFE)
+		}
+
+		// the rest of the position keeps the original entry
+		if second.Quantity != 6 || !second.EntryTime.Equal(riskStart) || second.BarsHeld != 4 {
+			t.Errorf("Expected 6 shares held 4 bars from day 0, got %+v", second)
+		}
+		if !closeTo(second.ReturnPct, -5) || !closeTo(second.MAE, -5) || !closeTo(second.MFE, 12) {
+			t.Errorf("Expected return -5%%, mae -5%% and mfe 12%%, got %v %v %v", second.ReturnPct, second.MAE, second.MFE)
+		}
+		if stored := first.ToDomain(uuid.New(), 0); stored.Side != domain.PositionSideLong || stored.Side.String() != "LONG" {
+			t.Errorf("Expected a stored long round trip, got %+v", stored)
+		}
+	})
+
+	t.Run("averaged entry", func(t *testing.T) {
+		trades := []domain.Trade{
+			trade(0, domain.TradeDirectionBuy, 10, 100, 0),
+			trade(1, domain.TradeDirectionBuy, 10, 104, 0),
+			trade(3, domain.TradeDirectionSell, 20, 110, 160),
+		}
+
+		trips := BuildRoundTrips(trades, bars)
+		if len(trips) != 1 || trips[0].EntryPrice != 102 || !trips[0].EntryTime.Equal(riskStart) {
+			t.Fatalf("Expected one round trip at the average cost of 102 from day 0, got %+v", trips)
+		}
+	})
+
+	t.Run("short", func(t *testing.T) {
+		trades := []domain.Trade{
+			trade(2, domain.TradeDirectionShort, 10, 100, 0),
+			trade(4, domain.TradeDirectionCover, 10, 95, 50),
+		}
+
+		trips := BuildRoundTrips(trades, bars)
+		if len(trips) != 1 || !trips[0].Short {
+			t.Fatalf("Expected one short round trip, got %+v", trips)
+		}
+		// highs of 110 and 112 run against the short, the cover at 95 is the best price seen
+		if !closeTo(trips[0].MAE, -12) || !closeTo(trips[0].MFE, 5) {
+			t.Errorf("Expected mae -12%% and mfe 5%%, got %v and %v", trips[0].MAE, trips[0].MFE)
+		}
+		if stored := trips[0].ToDomain(uuid.New(), 3); stored.Side != domain.PositionSideShort || stored.Index != 3 {
+			t.Errorf("Expected a stored short round trip, got %+v", stored)
+		}
+	})
+
+	t.Run("without bars", func(t *testing.T) {
+		trades := []domain.Trade{
+			trade(0, domain.TradeDirectionBuy, 10, 100, 0),
+			trade(3, domain.TradeDirectionSell, 10, 90, -100),
+		}
+
+		trips := BuildRoundTrips(trades, nil)
+		if len(trips) != 1 || trips[0].BarsHeld != 0 || !closeTo(trips[0].MAE, -10) || trips[0].MFE != 0 {
+			t.Errorf("Expected excursions from the entry and exit price only, got %+v", trips)
+		}
+		if trips[0].Duration() != 3*24*time.Hour {
+			t.Errorf("Expected a 3 day holding period, got %v", trips[0].Duration())
+		}
+	})
+}
+
+func TestCalculateRoundTrips(t *testing.T) {
+	pnls := []float64{100, 50, -20, -30, -10, 80, 0, 40}
+	var trades []domain.Trade
+	var bars []domain.Bar
+	for i, pnl := range pnls {
+		trades = append(trades,
+			trade(2*i, domain.TradeDirectionBuy, 10, 100, 0),
+			trade(2*i+1, domain.TradeDirectionSell, 10, 100+pnl/10, pnl),
+		)
+		bars = append(bars, bar(2*i, 96, 103), bar(2*i+1, 97, 102))
+	}
+
+	m, err := NewCalculator(10000, WithBars(map[string][]domain.Bar{"AAPL": bars})).
+		Calculate(trades, riskStart, riskStart.AddDate(0, 0, 16))
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	if len(m.RoundTrips) != 8 || !closeTo(m.AverageTrade, 210.0/8) {
+		t.Errorf("Expected 8 round trips averaging 26.25, got %d averaging %v", len(m.RoundTrips), m.AverageTrade)
+	}
+	if m.MaxWinStreak != 2 || m.MaxLossStreak != 3 {
+		t.Errorf("Expected streaks of 2 wins and 3 losses, got %d and %d", m.MaxWinStreak, m.MaxLossStreak)
+	}
+	if m.AverageBarsHeld != 1 || m.AverageWinnerBars != 1 || m.AverageLoserBars != 1 {
+		t.Errorf("Expected every round trip held 1 bar, got %v", m.AverageBarsHeld)
+	}
+	if !closeTo(m.AverageMAE, -4) || m.EdgeRatio <= 0 {
+		t.Errorf("Expected average mae -4%% and a positive edge ratio, got %v and %v", m.AverageMAE, m.EdgeRatio)
+	}
+}
@@ -6,19 +6,21 @@ import (
 	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
 )
 
-// calculateSharpe computes the Sharpe ratio
-// sharpe = (Average Return - Risk Free Rate) / Standard Deviation of Returns
-// this is using 0% risk-free rate for simplicity
+// calculateSharpe computes a Sharpe ratio from closed trades alone, for
+// trade sequences without an equity curve like Monte Carlo simulations.
+// every trade return is a percentage of the initial capital and the ratio is
+// annualized by the number of closed trades per year, the risk-free rate is
+// not applied. CalculateWithEquity replaces it with the time-series ratio
 func (c *Calculator) calculateSharpe(trades []domain.Trade, m *Metrics) {
 	if len(trades) < 2 {
 		m.SharpeRatio = 0
 		return
 	}
 
-	// collect returns from each closed trade (sell trades only)
+	// collect returns from each closed trade (sell and cover trades only)
 	var returns []float64
 	for _, trade := range trades {
-		if trade.Direction == domain.TradeDirectionSell {
+		if trade.IsClosing() {
 			// Return as percentage of capital
 			returnPct := (trade.PnL / c.initialCapital) * 100
 			returns = append(returns, returnPct)
@@ -45,9 +47,9 @@ func (c *Calculator) calculateSharpe(trades []domain.Trade, m *Metrics) {
 	variance /= float64(len(returns))
 	stdDev := math.Sqrt(variance)
 
-	// sharpe ratio (annualized)
-	// assuming ~252 trading days per year
+	// annualize by how many trades close per year, at least a day passes
+	years := math.Max(float64(m.Duration)/365, 1.0/365)
 	if stdDev > 0 {
-		m.SharpeRatio = (avgReturn / stdDev) * math.Sqrt(252)
+		m.SharpeRatio = (avgReturn / stdDev) * math.Sqrt(float64(len(returns))/years)
 	}
 }
@@ -0,0 +1,124 @@
+// Package optimize expands parameter grids, ranks the backtests run for
+// every combination and walks optimizations forward through time
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// MaxCombinations bounds the size of a grid so a single request can't flood
+// the worker pool
+const MaxCombinations = 1000
+
+// Steps lists min, min+step, ... up to and including max. values are rounded
+// to 10 decimals so steps like 0.1 land on the numbers the user typed
+func Steps(min, max, step float64) ([]any, error) {
+	for _, v := range []float64{min, max, step} {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("range bounds must be finite numbers")
+		}
+	}
+	if step <= 0 {
+		return nil, fmt.Errorf("step must be positive, got %v", step)
+	}
+	if max < min {
+		return nil, fmt.Errorf("max %v is below min %v", max, min)
+	}
+
+	count := int(math.Floor((max-min)/step+1e-9)) + 1
+	if count > MaxCombinations {
+		return nil, fmt.Errorf("range has %d values, at most %d are allowed", count, MaxCombinations)
+	}
+
+	values := make([]any, count)
+	for i := range values {
+		values[i] = math.Round((min+float64(i)*step)*1e10) / 1e10
+	}
+	return values, nil
+}
+
+// Range is the values one parameter takes in a grid search, either an
+// explicit list of values or a min, max and step
+type Range struct {
+	Values []any
+	Min    *float64
+	Max    *float64
+	Step   *float64
+}
+
+// Grid expands every parameter range to its list of values
+func Grid(ranges map[string]Range) (map[string][]any, error) {
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("grid needs at least one parameter")
+	}
+
+	grid := make(map[string][]any, len(ranges))
+	for name, r := range ranges {
+		bounded := r.Min != nil || r.Max != nil || r.Step != nil
+
+		switch {
+		case len(r.Values) > 0 && bounded:
+			return nil, fmt.Errorf("parameter %s: use either values or min, max and step", name)
+		case len(r.Values) > 0:
+			grid[name] = r.Values
+		case r.Min != nil && r.Max != nil && r.Step != nil:
+			values, err := Steps(*r.Min, *r.Max, *r.Step)
+			if err != nil {
+				return nil, fmt.Errorf("parameter %s: %w", name, err)
+			}
+			grid[name] = values
+		default:
+			return nil, fmt.Errorf("parameter %s: needs values or min, max and step", name)
+		}
+	}
+
+	return grid, nil
+}
+
+// Expand returns every combination of the grid values merged over the fixed
+// parameters. the order is stable: parameters are varied by name with the
+// last name changing fastest
+func Expand(grid map[string][]any, fixed map[string]any) ([]map[string]any, error) {
+	if len(grid) == 0 {
+		return nil, fmt.Errorf("grid has no parameters to search")
+	}
+
+	names := make([]string, 0, len(grid))
+	total := 1
+	for name, values := range grid {
+		if len(values) == 0 {
+			return nil, fmt.Errorf("parameter %s has no values", name)
+		}
+		if _, ok := fixed[name]; ok {
+			return nil, fmt.Errorf("parameter %s is both fixed and searched", name)
+		}
+		names = append(names, name)
+
+		total *= len(values)
+		if total > MaxCombinations {
+			return nil, fmt.Errorf("grid has more than %d combinations", MaxCombinations)
+		}
+	}
+	sort.Strings(names)
+
+	combinations := make([]map[string]any, total)
+	for i := range combinations {
+		params := make(map[string]any, len(fixed)+len(names))
+		for name, value := range fixed {
+			params[name] = value
+		}
+
+		// decode i as a mixed radix number, one digit per parameter
+		rest := i
+		for j := len(names) - 1; j >= 0; j-- {
+			values := grid[names[j]]
+			params[names[j]] = values[rest%len(values)]
+			rest /= len(values)
+		}
+		combinations[i] = params
+	}
+
+	return combinations, nil
+}
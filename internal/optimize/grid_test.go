@@ -0,0 +1,127 @@
+package optimize
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSteps(t *testing.T) {
+	tests := []struct {
+		name           string
+		min, max, step float64
+		want           []any
+		wantErr        bool
+	}{
+		{name: "integers", min: 10, max: 30, step: 10, want: []any{10.0, 20.0, 30.0}},
+		{name: "max between steps", min: 5, max: 12, step: 5, want: []any{5.0, 10.0}},
+		{name: "decimal steps", min: 0.1, max: 0.3, step: 0.1, want: []any{0.1, 0.2, 0.3}},
+		{name: "single value", min: 7, max: 7, step: 1, want: []any{7.0}},
+		{name: "zero step", min: 1, max: 5, step: 0, wantErr: true},
+		{name: "inverted", min: 5, max: 1, step: 1, wantErr: true},
+		{name: "too many", min: 0, max: 1e6, step: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Steps(tt.min, tt.max, tt.step)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Steps failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestGrid(t *testing.T) {
+	bound := func(v float64) *float64 { return &v }
+
+	grid, err := Grid(map[string]Range{
+		"short_period": {Values: []any{5.0, 8.0}},
+		"long_period":  {Min: bound(20), Max: bound(40), Step: bound(10)},
+	})
+	if err != nil {
+		t.Fatalf("Grid failed: %v", err)
+	}
+
+	want := map[string][]any{
+		"short_period": {5.0, 8.0},
+		"long_period":  {20.0, 30.0, 40.0},
+	}
+	if !reflect.DeepEqual(grid, want) {
+		t.Errorf("Expected %v, got %v", want, grid)
+	}
+
+	errorCases := []struct {
+		name   string
+		ranges map[string]Range
+	}{
+		{"empty grid", map[string]Range{}},
+		{"values and bounds", map[string]Range{"a": {Values: []any{1.0}, Min: bound(1)}}},
+		{"missing step", map[string]Range{"a": {Min: bound(1), Max: bound(5)}}},
+		{"nothing", map[string]Range{"a": {}}},
+		{"bad range", map[string]Range{"a": {Min: bound(5), Max: bound(1), Step: bound(1)}}},
+	}
+	for _, tt := range errorCases {
+		if _, err := Grid(tt.ranges); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	grid := map[string][]any{
+		"short_period": {10.0, 20.0},
+		"long_period":  {30.0, 50.0, 100.0},
+	}
+
+	combinations, err := Expand(grid, map[string]any{"size": 1.0})
+	if err != nil {
+		t.Fatalf("Expand failed: %v", err)
+	}
+
+	if len(combinations) != 6 {
+		t.Fatalf("Expected 6 combinations, got %d", len(combinations))
+	}
+
+	// long_period sorts first so short_period changes fastest
+	want := []map[string]any{
+		{"long_period": 30.0, "short_period": 10.0, "size": 1.0},
+		{"long_period": 30.0, "short_period": 20.0, "size": 1.0},
+		{"long_period": 50.0, "short_period": 10.0, "size": 1.0},
+	}
+	if !reflect.DeepEqual(combinations[:3], want) {
+		t.Errorf("Expected %v, got %v", want, combinations[:3])
+	}
+
+	seen := make(map[[2]float64]bool)
+	for _, c := range combinations {
+		seen[[2]float64{c["short_period"].(float64), c["long_period"].(float64)}] = true
+	}
+	if len(seen) != 6 {
+		t.Errorf("Expected 6 distinct combinations, got %d", len(seen))
+	}
+
+	errorCases := []struct {
+		name  string
+		grid  map[string][]any
+		fixed map[string]any
+	}{
+		{"empty grid", map[string][]any{}, nil},
+		{"empty values", map[string][]any{"a": {}}, nil},
+		{"fixed and searched", map[string][]any{"a": {1.0}}, map[string]any{"a": 2.0}},
+		{"too many", map[string][]any{"a": make([]any, 100), "b": make([]any, 11)}, nil},
+	}
+	for _, tt := range errorCases {
+		if _, err := Expand(tt.grid, tt.fixed); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
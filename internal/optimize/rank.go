@@ -0,0 +1,85 @@
+package optimize
+
+import (
+	"sort"
+
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+)
+
+// Entry is one child backtest of an optimization, Metrics is nil until it
+// completed
+type Entry struct {
+	Backtest *domain.Backtest
+	Metrics  *domain.Metrics
+}
+
+// Ranked is an entry with its place in the table, Rank is 0 for backtests
+// without metrics
+type Ranked struct {
+	Entry
+	Rank  int
+	Score float64
+}
+
+// Score is the value of the objective for metrics
+func Score(m *domain.Metrics, objective domain.OptimizationObjective) float64 {
+	switch objective {
+	case domain.OptimizationObjectiveTotalReturn:
+		return m.TotalReturn
+	case domain.OptimizationObjectiveMaxDrawdown:
+		return m.MaxDrawdown
+	case domain.OptimizationObjectiveProfitFactor:
+		return m.ProfitFactor
+	default:
+		return m.SharpeRatio
+	}
+}
+
+// better reports whether score a beats score b, drawdown is the only
+// objective where lower wins
+func better(a, b float64, objective domain.OptimizationObjective) bool {
+	if objective == domain.OptimizationObjectiveMaxDrawdown {
+		return a < b
+	}
+	return a > b
+}
+
+// idle reports whether metrics are ranked after the ones that traded
+func idle(m *domain.Metrics, objective domain.OptimizationObjective) bool {
+	return objective == domain.OptimizationObjectiveMaxDrawdown && m.TotalTrades == 0
+}
+
+// Rank orders the entries best first by the objective. entries without
+// metrics keep their order at the end of the table, ties keep the grid order.
+// for drawdown, entries that never traded come after the ones that did, an
+// idle strategy has no drawdown and would otherwise always win
+func Rank(entries []Entry, objective domain.OptimizationObjective) []Ranked {
+	ranked := make([]Ranked, len(entries))
+	for i, entry := range entries {
+		ranked[i] = Ranked{Entry: entry}
+		if entry.Metrics != nil {
+			ranked[i].Score = Score(entry.Metrics, objective)
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if (a.Metrics == nil) != (b.Metrics == nil) {
+			return a.Metrics != nil
+		}
+		if a.Metrics == nil {
+			return false
+		}
+		if idle(a.Metrics, objective) != idle(b.Metrics, objective) {
+			return !idle(a.Metrics, objective)
+		}
+		return better(a.Score, b.Score, objective)
+	})
+
+	for i := range ranked {
+		if ranked[i].Metrics != nil {
+			ranked[i].Rank = i + 1
+		}
+	}
+	return ranked
+}
@@ -0,0 +1,57 @@
+package optimize
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+)
+
+func TestRank(t *testing.T) {
+	entry := func(metrics *domain.Metrics) Entry {
+		return Entry{Backtest: &domain.Backtest{ID: uuid.New()}, Metrics: metrics}
+	}
+
+	entries := []Entry{
+		entry(&domain.Metrics{SharpeRatio: 0.5, TotalReturn: 900, MaxDrawdown: 0.10, ProfitFactor: 1.2, TotalTrades: 6}),
+		entry(nil), // failed
+		entry(&domain.Metrics{SharpeRatio: 1.5, TotalReturn: 300, MaxDrawdown: 0.30, ProfitFactor: 2.5, TotalTrades: 4}),
+		entry(&domain.Metrics{SharpeRatio: 1.0, TotalReturn: 600, MaxDrawdown: 0.05, ProfitFactor: 0.8, TotalTrades: 8}),
+		entry(&domain.Metrics{}), // never traded, no drawdown to speak of
+	}
+
+	tests := []struct {
+		objective domain.OptimizationObjective
+		order     []int // indexes into entries, best first
+	}{
+		{domain.OptimizationObjectiveSharpe, []int{2, 3, 0, 4, 1}},
+		{domain.OptimizationObjectiveTotalReturn, []int{0, 3, 2, 4, 1}},
+		{domain.OptimizationObjectiveMaxDrawdown, []int{3, 0, 2, 4, 1}},
+		{domain.OptimizationObjectiveProfitFactor, []int{2, 0, 3, 4, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.objective.String(), func(t *testing.T) {
+			ranked := Rank(entries, tt.objective)
+
+			for i, index := range tt.order {
+				if ranked[i].Backtest != entries[index].Backtest {
+					t.Errorf("Position %d: expected entry %d", i, index)
+				}
+			}
+
+			for i, r := range ranked[:4] {
+				if r.Rank != i+1 {
+					t.Errorf("Expected rank %d, got %d", i+1, r.Rank)
+				}
+			}
+			if last := ranked[4]; last.Rank != 0 || last.Metrics != nil {
+				t.Errorf("Expected the failed backtest unranked at the end, got rank %d", last.Rank)
+			}
+		})
+	}
+
+	if score := Score(entries[0].Metrics, domain.OptimizationObjectiveMaxDrawdown); score != 0.10 {
+		t.Errorf("Expected a drawdown score of 0.10, got %v", score)
+	}
+}
@@ -0,0 +1,248 @@
+package optimize
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/metrics"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/strategy"
+)
+
+// MaxWindows bounds the number of windows of a walk-forward run, every window
+// runs the whole grid again
+const MaxWindows = 100
+
+// Window is one walk-forward step: parameters are chosen on
+// [InStart, InEnd) and traded on [OutStart, OutEnd)
+type Window struct {
+	InStart  time.Time
+	InEnd    time.Time
+	OutStart time.Time
+	OutEnd   time.Time
+}
+
+// Windows splits [start, end) into an in-sample window of inDays followed by
+// an out-of-sample window of outDays, stepping forward by outDays so the
+// out-of-sample windows tile the range after the first in-sample window. the
+// last one is cut at end. rolling windows keep their length, anchored ones
+// all start at start and grow
+func Windows(start, end time.Time, inDays, outDays int, anchored bool) ([]Window, error) {
+	if inDays <= 0 || outDays <= 0 {
+		return nil, fmt.Errorf("in-sample and out-of-sample lengths must be positive, got %d and %d days", inDays, outDays)
+	}
+
+	var windows []Window
+	for i := 0; ; i++ {
+		outStart := start.AddDate(0, 0, inDays+i*outDays)
+		if !outStart.Before(end) {
+			break
+		}
+		if len(windows) == MaxWindows {
+			return nil, fmt.Errorf("range has more than %d windows", MaxWindows)
+		}
+
+		inStart := outStart.AddDate(0, 0, -inDays)
+		if anchored {
+			inStart = start
+		}
+		outEnd := outStart.AddDate(0, 0, outDays)
+		if outEnd.After(end) {
+			outEnd = end
+		}
+
+		windows = append(windows, Window{InStart: inStart, InEnd: outStart, OutStart: outStart, OutEnd: outEnd})
+	}
+
+	if len(windows) == 0 {
+		return nil, fmt.Errorf("range of %d days leaves nothing after a %d day in-sample window",
+			int(end.Sub(start).Hours()/24), inDays)
+	}
+	return windows, nil
+}
+
+// Runner simulates one parameter combination over [start, end) starting with
+// capital
+type Runner func(ctx context.Context, params map[string]any, start, end time.Time, capital float64) (*strategy.Result, error)
+
+// WindowResult is the report of one window: the in-sample winner and how it
+// did out of sample
+type WindowResult struct {
+	Window
+	Parameters       map[string]any
+	Evaluated        int // combinations that completed in sample
+	InSample         *domain.Metrics
+	OutOfSample      *domain.Metrics
+	InSampleScore    float64
+	OutOfSampleScore float64
+	Efficiency       float64
+}
+
+// WalkForwardResult is the out-of-sample trades of every window stitched
+// into one run
+type WalkForwardResult struct {
+	Windows     []WindowResult
+	Trades      []domain.Trade
+	EquityCurve []domain.EquityCurve
+	Bars        map[string][]domain.Bar // out-of-sample bars per symbol
+	Metrics     *domain.Metrics         // of the stitched trades over the out-of-sample span
+	Efficiency  float64                 // stitched return against the mean in-sample return
+}
+
+// WalkForward optimizes the combinations in every in-sample window and
+// trades the winner on the following out-of-sample window. each
+// out-of-sample window starts with the equity the previous one ended with,
+// positions still open at the end of a window are closed at the last close
+// and not carried over
+type WalkForward struct {
+	Windows      []Window
+	Combinations []map[string]any
+	Objective    domain.OptimizationObjective
+	Capital      float64
+	RiskFreeRate float64 // annual, passed on to the metrics calculator
+	Run          Runner
+}
+
+// Execute runs the windows in order, the in-sample combinations of a window
+// run concurrently so Run decides how many execute at once
+func (w *WalkForward) Execute(ctx context.Context) (*WalkForwardResult, error) {
+	if len(w.Windows) == 0 {
+		return nil, fmt.Errorf("walk-forward has no windows")
+	}
+
+	result := &WalkForwardResult{Windows: make([]WindowResult, 0, len(w.Windows))}
+	capital := w.Capital
+	inSampleReturn := 0.0
+
+	for i, window := range w.Windows {
+		report, err := w.optimizeWindow(ctx, window)
+		if err != nil {
+			return nil, fmt.Errorf("window %d: %w", i+1, err)
+		}
+
+		run, err := w.Run(ctx, report.Parameters, window.OutStart, window.OutEnd, capital)
+		if err != nil {
+			return nil, fmt.Errorf("window %d out of sample: %w", i+1, err)
+		}
+		liquidate(run)
+		report.OutOfSample, err = w.evaluate(run.Trades, run.EquityCurve, run.Bars, capital, window.OutStart, window.OutEnd)
+		if err != nil {
+			return nil, fmt.Errorf("window %d out of sample: %w", i+1, err)
+		}
+		report.OutOfSampleScore = Score(report.OutOfSample, w.Objective)
+		report.Efficiency = Efficiency(report.InSample.AnnualizedReturn, report.OutOfSample.AnnualizedReturn)
+
+		result.stitch(run)
+		if n := len(run.EquityCurve); n > 0 {
+			capital = run.EquityCurve[n-1].Equity
+		}
+
+		inSampleReturn += report.InSample.AnnualizedReturn
+		result.Windows = append(result.Windows, report)
+	}
+
+	start, end := w.Windows[0].OutStart, w.Windows[len(w.Windows)-1].OutEnd
+	combined, err := w.evaluate(result.Trades, result.EquityCurve, result.Bars, w.Capital, start, end)
+	if err != nil {
+		return nil, err
+	}
+	result.Metrics = combined
+	result.Efficiency = Efficiency(inSampleReturn/float64(len(w.Windows)), combined.AnnualizedReturn)
+
+	return result, nil
+}
+
+// optimizeWindow runs every combination in sample and picks the best by the
+// objective, combinations that fail are left out
+func (w *WalkForward) optimizeWindow(ctx context.Context, window Window) (WindowResult, error) {
+	entries := make([]Entry, len(w.Combinations))
+	errs := make([]error, len(w.Combinations))
+
+	var wg sync.WaitGroup
+	for i, params := range w.Combinations {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			entries[i].Backtest = &domain.Backtest{Parameters: params}
+			run, err := w.Run(ctx, params, window.InStart, window.InEnd, w.Capital)
+			if err == nil {
+				liquidate(run)
+				entries[i].Metrics, err = w.evaluate(run.Trades, run.EquityCurve, run.Bars, w.Capital, window.InStart, window.InEnd)
+			}
+			errs[i] = err
+		}()
+	}
+	wg.Wait()
+
+	report := WindowResult{Window: window}
+	for _, err := range errs {
+		if err == nil {
+			report.Evaluated++
+		}
+	}
+	if report.Evaluated == 0 {
+		return report, fmt.Errorf("no parameter combination completed in sample, first error: %w", errs[0])
+	}
+
+	best := Rank(entries, w.Objective)[0]
+	report.Parameters = best.Backtest.Parameters
+	report.InSample = best.Metrics
+	report.InSampleScore = best.Score
+	return report, nil
+}
+
+// liquidate records the closing trades of the positions open at the end of
+// a window run, the equity carried into the next window is realized so the
+// trades of the window account for its whole return
+func liquidate(run *strategy.Result) {
+	run.Trades = append(run.Trades, run.Liquidation()...)
+	run.Positions = nil
+}
+
+// stitch appends the trades, equity and bars of an out-of-sample run,
+// cumulative P&L continues from the previous windows
+func (r *WalkForwardResult) stitch(run *strategy.Result) {
+	cumulative := 0.0
+	if n := len(r.Trades); n > 0 {
+		cumulative = r.Trades[n-1].CumulativePnL
+	}
+
+	for _, trade := range run.Trades {
+		if trade.IsClosing() {
+			cumulative += trade.PnL
+		}
+		trade.CumulativePnL = cumulative
+		r.Trades = append(r.Trades, trade)
+	}
+	r.EquityCurve = append(r.EquityCurve, run.EquityCurve...)
+
+	if r.Bars == nil {
+		r.Bars = make(map[string][]domain.Bar, len(run.Bars))
+	}
+	for symbol, bars := range run.Bars {
+		r.Bars[symbol] = append(r.Bars[symbol], bars...)
+	}
+}
+
+// Efficiency is the walk-forward efficiency ratio: the annualized
+// out-of-sample return over the annualized in-sample return. it is 0 when
+// the in-sample return is not positive, there is no edge to keep
+func Efficiency(inSample, outOfSample float64) float64 {
+	if inSample <= 0 {
+		return 0
+	}
+	return outOfSample / inSample
+}
+
+func (w *WalkForward) evaluate(trades []domain.Trade, curve []domain.EquityCurve, bars map[string][]domain.Bar, capital float64, start, end time.Time) (*domain.Metrics, error) {
+	calculator := metrics.NewCalculator(capital, metrics.WithRiskFreeRate(w.RiskFreeRate), metrics.WithBars(bars))
+	results, err := calculator.CalculateWithEquity(trades, curve, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate metrics: %w", err)
+	}
+	return results.ToDomain(uuid.Nil), nil
+}
@@ -0,0 +1,303 @@
+package optimize
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/strategy"
+)
+
+func date(month, day int) time.Time {
+	return time.Date(2024, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestWindows(t *testing.T) {
+	start, end := date(1, 1), date(1, 26)
+
+	rolling, err := Windows(start, end, 10, 5, false)
+	if err != nil {
+		t.Fatalf("Failed to split: %v", err)
+	}
+	want := []Window{
+		{date(1, 1), date(1, 11), date(1, 11), date(1, 16)},
+		{date(1, 6), date(1, 16), date(1, 16), date(1, 21)},
+		{date(1, 11), date(1, 21), date(1, 21), date(1, 26)},
+	}
+	if len(rolling) != len(want) {
+		t.Fatalf("Expected %d windows, got %d", len(want), len(rolling))
+	}
+	for i := range want {
+		if rolling[i] != want[i] {
+			t.Errorf("Window %d: expected %+v, got %+v", i, want[i], rolling[i])
+		}
+	}
+
+	// anchored windows all start at the beginning, the last one is cut at end
+	anchored, err := Windows(start, date(1, 24), 10, 5, true)
+	if err != nil {
+		t.Fatalf("Failed to split: %v", err)
+	}
+	if len(anchored) != 3 {
+		t.Fatalf("Expected 3 windows, got %d", len(anchored))
+	}
+	for _, w := range anchored {
+		if !w.InStart.Equal(start) {
+			t.Errorf("Expected anchored in-sample start %v, got %v", start, w.InStart)
+		}
+	}
+	if last := anchored[2]; !last.InEnd.Equal(date(1, 21)) || !last.OutEnd.Equal(date(1, 24)) {
+		t.Errorf("Expected the last window to trade Jan 21 to Jan 24, got %+v", last)
+	}
+
+	errorCases := []struct {
+		name    string
+		end     time.Time
+		in, out int
+		want    string
+	}{
+		{"zero length", end, 0, 5, "must be positive"},
+		{"too short", date(1, 10), 10, 5, "leaves nothing"},
+		{"too many", date(12, 31), 1, 1, "more than 100 windows"},
+	}
+	for _, tt := range errorCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Windows(start, tt.end, tt.in, tt.out, false)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+// fakeRun closes one trade per run: in sample a parameter p earns 100*p,
+// out of sample 50*p. p = 0 fails. it records the capital of every run
+type fakeRun struct {
+	mu       sync.Mutex
+	outStart map[time.Time]float64
+	windows  []Window
+}
+
+func (f *fakeRun) run(ctx context.Context, params map[string]any, start, end time.Time, capital float64) (*strategy.Result, error) {
+	p := params["p"].(float64)
+	if p == 0 {
+		return nil, errors.New("p must not be zero")
+	}
+
+	pnl := 100 * p
+	for _, w := range f.windows {
+		if start.Equal(w.OutStart) {
+			pnl = 50 * p
+			f.mu.Lock()
+			f.outStart[start] = capital
+			f.mu.Unlock()
+		}
+	}
+
+	return &strategy.Result{
+		Trades: []domain.Trade{
+			{Symbol: "TEST", Direction: domain.TradeDirectionBuy, Quantity: 1, Price: 100, Timestamp: start},
+			{Symbol: "TEST", Direction: domain.TradeDirectionSell, Quantity: 1, Price: 100 + pnl, Timestamp: end, PnL: pnl, CumulativePnL: pnl},
+		},
+		EquityCurve: []domain.EquityCurve{
+			{Timestamp: start, Equity: capital},
+			{Timestamp: end, Equity: capital + pnl},
+		},
+	}, nil
+}
+
+func TestWalkForward(t *testing.T) {
+	windows, err := Windows(date(1, 1), date(3, 1), 30, 10, false)
+	if err != nil {
+		t.Fatalf("Failed to split: %v", err)
+	}
+	fake := &fakeRun{outStart: make(map[time.Time]float64), windows: windows}
+
+	wf := &WalkForward{
+		Windows:      windows,
+		Combinations: []map[string]any{{"p": 1.0}, {"p": 0.0}, {"p": 3.0}, {"p": 2.0}},
+		Objective:    domain.OptimizationObjectiveTotalReturn,
+		Capital:      10000,
+		Run:          fake.run,
+	}
+
+	result, err := wf.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("Walk-forward failed: %v", err)
+	}
+
+	if len(result.Windows) != len(windows) {
+		t.Fatalf("Expected %d window reports, got %d", len(windows), len(result.Windows))
+	}
+	for i, report := range result.Windows {
+		if report.Parameters["p"] != 3.0 {
+			t.Errorf("Window %d: expected p = 3 to win, got %v", i, report.Parameters)
+		}
+		if report.Evaluated != 3 {
+			t.Errorf("Window %d: expected 3 evaluated combinations, got %d", i, report.Evaluated)
+		}
+		if report.InSampleScore != 300 || report.OutOfSampleScore != 150 {
+			t.Errorf("Window %d: expected scores 300 and 150, got %v and %v", i, report.InSampleScore, report.OutOfSampleScore)
+		}
+		want := Efficiency(report.InSample.AnnualizedReturn, report.OutOfSample.AnnualizedReturn)
+		if report.Efficiency != want || want <= 0 {
+			t.Errorf("Window %d: expected a positive efficiency %v, got %v", i, want, report.Efficiency)
+		}
+
+		// every window starts with the equity the previous one ended with
+		if capital := fake.outStart[report.OutStart]; capital != 10000+150*float64(i) {
+			t.Errorf("Window %d: expected starting capital %v, got %v", i, 10000+150*float64(i), capital)
+		}
+	}
+
+	if len(result.Trades) != 2*len(windows) || len(result.EquityCurve) != 2*len(windows) {
+		t.Fatalf("Expected %d stitched trades and points, got %d and %d",
+			2*len(windows), len(result.Trades), len(result.EquityCurve))
+	}
+	last := result.Trades[len(result.Trades)-1]
+	if total := 150 * float64(len(windows)); last.CumulativePnL != total || result.Metrics.TotalReturn != total {
+		t.Errorf("Expected a stitched P&L of %v, got cumulative %v and total %v", total, last.CumulativePnL, result.Metrics.TotalReturn)
+	}
+	if result.Efficiency <= 0 {
+		t.Errorf("Expected a positive combined efficiency, got %v", result.Efficiency)
+	}
+}
+
+func TestWalkForwardClosesOpenPositions(t *testing.T) {
+	windows, err := Windows(date(1, 1), date(3, 1), 30, 10, false)
+	if err != nil {
+		t.Fatalf("Failed to split: %v", err)
+	}
+
+	// buys one share at 100 and holds it, the window ends with a close of 110
+	hold := func(ctx context.Context, params map[string]any, start, end time.Time, capital float64) (*strategy.Result, error) {
+		last := end.AddDate(0, 0, -1)
+		return &strategy.Result{
+			Trades: []domain.Trade{
+				{Symbol: "TEST", Direction: domain.TradeDirectionBuy, Quantity: 1, Price: 100, Timestamp: start},
+			},
+			EquityCurve: []domain.EquityCurve{
+				{Timestamp: start, Equity: capital},
+				{Timestamp: last, Equity: capital + 10},
+			},
+			Bars: map[string][]domain.Bar{
+				"TEST": {{Symbol: "TEST", Timestamp: start, Close: 100}, {Symbol: "TEST", Timestamp: last, Close: 110}},
+			},
+			Positions: map[string]*strategy.Position{
+				"TEST": {Symbol: "TEST", Shares: 1, EntryPrice: 100, EntryTime: start},
+			},
+		}, nil
+	}
+
+	wf := &WalkForward{
+		Windows:      windows,
+		Combinations: []map[string]any{{"p": 1.0}},
+		Objective:    domain.OptimizationObjectiveTotalReturn,
+		Capital:      10000,
+		Run:          hold,
+	}
+	result, err := wf.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("Walk-forward failed: %v", err)
+	}
+
+	if len(result.Trades) != 2*len(windows) {
+		t.Fatalf("Expected a buy and a closing sell per window, got %d trades", len(result.Trades))
+	}
+	for i, report := range result.Windows {
+		exit := result.Trades[2*i+1]
+		if exit.Direction != domain.TradeDirectionSell || exit.Price != 110 || exit.PnL != 10 {
+			t.Errorf("Window %d: expected a sell at 110 realizing 10, got %+v", i, exit)
+		}
+		if report.OutOfSample.WinningTrades != 1 || report.OutOfSample.AnnualizedReturn <= 0 {
+			t.Errorf("Window %d: expected the held share to count as a winning trade, got %d winners and %v annualized",
+				i, report.OutOfSample.WinningTrades, report.OutOfSample.AnnualizedReturn)
+		}
+	}
+
+	// the stitched trades add up to the stitched curve
+	gain := result.EquityCurve[len(result.EquityCurve)-1].Equity - 10000
+	last := result.Trades[len(result.Trades)-1]
+	if last.CumulativePnL != gain || result.Metrics.TotalReturn != gain {
+		t.Errorf("Expected a stitched P&L of %v, got cumulative %v and total %v", gain, last.CumulativePnL, result.Metrics.TotalReturn)
+	}
+}
+
+func TestWalkForwardDrawdownSkipsIdle(t *testing.T) {
+	windows, err := Windows(date(1, 1), date(3, 1), 30, 10, false)
+	if err != nil {
+		t.Fatalf("Failed to split: %v", err)
+	}
+
+	// p = 1 never trades, p = 2 trades through a dip
+	run := func(ctx context.Context, params map[string]any, start, end time.Time, capital float64) (*strategy.Result, error) {
+		result := &strategy.Result{EquityCurve: []domain.EquityCurve{
+			{Timestamp: start, Equity: capital},
+			{Timestamp: end, Equity: capital},
+		}}
+		if params["p"] == 2.0 {
+			result.Trades = []domain.Trade{
+				{Symbol: "TEST", Direction: domain.TradeDirectionBuy, Quantity: 1, Price: 100, Timestamp: start},
+				{Symbol: "TEST", Direction: domain.TradeDirectionSell, Quantity: 1, Price: 90, Timestamp: end, PnL: -10},
+			}
+			result.EquityCurve = []domain.EquityCurve{
+				{Timestamp: start, Equity: capital},
+				{Timestamp: end, Equity: capital - 10},
+			}
+		}
+		return result, nil
+	}
+
+	wf := &WalkForward{
+		Windows:      windows,
+		Combinations: []map[string]any{{"p": 1.0}, {"p": 2.0}},
+		Objective:    domain.OptimizationObjectiveMaxDrawdown,
+		Capital:      10000,
+		Run:          run,
+	}
+	result, err := wf.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("Walk-forward failed: %v", err)
+	}
+	for i, report := range result.Windows {
+		if report.Parameters["p"] != 2.0 {
+			t.Errorf("Window %d: expected the trading combination to win, got %v", i, report.Parameters)
+		}
+	}
+}
+
+func TestWalkForwardErrors(t *testing.T) {
+	windows, _ := Windows(date(1, 1), date(3, 1), 30, 10, false)
+	fake := &fakeRun{outStart: make(map[time.Time]float64), windows: windows}
+
+	wf := &WalkForward{
+		Windows:      windows,
+		Combinations: []map[string]any{{"p": 0.0}},
+		Capital:      10000,
+		Run:          fake.run,
+	}
+	_, err := wf.Execute(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "window 1: no parameter combination completed") {
+		t.Errorf("Expected the first window to fail, got %v", err)
+	}
+}
+
+func TestEfficiency(t *testing.T) {
+	tests := []struct {
+		inSample, outOfSample, want float64
+	}{
+		{20, 10, 0.5},
+		{10, -5, -0.5},
+		{0, 10, 0},
+		{-10, 5, 0},
+	}
+	for _, tt := range tests {
+		if got := Efficiency(tt.inSample, tt.outOfSample); got != tt.want {
+			t.Errorf("Efficiency(%v, %v): expected %v, got %v", tt.inSample, tt.outOfSample, tt.want, got)
+		}
+	}
+}
@@ -26,6 +26,25 @@ type BacktestRepository interface {
 	List(ctx context.Context, limit, offset int) ([]*domain.Backtest, error)
 	ListByStatus(ctx context.Context, status domain.BacktestStatus) ([]*domain.Backtest, error)
 	ListByStrategy(ctx context.Context, strategyID string) ([]*domain.Backtest, error)
+	ListByOptimization(ctx context.Context, optimizationID uuid.UUID) ([]*domain.Backtest, error)
+}
+
+type OptimizationRepository interface {
+	Create(ctx context.Context, optimization *domain.Optimization) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Optimization, error)
+	Update(ctx context.Context, optimization *domain.Optimization) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, limit, offset int) ([]*domain.Optimization, error)
+}
+
+type WalkForwardRepository interface {
+	Create(ctx context.Context, walkForward *domain.WalkForward) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.WalkForward, error)
+	Update(ctx context.Context, walkForward *domain.WalkForward) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, limit, offset int) ([]*domain.WalkForward, error)
+	CreateWindows(ctx context.Context, windows []domain.WalkForwardWindow) error
+	ListWindows(ctx context.Context, walkForwardID uuid.UUID) ([]domain.WalkForwardWindow, error)
 }
 
 type TradeRepository interface {
@@ -47,3 +66,21 @@ type MetricsRepository interface {
 	Exists(ctx context.Context, backtestID uuid.UUID) (bool, error)
 	ListTopPerformers(ctx context.Context, limit int) ([]*domain.Metrics, error)
 }
+
+type EquityRepository interface {
+	CreateBatch(ctx context.Context, backtestID uuid.UUID, points []domain.EquityCurve) error
+	GetByBacktestID(ctx context.Context, backtestID uuid.UUID) ([]domain.EquityCurve, error)
+	DeleteByBacktest(ctx context.Context, backtestID uuid.UUID) error
+}
+
+type DrawdownRepository interface {
+	CreateBatch(ctx context.Context, drawdowns []domain.Drawdown) error
+	GetByBacktestID(ctx context.Context, backtestID uuid.UUID) ([]domain.Drawdown, error)
+	DeleteByBacktest(ctx context.Context, backtestID uuid.UUID) error
+}
+
+type RoundTripRepository interface {
+	CreateBatch(ctx context.Context, trips []domain.RoundTrip) error
+	GetByBacktestID(ctx context.Context, backtestID uuid.UUID) ([]domain.RoundTrip, error)
+	DeleteByBacktest(ctx context.Context, backtestID uuid.UUID) error
+}
@@ -3,13 +3,26 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
 )
 
+// backtestColumns is the column list shared by every backtest query, in the
+// order scanBacktest reads them
+const backtestColumns = `id, strategy_id, parameters, symbols, status, start_date, end_date,
+		       initial_capital, fill_model, commission_model, commission_rate,
+		       slippage_model, slippage_rate, allow_short, initial_margin,
+		       maintenance_margin, borrow_rate, sizing_method, sizing_value,
+		       sizing_lookback, rebalance_frequency, rebalance_threshold,
+		       min_trade_shares, min_trade_notional, corporate_actions,
+		       reinvest_dividends, risk_free_rate, benchmark, optimization_id,
+		       created_at, updated_at, completed_at, error_message`
+
 type backtestRepository struct {
 	db *sql.DB
 }
@@ -24,23 +37,56 @@ func (r *backtestRepository) Create(ctx context.Context, b *domain.Backtest) err
 	}
 	b.UpdatedAt = b.CreatedAt
 
+	parameters, err := marshalParameters(b.Parameters)
+	if err != nil {
+		return err
+	}
+
 	query := `
 		INSERT INTO backtests (
-			strategy_id, symbol, status, start_date, end_date,
-			initial_capital, created_at, updated_at
+			strategy_id, parameters, symbols, status, start_date, end_date,
+			initial_capital, fill_model, commission_model, commission_rate,
+			slippage_model, slippage_rate, allow_short, initial_margin,
+			maintenance_margin, borrow_rate, sizing_method, sizing_value,
+			sizing_lookback, rebalance_frequency, rebalance_threshold,
+			min_trade_shares, min_trade_notional, corporate_actions,
+			reinvest_dividends, risk_free_rate, benchmark, optimization_id,
+			created_at, updated_at
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30)
 		RETURNING id`
 
-	err := r.db.QueryRowContext(
+	err = r.db.QueryRowContext(
 		ctx,
 		query,
 		b.StrategyID,
-		b.Symbol,
+		parameters,
+		pq.Array(b.Symbols),
 		b.Status.String(),
 		b.StartDate,
 		b.EndDate,
 		b.InitialCapital,
+		b.FillModel.String(),
+		b.Costs.CommissionModel.String(),
+		b.Costs.CommissionRate,
+		b.Costs.SlippageModel.String(),
+		b.Costs.SlippageRate,
+		b.Margin.AllowShort,
+		b.Margin.InitialMargin,
+		b.Margin.MaintenanceMargin,
+		b.Margin.BorrowRate,
+		b.Sizing.Method.String(),
+		b.Sizing.Value,
+		b.Sizing.Lookback,
+		b.Rebalance.Frequency.String(),
+		b.Rebalance.DriftThreshold,
+		b.Rebalance.MinShares,
+		b.Rebalance.MinNotional,
+		b.Actions.Mode.String(),
+		b.Actions.ReinvestDividends,
+		b.RiskFreeRate,
+		b.Benchmark,
+		b.OptimizationID,
 		b.CreatedAt,
 		b.UpdatedAt,
 	).Scan(&b.ID)
@@ -54,29 +100,11 @@ func (r *backtestRepository) Create(ctx context.Context, b *domain.Backtest) err
 
 func (r *backtestRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Backtest, error) {
 	query := `
-		SELECT id, strategy_id, symbol, status, start_date, end_date,
-		       initial_capital, created_at, updated_at, completed_at, error_message
+		SELECT ` + backtestColumns + `
 		FROM backtests
 		WHERE id = $1`
 
-	b := &domain.Backtest{}
-	var statusStr string
-	var completedAt sql.NullTime
-	var errorMessage sql.NullString
-
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&b.ID,
-		&b.StrategyID,
-		&b.Symbol,
-		&statusStr,
-		&b.StartDate,
-		&b.EndDate,
-		&b.InitialCapital,
-		&b.CreatedAt,
-		&b.UpdatedAt,
-		&completedAt,
-		&errorMessage,
-	)
+	b, err := scanBacktest(r.db.QueryRowContext(ctx, query, id))
 
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("backtest not found")
@@ -85,27 +113,31 @@ func (r *backtestRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain
 		return nil, fmt.Errorf("error fetching backtest: %w", err)
 	}
 
-	b.Status = parseStatus(statusStr)
-
-	if completedAt.Valid {
-		b.CompletedAt = &completedAt.Time
-	}
-	if errorMessage.Valid {
-		b.ErrorMessage = errorMessage.String
-	}
-
 	return b, nil
 }
 
 func (r *backtestRepository) Update(ctx context.Context, backtest *domain.Backtest) error {
 	backtest.UpdatedAt = time.Now()
 
+	parameters, err := marshalParameters(backtest.Parameters)
+	if err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE backtests
-		SET strategy_id = $1, symbol = $2, status = $3,
-		    start_date = $4, end_date = $5, initial_capital = $6,
-		    updated_at = $7, completed_at = $8, error_message = $9
-		WHERE id = $10`
+		SET strategy_id = $1, parameters = $2, symbols = $3, status = $4,
+		    start_date = $5, end_date = $6, initial_capital = $7,
+		    fill_model = $8, commission_model = $9, commission_rate = $10,
+		    slippage_model = $11, slippage_rate = $12, allow_short = $13,
+		    initial_margin = $14, maintenance_margin = $15, borrow_rate = $16,
+		    sizing_method = $17, sizing_value = $18, sizing_lookback = $19,
+		    rebalance_frequency = $20, rebalance_threshold = $21,
+		    min_trade_shares = $22, min_trade_notional = $23,
+		    corporate_actions = $24, reinvest_dividends = $25,
+		    risk_free_rate = $26, benchmark = $27, updated_at = $28,
+		    completed_at = $29, error_message = $30
+		WHERE id = $31`
 
 	// Handle nullable fields
 	var completedAt sql.NullTime
@@ -122,11 +154,32 @@ func (r *backtestRepository) Update(ctx context.Context, backtest *domain.Backte
 		ctx,
 		query,
 		backtest.StrategyID,
-		backtest.Symbol,
+		parameters,
+		pq.Array(backtest.Symbols),
 		backtest.Status.String(),
 		backtest.StartDate,
 		backtest.EndDate,
 		backtest.InitialCapital,
+		backtest.FillModel.String(),
+		backtest.Costs.CommissionModel.String(),
+		backtest.Costs.CommissionRate,
+		backtest.Costs.SlippageModel.String(),
+		backtest.Costs.SlippageRate,
+		backtest.Margin.AllowShort,
+		backtest.Margin.InitialMargin,
+		backtest.Margin.MaintenanceMargin,
+		backtest.Margin.BorrowRate,
+		backtest.Sizing.Method.String(),
+		backtest.Sizing.Value,
+		backtest.Sizing.Lookback,
+		backtest.Rebalance.Frequency.String(),
+		backtest.Rebalance.DriftThreshold,
+		backtest.Rebalance.MinShares,
+		backtest.Rebalance.MinNotional,
+		backtest.Actions.Mode.String(),
+		backtest.Actions.ReinvestDividends,
+		backtest.RiskFreeRate,
+		backtest.Benchmark,
 		backtest.UpdatedAt,
 		completedAt,
 		errorMessage,
@@ -260,8 +313,7 @@ func (r *backtestRepository) Delete(ctx context.Context, id uuid.UUID) error {
 
 func (r *backtestRepository) List(ctx context.Context, limit, offset int) ([]*domain.Backtest, error) {
 	query := `
-		SELECT id, strategy_id, symbol, status, start_date, end_date,
-		       initial_capital, created_at, updated_at, completed_at, error_message
+		SELECT ` + backtestColumns + `
 		FROM backtests
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2`
@@ -274,36 +326,11 @@ func (r *backtestRepository) List(ctx context.Context, limit, offset int) ([]*do
 
 	var backtests []*domain.Backtest
 	for rows.Next() {
-		b := &domain.Backtest{}
-		var statusStr string
-		var completedAt sql.NullTime
-		var errorMessage sql.NullString
-
-		if err := rows.Scan(
-			&b.ID,
-			&b.StrategyID,
-			&b.Symbol,
-			&statusStr,
-			&b.StartDate,
-			&b.EndDate,
-			&b.InitialCapital,
-			&b.CreatedAt,
-			&b.UpdatedAt,
-			&completedAt,
-			&errorMessage,
-		); err != nil {
+		b, err := scanBacktest(rows)
+		if err != nil {
 			return nil, fmt.Errorf("error scanning backtest: %w", err)
 		}
 
-		b.Status = parseStatus(statusStr)
-
-		if completedAt.Valid {
-			b.CompletedAt = &completedAt.Time
-		}
-		if errorMessage.Valid {
-			b.ErrorMessage = errorMessage.String
-		}
-
 		backtests = append(backtests, b)
 	}
 
@@ -316,8 +343,7 @@ func (r *backtestRepository) List(ctx context.Context, limit, offset int) ([]*do
 
 func (r *backtestRepository) ListByStatus(ctx context.Context, status domain.BacktestStatus) ([]*domain.Backtest, error) {
 	query := `
-		SELECT id, strategy_id, symbol, status, start_date, end_date,
-		       initial_capital, created_at, updated_at, completed_at, error_message
+		SELECT ` + backtestColumns + `
 		FROM backtests
 		WHERE status = $1
 		ORDER BY created_at ASC`
@@ -330,34 +356,41 @@ func (r *backtestRepository) ListByStatus(ctx context.Context, status domain.Bac
 
 	var backtests []*domain.Backtest
 	for rows.Next() {
-		b := &domain.Backtest{}
-		var statusStr string
-		var completedAt sql.NullTime
-		var errorMessage sql.NullString
-
-		if err := rows.Scan(
-			&b.ID,
-			&b.StrategyID,
-			&b.Symbol,
-			&statusStr,
-			&b.StartDate,
-			&b.EndDate,
-			&b.InitialCapital,
-			&b.CreatedAt,
-			&b.UpdatedAt,
-			&completedAt,
-			&errorMessage,
-		); err != nil {
+		b, err := scanBacktest(rows)
+		if err != nil {
 			return nil, fmt.Errorf("error scanning backtest: %w", err)
 		}
 
-		b.Status = parseStatus(statusStr)
+		backtests = append(backtests, b)
+	}
 
-		if completedAt.Valid {
-			b.CompletedAt = &completedAt.Time
-		}
-		if errorMessage.Valid {
-			b.ErrorMessage = errorMessage.String
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating backtests: %w", err)
+	}
+
+	return backtests, nil
+}
+
+// ListByOptimization returns the child backtests of a grid search in the
+// order they were created, which is the grid order
+func (r *backtestRepository) ListByOptimization(ctx context.Context, optimizationID uuid.UUID) ([]*domain.Backtest, error) {
+	query := `
+		SELECT ` + backtestColumns + `
+		FROM backtests
+		WHERE optimization_id = $1
+		ORDER BY created_at ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, optimizationID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing backtests by optimization: %w", err)
+	}
+	defer rows.Close()
+
+	var backtests []*domain.Backtest
+	for rows.Next() {
+		b, err := scanBacktest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning backtest: %w", err)
 		}
 
 		backtests = append(backtests, b)
@@ -372,8 +405,7 @@ func (r *backtestRepository) ListByStatus(ctx context.Context, status domain.Bac
 
 func (r *backtestRepository) ListByStrategy(ctx context.Context, strategyID string) ([]*domain.Backtest, error) {
 	query := `
-		SELECT id, strategy_id, symbol, status, start_date, end_date,
-		       initial_capital, created_at, updated_at, completed_at, error_message
+		SELECT ` + backtestColumns + `
 		FROM backtests
 		WHERE strategy_id = $1
 		ORDER BY created_at DESC`
@@ -386,36 +418,11 @@ func (r *backtestRepository) ListByStrategy(ctx context.Context, strategyID stri
 
 	var backtests []*domain.Backtest
 	for rows.Next() {
-		b := &domain.Backtest{}
-		var statusStr string
-		var completedAt sql.NullTime
-		var errorMessage sql.NullString
-
-		if err := rows.Scan(
-			&b.ID,
-			&b.StrategyID,
-			&b.Symbol,
-			&statusStr,
-			&b.StartDate,
-			&b.EndDate,
-			&b.InitialCapital,
-			&b.CreatedAt,
-			&b.UpdatedAt,
-			&completedAt,
-			&errorMessage,
-		); err != nil {
+		b, err := scanBacktest(rows)
+		if err != nil {
 			return nil, fmt.Errorf("error scanning backtest: %w", err)
 		}
 
-		b.Status = parseStatus(statusStr)
-
-		if completedAt.Valid {
-			b.CompletedAt = &completedAt.Time
-		}
-		if errorMessage.Valid {
-			b.ErrorMessage = errorMessage.String
-		}
-
 		backtests = append(backtests, b)
 	}
 
@@ -426,6 +433,103 @@ func (r *backtestRepository) ListByStrategy(ctx context.Context, strategyID stri
 	return backtests, nil
 }
 
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanBacktest(row rowScanner) (*domain.Backtest, error) {
+	b := &domain.Backtest{}
+	var statusStr string
+	var fillModelStr string
+	var commissionModelStr string
+	var slippageModelStr string
+	var sizingMethodStr string
+	var rebalanceFrequencyStr string
+	var actionModeStr string
+	var parameters []byte
+	var optimizationID uuid.NullUUID
+	var completedAt sql.NullTime
+	var errorMessage sql.NullString
+
+	if err := row.Scan(
+		&b.ID,
+		&b.StrategyID,
+		&parameters,
+		pq.Array(&b.Symbols),
+		&statusStr,
+		&b.StartDate,
+		&b.EndDate,
+		&b.InitialCapital,
+		&fillModelStr,
+		&commissionModelStr,
+		&b.Costs.CommissionRate,
+		&slippageModelStr,
+		&b.Costs.SlippageRate,
+		&b.Margin.AllowShort,
+		&b.Margin.InitialMargin,
+		&b.Margin.MaintenanceMargin,
+		&b.Margin.BorrowRate,
+		&sizingMethodStr,
+		&b.Sizing.Value,
+		&b.Sizing.Lookback,
+		&rebalanceFrequencyStr,
+		&b.Rebalance.DriftThreshold,
+		&b.Rebalance.MinShares,
+		&b.Rebalance.MinNotional,
+		&actionModeStr,
+		&b.Actions.ReinvestDividends,
+		&b.RiskFreeRate,
+		&b.Benchmark,
+		&optimizationID,
+		&b.CreatedAt,
+		&b.UpdatedAt,
+		&completedAt,
+		&errorMessage,
+	); err != nil {
+		return nil, err
+	}
+
+	b.Status = parseStatus(statusStr)
+	b.FillModel = parseFillModel(fillModelStr)
+	b.Costs.CommissionModel = parseCommissionModel(commissionModelStr)
+	b.Costs.SlippageModel = parseSlippageModel(slippageModelStr)
+	b.Sizing.Method = parseSizingMethod(sizingMethodStr)
+	b.Rebalance.Frequency = parseRebalanceFrequency(rebalanceFrequencyStr)
+	b.Actions.Mode = parseCorporateActionMode(actionModeStr)
+
+	if err := json.Unmarshal(parameters, &b.Parameters); err != nil {
+		return nil, fmt.Errorf("invalid strategy parameters: %w", err)
+	}
+
+	if optimizationID.Valid {
+		b.OptimizationID = &optimizationID.UUID
+	}
+	if completedAt.Valid {
+		b.CompletedAt = &completedAt.Time
+	}
+	if errorMessage.Valid {
+		b.ErrorMessage = errorMessage.String
+	}
+
+	return b, nil
+}
+
+// marshalParameters encodes strategy parameters for the JSONB column, nil
+// becomes an empty object
+func marshalParameters(params map[string]any) ([]byte, error) {
+	if params == nil {
+		params = map[string]any{}
+	}
+
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode strategy parameters: %w", err)
+	}
+
+	return data, nil
+}
+
 func parseStatus(s string) domain.BacktestStatus {
 	switch s {
 	case "PENDING":
@@ -442,3 +546,87 @@ func parseStatus(s string) domain.BacktestStatus {
 		return domain.BacktestStatusPending
 	}
 }
+
+func parseFillModel(s string) domain.FillModel {
+	switch s {
+	case "NEXT_BAR_OPEN":
+		return domain.FillModelNextBarOpen
+	case "SAME_BAR_CLOSE":
+		return domain.FillModelSameBarClose
+	case "NEXT_BAR_VWAP":
+		return domain.FillModelNextBarVWAP
+	case "NEXT_BAR_CLOSE":
+		return domain.FillModelNextBarClose
+	default:
+		return domain.FillModelNextBarOpen
+	}
+}
+
+func parseCommissionModel(s string) domain.CommissionModel {
+	switch s {
+	case "FIXED":
+		return domain.CommissionModelFixed
+	case "PER_SHARE":
+		return domain.CommissionModelPerShare
+	case "BPS":
+		return domain.CommissionModelBps
+	case "TIERED":
+		return domain.CommissionModelTiered
+	default:
+		return domain.CommissionModelNone
+	}
+}
+
+func parseSlippageModel(s string) domain.SlippageModel {
+	switch s {
+	case "FIXED_BPS":
+		return domain.SlippageModelFixedBps
+	case "VOLATILITY":
+		return domain.SlippageModelVolatility
+	default:
+		return domain.SlippageModelNone
+	}
+}
+
+func parseSizingMethod(s string) domain.SizingMethod {
+	switch s {
+	case "FIXED_SHARES":
+		return domain.SizingMethodFixedShares
+	case "FIXED_NOTIONAL":
+		return domain.SizingMethodFixedNotional
+	case "PERCENT_EQUITY":
+		return domain.SizingMethodPercentEquity
+	case "ATR":
+		return domain.SizingMethodATR
+	case "VOLATILITY_TARGET":
+		return domain.SizingMethodVolatilityTarget
+	case "KELLY":
+		return domain.SizingMethodKelly
+	default:
+		return domain.SizingMethodAllIn
+	}
+}
+
+func parseRebalanceFrequency(s string) domain.RebalanceFrequency {
+	switch s {
+	case "WEEKLY":
+		return domain.RebalanceFrequencyWeekly
+	case "DAILY":
+		return domain.RebalanceFrequencyDaily
+	case "DRIFT":
+		return domain.RebalanceFrequencyDrift
+	default:
+		return domain.RebalanceFrequencyMonthly
+	}
+}
+
+func parseCorporateActionMode(s string) domain.CorporateActionMode {
+	switch s {
+	case "ADJUST":
+		return domain.CorporateActionModeAdjust
+	case "IGNORE":
+		return domain.CorporateActionModeIgnore
+	default:
+		return domain.CorporateActionModeApply
+	}
+}
@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+)
+
+type drawdownRepository struct {
+	db *sql.DB
+}
+
+func NewDrawdownRepository(db *sql.DB) *drawdownRepository {
+	return &drawdownRepository{db: db}
+}
+
+// CreateBatch saves the drawdown episodes of a backtest in one transaction
+func (r *drawdownRepository) CreateBatch(ctx context.Context, drawdowns []domain.Drawdown) error {
+	if len(drawdowns) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO drawdowns (
+			backtest_id, drawdown_index, peak_date, trough_date, recovery_date,
+			peak_equity, trough_equity, depth, length_days
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+
+	for _, d := range drawdowns {
+		var recoveryDate sql.NullTime
+		if d.RecoveryDate != nil {
+			recoveryDate = sql.NullTime{Time: *d.RecoveryDate, Valid: true}
+		}
+
+		if _, err := tx.ExecContext(
+			ctx,
+			query,
+			d.BacktestID,
+			d.Index,
+			d.PeakDate,
+			d.TroughDate,
+			recoveryDate,
+			d.PeakEquity,
+			d.TroughEquity,
+			d.Depth,
+			d.Length,
+		); err != nil {
+			return fmt.Errorf("failed to insert drawdown: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *drawdownRepository) GetByBacktestID(ctx context.Context, backtestID uuid.UUID) ([]domain.Drawdown, error) {
+	query := `
+		SELECT backtest_id, drawdown_index, peak_date, trough_date, recovery_date,
+		       peak_equity, trough_equity, depth, length_days
+		FROM drawdowns
+		WHERE backtest_id = $1
+		ORDER BY drawdown_index ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, backtestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query drawdowns: %w", err)
+	}
+	defer rows.Close()
+
+	var drawdowns []domain.Drawdown
+	for rows.Next() {
+		var d domain.Drawdown
+		var recoveryDate sql.NullTime
+		if err := rows.Scan(
+			&d.BacktestID,
+			&d.Index,
+			&d.PeakDate,
+			&d.TroughDate,
+			&recoveryDate,
+			&d.PeakEquity,
+			&d.TroughEquity,
+			&d.Depth,
+			&d.Length,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan drawdown: %w", err)
+		}
+		if recoveryDate.Valid {
+			d.RecoveryDate = &recoveryDate.Time
+		}
+		drawdowns = append(drawdowns, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating drawdowns: %w", err)
+	}
+
+	return drawdowns, nil
+}
+
+func (r *drawdownRepository) DeleteByBacktest(ctx context.Context, backtestID uuid.UUID) error {
+	query := `DELETE FROM drawdowns WHERE backtest_id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, backtestID); err != nil {
+		return fmt.Errorf("failed to delete drawdowns: %w", err)
+	}
+
+	return nil
+}
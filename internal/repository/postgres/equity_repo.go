@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+)
+
+// rows per insert statement, keeps the bind parameters under the postgres limit
+const equityBatchSize = 1000
+
+type equityRepository struct {
+	db *sql.DB
+}
+
+func NewEquityRepository(db *sql.DB) *equityRepository {
+	return &equityRepository{db: db}
+}
+
+func (r *equityRepository) CreateBatch(ctx context.Context, backtestID uuid.UUID, points []domain.EquityCurve) error {
+	if len(points) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for start := 0; start < len(points); start += equityBatchSize {
+		end := min(start+equityBatchSize, len(points))
+		batch := points[start:end]
+
+		valueStrings := make([]string, 0, len(batch))
+		valueArgs := make([]interface{}, 0, len(batch)*7)
+
+		for i, point := range batch {
+			valueStrings = append(valueStrings, fmt.Sprintf(
+				"($%d, $%d, $%d, $%d, $%d, $%d, $%d)",
+				i*7+1, i*7+2, i*7+3, i*7+4, i*7+5, i*7+6, i*7+7,
+			))
+
+			valueArgs = append(valueArgs,
+				backtestID,
+				point.Timestamp,
+				point.Equity,
+				point.Cash,
+				point.PositionValue,
+				point.GrossExposure,
+				point.NetExposure,
+			)
+		}
+
+		query := fmt.Sprintf(`
+			INSERT INTO equity_curves (
+				backtest_id, timestamp, equity, cash, position_value,
+				gross_exposure, net_exposure
+			)
+			VALUES %s`,
+			strings.Join(valueStrings, ","),
+		)
+
+		if _, err := tx.ExecContext(ctx, query, valueArgs...); err != nil {
+			return fmt.Errorf("failed to bulk insert equity curve: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *equityRepository) GetByBacktestID(ctx context.Context, backtestID uuid.UUID) ([]domain.EquityCurve, error) {
+	query := `
+		SELECT backtest_id, timestamp, equity, cash, position_value,
+		       gross_exposure, net_exposure
+		FROM equity_curves
+		WHERE backtest_id = $1
+		ORDER BY timestamp ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, backtestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query equity curve: %w", err)
+	}
+	defer rows.Close()
+
+	var points []domain.EquityCurve
+	for rows.Next() {
+		var point domain.EquityCurve
+		if err := rows.Scan(
+			&point.BacktestID,
+			&point.Timestamp,
+			&point.Equity,
+			&point.Cash,
+			&point.PositionValue,
+			&point.GrossExposure,
+			&point.NetExposure,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan equity point: %w", err)
+		}
+		points = append(points, point)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating equity curve: %w", err)
+	}
+
+	return points, nil
+}
+
+func (r *equityRepository) DeleteByBacktest(ctx context.Context, backtestID uuid.UUID) error {
+	query := `DELETE FROM equity_curves WHERE backtest_id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, backtestID); err != nil {
+		return fmt.Errorf("failed to delete equity curve: %w", err)
+	}
+
+	return nil
+}
@@ -9,6 +9,16 @@ import (
 	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
 )
 
+const metricsColumns = `backtest_id, total_return, annualized_return, sharpe_ratio,
+		       max_drawdown, max_drawdown_duration, win_rate, total_trades,
+		       winning_trades, losing_trades, profit_factor, avg_win, avg_loss,
+		       largest_win, largest_loss, volatility, sortino_ratio, calmar_ratio,
+		       omega_ratio, skewness, kurtosis, tail_ratio, avg_drawdown,
+		       time_under_water, ulcer_index, avg_bars_held, max_win_streak,
+		       max_loss_streak, avg_mae, avg_mfe, edge_ratio, benchmark_return,
+		       excess_return, alpha, beta, correlation, tracking_error,
+		       information_ratio, up_capture, down_capture`
+
 type metricsRepository struct {
 	db *sql.DB
 }
@@ -23,9 +33,16 @@ func (r *metricsRepository) Create(ctx context.Context, metrics *domain.Metrics)
 			backtest_id, total_return, annualized_return, sharpe_ratio,
 			max_drawdown, max_drawdown_duration, win_rate, total_trades,
 			winning_trades, losing_trades, profit_factor, avg_win, avg_loss,
-			largest_win, largest_loss
+			largest_win, largest_loss, volatility, sortino_ratio, calmar_ratio,
+			omega_ratio, skewness, kurtosis, tail_ratio, avg_drawdown,
+			time_under_water, ulcer_index, avg_bars_held, max_win_streak,
+			max_loss_streak, avg_mae, avg_mfe, edge_ratio, benchmark_return,
+			excess_return, alpha, beta, correlation, tracking_error,
+			information_ratio, up_capture, down_capture
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15,
+		        $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28,
+		        $29, $30, $31, $32, $33, $34, $35, $36, $37, $38, $39, $40)`
 
 	_, err := r.db.ExecContext(
 		ctx,
@@ -45,6 +62,31 @@ func (r *metricsRepository) Create(ctx context.Context, metrics *domain.Metrics)
 		metrics.AvgLoss,
 		metrics.LargestWin,
 		metrics.LargestLoss,
+		metrics.Volatility,
+		metrics.SortinoRatio,
+		metrics.CalmarRatio,
+		metrics.OmegaRatio,
+		metrics.Skewness,
+		metrics.Kurtosis,
+		metrics.TailRatio,
+		metrics.AvgDrawdown,
+		metrics.TimeUnderWater,
+		metrics.UlcerIndex,
+		metrics.AvgBarsHeld,
+		metrics.MaxWinStreak,
+		metrics.MaxLossStreak,
+		metrics.AvgMAE,
+		metrics.AvgMFE,
+		metrics.EdgeRatio,
+		metrics.BenchmarkReturn,
+		metrics.ExcessReturn,
+		metrics.Alpha,
+		metrics.Beta,
+		metrics.Correlation,
+		metrics.TrackingError,
+		metrics.InformationRatio,
+		metrics.UpCapture,
+		metrics.DownCapture,
 	)
 
 	if err != nil {
@@ -55,34 +97,9 @@ func (r *metricsRepository) Create(ctx context.Context, metrics *domain.Metrics)
 }
 
 func (r *metricsRepository) GetByBacktestID(ctx context.Context, backtestID uuid.UUID) (*domain.Metrics, error) {
-	query := `
-		SELECT backtest_id, total_return, annualized_return, sharpe_ratio,
-		       max_drawdown, max_drawdown_duration, win_rate, total_trades,
-		       winning_trades, losing_trades, profit_factor, avg_win, avg_loss,
-		       largest_win, largest_loss
-		FROM metrics
-		WHERE backtest_id = $1`
-
-	metrics := &domain.Metrics{}
-
-	err := r.db.QueryRowContext(ctx, query, backtestID).Scan(
-		&metrics.BacktestID,
-		&metrics.TotalReturn,
-		&metrics.AnnualizedReturn,
-		&metrics.SharpeRatio,
-		&metrics.MaxDrawdown,
-		&metrics.MaxDrawdownDuration,
-		&metrics.WinRate,
-		&metrics.TotalTrades,
-		&metrics.WinningTrades,
-		&metrics.LosingTrades,
-		&metrics.ProfitFactor,
-		&metrics.AvgWin,
-		&metrics.AvgLoss,
-		&metrics.LargestWin,
-		&metrics.LargestLoss,
-	)
+	query := `SELECT ` + metricsColumns + ` FROM metrics WHERE backtest_id = $1`
 
+	metrics, err := scanMetrics(r.db.QueryRowContext(ctx, query, backtestID))
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("metrics not found for backtest")
 	}
@@ -100,8 +117,16 @@ func (r *metricsRepository) Update(ctx context.Context, metrics *domain.Metrics)
 		    max_drawdown = $4, max_drawdown_duration = $5, win_rate = $6,
 		    total_trades = $7, winning_trades = $8, losing_trades = $9,
 		    profit_factor = $10, avg_win = $11, avg_loss = $12,
-		    largest_win = $13, largest_loss = $14
-		WHERE backtest_id = $15`
+		    largest_win = $13, largest_loss = $14, volatility = $15,
+		    sortino_ratio = $16, calmar_ratio = $17, omega_ratio = $18,
+		    skewness = $19, kurtosis = $20, tail_ratio = $21,
+		    avg_drawdown = $22, time_under_water = $23, ulcer_index = $24,
+		    avg_bars_held = $25, max_win_streak = $26, max_loss_streak = $27,
+		    avg_mae = $28, avg_mfe = $29, edge_ratio = $30,
+		    benchmark_return = $31, excess_return = $32, alpha = $33,
+		    beta = $34, correlation = $35, tracking_error = $36,
+		    information_ratio = $37, up_capture = $38, down_capture = $39
+		WHERE backtest_id = $40`
 
 	result, err := r.db.ExecContext(
 		ctx,
@@ -120,6 +145,31 @@ func (r *metricsRepository) Update(ctx context.Context, metrics *domain.Metrics)
 		metrics.AvgLoss,
 		metrics.LargestWin,
 		metrics.LargestLoss,
+		metrics.Volatility,
+		metrics.SortinoRatio,
+		metrics.CalmarRatio,
+		metrics.OmegaRatio,
+		metrics.Skewness,
+		metrics.Kurtosis,
+		metrics.TailRatio,
+		metrics.AvgDrawdown,
+		metrics.TimeUnderWater,
+		metrics.UlcerIndex,
+		metrics.AvgBarsHeld,
+		metrics.MaxWinStreak,
+		metrics.MaxLossStreak,
+		metrics.AvgMAE,
+		metrics.AvgMFE,
+		metrics.EdgeRatio,
+		metrics.BenchmarkReturn,
+		metrics.ExcessReturn,
+		metrics.Alpha,
+		metrics.Beta,
+		metrics.Correlation,
+		metrics.TrackingError,
+		metrics.InformationRatio,
+		metrics.UpCapture,
+		metrics.DownCapture,
 		metrics.BacktestID,
 	)
 
@@ -173,10 +223,7 @@ func (r *metricsRepository) Exists(ctx context.Context, backtestID uuid.UUID) (b
 
 func (r *metricsRepository) ListTopPerformers(ctx context.Context, limit int) ([]*domain.Metrics, error) {
 	query := `
-		SELECT backtest_id, total_return, annualized_return, sharpe_ratio,
-		       max_drawdown, max_drawdown_duration, win_rate, total_trades,
-		       winning_trades, losing_trades, profit_factor, avg_win, avg_loss,
-		       largest_win, largest_loss
+		SELECT ` + metricsColumns + `
 		FROM metrics
 		ORDER BY sharpe_ratio DESC
 		LIMIT $1`
@@ -189,25 +236,8 @@ func (r *metricsRepository) ListTopPerformers(ctx context.Context, limit int) ([
 
 	var metricsList []*domain.Metrics
 	for rows.Next() {
-		metrics := &domain.Metrics{}
-
-		if err := rows.Scan(
-			&metrics.BacktestID,
-			&metrics.TotalReturn,
-			&metrics.AnnualizedReturn,
-			&metrics.SharpeRatio,
-			&metrics.MaxDrawdown,
-			&metrics.MaxDrawdownDuration,
-			&metrics.WinRate,
-			&metrics.TotalTrades,
-			&metrics.WinningTrades,
-			&metrics.LosingTrades,
-			&metrics.ProfitFactor,
-			&metrics.AvgWin,
-			&metrics.AvgLoss,
-			&metrics.LargestWin,
-			&metrics.LargestLoss,
-		); err != nil {
+		metrics, err := scanMetrics(rows)
+		if err != nil {
 			return nil, fmt.Errorf("error scanning metrics: %w", err)
 		}
 
@@ -220,3 +250,55 @@ func (r *metricsRepository) ListTopPerformers(ctx context.Context, limit int) ([
 
 	return metricsList, nil
 }
+
+func scanMetrics(row rowScanner) (*domain.Metrics, error) {
+	metrics := &domain.Metrics{}
+
+	err := row.Scan(
+		&metrics.BacktestID,
+		&metrics.TotalReturn,
+		&metrics.AnnualizedReturn,
+		&metrics.SharpeRatio,
+		&metrics.MaxDrawdown,
+		&metrics.MaxDrawdownDuration,
+		&metrics.WinRate,
+		&metrics.TotalTrades,
+		&metrics.WinningTrades,
+		&metrics.LosingTrades,
+		&metrics.ProfitFactor,
+		&metrics.AvgWin,
+		&metrics.AvgLoss,
+		&metrics.LargestWin,
+		&metrics.LargestLoss,
+		&metrics.Volatility,
+		&metrics.SortinoRatio,
+		&metrics.CalmarRatio,
+		&metrics.OmegaRatio,
+		&metrics.Skewness,
+		&metrics.Kurtosis,
+		&metrics.TailRatio,
+		&metrics.AvgDrawdown,
+		&metrics.TimeUnderWater,
+		&metrics.UlcerIndex,
+		&metrics.AvgBarsHeld,
+		&metrics.MaxWinStreak,
+		&metrics.MaxLossStreak,
+		&metrics.AvgMAE,
+		&metrics.AvgMFE,
+		&metrics.EdgeRatio,
+		&metrics.BenchmarkReturn,
+		&metrics.ExcessReturn,
+		&metrics.Alpha,
+		&metrics.Beta,
+		&metrics.Correlation,
+		&metrics.TrackingError,
+		&metrics.InformationRatio,
+		&metrics.UpCapture,
+		&metrics.DownCapture,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return metrics, nil
+}
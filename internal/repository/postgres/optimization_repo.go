@@ -0,0 +1,230 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+)
+
+const optimizationColumns = `id, strategy_id, symbols, start_date, end_date, grid,
+		       objective, status, combinations, skipped, created_at, updated_at,
+		       completed_at, error_message`
+
+type optimizationRepository struct {
+	db *sql.DB
+}
+
+func NewOptimizationRepository(db *sql.DB) *optimizationRepository {
+	return &optimizationRepository{db: db}
+}
+
+func (r *optimizationRepository) Create(ctx context.Context, o *domain.Optimization) error {
+	if o.CreatedAt.IsZero() {
+		o.CreatedAt = time.Now()
+	}
+	o.UpdatedAt = o.CreatedAt
+
+	grid, err := json.Marshal(o.Grid)
+	if err != nil {
+		return fmt.Errorf("failed to encode parameter grid: %w", err)
+	}
+
+	query := `
+		INSERT INTO optimizations (
+			strategy_id, symbols, start_date, end_date, grid, objective,
+			status, combinations, skipped, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id`
+
+	err = r.db.QueryRowContext(
+		ctx,
+		query,
+		o.StrategyID,
+		pq.Array(o.Symbols),
+		o.StartDate,
+		o.EndDate,
+		grid,
+		o.Objective.String(),
+		o.Status.String(),
+		o.Combinations,
+		o.Skipped,
+		o.CreatedAt,
+		o.UpdatedAt,
+	).Scan(&o.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to insert optimization: %w", err)
+	}
+
+	return nil
+}
+
+func (r *optimizationRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Optimization, error) {
+	query := `
+		SELECT ` + optimizationColumns + `
+		FROM optimizations
+		WHERE id = $1`
+
+	o, err := scanOptimization(r.db.QueryRowContext(ctx, query, id))
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("optimization not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching optimization: %w", err)
+	}
+
+	return o, nil
+}
+
+// Update saves the progress of an optimization, its search settings never
+// change after creation
+func (r *optimizationRepository) Update(ctx context.Context, o *domain.Optimization) error {
+	o.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE optimizations
+		SET status = $1, updated_at = $2, completed_at = $3, error_message = $4
+		WHERE id = $5`
+
+	var completedAt sql.NullTime
+	if o.CompletedAt != nil {
+		completedAt = sql.NullTime{Time: *o.CompletedAt, Valid: true}
+	}
+
+	var errorMessage sql.NullString
+	if o.ErrorMessage != "" {
+		errorMessage = sql.NullString{String: o.ErrorMessage, Valid: true}
+	}
+
+	result, err := r.db.ExecContext(ctx, query, o.Status.String(), o.UpdatedAt, completedAt, errorMessage, o.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update optimization: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("optimization not found")
+	}
+
+	return nil
+}
+
+// Delete removes the optimization, its child backtests cascade
+func (r *optimizationRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM optimizations WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete optimization: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("optimization not found")
+	}
+
+	return nil
+}
+
+func (r *optimizationRepository) List(ctx context.Context, limit, offset int) ([]*domain.Optimization, error) {
+	query := `
+		SELECT ` + optimizationColumns + `
+		FROM optimizations
+		ORDER BY created_at DESC
+		LIMIT $1 OFFSET $2`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error listing optimizations: %w", err)
+	}
+	defer rows.Close()
+
+	var optimizations []*domain.Optimization
+	for rows.Next() {
+		o, err := scanOptimization(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning optimization: %w", err)
+		}
+
+		optimizations = append(optimizations, o)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating optimizations: %w", err)
+	}
+
+	return optimizations, nil
+}
+
+func scanOptimization(row rowScanner) (*domain.Optimization, error) {
+	o := &domain.Optimization{}
+	var grid []byte
+	var objectiveStr string
+	var statusStr string
+	var completedAt sql.NullTime
+	var errorMessage sql.NullString
+
+	if err := row.Scan(
+		&o.ID,
+		&o.StrategyID,
+		pq.Array(&o.Symbols),
+		&o.StartDate,
+		&o.EndDate,
+		&grid,
+		&objectiveStr,
+		&statusStr,
+		&o.Combinations,
+		&o.Skipped,
+		&o.CreatedAt,
+		&o.UpdatedAt,
+		&completedAt,
+		&errorMessage,
+	); err != nil {
+		return nil, err
+	}
+
+	o.Objective = parseObjective(objectiveStr)
+	o.Status = parseStatus(statusStr)
+
+	if err := json.Unmarshal(grid, &o.Grid); err != nil {
+		return nil, fmt.Errorf("invalid parameter grid: %w", err)
+	}
+
+	if completedAt.Valid {
+		o.CompletedAt = &completedAt.Time
+	}
+	if errorMessage.Valid {
+		o.ErrorMessage = errorMessage.String
+	}
+
+	return o, nil
+}
+
+func parseObjective(s string) domain.OptimizationObjective {
+	switch s {
+	case "TOTAL_RETURN":
+		return domain.OptimizationObjectiveTotalReturn
+	case "MAX_DRAWDOWN":
+		return domain.OptimizationObjectiveMaxDrawdown
+	case "PROFIT_FACTOR":
+		return domain.OptimizationObjectiveProfitFactor
+	default:
+		return domain.OptimizationObjectiveSharpe
+	}
+}
@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+)
+
+// rows per insert statement, keeps the bind parameters under the postgres limit
+const roundTripBatchSize = 1000
+
+type roundTripRepository struct {
+	db *sql.DB
+}
+
+func NewRoundTripRepository(db *sql.DB) *roundTripRepository {
+	return &roundTripRepository{db: db}
+}
+
+func (r *roundTripRepository) CreateBatch(ctx context.Context, trips []domain.RoundTrip) error {
+	if len(trips) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	const columns = 14
+	for start := 0; start < len(trips); start += roundTripBatchSize {
+		end := min(start+roundTripBatchSize, len(trips))
+		batch := trips[start:end]
+
+		valueStrings := make([]string, 0, len(batch))
+		valueArgs := make([]interface{}, 0, len(batch)*columns)
+
+		for i, trip := range batch {
+			placeholders := make([]string, columns)
+			for j := range placeholders {
+				placeholders[j] = fmt.Sprintf("$%d", i*columns+j+1)
+			}
+			valueStrings = append(valueStrings, "("+strings.Join(placeholders, ", ")+")")
+
+			valueArgs = append(valueArgs,
+				trip.BacktestID,
+				trip.Index,
+				trip.Symbol,
+				trip.Side.String(),
+				trip.EntryTime,
+				trip.ExitTime,
+				trip.EntryPrice,
+				trip.ExitPrice,
+				trip.Quantity,
+				trip.PnL,
+				trip.ReturnPct,
+				trip.BarsHeld,
+				trip.MAE,
+				trip.MFE,
+			)
+		}
+
+		query := fmt.Sprintf(`
+			INSERT INTO round_trips (
+				backtest_id, trip_index, symbol, direction, entry_time,
+				exit_time, entry_price, exit_price, quantity, pnl, return_pct,
+				bars_held, mae, mfe
+			)
+			VALUES %s`,
+			strings.Join(valueStrings, ","),
+		)
+
+		if _, err := tx.ExecContext(ctx, query, valueArgs...); err != nil {
+			return fmt.Errorf("failed to bulk insert round trips: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *roundTripRepository) GetByBacktestID(ctx context.Context, backtestID uuid.UUID) ([]domain.RoundTrip, error) {
+	query := `
+		SELECT backtest_id, trip_index, symbol, direction, entry_time,
+		       exit_time, entry_price, exit_price, quantity, pnl, return_pct,
+		       bars_held, mae, mfe
+		FROM round_trips
+		WHERE backtest_id = $1
+		ORDER BY trip_index ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, backtestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query round trips: %w", err)
+	}
+	defer rows.Close()
+
+	var trips []domain.RoundTrip
+	for rows.Next() {
+		var trip domain.RoundTrip
+		var sideStr string
+		if err := rows.Scan(
+			&trip.BacktestID,
+			&trip.Index,
+			&trip.Symbol,
+			&sideStr,
+			&trip.EntryTime,
+			&trip.ExitTime,
+			&trip.EntryPrice,
+			&trip.ExitPrice,
+			&trip.Quantity,
+			&trip.PnL,
+			&trip.ReturnPct,
+			&trip.BarsHeld,
+			&trip.MAE,
+			&trip.MFE,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan round trip: %w", err)
+		}
+		trip.Side = parsePositionSide(sideStr)
+		trips = append(trips, trip)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating round trips: %w", err)
+	}
+
+	return trips, nil
+}
+
+func (r *roundTripRepository) DeleteByBacktest(ctx context.Context, backtestID uuid.UUID) error {
+	query := `DELETE FROM round_trips WHERE backtest_id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, backtestID); err != nil {
+		return fmt.Errorf("failed to delete round trips: %w", err)
+	}
+
+	return nil
+}
+
+func parsePositionSide(s string) domain.PositionSide {
+	if s == "SHORT" {
+		return domain.PositionSideShort
+	}
+	return domain.PositionSideLong
+}
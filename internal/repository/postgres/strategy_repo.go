@@ -176,6 +176,10 @@ func parseLanguage(l string) domain.StrategyLanguage {
 		return domain.StrategyLanguageGo
 	case "PYTHON":
 		return domain.StrategyLanguagePython
+	case "WASM":
+		return domain.StrategyLanguageWasm
+	case "RULES":
+		return domain.StrategyLanguageRules
 	default:
 		log.Printf("Unknown language '%s', defaulting to Python", l)
 		return domain.StrategyLanguagePython
@@ -22,9 +22,9 @@ func (r *tradeRepository) Create(ctx context.Context, trade *domain.Trade) error
 	query := `
 		INSERT INTO trades (
 			backtest_id, symbol, direction, quantity, price,
-			commission, timestamp, pnl, cumulative_pnl
+			commission, slippage, borrow_fee, dividends, timestamp, pnl, cumulative_pnl
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING id`
 
 	err := r.db.QueryRowContext(
@@ -36,6 +36,9 @@ func (r *tradeRepository) Create(ctx context.Context, trade *domain.Trade) error
 		trade.Quantity,
 		trade.Price,
 		trade.Commission,
+		trade.Slippage,
+		trade.BorrowFee,
+		trade.Dividends,
 		trade.Timestamp,
 		trade.PnL,
 		trade.CumulativePnL,
@@ -60,12 +63,12 @@ func (r *tradeRepository) CreateBatch(ctx context.Context, trades []*domain.Trad
 	defer tx.Rollback()
 
 	valueStrings := make([]string, 0, len(trades))
-	valueArgs := make([]interface{}, 0, len(trades)*9)
+	valueArgs := make([]interface{}, 0, len(trades)*12)
 
 	for i, trade := range trades {
 		valueStrings = append(valueStrings, fmt.Sprintf(
-			"($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
-			i*9+1, i*9+2, i*9+3, i*9+4, i*9+5, i*9+6, i*9+7, i*9+8, i*9+9,
+			"($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
+			i*12+1, i*12+2, i*12+3, i*12+4, i*12+5, i*12+6, i*12+7, i*12+8, i*12+9, i*12+10, i*12+11, i*12+12,
 		))
 
 		valueArgs = append(valueArgs,
@@ -75,6 +78,9 @@ func (r *tradeRepository) CreateBatch(ctx context.Context, trades []*domain.Trad
 			trade.Quantity,
 			trade.Price,
 			trade.Commission,
+			trade.Slippage,
+			trade.BorrowFee,
+			trade.Dividends,
 			trade.Timestamp,
 			trade.PnL,
 			trade.CumulativePnL,
@@ -84,7 +90,7 @@ func (r *tradeRepository) CreateBatch(ctx context.Context, trades []*domain.Trad
 	query := fmt.Sprintf(`
 		INSERT INTO trades (
 			backtest_id, symbol, direction, quantity, price,
-			commission, timestamp, pnl, cumulative_pnl
+			commission, slippage, borrow_fee, dividends, timestamp, pnl, cumulative_pnl
 		)
 		VALUES %s
 		RETURNING id`,
@@ -122,7 +128,7 @@ func (r *tradeRepository) CreateBatch(ctx context.Context, trades []*domain.Trad
 func (r *tradeRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Trade, error) {
 	query := `
 		SELECT id, backtest_id, symbol, direction, quantity, price,
-		       commission, timestamp, pnl, cumulative_pnl
+		       commission, slippage, borrow_fee, dividends, timestamp, pnl, cumulative_pnl
 		FROM trades
 		WHERE id = $1`
 
@@ -137,6 +143,9 @@ func (r *tradeRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Tr
 		&trade.Quantity,
 		&trade.Price,
 		&trade.Commission,
+		&trade.Slippage,
+		&trade.BorrowFee,
+		&trade.Dividends,
 		&trade.Timestamp,
 		&trade.PnL,
 		&trade.CumulativePnL,
@@ -156,7 +165,7 @@ func (r *tradeRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Tr
 func (r *tradeRepository) GetByBacktestID(ctx context.Context, backtestID uuid.UUID) ([]*domain.Trade, error) {
 	query := `
         SELECT id, backtest_id, symbol, direction, quantity, price,
-               commission, timestamp, pnl, cumulative_pnl
+               commission, slippage, borrow_fee, dividends, timestamp, pnl, cumulative_pnl
         FROM trades
         WHERE backtest_id = $1
         ORDER BY timestamp ASC
@@ -171,14 +180,18 @@ func (r *tradeRepository) GetByBacktestID(ctx context.Context, backtestID uuid.U
 	var trades []*domain.Trade
 	for rows.Next() {
 		trade := &domain.Trade{}
+		var directionStr string
 		err := rows.Scan(
 			&trade.ID,
 			&trade.BacktestID,
 			&trade.Symbol,
-			&trade.Direction,
+			&directionStr,
 			&trade.Quantity,
 			&trade.Price,
 			&trade.Commission,
+			&trade.Slippage,
+			&trade.BorrowFee,
+			&trade.Dividends,
 			&trade.Timestamp,
 			&trade.PnL,
 			&trade.CumulativePnL,
@@ -186,6 +199,7 @@ func (r *tradeRepository) GetByBacktestID(ctx context.Context, backtestID uuid.U
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan trade: %w", err)
 		}
+		trade.Direction = parseDirection(directionStr)
 		trades = append(trades, trade)
 	}
 
@@ -199,7 +213,7 @@ func (r *tradeRepository) GetByBacktestID(ctx context.Context, backtestID uuid.U
 func (r *tradeRepository) ListByBacktest(ctx context.Context, backtestID uuid.UUID) ([]*domain.Trade, error) {
 	query := `
 		SELECT id, backtest_id, symbol, direction, quantity, price,
-		       commission, timestamp, pnl, cumulative_pnl
+		       commission, slippage, borrow_fee, dividends, timestamp, pnl, cumulative_pnl
 		FROM trades
 		WHERE backtest_id = $1
 		ORDER BY timestamp ASC`
@@ -223,6 +237,9 @@ func (r *tradeRepository) ListByBacktest(ctx context.Context, backtestID uuid.UU
 			&trade.Quantity,
 			&trade.Price,
 			&trade.Commission,
+			&trade.Slippage,
+			&trade.BorrowFee,
+			&trade.Dividends,
 			&trade.Timestamp,
 			&trade.PnL,
 			&trade.CumulativePnL,
@@ -293,6 +310,10 @@ func parseDirection(d string) domain.TradeDirection {
 		return domain.TradeDirectionBuy
 	case "SELL":
 		return domain.TradeDirectionSell
+	case "SHORT":
+		return domain.TradeDirectionShort
+	case "COVER":
+		return domain.TradeDirectionCover
 	default:
 		return domain.TradeDirectionBuy
 	}
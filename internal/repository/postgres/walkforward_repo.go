@@ -0,0 +1,340 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+)
+
+const walkForwardColumns = `id, strategy_id, symbols, start_date, end_date, in_sample_days,
+		       out_of_sample_days, anchored, grid, objective, status, combinations,
+		       backtest_id, efficiency, created_at, updated_at, completed_at, error_message`
+
+type walkForwardRepository struct {
+	db *sql.DB
+}
+
+func NewWalkForwardRepository(db *sql.DB) *walkForwardRepository {
+	return &walkForwardRepository{db: db}
+}
+
+func (r *walkForwardRepository) Create(ctx context.Context, w *domain.WalkForward) error {
+	if w.CreatedAt.IsZero() {
+		w.CreatedAt = time.Now()
+	}
+	w.UpdatedAt = w.CreatedAt
+
+	grid, err := json.Marshal(w.Grid)
+	if err != nil {
+		return fmt.Errorf("failed to encode parameter grid: %w", err)
+	}
+
+	query := `
+		INSERT INTO walk_forwards (
+			strategy_id, symbols, start_date, end_date, in_sample_days,
+			out_of_sample_days, anchored, grid, objective, status, combinations,
+			backtest_id, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id`
+
+	err = r.db.QueryRowContext(
+		ctx,
+		query,
+		w.StrategyID,
+		pq.Array(w.Symbols),
+		w.StartDate,
+		w.EndDate,
+		w.InSampleDays,
+		w.OutOfSampleDays,
+		w.Anchored,
+		grid,
+		w.Objective.String(),
+		w.Status.String(),
+		w.Combinations,
+		w.BacktestID,
+		w.CreatedAt,
+		w.UpdatedAt,
+	).Scan(&w.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to insert walk-forward: %w", err)
+	}
+
+	return nil
+}
+
+func (r *walkForwardRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.WalkForward, error) {
+	query := `
+		SELECT ` + walkForwardColumns + `
+		FROM walk_forwards
+		WHERE id = $1`
+
+	w, err := scanWalkForward(r.db.QueryRowContext(ctx, query, id))
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("walk-forward not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching walk-forward: %w", err)
+	}
+
+	return w, nil
+}
+
+// Update saves the progress and outcome of a walk-forward, its settings
+// never change after creation
+func (r *walkForwardRepository) Update(ctx context.Context, w *domain.WalkForward) error {
+	w.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE walk_forwards
+		SET status = $1, efficiency = $2, updated_at = $3, completed_at = $4, error_message = $5
+		WHERE id = $6`
+
+	var completedAt sql.NullTime
+	if w.CompletedAt != nil {
+		completedAt = sql.NullTime{Time: *w.CompletedAt, Valid: true}
+	}
+
+	var errorMessage sql.NullString
+	if w.ErrorMessage != "" {
+		errorMessage = sql.NullString{String: w.ErrorMessage, Valid: true}
+	}
+
+	result, err := r.db.ExecContext(ctx, query, w.Status.String(), w.Efficiency, w.UpdatedAt, completedAt, errorMessage, w.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update walk-forward: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("walk-forward not found")
+	}
+
+	return nil
+}
+
+// Delete removes the walk-forward and its window reports, the stitched
+// backtest stays
+func (r *walkForwardRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM walk_forwards WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete walk-forward: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("walk-forward not found")
+	}
+
+	return nil
+}
+
+func (r *walkForwardRepository) List(ctx context.Context, limit, offset int) ([]*domain.WalkForward, error) {
+	query := `
+		SELECT ` + walkForwardColumns + `
+		FROM walk_forwards
+		ORDER BY created_at DESC
+		LIMIT $1 OFFSET $2`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error listing walk-forwards: %w", err)
+	}
+	defer rows.Close()
+
+	var walkForwards []*domain.WalkForward
+	for rows.Next() {
+		w, err := scanWalkForward(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning walk-forward: %w", err)
+		}
+
+		walkForwards = append(walkForwards, w)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating walk-forwards: %w", err)
+	}
+
+	return walkForwards, nil
+}
+
+// CreateWindows saves the window reports of a finished walk-forward
+func (r *walkForwardRepository) CreateWindows(ctx context.Context, windows []domain.WalkForwardWindow) error {
+	if len(windows) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO walk_forward_windows (
+			walk_forward_id, window_index, in_sample_start, in_sample_end,
+			out_of_sample_start, out_of_sample_end, parameters, evaluated,
+			in_sample_score, out_of_sample_score, in_sample_return,
+			out_of_sample_return, out_of_sample_trades, efficiency
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`
+
+	for _, w := range windows {
+		params, err := json.Marshal(w.Parameters)
+		if err != nil {
+			return fmt.Errorf("failed to encode window parameters: %w", err)
+		}
+
+		if _, err := tx.ExecContext(
+			ctx,
+			query,
+			w.WalkForwardID,
+			w.Index,
+			w.InSampleStart,
+			w.InSampleEnd,
+			w.OutOfSampleStart,
+			w.OutOfSampleEnd,
+			params,
+			w.Evaluated,
+			w.InSampleScore,
+			w.OutOfSampleScore,
+			w.InSampleReturn,
+			w.OutOfSampleReturn,
+			w.OutOfSampleTrades,
+			w.Efficiency,
+		); err != nil {
+			return fmt.Errorf("failed to insert walk-forward window: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *walkForwardRepository) ListWindows(ctx context.Context, walkForwardID uuid.UUID) ([]domain.WalkForwardWindow, error) {
+	query := `
+		SELECT walk_forward_id, window_index, in_sample_start, in_sample_end,
+		       out_of_sample_start, out_of_sample_end, parameters, evaluated,
+		       in_sample_score, out_of_sample_score, in_sample_return,
+		       out_of_sample_return, out_of_sample_trades, efficiency
+		FROM walk_forward_windows
+		WHERE walk_forward_id = $1
+		ORDER BY window_index ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, walkForwardID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing walk-forward windows: %w", err)
+	}
+	defer rows.Close()
+
+	var windows []domain.WalkForwardWindow
+	for rows.Next() {
+		var w domain.WalkForwardWindow
+		var params []byte
+
+		if err := rows.Scan(
+			&w.WalkForwardID,
+			&w.Index,
+			&w.InSampleStart,
+			&w.InSampleEnd,
+			&w.OutOfSampleStart,
+			&w.OutOfSampleEnd,
+			&params,
+			&w.Evaluated,
+			&w.InSampleScore,
+			&w.OutOfSampleScore,
+			&w.InSampleReturn,
+			&w.OutOfSampleReturn,
+			&w.OutOfSampleTrades,
+			&w.Efficiency,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning walk-forward window: %w", err)
+		}
+
+		if err := json.Unmarshal(params, &w.Parameters); err != nil {
+			return nil, fmt.Errorf("invalid window parameters: %w", err)
+		}
+
+		windows = append(windows, w)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating walk-forward windows: %w", err)
+	}
+
+	return windows, nil
+}
+
+func scanWalkForward(row rowScanner) (*domain.WalkForward, error) {
+	w := &domain.WalkForward{}
+	var grid []byte
+	var objectiveStr string
+	var statusStr string
+	var backtestID uuid.NullUUID
+	var completedAt sql.NullTime
+	var errorMessage sql.NullString
+
+	if err := row.Scan(
+		&w.ID,
+		&w.StrategyID,
+		pq.Array(&w.Symbols),
+		&w.StartDate,
+		&w.EndDate,
+		&w.InSampleDays,
+		&w.OutOfSampleDays,
+		&w.Anchored,
+		&grid,
+		&objectiveStr,
+		&statusStr,
+		&w.Combinations,
+		&backtestID,
+		&w.Efficiency,
+		&w.CreatedAt,
+		&w.UpdatedAt,
+		&completedAt,
+		&errorMessage,
+	); err != nil {
+		return nil, err
+	}
+
+	w.Objective = parseObjective(objectiveStr)
+	w.Status = parseStatus(statusStr)
+
+	if err := json.Unmarshal(grid, &w.Grid); err != nil {
+		return nil, fmt.Errorf("invalid parameter grid: %w", err)
+	}
+
+	if backtestID.Valid {
+		w.BacktestID = &backtestID.UUID
+	}
+	if completedAt.Valid {
+		w.CompletedAt = &completedAt.Time
+	}
+	if errorMessage.Valid {
+		w.ErrorMessage = errorMessage.String
+	}
+
+	return w, nil
+}
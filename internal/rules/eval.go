@@ -0,0 +1,593 @@
+package rules
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/strategy"
+)
+
+// series is a numeric expression evaluated at a bar index. it is NaN while
+// an indicator does not have enough bars yet, which makes every comparison
+// using it false
+type series interface {
+	at(e *env, i int) float64
+}
+
+// condition is a boolean expression evaluated at a bar index
+type condition interface {
+	test(e *env, i int) bool
+}
+
+// env holds the bars of one symbol and memoizes the recursive indicators by
+// bar index, so evaluating them on every new bar stays linear. ctx is the
+// context of the latest bar, its streaming indicators serve the indicators
+// of a bar field at the last two bars
+type env struct {
+	bars []domain.Bar
+	ctx  *strategy.Context
+	memo map[series][]memoValue
+}
+
+type memoValue struct {
+	value float64
+	ok    bool
+}
+
+func newEnv() *env {
+	return &env{memo: make(map[series][]memoValue)}
+}
+
+// extends reports whether bars continues the bars seen so far, a split
+// restating the history invalidates the memoized values
+func (e *env) extends(bars []domain.Bar) bool {
+	n := len(e.bars)
+	return len(bars) >= n && (n == 0 || bars[n-1] == e.bars[n-1])
+}
+
+func (e *env) remember(s series, i int, compute func() float64) float64 {
+	values := e.memo[s]
+	if i < len(values) && values[i].ok {
+		return values[i].value
+	}
+
+	value := compute()
+	values = e.memo[s] // compute may have grown it
+	if i >= len(values) {
+		values = append(values, make([]memoValue, i+1-len(values))...)
+	}
+	values[i] = memoValue{value: value, ok: true}
+	e.memo[s] = values
+	return value
+}
+
+// window returns the values of s over the n bars ending at i, false when
+// one of them is missing
+func window(e *env, s series, i, n int) ([]float64, bool) {
+	if i-n+1 < 0 {
+		return nil, false
+	}
+	values := make([]float64, n)
+	for k := range values {
+		values[k] = s.at(e, i-n+1+k)
+		if math.IsNaN(values[k]) {
+			return nil, false
+		}
+	}
+	return values, true
+}
+
+type constant float64
+
+func (c constant) at(e *env, i int) float64 {
+	return float64(c)
+}
+
+type field func(bar domain.Bar) float64
+
+func (f field) at(e *env, i int) float64 {
+	if i < 0 || i >= len(e.bars) {
+		return math.NaN()
+	}
+	return f(e.bars[i])
+}
+
+var fields = map[string]field{
+	"open":   func(b domain.Bar) float64 { return b.Open },
+	"high":   func(b domain.Bar) float64 { return b.High },
+	"low":    func(b domain.Bar) float64 { return b.Low },
+	"close":  func(b domain.Bar) float64 { return b.Close },
+	"volume": func(b domain.Bar) float64 { return float64(b.Volume) },
+}
+
+type negate struct{ x series }
+
+func (n *negate) at(e *env, i int) float64 {
+	return -n.x.at(e, i)
+}
+
+type arithmetic struct {
+	op          string
+	left, right series
+}
+
+func (a *arithmetic) at(e *env, i int) float64 {
+	l, r := a.left.at(e, i), a.right.at(e, i)
+	switch a.op {
+	case "+":
+		return l + r
+	case "-":
+		return l - r
+	case "*":
+		return l * r
+	default:
+		if r == 0 {
+			return math.NaN()
+		}
+		return l / r
+	}
+}
+
+type compare struct {
+	op          string
+	left, right series
+}
+
+func (c *compare) test(e *env, i int) bool {
+	l, r := c.left.at(e, i), c.right.at(e, i)
+	switch c.op {
+	case "<":
+		return l < r
+	case "<=":
+		return l <= r
+	case ">":
+		return l > r
+	case ">=":
+		return l >= r
+	case "==":
+		return l == r
+	default:
+		return l != r && !math.IsNaN(l) && !math.IsNaN(r)
+	}
+}
+
+// cross is true on the bar where left moves from at or below right to above
+// it, or the other way round for crosses_below
+type cross struct {
+	above       bool
+	left, right series
+}
+
+func (c *cross) test(e *env, i int) bool {
+	prevL, prevR := c.left.at(e, i-1), c.right.at(e, i-1)
+	l, r := c.left.at(e, i), c.right.at(e, i)
+	if c.above {
+		return prevL <= prevR && l > r
+	}
+	return prevL >= prevR && l < r
+}
+
+type logical struct {
+	and         bool
+	left, right condition
+}
+
+func (l *logical) test(e *env, i int) bool {
+	if l.and {
+		return l.left.test(e, i) && l.right.test(e, i)
+	}
+	return l.left.test(e, i) || l.right.test(e, i)
+}
+
+type not struct{ x condition }
+
+func (n *not) test(e *env, i int) bool {
+	return !n.x.test(e, i)
+}
+
+// function describes an indicator call. period arguments follow the
+// optional source series and must be positive integer literals
+type function struct {
+	source  bool // first argument is a series
+	periods int
+	build   func(src series, periods []int) series
+}
+
+var functions = map[string]function{
+	"sma":     {source: true, periods: 1, build: func(src series, p []int) series { return &sma{src, p[0]} }},
+	"ema":     {source: true, periods: 1, build: func(src series, p []int) series { return &ema{src, p[0]} }},
+	"rsi":     {source: true, periods: 1, build: func(src series, p []int) series { return newRSI(src, p[0]) }},
+	"highest": {source: true, periods: 1, build: func(src series, p []int) series { return &extreme{src, p[0], true} }},
+	"lowest":  {source: true, periods: 1, build: func(src series, p []int) series { return &extreme{src, p[0], false} }},
+	"stddev":  {source: true, periods: 1, build: func(src series, p []int) series { return &stddev{src, p[0]} }},
+	"roc":     {source: true, periods: 1, build: func(src series, p []int) series { return &roc{src, p[0]} }},
+	"prev":    {source: true, periods: 1, build: func(src series, p []int) series { return &prev{src, p[0]} }},
+	"atr":     {periods: 1, build: func(src series, p []int) series { return &sma{trueRange{}, p[0]} }},
+	"abs":     {source: true, build: func(src series, p []int) series { return &absolute{src} }},
+}
+
+// streams maps the indicators the strategy package keeps incrementally to
+// their constructor and the bar field they read, atr reads whole bars
+var streams = map[string]struct {
+	source string
+	build  func(period int) (strategy.Indicator, error)
+}{
+	"sma":     {"close", strategy.NewRollingSMA},
+	"ema":     {"close", strategy.NewRollingEMA},
+	"rsi":     {"close", strategy.NewRollingRSI},
+	"stddev":  {"close", strategy.NewRollingStdDev},
+	"highest": {"high", strategy.NewRollingHighest},
+	"lowest":  {"low", strategy.NewRollingLowest},
+	"atr":     {"", strategy.NewRollingATR},
+}
+
+// streamed reads an indicator of a bar field from the context, which the
+// executor updates once per bar. the indicator only knows the current and
+// the previous value, older bars fall back to the windowed evaluation
+type streamed struct {
+	key      string
+	build    func() (strategy.Indicator, error)
+	fallback series
+}
+
+func (s *streamed) at(e *env, i int) float64 {
+	last := len(e.bars) - 1
+	if e.ctx == nil || i < last-1 || i > last {
+		return s.fallback.at(e, i)
+	}
+
+	indicator, err := e.ctx.Indicator(s.key, s.build)
+	if err != nil {
+		return s.fallback.at(e, i)
+	}
+	if i == last {
+		return indicator.Value()
+	}
+	return indicator.Previous()
+}
+
+// largest period accepted, to keep a typo from scanning the whole history
+const maxPeriod = 5000
+
+// functionNames lists the functions for error messages
+func functionNames() string {
+	names := make([]string, 0, len(functions))
+	for name := range functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+type sma struct {
+	src    series
+	period int
+}
+
+func (s *sma) at(e *env, i int) float64 {
+	values, ok := window(e, s.src, i, s.period)
+	if !ok {
+		return math.NaN()
+	}
+	return mean(values)
+}
+
+// ema is seeded with the simple average of its first full window
+type ema struct {
+	src    series
+	period int
+}
+
+func (s *ema) at(e *env, i int) float64 {
+	if i < 0 {
+		return math.NaN()
+	}
+	return e.remember(s, i, func() float64 {
+		previous := s.at(e, i-1)
+		if math.IsNaN(previous) {
+			values, ok := window(e, s.src, i, s.period)
+			if !ok {
+				return math.NaN()
+			}
+			return mean(values)
+		}
+
+		alpha := 2 / float64(s.period+1)
+		return previous + alpha*(s.src.at(e, i)-previous)
+	})
+}
+
+// rsi uses Wilder smoothing of the average gain and loss
+type rsi struct {
+	period int
+	gain   *wilder
+	loss   *wilder
+}
+
+func newRSI(src series, period int) *rsi {
+	return &rsi{
+		period: period,
+		gain:   &wilder{change: &change{src, true}, period: period},
+		loss:   &wilder{change: &change{src, false}, period: period},
+	}
+}
+
+func (s *rsi) at(e *env, i int) float64 {
+	gain, loss := s.gain.at(e, i), s.loss.at(e, i)
+	if math.IsNaN(gain) || math.IsNaN(loss) {
+		return math.NaN()
+	}
+	if loss == 0 {
+		return 100
+	}
+	return 100 - 100/(1+gain/loss)
+}
+
+// change is the bar to bar gain, or loss, of a series, zero when it moved
+// the other way
+type change struct {
+	src  series
+	gain bool
+}
+
+func (c *change) at(e *env, i int) float64 {
+	delta := c.src.at(e, i) - c.src.at(e, i-1)
+	if !c.gain {
+		delta = -delta
+	}
+	return math.Max(delta, 0) // NaN stays NaN
+}
+
+type wilder struct {
+	change series
+	period int
+}
+
+func (w *wilder) at(e *env, i int) float64 {
+	if i < 0 {
+		return math.NaN()
+	}
+	return e.remember(w, i, func() float64 {
+		previous := w.at(e, i-1)
+		if math.IsNaN(previous) {
+			values, ok := window(e, w.change, i, w.period)
+			if !ok {
+				return math.NaN()
+			}
+			return mean(values)
+		}
+		return (previous*float64(w.period-1) + w.change.at(e, i)) / float64(w.period)
+	})
+}
+
+type extreme struct {
+	src     series
+	period  int
+	highest bool
+}
+
+func (s *extreme) at(e *env, i int) float64 {
+	values, ok := window(e, s.src, i, s.period)
+	if !ok {
+		return math.NaN()
+	}
+	best := values[0]
+	for _, v := range values[1:] {
+		if s.highest && v > best || !s.highest && v < best {
+			best = v
+		}
+	}
+	return best
+}
+
+// stddev is the population standard deviation over the window
+type stddev struct {
+	src    series
+	period int
+}
+
+func (s *stddev) at(e *env, i int) float64 {
+	values, ok := window(e, s.src, i, s.period)
+	if !ok {
+		return math.NaN()
+	}
+	avg := mean(values)
+	sum := 0.0
+	for _, v := range values {
+		sum += (v - avg) * (v - avg)
+	}
+	return math.Sqrt(sum / float64(len(values)))
+}
+
+// roc is the percent change over period bars
+type roc struct {
+	src    series
+	period int
+}
+
+func (s *roc) at(e *env, i int) float64 {
+	then := s.src.at(e, i-s.period)
+	if then == 0 {
+		return math.NaN()
+	}
+	return (s.src.at(e, i)/then - 1) * 100
+}
+
+type prev struct {
+	src    series
+	period int
+}
+
+func (s *prev) at(e *env, i int) float64 {
+	return s.src.at(e, i-s.period)
+}
+
+// trueRange also covers the gap from the previous close
+type trueRange struct{}
+
+func (trueRange) at(e *env, i int) float64 {
+	if i < 1 || i >= len(e.bars) {
+		return math.NaN()
+	}
+	bar, prevClose := e.bars[i], e.bars[i-1].Close
+	return math.Max(bar.High-bar.Low, math.Max(math.Abs(bar.High-prevClose), math.Abs(bar.Low-prevClose)))
+}
+
+type absolute struct{ x series }
+
+func (a *absolute) at(e *env, i int) float64 {
+	return math.Abs(a.x.at(e, i))
+}
+
+func mean(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// compileCondition type checks an expression that must be true or false
+func compileCondition(n *node) (condition, error) {
+	switch {
+	case n.kind == nodeUnary && n.op == "not":
+		x, err := compileCondition(n.args[0])
+		if err != nil {
+			return nil, err
+		}
+		return &not{x}, nil
+
+	case n.kind == nodeBinary && (n.op == "and" || n.op == "or"):
+		left, err := compileCondition(n.args[0])
+		if err != nil {
+			return nil, err
+		}
+		right, err := compileCondition(n.args[1])
+		if err != nil {
+			return nil, err
+		}
+		return &logical{and: n.op == "and", left: left, right: right}, nil
+
+	case n.kind == nodeBinary && contains(comparisons, n.op):
+		left, err := compileSeries(n.args[0])
+		if err != nil {
+			return nil, err
+		}
+		right, err := compileSeries(n.args[1])
+		if err != nil {
+			return nil, err
+		}
+		switch n.op {
+		case "crosses_above", "crosses_below":
+			return &cross{above: n.op == "crosses_above", left: left, right: right}, nil
+		default:
+			return &compare{op: n.op, left: left, right: right}, nil
+		}
+	}
+
+	return nil, &exprError{pos: n.pos, msg: "expected a condition, got a number"}
+}
+
+// compileSeries type checks a numeric expression
+func compileSeries(n *node) (series, error) {
+	switch n.kind {
+	case nodeNumber:
+		return constant(n.num), nil
+
+	case nodeIdent:
+		if f, ok := fields[n.op]; ok {
+			return f, nil
+		}
+		if _, ok := functions[n.op]; ok {
+			return nil, &exprError{pos: n.pos, msg: fmt.Sprintf("%s is a function, call it as %s(...)", n.op, n.op)}
+		}
+		return nil, &exprError{pos: n.pos, msg: fmt.Sprintf("unknown name %q, expected open, high, low, close or volume", n.op)}
+
+	case nodeUnary:
+		if n.op == "-" {
+			x, err := compileSeries(n.args[0])
+			if err != nil {
+				return nil, err
+			}
+			return &negate{x}, nil
+		}
+
+	case nodeBinary:
+		switch n.op {
+		case "+", "-", "*", "/":
+			left, err := compileSeries(n.args[0])
+			if err != nil {
+				return nil, err
+			}
+			right, err := compileSeries(n.args[1])
+			if err != nil {
+				return nil, err
+			}
+			return &arithmetic{op: n.op, left: left, right: right}, nil
+		}
+
+	case nodeCall:
+		return compileCall(n)
+	}
+
+	return nil, &exprError{pos: n.pos, msg: "expected a number, got a condition"}
+}
+
+func compileCall(n *node) (series, error) {
+	fn, ok := functions[n.op]
+	if !ok {
+		return nil, &exprError{pos: n.pos, msg: fmt.Sprintf("unknown function %q, expected one of %s", n.op, functionNames())}
+	}
+
+	args := n.args
+	var src series = fields["close"]
+	source := "close"
+	if !fn.source {
+		source = ""
+	}
+	switch {
+	case fn.source && len(args) == fn.periods+1:
+		s, err := compileSeries(args[0])
+		if err != nil {
+			return nil, err
+		}
+		source = ""
+		if args[0].kind == nodeIdent {
+			source = args[0].op
+		}
+		src, args = s, args[1:]
+	case fn.source && fn.periods > 0 && len(args) == fn.periods:
+		// the source defaults to close
+	case len(args) != fn.periods:
+		want := fmt.Sprintf("%d", fn.periods)
+		if fn.source {
+			want = fmt.Sprintf("%d or %d", fn.periods, fn.periods+1)
+			if fn.periods == 0 {
+				want = "1"
+			}
+		}
+		return nil, &exprError{pos: n.pos, msg: fmt.Sprintf("%s takes %s arguments, got %d", n.op, want, len(n.args))}
+	}
+
+	periods := make([]int, len(args))
+	for i, arg := range args {
+		if arg.kind != nodeNumber || arg.num != math.Trunc(arg.num) || arg.num < 1 || arg.num > maxPeriod {
+			return nil, &exprError{pos: arg.pos, msg: fmt.Sprintf("period of %s must be a whole number between 1 and %d", n.op, maxPeriod)}
+		}
+		periods[i] = int(arg.num)
+	}
+
+	built := fn.build(src, periods)
+	if stream, ok := streams[n.op]; ok && stream.source == source {
+		period := periods[0]
+		return &streamed{
+			key:      fmt.Sprintf("%s:%d", n.op, period),
+			build:    func() (strategy.Indicator, error) { return stream.build(period) },
+			fallback: built,
+		}, nil
+	}
+	return built, nil
+}
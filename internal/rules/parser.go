@@ -0,0 +1,296 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenIdent
+	tokenOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int // 1-based column in the expression
+}
+
+// lex splits an expression into tokens. identifiers are lowercased so
+// keywords and functions are case insensitive
+func lex(src string) ([]token, error) {
+	var tokens []token
+	runes := []rune(src)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+
+		case unicode.IsDigit(r) || r == '.':
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{tokenNumber, string(runes[start:i]), start + 1})
+
+		case unicode.IsLetter(r) || r == '_':
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{tokenIdent, strings.ToLower(string(runes[start:i])), start + 1})
+
+		default:
+			op := string(r)
+			if i+1 < len(runes) {
+				switch two := string(runes[i : i+2]); two {
+				case "<=", ">=", "==", "!=":
+					op = two
+				}
+			}
+			if len(op) == 1 && !strings.ContainsRune("()+-*/<>,", r) {
+				return nil, &exprError{pos: start + 1, msg: fmt.Sprintf("unexpected character %q", r)}
+			}
+			i += len([]rune(op))
+			tokens = append(tokens, token{tokenOp, op, start + 1})
+		}
+	}
+
+	return append(tokens, token{tokenEOF, "", len(runes) + 1}), nil
+}
+
+type nodeKind int
+
+const (
+	nodeNumber nodeKind = iota
+	nodeIdent
+	nodeCall
+	nodeUnary
+	nodeBinary
+)
+
+// node is the syntax tree of an expression before type checking
+type node struct {
+	kind nodeKind
+	pos  int
+	op   string // operator, identifier or function name
+	num  float64
+	args []*node
+}
+
+// exprError is a syntax or type error at a column of the expression
+type exprError struct {
+	pos int
+	msg string
+}
+
+func (e *exprError) Error() string {
+	return fmt.Sprintf("column %d: %s", e.pos, e.msg)
+}
+
+// parser is a recursive descent parser, lowest precedence first:
+//
+//	or, and, not, comparisons and crosses, + -, * /, unary -
+type parser struct {
+	tokens []token
+	i      int
+}
+
+func parse(src string) (*node, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	n, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.unexpected(tok)
+	}
+	return n, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.i]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.i]
+	if tok.kind != tokenEOF {
+		p.i++
+	}
+	return tok
+}
+
+func (p *parser) unexpected(tok token) error {
+	if tok.kind == tokenEOF {
+		return &exprError{pos: tok.pos, msg: "unexpected end of expression"}
+	}
+	return &exprError{pos: tok.pos, msg: fmt.Sprintf("unexpected %q", tok.text)}
+}
+
+// binary parses a left associative chain of the given operators
+func (p *parser) binary(operand func() (*node, error), ops ...string) (*node, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tok := p.peek()
+		if tok.kind != tokenOp && tok.kind != tokenIdent || !contains(ops, tok.text) {
+			return left, nil
+		}
+		p.next()
+
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = &node{kind: nodeBinary, pos: tok.pos, op: tok.text, args: []*node{left, right}}
+	}
+}
+
+func (p *parser) or() (*node, error) {
+	return p.binary(p.and, "or")
+}
+
+func (p *parser) and() (*node, error) {
+	return p.binary(p.not, "and")
+}
+
+func (p *parser) not() (*node, error) {
+	if tok := p.peek(); tok.kind == tokenIdent && tok.text == "not" {
+		p.next()
+		operand, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return &node{kind: nodeUnary, pos: tok.pos, op: "not", args: []*node{operand}}, nil
+	}
+	return p.comparison()
+}
+
+// comparison does not chain, a < b < c is an error
+func (p *parser) comparison() (*node, error) {
+	left, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+
+	tok := p.peek()
+	if !contains(comparisons, tok.text) {
+		return left, nil
+	}
+	p.next()
+
+	right, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+	return &node{kind: nodeBinary, pos: tok.pos, op: tok.text, args: []*node{left, right}}, nil
+}
+
+func (p *parser) sum() (*node, error) {
+	return p.binary(p.product, "+", "-")
+}
+
+func (p *parser) product() (*node, error) {
+	return p.binary(p.unary, "*", "/")
+}
+
+func (p *parser) unary() (*node, error) {
+	if tok := p.peek(); tok.kind == tokenOp && tok.text == "-" {
+		p.next()
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &node{kind: nodeUnary, pos: tok.pos, op: "-", args: []*node{operand}}, nil
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() (*node, error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokenNumber:
+		value, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, &exprError{pos: tok.pos, msg: fmt.Sprintf("invalid number %q", tok.text)}
+		}
+		return &node{kind: nodeNumber, pos: tok.pos, num: value}, nil
+
+	case tokenIdent:
+		if contains(keywords, tok.text) {
+			return nil, p.unexpected(tok)
+		}
+		if p.peek().text != "(" {
+			return &node{kind: nodeIdent, pos: tok.pos, op: tok.text}, nil
+		}
+		p.next()
+
+		call := &node{kind: nodeCall, pos: tok.pos, op: tok.text}
+		if p.peek().text == ")" {
+			p.next()
+			return call, nil
+		}
+		for {
+			arg, err := p.or()
+			if err != nil {
+				return nil, err
+			}
+			call.args = append(call.args, arg)
+
+			switch sep := p.next(); sep.text {
+			case ",":
+				continue
+			case ")":
+				return call, nil
+			default:
+				return nil, p.unexpected(sep)
+			}
+		}
+
+	case tokenOp:
+		if tok.text == "(" {
+			inner, err := p.or()
+			if err != nil {
+				return nil, err
+			}
+			if closing := p.next(); closing.text != ")" {
+				return nil, p.unexpected(closing)
+			}
+			return inner, nil
+		}
+	}
+
+	return nil, p.unexpected(tok)
+}
+
+var (
+	comparisons = []string{"<", "<=", ">", ">=", "==", "!=", "crosses_above", "crosses_below"}
+	keywords    = []string{"and", "or", "not", "crosses_above", "crosses_below"}
+)
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,232 @@
+// Package rules compiles declarative trading rules into strategies. a rule
+// set is a YAML or JSON document:
+//
+//	name: Trend follower
+//	side: long
+//	entry: sma(close, 10) crosses_above sma(close, 30) and rsi(14) < 70
+//	exit: sma(close, 10) crosses_below sma(close, 30)
+//	stop_loss: 5%
+//	take_profit: 15%
+//
+// expressions combine the bar fields open, high, low, close and volume,
+// numbers, + - * /, comparisons, crosses_above, crosses_below, and, or, not
+// and the indicators sma, ema, rsi, highest, lowest, stddev, roc, prev, atr
+// and abs. the source series of an indicator defaults to close.
+package rules
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/wreckitral/distributed-backtesting-platform/internal/strategy"
+	"go.yaml.in/yaml/v3"
+)
+
+// Error is a problem in one field of a rule set. Line is the line of the
+// field in the document and Column the 1-based column in its expression,
+// both are zero when they don't apply
+type Error struct {
+	Field  string
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *Error) Error() string {
+	var where strings.Builder
+	where.WriteString(e.Field)
+	if e.Line > 0 {
+		fmt.Fprintf(&where, " (line %d)", e.Line)
+	}
+	if e.Column > 0 {
+		fmt.Fprintf(&where, " column %d", e.Column)
+	}
+	return where.String() + ": " + e.Msg
+}
+
+// Spec is a parsed rule set
+type Spec struct {
+	Name       string
+	Short      bool
+	Entry      string
+	Exit       string
+	StopLoss   float64 // fraction of the entry price, zero disables it
+	TakeProfit float64 // fraction of the entry price, zero disables it
+}
+
+// Strategy trades a compiled rule set. it enters on the entry condition when
+// flat and leaves on the exit condition, the stop loss or the take profit,
+// the last two are checked against the close
+type Strategy struct {
+	spec  Spec
+	entry condition
+	exit  condition
+	envs  map[string]*env
+}
+
+// Compile parses and type checks a rule set, errors point at the field and
+// the column of the expression at fault
+func Compile(source string) (*Strategy, error) {
+	spec, lines, err := parseSpec(source)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Strategy{spec: spec, envs: make(map[string]*env)}
+
+	if s.entry, err = compileField("entry", spec.Entry, lines["entry"]); err != nil {
+		return nil, err
+	}
+	if spec.Exit != "" {
+		if s.exit, err = compileField("exit", spec.Exit, lines["exit"]); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+func compileField(field, expr string, line int) (condition, error) {
+	n, err := parse(expr)
+	if err == nil {
+		var c condition
+		if c, err = compileCondition(n); err == nil {
+			return c, nil
+		}
+	}
+
+	var exprErr *exprError
+	if errors.As(err, &exprErr) {
+		return nil, &Error{Field: field, Line: line, Column: exprErr.pos, Msg: exprErr.msg}
+	}
+	return nil, &Error{Field: field, Line: line, Msg: err.Error()}
+}
+
+// parseSpec decodes the document and returns the line of every field
+func parseSpec(source string) (Spec, map[string]int, error) {
+	var spec Spec
+	lines := make(map[string]int)
+
+	var doc yaml.Node
+	if err := yaml.NewDecoder(bytes.NewBufferString(source)).Decode(&doc); err != nil {
+		return spec, nil, fmt.Errorf("invalid rule document: %w", err)
+	}
+	if len(doc.Content) != 1 || doc.Content[0].Kind != yaml.MappingNode {
+		return spec, nil, fmt.Errorf("invalid rule document: expected a mapping of fields")
+	}
+
+	mapping := doc.Content[0]
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key, value := mapping.Content[i], mapping.Content[i+1]
+		field := key.Value
+		lines[field] = key.Line
+
+		if value.Kind != yaml.ScalarNode {
+			return spec, nil, &Error{Field: field, Line: key.Line, Msg: "expected a single value"}
+		}
+
+		var err error
+		switch field {
+		case "name":
+			spec.Name = value.Value
+		case "side":
+			switch strings.ToLower(value.Value) {
+			case "long":
+			case "short":
+				spec.Short = true
+			default:
+				err = fmt.Errorf("expected long or short, got %q", value.Value)
+			}
+		case "entry":
+			spec.Entry = value.Value
+		case "exit":
+			spec.Exit = value.Value
+		case "stop_loss":
+			spec.StopLoss, err = parsePercent(value.Value)
+		case "take_profit":
+			spec.TakeProfit, err = parsePercent(value.Value)
+		default:
+			err = fmt.Errorf("unknown field, expected name, side, entry, exit, stop_loss or take_profit")
+		}
+		if err != nil {
+			return spec, nil, &Error{Field: field, Line: key.Line, Msg: err.Error()}
+		}
+	}
+
+	if strings.TrimSpace(spec.Entry) == "" {
+		return spec, nil, &Error{Field: "entry", Msg: "an entry condition is required"}
+	}
+
+	return spec, lines, nil
+}
+
+// parsePercent reads 5% or 5 as 0.05
+func parsePercent(s string) (float64, error) {
+	value, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "%")), 64)
+	if err != nil {
+		return 0, fmt.Errorf("expected a percentage like 5%%, got %q", s)
+	}
+	if value <= 0 || value >= 100 {
+		return 0, fmt.Errorf("must be between 0%% and 100%%, got %v%%", value)
+	}
+	return value / 100, nil
+}
+
+func (s *Strategy) Spec() Spec {
+	return s.spec
+}
+
+func (s *Strategy) Name() string {
+	if s.spec.Name != "" {
+		return s.spec.Name
+	}
+	return "Rules"
+}
+
+func (s *Strategy) Generate(ctx *strategy.Context) (strategy.Signal, error) {
+	// the history is shared with the executor, copying it every bar would
+	// make a run quadratic in its length
+	bars := ctx.History(ctx.Symbol)
+	e := s.envs[ctx.Symbol]
+	if e == nil || !e.extends(bars) {
+		e = newEnv()
+		s.envs[ctx.Symbol] = e
+	}
+	e.bars, e.ctx = bars, ctx
+	i := len(bars) - 1
+
+	enter, leave := strategy.SignalBuy, strategy.SignalSell
+	inPosition := ctx.IsLong()
+	if s.spec.Short {
+		enter, leave = strategy.SignalSell, strategy.SignalBuy
+		inPosition = ctx.IsShort()
+	}
+
+	if !inPosition {
+		if s.entry.test(e, i) {
+			return enter, nil
+		}
+		return strategy.SignalHold, nil
+	}
+
+	if entryPrice := ctx.CurrentPosition.EntryPrice; entryPrice > 0 {
+		gain := ctx.CurrentBar.Close/entryPrice - 1
+		if s.spec.Short {
+			gain = -gain
+		}
+		if s.spec.StopLoss > 0 && gain <= -s.spec.StopLoss {
+			return leave, nil
+		}
+		if s.spec.TakeProfit > 0 && gain >= s.spec.TakeProfit {
+			return leave, nil
+		}
+	}
+
+	if s.exit != nil && s.exit.test(e, i) {
+		return leave, nil
+	}
+	return strategy.SignalHold, nil
+}
@@ -0,0 +1,423 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/marketdata"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/strategy"
+)
+
+func barsFromCloses(closes ...float64) []domain.Bar {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	bars := make([]domain.Bar, len(closes))
+	for i, c := range closes {
+		bars[i] = domain.Bar{
+			Symbol:    "TEST",
+			Timestamp: start.AddDate(0, 0, i),
+			Open:      c,
+			High:      c + 1,
+			Low:       c - 1,
+			Close:     c,
+			Volume:    1000,
+		}
+	}
+	return bars
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		field  string
+		line   int
+		column int
+		msg    string
+	}{
+		{
+			name:   "unknown function",
+			source: "entry: smaa(close, 10) > close",
+			field:  "entry", line: 1, column: 1,
+			msg: `unknown function "smaa"`,
+		},
+		{
+			name:   "unknown name",
+			source: "entry: sma(clsoe, 10) > close",
+			field:  "entry", line: 1, column: 5,
+			msg: `unknown name "clsoe"`,
+		},
+		{
+			name:   "unclosed call",
+			source: "entry: close > 1\nexit: sma(close, 10 close) < close",
+			field:  "exit", line: 2, column: 15,
+			msg: `unexpected "close"`,
+		},
+		{
+			name:   "trailing tokens",
+			source: "entry: close > open open",
+			field:  "entry", line: 1, column: 14,
+			msg: `unexpected "open"`,
+		},
+		{
+			name:   "number instead of condition",
+			source: "entry: close > open and volume",
+			field:  "entry", line: 1, column: 18,
+			msg: "expected a condition, got a number",
+		},
+		{
+			name:   "condition instead of number",
+			source: "entry: (close > open) + 1 > 2",
+			field:  "entry", line: 1, column: 8,
+			msg: "expected a number, got a condition",
+		},
+		{
+			name:   "period expression",
+			source: "entry: rsi(close, 7 * 2) < 30",
+			field:  "entry", line: 1, column: 14,
+			msg: "period of rsi must be a whole number",
+		},
+		{
+			name:   "argument count",
+			source: "entry: atr(close, 14) > 2",
+			field:  "entry", line: 1, column: 1,
+			msg: "atr takes 1 arguments, got 2",
+		},
+		{
+			name:   "bad character",
+			source: "entry: close > open & volume > 0",
+			field:  "entry", line: 1, column: 14,
+			msg: `unexpected character '&'`,
+		},
+		{
+			name:   "unknown field",
+			source: "entry: close > open\nstoploss: 5%",
+			field:  "stoploss", line: 2,
+			msg: "unknown field",
+		},
+		{
+			name:   "bad percentage",
+			source: "entry: close > open\nstop_loss: 150%",
+			field:  "stop_loss", line: 2,
+			msg: "must be between 0% and 100%",
+		},
+		{
+			name:   "missing entry",
+			source: "exit: close < open",
+			field:  "entry",
+			msg:    "an entry condition is required",
+		},
+		{
+			name:   "json",
+			source: "{\n  \"entry\": \"close >\"\n}",
+			field:  "entry", line: 2, column: 8,
+			msg: "unexpected end of expression",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.source)
+
+			var ruleErr *Error
+			if !errors.As(err, &ruleErr) {
+				t.Fatalf("Expected a rule error, got %v", err)
+			}
+			if ruleErr.Field != tt.field || ruleErr.Line != tt.line || ruleErr.Column != tt.column {
+				t.Errorf("Expected %s line %d column %d, got %s line %d column %d (%v)",
+					tt.field, tt.line, tt.column, ruleErr.Field, ruleErr.Line, ruleErr.Column, err)
+			}
+			if !strings.Contains(ruleErr.Msg, tt.msg) {
+				t.Errorf("Expected message to contain %q, got %q", tt.msg, ruleErr.Msg)
+			}
+		})
+	}
+
+	if _, err := Compile("entry: [1, 2"); err == nil {
+		t.Error("Expected an error for invalid YAML")
+	}
+}
+
+func TestIndicators(t *testing.T) {
+	bars := barsFromCloses(10, 11, 12, 11, 13, 14, 13, 15)
+
+	tests := []struct {
+		expr string
+		at   int
+		want float64
+	}{
+		{"sma(close, 3)", 7, 14},
+		{"sma(3)", 1, math.NaN()},
+		// seeded with sma(10, 11, 12) = 11, then alpha 0.5
+		{"ema(close, 3)", 2, 11},
+		{"ema(close, 3)", 3, 11},
+		{"ema(close, 3)", 4, 12},
+		{"highest(high, 4)", 7, 16},
+		{"lowest(low, 4)", 7, 12},
+		{"prev(close, 2)", 7, 14},
+		{"roc(close, 1)", 1, 10},
+		{"stddev(close, 2)", 1, 0.5},
+		{"atr(2)", 2, 2},
+		{"abs(open - close - 3)", 0, 3},
+		{"-close / 2 + 1", 0, -4},
+		{"close / (open - close)", 0, math.NaN()},
+		// gains 1 1 0 2 1 0 2, losses 0 0 1 0 0 1 0 over 4 bars: 4/4 and 1/4
+		{"rsi(4)", 4, 80},
+		// wilder: gain (1*3 + 1)/4 = 1, loss (0.25*3 + 0)/4 = 0.1875
+		{"rsi(close, 4)", 5, 100 - 100/(1+1/0.1875)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			n, err := parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Failed to parse: %v", err)
+			}
+			s, err := compileSeries(n)
+			if err != nil {
+				t.Fatalf("Failed to compile: %v", err)
+			}
+
+			e := newEnv()
+			e.bars = bars
+			got := s.at(e, tt.at)
+			if math.IsNaN(tt.want) {
+				if !math.IsNaN(got) {
+					t.Errorf("Expected NaN, got %v", got)
+				}
+				return
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestConditions(t *testing.T) {
+	bars := barsFromCloses(10, 9, 8, 12, 13)
+
+	tests := []struct {
+		expr string
+		want []bool
+	}{
+		{"close crosses_above sma(close, 2)", []bool{false, false, false, true, false}},
+		{"close crosses_below 9.5", []bool{false, true, false, false, false}},
+		{"close > 9 and close < 13", []bool{true, false, false, true, false}},
+		{"close < 9 or close >= 13", []bool{false, false, true, false, true}},
+		{"not close == 10", []bool{false, true, true, true, true}},
+		{"sma(close, 3) != 0", []bool{false, false, true, true, true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			n, err := parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Failed to parse: %v", err)
+			}
+			c, err := compileCondition(n)
+			if err != nil {
+				t.Fatalf("Failed to compile: %v", err)
+			}
+
+			e := newEnv()
+			e.bars = bars
+			for i, want := range tt.want {
+				if got := c.test(e, i); got != want {
+					t.Errorf("Bar %d: expected %v, got %v", i, want, got)
+				}
+			}
+		})
+	}
+}
+
+// run feeds bars one at a time, filling entries at the close of the signal
+// bar so the test can follow the position
+func run(t *testing.T, s *Strategy, bars []domain.Bar, short bool) []strategy.Signal {
+	t.Helper()
+
+	var position *strategy.Position
+	signals := make([]strategy.Signal, len(bars))
+	for i := range bars {
+		ctx := &strategy.Context{
+			Symbol:          "TEST",
+			Timestamp:       bars[i].Timestamp,
+			CurrentBar:      bars[i],
+			HistoricalBars:  bars[:i],
+			CurrentPosition: position,
+		}
+
+		signal, err := s.Generate(ctx)
+		if err != nil {
+			t.Fatalf("Generate failed on bar %d: %v", i, err)
+		}
+		signals[i] = signal
+
+		shares := 1.0
+		if short {
+			shares = -1
+		}
+		switch {
+		case position == nil && signal != strategy.SignalHold:
+			position = &strategy.Position{Symbol: "TEST", Shares: shares, EntryPrice: bars[i].Close}
+		case position != nil && signal != strategy.SignalHold:
+			position = nil
+		}
+	}
+	return signals
+}
+
+func TestStrategy(t *testing.T) {
+	hold, buy, sell := strategy.SignalHold, strategy.SignalBuy, strategy.SignalSell
+
+	tests := []struct {
+		name   string
+		source string
+		closes []float64
+		want   []strategy.Signal
+	}{
+		{
+			name:   "entry and exit",
+			source: "entry: close crosses_above 10\nexit: close crosses_below 10",
+			closes: []float64{9, 11, 12, 9, 11},
+			want:   []strategy.Signal{hold, buy, hold, sell, buy},
+		},
+		{
+			name:   "stop loss",
+			source: "entry: close > 0\nstop_loss: 5%",
+			closes: []float64{100, 97, 94, 100},
+			want:   []strategy.Signal{buy, hold, sell, buy},
+		},
+		{
+			name:   "take profit",
+			source: `{"entry": "close > 0", "take_profit": 10}`,
+			closes: []float64{100, 109, 111},
+			want:   []strategy.Signal{buy, hold, sell},
+		},
+		{
+			name:   "short side",
+			source: "side: short\nentry: close < prev(close, 1)\nstop_loss: 5%",
+			closes: []float64{100, 99, 98, 104},
+			want:   []strategy.Signal{hold, sell, hold, buy},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Compile(tt.source)
+			if err != nil {
+				t.Fatalf("Failed to compile: %v", err)
+			}
+
+			got := run(t, s, barsFromCloses(tt.closes...), s.Spec().Short)
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("Bar %d: expected %s, got %s", i, tt.want[i], got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestStrategyRestatedHistory(t *testing.T) {
+	s, err := Compile("entry: ema(close, 2) > 50")
+	if err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+
+	bars := barsFromCloses(100, 100, 100)
+	run(t, s, bars, false)
+
+	// a 1:4 split restates the history, the memoized ema must not survive it
+	restated := barsFromCloses(25, 25, 25, 25)
+	ctx := &strategy.Context{Symbol: "TEST", CurrentBar: restated[3], HistoricalBars: restated[:3]}
+	signal, err := s.Generate(ctx)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if signal != strategy.SignalHold {
+		t.Errorf("Expected HOLD on the restated history, got %s", signal)
+	}
+}
+
+// compareStreamed evaluates expressions at every bar of an executor run
+// through the context indicators and through the windowed evaluation
+type compareStreamed struct {
+	t      *testing.T
+	exprs  map[string]series
+	envs   map[string]*env
+	checks int
+}
+
+func (c *compareStreamed) Name() string { return "compare" }
+
+func (c *compareStreamed) Generate(ctx *strategy.Context) (strategy.Signal, error) {
+	bars := ctx.History(ctx.Symbol)
+	last := len(bars) - 1
+	for expr, s := range c.exprs {
+		e := c.envs[expr]
+		e.bars, e.ctx = bars, ctx
+		windowed := newEnv()
+		windowed.bars = bars
+
+		for _, i := range []int{last - 1, last} {
+			got, want := s.at(e, i), s.at(windowed, i)
+			if math.IsNaN(got) != math.IsNaN(want) || math.Abs(got-want) > 1e-9 {
+				c.t.Errorf("%s at bar %d: streamed %v, windowed %v", expr, i, got, want)
+			}
+			c.checks++
+		}
+	}
+	return strategy.SignalHold, nil
+}
+
+func TestStreamedIndicators(t *testing.T) {
+	provider, err := marketdata.NewCSVProvider("../../data/sample")
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	compare := &compareStreamed{t: t, exprs: make(map[string]series), envs: make(map[string]*env)}
+	for _, expr := range []string{"sma(5)", "ema(close, 5)", "rsi(14)", "stddev(close, 10)", "highest(high, 7)", "lowest(low, 7)", "atr(14)"} {
+		n, err := parse(expr)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", expr, err)
+		}
+		s, err := compileSeries(n)
+		if err != nil {
+			t.Fatalf("Failed to compile %s: %v", expr, err)
+		}
+		if _, ok := s.(*streamed); !ok {
+			t.Fatalf("Expected %s to read a streaming indicator", expr)
+		}
+		compare.exprs[expr] = s
+		compare.envs[expr] = newEnv()
+	}
+
+	executor := strategy.NewExecutor(compare, provider, 10000)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := executor.Run(context.Background(), "AAPL", start, end); err != nil {
+		t.Fatalf("Executor failed: %v", err)
+	}
+	if compare.checks == 0 {
+		t.Fatal("Expected the strategy to run")
+	}
+
+	// other sources keep the windowed evaluation
+	n, err := parse("sma(open, 5)")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	s, err := compileSeries(n)
+	if err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+	if _, ok := s.(*streamed); ok {
+		t.Error("Expected sma(open, 5) to be evaluated over its window")
+	}
+}
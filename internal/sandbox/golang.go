@@ -0,0 +1,253 @@
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/traefik/yaegi/interp"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/strategy"
+)
+
+// GoConfig controls how Go strategies are run
+type GoConfig struct {
+	CallTimeout time.Duration // limit per Generate call, zero disables it
+}
+
+// goFactory is the constructor every Go strategy must declare
+type goFactory = func(params map[string]any) (strategy.Strategy, error)
+
+// GoStrategy runs user Go code in an embedded interpreter. the code is a
+// single package that imports only allow-listed packages, starts no
+// goroutines or time callbacks and declares
+//
+//	func New(params map[string]any) (strategy.Strategy, error)
+type GoStrategy struct {
+	strategy.Strategy
+
+	interp  *interp.Interpreter
+	timeout time.Duration
+	stopped error // set once a call timed out, the interpreter is not used again
+}
+
+// maximum number of tries to interrupt a call that timed out
+const maxInterruptAttempts = 20
+
+// NewGoStrategy interprets code and builds the strategy it declares with params
+func NewGoStrategy(config GoConfig, code string, params map[string]any) (*GoStrategy, error) {
+	file, err := parseGo(code)
+	if err != nil {
+		return nil, err
+	}
+
+	i := interp.New(interp.Options{})
+	if err := i.Use(goSymbols()); err != nil {
+		return nil, fmt.Errorf("failed to prepare go interpreter: %w", err)
+	}
+
+	s := &GoStrategy{interp: i, timeout: config.CallTimeout}
+
+	built, err := guard(s, func() (strategy.Strategy, error) {
+		if _, err := i.Eval(code); err != nil {
+			return nil, fmt.Errorf("failed to compile go strategy: %w", err)
+		}
+
+		value, err := i.Eval(file.Name.Name + ".New")
+		if err != nil {
+			return nil, fmt.Errorf("go strategy must declare func New(params map[string]any) (strategy.Strategy, error)")
+		}
+		factory, ok := value.Interface().(goFactory)
+		if !ok {
+			return nil, fmt.Errorf("go strategy New has type %s, want func(map[string]any) (strategy.Strategy, error)", value.Type())
+		}
+
+		if params == nil {
+			params = map[string]any{}
+		}
+		built, err := factory(params)
+		if err != nil {
+			return nil, err
+		}
+		if built == nil {
+			return nil, fmt.Errorf("go strategy New returned a nil strategy")
+		}
+		return built, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.Strategy = built
+	return s, nil
+}
+
+// CheckGo parses code and checks its imports and statements without running
+// it, for validating a strategy when it is submitted
+func CheckGo(code string) error {
+	_, err := parseGo(code)
+	return err
+}
+
+// parseGo parses code and rejects what the guard cannot contain: imports
+// outside the allow-list, goroutines, whose panics would crash the process,
+// and time callbacks, which run on goroutines of their own
+func parseGo(code string) (*ast.File, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "strategy.go", code, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse go strategy: %w", err)
+	}
+	if err := checkImports(file.Imports); err != nil {
+		return nil, err
+	}
+
+	// the name the file refers to the time package by
+	timeName := ""
+	for _, spec := range file.Imports {
+		if spec.Path.Value == `"time"` {
+			timeName = "time"
+			if spec.Name != nil {
+				timeName = spec.Name.Name
+			}
+		}
+	}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		if err != nil {
+			return false
+		}
+		switch n := n.(type) {
+		case *ast.GoStmt:
+			err = fmt.Errorf("%s: go statements are not allowed in go strategies", fset.Position(n.Pos()))
+		case *ast.SelectorExpr:
+			pkg, ok := n.X.(*ast.Ident)
+			if ok && pkg.Name == timeName && pkg.Obj == nil && slices.Contains(timeCallbacks, n.Sel.Name) {
+				err = fmt.Errorf("%s: time.%s is not allowed in go strategies", fset.Position(n.Pos()), n.Sel.Name)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// Name is guarded like Generate, a strategy that fails to name itself gets
+// a generic name
+func (s *GoStrategy) Name() string {
+	if s.stopped != nil {
+		return "Go strategy"
+	}
+
+	name, err := guard(s, func() (string, error) {
+		return s.Strategy.Name(), nil
+	})
+	if err != nil {
+		return "Go strategy"
+	}
+	return name
+}
+
+func (s *GoStrategy) Generate(ctx *strategy.Context) (strategy.Signal, error) {
+	if s.stopped != nil {
+		return strategy.SignalHold, s.stopped
+	}
+
+	return guard(s, func() (strategy.Signal, error) {
+		return s.Strategy.Generate(ctx)
+	})
+}
+
+// guarded is the outcome of a guarded call
+type guarded[T any] struct {
+	value T
+	err   error
+}
+
+// guard runs interpreted code on its own goroutine so a panic becomes an
+// error and a call running past the timeout can be stopped. the result only
+// travels over the channel, a call that timed out is interrupted and the
+// strategy is marked stopped
+func guard[T any](s *GoStrategy, run func() (T, error)) (T, error) {
+	done := make(chan guarded[T], 1)
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		defer func() {
+			if r := recover(); r != nil {
+				done <- guarded[T]{err: fmt.Errorf("go strategy panicked: %v", r)}
+			}
+		}()
+		value, err := run()
+		done <- guarded[T]{value: value, err: err}
+	}()
+
+	var timeout <-chan time.Time
+	if s.timeout > 0 {
+		timer := time.NewTimer(s.timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case result := <-done:
+		return result.value, result.err
+	case <-timeout:
+		s.stopped = fmt.Errorf("go strategy stopped after timing out")
+		if !s.interrupt(exited) {
+			s.stopped = fmt.Errorf("go strategy stopped after timing out, its call could not be interrupted")
+		}
+		var zero T
+		return zero, fmt.Errorf("go strategy timed out after %s", s.timeout)
+	}
+}
+
+// interrupt stops every frame running in the interpreter and reports whether
+// the goroutine of the call exited. yaegi only stops frames when the context
+// of an EvalWithContext is done before the evaluation finishes, so a trivial
+// evaluation is retried with a cancelled context, backing off between tries,
+// until the call is gone or it gives up. a call blocked in a native function
+// cannot be stopped
+func (s *GoStrategy) interrupt(exited <-chan struct{}) bool {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	backoff := time.Millisecond
+	for range maxInterruptAttempts {
+		_, _ = s.interp.EvalWithContext(ctx, "0")
+
+		select {
+		case <-exited:
+			return true
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, 50*time.Millisecond)
+	}
+	return false
+}
+
+// checkImports rejects any import outside the allow-list
+func checkImports(imports []*ast.ImportSpec) error {
+	allowed := make(map[string]bool, len(goImports))
+	for _, pkg := range goImports {
+		allowed[pkg] = true
+	}
+
+	for _, spec := range imports {
+		pkg, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return fmt.Errorf("invalid import %s", spec.Path.Value)
+		}
+		if !allowed[pkg] {
+			return fmt.Errorf("import %q is not allowed in go strategies", pkg)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,324 @@
+package sandbox
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/strategy"
+)
+
+const goCrossover = `package momentum
+
+import (
+	"fmt"
+
+	"github.com/wreckitral/distributed-backtesting-platform/internal/strategy"
+)
+
+type momentum struct {
+	shares float64
+	exit   int
+}
+
+func New(params map[string]any) (strategy.Strategy, error) {
+	shares, _ := params["shares"].(float64)
+	if shares <= 0 {
+		return nil, fmt.Errorf("shares must be positive")
+	}
+	return &momentum{shares: shares, exit: 3}, nil
+}
+
+func (m *momentum) Name() string {
+	return "Momentum"
+}
+
+func (m *momentum) Generate(ctx *strategy.Context) (strategy.Signal, error) {
+	if !ctx.HasPosition() && ctx.BarCount() == 1 {
+		ctx.SetQuantity(m.shares)
+		return strategy.SignalBuy, nil
+	}
+	if ctx.HasPosition() && ctx.BarCount() == m.exit {
+		return strategy.SignalSell, nil
+	}
+	return strategy.SignalHold, nil
+}
+`
+
+func TestGoStrategySignals(t *testing.T) {
+	strat, err := NewGoStrategy(GoConfig{CallTimeout: 5 * time.Second}, goCrossover, map[string]any{"shares": 10.0})
+	if err != nil {
+		t.Fatalf("Failed to load strategy: %v", err)
+	}
+
+	if strat.Name() != "Momentum" {
+		t.Errorf("Expected name Momentum, got %s", strat.Name())
+	}
+
+	trades, err := runBacktest(strat)
+	if err != nil {
+		t.Fatalf("Backtest failed: %v", err)
+	}
+
+	if len(trades) != 2 {
+		t.Fatalf("Expected 2 trades, got %d", len(trades))
+	}
+	if trades[0].Quantity != 10 || trades[0].Price != 102 {
+		t.Errorf("Expected buy of 10 at 102, got %.0f at %.2f", trades[0].Quantity, trades[0].Price)
+	}
+	if trades[1].Direction != domain.TradeDirectionSell || trades[1].Price != 106 {
+		t.Errorf("Expected sell at 106, got direction %d at %.2f", trades[1].Direction, trades[1].Price)
+	}
+}
+
+func TestGoStrategyOrders(t *testing.T) {
+	code := `package orders
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/strategy"
+)
+
+type orders struct {
+	limit uuid.UUID
+}
+
+func New(params map[string]any) (strategy.Strategy, error) {
+	return &orders{}, nil
+}
+
+func (o *orders) Name() string { return "Orders" }
+
+func (o *orders) Generate(ctx *strategy.Context) (strategy.Signal, error) {
+	switch ctx.BarCount() {
+	case 1:
+		id, err := ctx.SubmitOrder(strategy.NewLimitOrder(strategy.OrderSideBuy, 5, 50, strategy.TimeInForceGTC))
+		if err != nil {
+			return strategy.SignalHold, err
+		}
+		o.limit = id
+	case 2:
+		pending := ctx.PendingOrders()
+		if len(pending) != 1 || pending[0].ID != o.limit {
+			return strategy.SignalHold, errors.New("limit order not pending")
+		}
+		ctx.CancelOrder(o.limit)
+		ctx.SubmitOrder(strategy.NewMarketOrder(strategy.OrderSideBuy, 3))
+	}
+	return strategy.SignalHold, nil
+}
+`
+
+	strat, err := NewGoStrategy(GoConfig{}, code, nil)
+	if err != nil {
+		t.Fatalf("Failed to load strategy: %v", err)
+	}
+
+	trades, err := runBacktest(strat)
+	if err != nil {
+		t.Fatalf("Backtest failed: %v", err)
+	}
+
+	if len(trades) != 1 {
+		t.Fatalf("Expected 1 trade, got %d", len(trades))
+	}
+	if trades[0].Quantity != 3 || trades[0].Price != 104 {
+		t.Errorf("Expected buy of 3 at 104, got %.0f at %.2f", trades[0].Quantity, trades[0].Price)
+	}
+}
+
+func TestGoStrategyErrors(t *testing.T) {
+	// wraps a Generate body in a complete strategy
+	generate := func(imports, body string) string {
+		return `package s
+
+import (
+	"github.com/wreckitral/distributed-backtesting-platform/internal/strategy"
+	` + imports + `
+)
+
+type s struct{}
+
+func New(params map[string]any) (strategy.Strategy, error) { return &s{}, nil }
+
+func (*s) Name() string { return "S" }
+
+func (*s) Generate(ctx *strategy.Context) (strategy.Signal, error) {
+	` + body + `
+}
+`
+	}
+
+	tests := []struct {
+		name   string
+		code   string
+		params map[string]any
+		want   string
+	}{
+		{
+			name: "forbidden import",
+			code: generate(`"os"`, `os.Remove("x"); return strategy.SignalHold, nil`),
+			want: `import "os" is not allowed`,
+		},
+		{
+			name: "forbidden unsafe",
+			code: generate(`"unsafe"`, `_ = unsafe.Sizeof(0); return strategy.SignalHold, nil`),
+			want: `import "unsafe" is not allowed`,
+		},
+		{
+			name: "compile error",
+			code: generate("", `return strategy.SignalBuy`),
+			want: "failed to compile go strategy",
+		},
+		{
+			name: "missing constructor",
+			code: "package s\n\nfunc Helper() int { return 1 }\n",
+			want: "must declare func New",
+		},
+		{
+			name: "wrong constructor",
+			code: "package s\n\nfunc New() int { return 1 }\n",
+			want: "go strategy New has type",
+		},
+		{
+			name:   "constructor error",
+			code:   goCrossover,
+			params: map[string]any{"shares": -1.0},
+			want:   "shares must be positive",
+		},
+		{
+			name: "panic",
+			code: generate("", `var bars []float64; return strategy.Signal(bars[ctx.BarCount()]), nil`),
+			want: "go strategy panicked",
+		},
+		{
+			name: "panicking goroutine",
+			code: generate("", `go func() { panic("boom") }(); return strategy.SignalHold, nil`),
+			want: "go statements are not allowed",
+		},
+		{
+			name: "time callback",
+			code: generate(`clock "time"`, `clock.AfterFunc(0, func() { panic("boom") }); return strategy.SignalHold, nil`),
+			want: "time.AfterFunc is not allowed",
+		},
+		{
+			name: "time callback without a qualifier",
+			code: generate(`. "time"`, `AfterFunc(0, func() { panic("boom") }); return strategy.SignalHold, nil`),
+			want: "failed to compile go strategy",
+		},
+		{
+			name: "timeout",
+			code: generate("", `for { }`),
+			want: "go strategy timed out after 200ms",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strat, err := NewGoStrategy(GoConfig{CallTimeout: 200 * time.Millisecond}, tt.code, tt.params)
+			if err == nil {
+				_, err = runBacktest(strat)
+			}
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error to contain %q, got: %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestGoStrategyStopsAfterTimeout(t *testing.T) {
+	code := `package s
+
+import "github.com/wreckitral/distributed-backtesting-platform/internal/strategy"
+
+type s struct{}
+
+func New(params map[string]any) (strategy.Strategy, error) { return &s{}, nil }
+
+func (*s) Name() string { return "S" }
+
+func (*s) Generate(ctx *strategy.Context) (strategy.Signal, error) {
+	for {
+	}
+}
+`
+	strat, err := NewGoStrategy(GoConfig{CallTimeout: 100 * time.Millisecond}, code, nil)
+	if err != nil {
+		t.Fatalf("Failed to load strategy: %v", err)
+	}
+
+	if _, err := strat.Generate(&strategy.Context{}); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("Expected a timeout, got %v", err)
+	}
+
+	start := time.Now()
+	_, err = strat.Generate(&strategy.Context{})
+	if err == nil || !strings.Contains(err.Error(), "stopped") {
+		t.Errorf("Expected the strategy to refuse calls after a timeout, got %v", err)
+	}
+	if err != nil && strings.Contains(err.Error(), "could not be interrupted") {
+		t.Errorf("Expected the looping call to be interrupted, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("Expected the stopped strategy to fail right away, took %s", elapsed)
+	}
+}
+
+func TestCheckGo(t *testing.T) {
+	if err := CheckGo(goCrossover); err != nil {
+		t.Errorf("Expected the crossover to pass, got %v", err)
+	}
+
+	// a local variable named time is not the package
+	local := "package s\n\nimport \"time\"\n\nvar _ = time.Now\n\nfunc f() {\n\ttime := struct{ NewTimer int }{}\n\t_ = time.NewTimer\n}\n"
+	if err := CheckGo(local); err != nil {
+		t.Errorf("Expected a local time variable to pass, got %v", err)
+	}
+
+	tests := map[string]string{
+		"package s\n\nfunc New( {":                                 "failed to parse go strategy",
+		"package s\n\nimport \"net/http\"\n\nvar _ = http.Get\n":   `import "net/http" is not allowed`,
+		"package s\n\nfunc f() {\n\tgo f()\n}\n":                   "strategy.go:4:2: go statements are not allowed",
+		"package s\n\nimport \"time\"\n\nvar _ = time.NewTicker\n": "time.NewTicker is not allowed",
+	}
+	for code, want := range tests {
+		if err := CheckGo(code); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected an error containing %q, got %v", want, err)
+		}
+	}
+}
+
+func TestLoader(t *testing.T) {
+	loader := NewLoader(PythonConfig{Path: "python3"}, GoConfig{}, WasmConfig{})
+
+	strat, err := loader.Load(&domain.Strategy{Name: "momentum", Language: domain.StrategyLanguageGo, Code: goCrossover}, map[string]any{"shares": 1.0})
+	if err != nil {
+		t.Fatalf("Failed to load go strategy: %v", err)
+	}
+	if _, ok := strat.(*GoStrategy); !ok {
+		t.Errorf("Expected a GoStrategy, got %T", strat)
+	}
+
+	rules := &domain.Strategy{Name: "rules", Language: domain.StrategyLanguageRules, Code: "entry: close > sma(close, 3)"}
+	if _, err := loader.Load(rules, nil); err != nil {
+		t.Errorf("Failed to load rule strategy: %v", err)
+	}
+	if _, err := loader.Load(rules, map[string]any{"period": 3.0}); err == nil {
+		t.Error("Expected an error for parameters on a rule strategy")
+	}
+
+	python := &domain.Strategy{Name: "python", Language: domain.StrategyLanguagePython, Code: "def on_bar(ctx): pass"}
+	if _, err := loader.Load(python, nil); err == nil || !strings.Contains(err.Error(), "no jail") {
+		t.Errorf("Expected python to be refused without a jail, got %v", err)
+	}
+
+	if _, err := loader.Load(&domain.Strategy{Name: "bad", Language: domain.StrategyLanguage(99)}, nil); err == nil {
+		t.Error("Expected an error for a language without a runtime")
+	}
+}
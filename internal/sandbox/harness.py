@@ -0,0 +1,222 @@
+# harness that runs a user strategy for the backtest executor. it reads one
+# JSON message per line on stdin and answers each with one JSON line on the
+# original stdout. user code printing goes to stderr so it can't corrupt the
+# protocol.
+#
+# a strategy defines on_bar(ctx) returning "BUY", "SELL", "HOLD" or None and
+# optionally initialize(ctx), called once before the first bar.
+
+import json
+import sys
+import traceback
+import uuid
+
+protocol = sys.stdout
+sys.stdout = sys.stderr
+
+
+def limit_memory(limit):
+    if limit <= 0:
+        return
+    try:
+        import resource
+
+        resource.setrlimit(resource.RLIMIT_AS, (limit, limit))
+    except (ImportError, ValueError, OSError) as exc:
+        print("memory limit not applied: %s" % exc, file=sys.stderr)
+
+
+def limit_jail():
+    # inside a jail the harness runs as its own user, so it can take away
+    # forking and writing files for good before any user code is loaded
+    import resource
+
+    resource.setrlimit(resource.RLIMIT_NPROC, (0, 0))
+    resource.setrlimit(resource.RLIMIT_FSIZE, (0, 0))
+
+
+class Bar(object):
+    __slots__ = ("timestamp", "open", "high", "low", "close", "volume")
+
+    def __init__(self, data):
+        self.timestamp = data["timestamp"]
+        self.open = data["open"]
+        self.high = data["high"]
+        self.low = data["low"]
+        self.close = data["close"]
+        self.volume = data["volume"]
+
+    def __repr__(self):
+        return "Bar(%s o=%s h=%s l=%s c=%s v=%s)" % (
+            self.timestamp, self.open, self.high, self.low, self.close, self.volume)
+
+
+class Position(object):
+    __slots__ = ("symbol", "shares", "entry_price")
+
+    def __init__(self, data):
+        self.symbol = data["symbol"]
+        self.shares = data["shares"]
+        self.entry_price = data["entry_price"]
+
+    @property
+    def is_long(self):
+        return self.shares > 0
+
+    @property
+    def is_short(self):
+        return self.shares < 0
+
+
+class Context(object):
+    def __init__(self, params):
+        self.params = params
+        self.state = {}
+        self.histories = {}
+        self.symbol = None
+        self.timestamp = None
+        self.bar = None
+        self.position = None
+        self.positions = {}
+        self.bars = {}
+        self.universe = []
+        self.cash = 0.0
+        self.equity = 0.0
+        self.fills = []
+        self.cancellations = []
+        self.pending_orders = []
+        self._orders = []
+        self._cancels = []
+        self._quantity = None
+
+    @property
+    def history(self):
+        return self.histories.get(self.symbol, [])
+
+    def history_of(self, symbol):
+        return self.histories.get(symbol, [])
+
+    def update(self, msg):
+        symbol = msg["symbol"]
+        if "history" in msg:
+            self.histories[symbol] = [Bar(b) for b in msg["history"]]
+        self.histories.setdefault(symbol, []).append(Bar(msg["bar"]))
+
+        self.symbol = symbol
+        self.timestamp = msg["timestamp"]
+        self.bar = self.histories[symbol][-1]
+        self.positions = dict((s, Position(p)) for s, p in (msg.get("positions") or {}).items())
+        self.position = self.positions.get(symbol)
+        self.bars = dict((s, Bar(b)) for s, b in (msg.get("bars") or {}).items())
+        self.universe = msg.get("universe") or []
+        self.cash = msg["cash"]
+        self.equity = msg["equity"]
+        self.fills = msg.get("fills") or []
+        self.cancellations = msg.get("cancellations") or []
+        self.pending_orders = msg.get("pending_orders") or []
+        self._orders = []
+        self._cancels = []
+        self._quantity = None
+
+    def set_quantity(self, shares):
+        self._quantity = max(float(shares), 0.0)
+
+    def submit_order(self, side, quantity=0, type="MARKET", limit_price=0, stop_price=0,
+                     trail_amount=0, trail_percent=0, time_in_force="DAY", symbol=None):
+        order_id = str(uuid.uuid4())
+        self._orders.append({
+            "id": order_id,
+            "symbol": symbol or self.symbol,
+            "side": side.upper(),
+            "type": type.upper(),
+            "quantity": float(quantity),
+            "limit_price": float(limit_price),
+            "stop_price": float(stop_price),
+            "trail_amount": float(trail_amount),
+            "trail_percent": float(trail_percent),
+            "time_in_force": time_in_force.upper(),
+        })
+        return order_id
+
+    def buy(self, quantity=0, **kwargs):
+        return self.submit_order("BUY", quantity, **kwargs)
+
+    def sell(self, quantity=0, **kwargs):
+        return self.submit_order("SELL", quantity, **kwargs)
+
+    def cancel_order(self, order_id):
+        self._cancels.append(order_id)
+
+
+def send(msg):
+    protocol.write(json.dumps(msg) + "\n")
+    protocol.flush()
+
+
+def send_error(exc):
+    send({
+        "type": "error",
+        "error": "%s: %s" % (type(exc).__name__, exc),
+        "traceback": traceback.format_exc(),
+    })
+
+
+def main():
+    limit = 0
+    if len(sys.argv) > 1:
+        limit = int(sys.argv[1])
+    limit_memory(limit)
+    if len(sys.argv) > 2 and sys.argv[2] == "true":
+        limit_jail()
+
+    namespace = {"__name__": "strategy"}
+    ctx = None
+
+    for line in sys.stdin:
+        try:
+            msg = json.loads(line)
+        except ValueError as exc:
+            send_error(exc)
+            continue
+
+        kind = msg.get("type")
+        if kind == "shutdown":
+            break
+
+        try:
+            if kind == "init":
+                code = compile(msg["code"], "<strategy>", "exec")
+                exec(code, namespace)
+                if not callable(namespace.get("on_bar")):
+                    raise NameError("strategy must define on_bar(ctx)")
+                ctx = Context(msg.get("params") or {})
+                if callable(namespace.get("initialize")):
+                    namespace["initialize"](ctx)
+                send({"type": "ready"})
+
+            elif kind == "bar":
+                if ctx is None:
+                    raise RuntimeError("bar received before init")
+                ctx.update(msg)
+                signal = namespace["on_bar"](ctx)
+                if signal is None:
+                    signal = "HOLD"
+                send({
+                    "type": "result",
+                    "signal": str(signal).upper(),
+                    "quantity": ctx._quantity,
+                    "orders": ctx._orders,
+                    "cancels": ctx._cancels,
+                })
+
+            else:
+                raise ValueError("unknown message type %r" % kind)
+
+        except MemoryError as exc:
+            send_error(MemoryError("strategy exceeded its memory limit"))
+        except BaseException as exc:
+            send_error(exc)
+
+
+if __name__ == "__main__":
+    main()
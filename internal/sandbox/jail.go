@@ -0,0 +1,14 @@
+package sandbox
+
+// Jail isolates an interpreter process from the server. the process runs in
+// new network, mount, PID, IPC and UTS namespaces, so it has no network
+// interfaces besides a loopback that is down and sees no other process. it
+// is chrooted into Root and runs as UID and GID, which must not be root.
+// Root has to hold the interpreter and its libraries and must not be
+// writable by that user, the harness also sets a zero file size limit.
+// jails need the server to run as root and are only supported on linux
+type Jail struct {
+	Root string
+	UID  int
+	GID  int
+}
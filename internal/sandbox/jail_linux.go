@@ -0,0 +1,43 @@
+//go:build linux
+
+package sandbox
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// apply makes cmd start inside the jail
+func (j *Jail) apply(cmd *exec.Cmd) error {
+	if j.UID <= 0 || j.GID <= 0 {
+		return fmt.Errorf("jail needs an unprivileged uid and gid, got %d:%d", j.UID, j.GID)
+	}
+	if os.Geteuid() != 0 {
+		return fmt.Errorf("jails need the server to run as root")
+	}
+
+	info, err := os.Stat(j.Root)
+	if err != nil {
+		return fmt.Errorf("invalid jail root: %w", err)
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !info.IsDir() || !ok || stat.Uid != 0 || info.Mode().Perm()&0o022 != 0 {
+		return fmt.Errorf("jail root %s must be a directory owned by root and writable only by it", j.Root)
+	}
+
+	cmd.Dir = "/"
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Chroot: j.Root,
+		Credential: &syscall.Credential{
+			Uid:    uint32(j.UID),
+			Gid:    uint32(j.GID),
+			Groups: []uint32{},
+		},
+		Cloneflags: syscall.CLONE_NEWNET | syscall.CLONE_NEWNS | syscall.CLONE_NEWPID |
+			syscall.CLONE_NEWIPC | syscall.CLONE_NEWUTS,
+		Pdeathsig: syscall.SIGKILL,
+	}
+	return nil
+}
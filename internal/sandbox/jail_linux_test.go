@@ -0,0 +1,74 @@
+//go:build linux
+
+package sandbox
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// jailProbe fails to load when anything the jail takes away is reachable
+const jailProbe = `
+import os
+import socket
+
+def initialize(ctx):
+    if os.getuid() != 65534 or os.getgid() != 65534:
+        raise RuntimeError("running as %d:%d" % (os.getuid(), os.getgid()))
+    if os.getpid() != 1:
+        raise RuntimeError("host pid namespace, pid %d" % os.getpid())
+    try:
+        socket.create_connection(("1.1.1.1", 53), timeout=1)
+        raise RuntimeError("network reachable")
+    except OSError:
+        pass
+    try:
+        with open("/tmp/jail-probe", "w") as f:
+            f.write("x")
+            f.flush()
+        raise RuntimeError("file written")
+    except OSError:
+        pass
+    try:
+        pid = os.fork()
+        if pid == 0:
+            os._exit(0)
+        raise RuntimeError("forked")
+    except OSError:
+        pass
+    if "DB_PASSWORD" in os.environ:
+        raise RuntimeError("server environment leaked")
+
+def on_bar(ctx):
+    return "HOLD"
+`
+
+func TestPythonJail(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("jails need root")
+	}
+	if _, err := os.Stat("/usr/bin/python3"); err != nil {
+		t.Skip("no system python3")
+	}
+	t.Setenv("DB_PASSWORD", "secret")
+	// the root of the test jail has a writable /tmp, files can be created but stay empty
+	t.Cleanup(func() { os.Remove("/tmp/jail-probe") })
+
+	config := PythonConfig{
+		Path:        "/usr/bin/python3",
+		CallTimeout: 5 * time.Second,
+		Jail:        &Jail{Root: "/", UID: 65534, GID: 65534},
+	}
+	strat, err := NewPythonStrategy(config, "probe", jailProbe, nil)
+	if err != nil {
+		t.Fatalf("Jailed strategy failed to load: %v", err)
+	}
+	strat.Close()
+
+	config.Jail = &Jail{Root: "/", UID: 0, GID: 0}
+	if _, err := NewPythonStrategy(config, "probe", jailProbe, nil); err == nil || !strings.Contains(err.Error(), "unprivileged") {
+		t.Errorf("Expected a root jail user to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package sandbox
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// apply fails, namespaces are linux only
+func (j *Jail) apply(cmd *exec.Cmd) error {
+	return fmt.Errorf("jails are only supported on linux")
+}
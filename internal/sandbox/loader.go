@@ -0,0 +1,63 @@
+// Package sandbox runs uploaded strategy code. WebAssembly and rule strategies
+// cannot reach anything outside the values passed in, Go strategies are
+// limited to an import allow-list and Python strategies run with the full
+// standard library inside a Jail
+package sandbox
+
+import (
+	"fmt"
+
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/rules"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/strategy"
+)
+
+// Loader builds runnable strategies from user code stored with a strategy
+type Loader struct {
+	python PythonConfig
+	golang GoConfig
+	wasm   WasmConfig
+}
+
+func NewLoader(python PythonConfig, golang GoConfig, wasm WasmConfig) *Loader {
+	return &Loader{python: python, golang: golang, wasm: wasm}
+}
+
+// Load starts the runtime for the strategy language. the caller must Close
+// the returned strategy when it implements io.Closer
+func (l *Loader) Load(s *domain.Strategy, params map[string]any) (strategy.Strategy, error) {
+	switch s.Language {
+	case domain.StrategyLanguagePython:
+		return NewPythonStrategy(l.python, s.Name, s.Code, params)
+	case domain.StrategyLanguageGo:
+		return NewGoStrategy(l.golang, s.Code, params)
+	case domain.StrategyLanguageWasm:
+		return NewWasmStrategy(l.wasm, s.Name, s.Code, params)
+	case domain.StrategyLanguageRules:
+		if len(params) > 0 {
+			return nil, fmt.Errorf("rule strategies take no parameters")
+		}
+		return rules.Compile(s.Code)
+	default:
+		return nil, fmt.Errorf("no runtime for %s strategies", s.Language)
+	}
+}
+
+// Check compiles the strategy code without starting a runtime so broken or
+// forbidden code is refused when it is submitted rather than when it runs
+func Check(s *domain.Strategy) error {
+	switch s.Language {
+	case domain.StrategyLanguagePython:
+		return nil
+	case domain.StrategyLanguageGo:
+		return CheckGo(s.Code)
+	case domain.StrategyLanguageWasm:
+		_, err := DecodeWasm(s.Code)
+		return err
+	case domain.StrategyLanguageRules:
+		_, err := rules.Compile(s.Code)
+		return err
+	default:
+		return fmt.Errorf("no runtime for %s strategies", s.Language)
+	}
+}
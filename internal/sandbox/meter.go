@@ -0,0 +1,525 @@
+package sandbox
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// fuelExport is the name a metered module exports its fuel counter under
+const fuelExport = "__strategy_fuel"
+
+// order of the known sections in a module, custom sections go anywhere
+var sectionRank = map[byte]int{1: 1, 2: 2, 3: 3, 4: 4, 5: 5, 13: 6, 6: 7, 7: 8, 8: 9, 9: 10, 12: 11, 10: 12, 11: 13}
+
+type wasmSection struct {
+	id      byte
+	content []byte
+}
+
+// meter instruments a module with an i64 fuel counter exported as fuelExport.
+// every function entry and loop header charges the instructions up to the
+// next of them and traps once the counter drops below zero, so a call runs
+// at most roughly as many instructions as the counter held when it started.
+// modules touching the counter themselves and debug sections, whose code
+// offsets the instrumentation moves, are rejected and dropped respectively
+func meter(wasm []byte) ([]byte, error) {
+	sections, err := readSections(wasm)
+	if err != nil {
+		return nil, err
+	}
+
+	var imported, defined uint32
+	for _, s := range sections {
+		switch s.id {
+		case 2:
+			if imported, err = importedGlobals(s.content); err != nil {
+				return nil, fmt.Errorf("invalid import section: %w", err)
+			}
+		case 6:
+			if defined, err = (&wasmReader{data: s.content}).u32(); err != nil {
+				return nil, fmt.Errorf("invalid global section: %w", err)
+			}
+		}
+	}
+	fuel := imported + defined
+
+	// i64, mutable, initialized to zero
+	global := []byte{0x7e, 0x01, 0x42, 0x00, opcodeEnd}
+	export := append(wasmName(fuelExport), 0x03)
+	export = append(export, uleb32(fuel)...)
+
+	var out []wasmSection
+	addedGlobal, addedExport := false, false
+	for _, s := range sections {
+		if s.id == 0 {
+			if name, _ := (&wasmReader{data: s.content}).name(); strings.HasPrefix(name, ".debug") {
+				continue
+			}
+			out = append(out, s)
+			continue
+		}
+
+		if !addedGlobal && sectionRank[s.id] > sectionRank[6] {
+			out = append(out, wasmSection{id: 6, content: append([]byte{1}, global...)})
+			addedGlobal = true
+		}
+		if !addedExport && sectionRank[s.id] > sectionRank[7] {
+			out = append(out, wasmSection{id: 7, content: append([]byte{1}, export...)})
+			addedExport = true
+		}
+
+		switch s.id {
+		case 6:
+			s.content = appendEntry(s.content, global)
+			addedGlobal = true
+		case 7:
+			s.content = appendEntry(s.content, export)
+			addedExport = true
+		case 10:
+			if s.content, err = meterCode(s.content, fuel); err != nil {
+				return nil, err
+			}
+		}
+		out = append(out, s)
+	}
+	if !addedGlobal {
+		out = append(out, wasmSection{id: 6, content: append([]byte{1}, global...)})
+	}
+	if !addedExport {
+		out = append(out, wasmSection{id: 7, content: append([]byte{1}, export...)})
+	}
+
+	metered := append([]byte{}, wasm[:8]...)
+	for _, s := range out {
+		metered = append(metered, s.id)
+		metered = append(metered, uleb32(uint32(len(s.content)))...)
+		metered = append(metered, s.content...)
+	}
+	return metered, nil
+}
+
+func readSections(wasm []byte) ([]wasmSection, error) {
+	if len(wasm) < 8 || !bytes.HasPrefix(wasm, wasmMagic) {
+		return nil, fmt.Errorf("not a wasm module")
+	}
+
+	r := &wasmReader{data: wasm, pos: 8}
+	var sections []wasmSection
+	for r.pos < len(r.data) {
+		id, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		size, err := r.u32()
+		if err != nil {
+			return nil, err
+		}
+		content, err := r.bytes(int(size))
+		if err != nil {
+			return nil, fmt.Errorf("section %d: %w", id, err)
+		}
+		sections = append(sections, wasmSection{id: id, content: content})
+	}
+	return sections, nil
+}
+
+// importedGlobals counts the globals of an import section, they come before
+// the defined ones in the global index space
+func importedGlobals(content []byte) (uint32, error) {
+	r := &wasmReader{data: content}
+	count, err := r.u32()
+	if err != nil {
+		return 0, err
+	}
+
+	var globals uint32
+	for range count {
+		if _, err := r.name(); err != nil {
+			return 0, err
+		}
+		if _, err := r.name(); err != nil {
+			return 0, err
+		}
+		kind, err := r.byte()
+		if err != nil {
+			return 0, err
+		}
+
+		switch kind {
+		case 0x00: // func: type index
+			_, err = r.u32()
+		case 0x01: // table: reference type and limits
+			if _, err = r.byte(); err == nil {
+				err = r.limits()
+			}
+		case 0x02: // memory: limits
+			err = r.limits()
+		case 0x03: // global: value type and mutability
+			globals++
+			_, err = r.bytes(2)
+		case 0x04: // tag: attribute and type index
+			if _, err = r.byte(); err == nil {
+				_, err = r.u32()
+			}
+		default:
+			err = fmt.Errorf("unknown import kind %d", kind)
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	return globals, nil
+}
+
+// appendEntry adds an entry to a vector section
+func appendEntry(content, entry []byte) []byte {
+	r := &wasmReader{data: content}
+	count, _ := r.u32()
+	out := uleb32(count + 1)
+	out = append(out, content[r.pos:]...)
+	return append(out, entry...)
+}
+
+func meterCode(content []byte, fuel uint32) ([]byte, error) {
+	r := &wasmReader{data: content}
+	count, err := r.u32()
+	if err != nil {
+		return nil, fmt.Errorf("invalid code section: %w", err)
+	}
+
+	out := uleb32(count)
+	for i := range count {
+		size, err := r.u32()
+		if err != nil {
+			return nil, fmt.Errorf("invalid code section: %w", err)
+		}
+		body, err := r.bytes(int(size))
+		if err != nil {
+			return nil, fmt.Errorf("invalid code section: %w", err)
+		}
+
+		metered, err := meterBody(body, fuel)
+		if err != nil {
+			return nil, fmt.Errorf("cannot meter function %d: %w", i, err)
+		}
+		out = append(out, uleb32(uint32(len(metered)))...)
+		out = append(out, metered...)
+	}
+	return out, nil
+}
+
+// meterPoint is where fuel is charged and the instructions it pays for
+type meterPoint struct {
+	at   int
+	cost int64
+}
+
+func meterBody(body []byte, fuel uint32) ([]byte, error) {
+	r := &wasmReader{data: body}
+
+	locals, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+	for range locals {
+		if _, err := r.u32(); err != nil {
+			return nil, err
+		}
+		if _, err := r.byte(); err != nil {
+			return nil, err
+		}
+	}
+
+	points := []meterPoint{{at: r.pos}}
+	for r.pos < len(body) {
+		op, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		points[len(points)-1].cost++
+
+		if op == opcodeGlobalGet || op == opcodeGlobalSet {
+			index, err := r.u32()
+			if err != nil {
+				return nil, err
+			}
+			if index == fuel {
+				return nil, fmt.Errorf("global %d is reserved for fuel", index)
+			}
+			continue
+		}
+		if err := r.immediates(op); err != nil {
+			return nil, err
+		}
+		if op == opcodeLoop {
+			points = append(points, meterPoint{at: r.pos})
+		}
+	}
+
+	out := append([]byte{}, body[:points[0].at]...)
+	prev := points[0].at
+	for _, p := range points {
+		out = append(out, body[prev:p.at]...)
+		out = append(out, charge(fuel, p.cost)...)
+		prev = p.at
+	}
+	return append(out, body[prev:]...), nil
+}
+
+// charge subtracts cost from the fuel global and traps when it went negative
+func charge(fuel uint32, cost int64) []byte {
+	index := uleb32(fuel)
+
+	code := append([]byte{opcodeGlobalGet}, index...)
+	code = append(code, 0x42)
+	code = append(code, sleb64(cost)...)
+	code = append(code, 0x7d, opcodeGlobalSet) // i64.sub
+	code = append(code, index...)
+	code = append(code, opcodeGlobalGet)
+	code = append(code, index...)
+	// i64.const 0, i64.lt_s, if, unreachable, end
+	return append(code, 0x42, 0x00, 0x53, 0x04, 0x40, 0x00, opcodeEnd)
+}
+
+const (
+	opcodeLoop      = 0x03
+	opcodeEnd       = 0x0b
+	opcodeGlobalGet = 0x23
+	opcodeGlobalSet = 0x24
+)
+
+// wasmReader decodes the binary format
+type wasmReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *wasmReader) byte() (byte, error) {
+	if r.pos >= len(r.data) {
+		return 0, fmt.Errorf("unexpected end of module")
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b, nil
+}
+
+func (r *wasmReader) bytes(n int) ([]byte, error) {
+	if n < 0 || r.pos+n > len(r.data) {
+		return nil, fmt.Errorf("unexpected end of module")
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
+
+func (r *wasmReader) u32() (uint32, error) {
+	var v uint32
+	for shift := 0; shift < 35; shift += 7 {
+		b, err := r.byte()
+		if err != nil {
+			return 0, err
+		}
+		v |= uint32(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return v, nil
+		}
+	}
+	return 0, fmt.Errorf("integer too long")
+}
+
+// leb skips a signed or unsigned integer of up to 64 bits
+func (r *wasmReader) leb() error {
+	for range 10 {
+		b, err := r.byte()
+		if err != nil {
+			return err
+		}
+		if b&0x80 == 0 {
+			return nil
+		}
+	}
+	return fmt.Errorf("integer too long")
+}
+
+func (r *wasmReader) name() (string, error) {
+	size, err := r.u32()
+	if err != nil {
+		return "", err
+	}
+	b, err := r.bytes(int(size))
+	return string(b), err
+}
+
+func (r *wasmReader) limits() error {
+	flags, err := r.byte()
+	if err != nil {
+		return err
+	}
+	if err := r.leb(); err != nil {
+		return err
+	}
+	if flags&0x01 != 0 {
+		return r.leb()
+	}
+	return nil
+}
+
+// immediates skips the immediates of the instruction op
+func (r *wasmReader) immediates(op byte) error {
+	switch {
+	case op == 0x02 || op == 0x03 || op == 0x04: // block, loop, if
+		b, err := r.byte()
+		if err != nil || b&0x80 == 0 {
+			return err
+		}
+		return r.leb() // type index
+	case op == 0x0c || op == 0x0d || op == 0x10 || op == 0x12 || op == 0xd2,
+		op >= 0x20 && op <= 0x26:
+		return r.leb() // label, function, local, global or table index
+	case op == 0x0e: // br_table
+		count, err := r.u32()
+		if err != nil {
+			return err
+		}
+		for range count + 1 {
+			if err := r.leb(); err != nil {
+				return err
+			}
+		}
+		return nil
+	case op == 0x11 || op == 0x13: // call_indirect: type and table index
+		if err := r.leb(); err != nil {
+			return err
+		}
+		return r.leb()
+	case op == 0x1c: // typed select
+		count, err := r.u32()
+		if err != nil {
+			return err
+		}
+		_, err = r.bytes(int(count))
+		return err
+	case op >= 0x28 && op <= 0x3e:
+		return r.memarg()
+	case op == 0x3f || op == 0x40 || op == 0xd0: // memory index or reference type
+		_, err := r.byte()
+		return err
+	case op == 0x41 || op == 0x42:
+		return r.leb()
+	case op == 0x43:
+		_, err := r.bytes(4)
+		return err
+	case op == 0x44:
+		_, err := r.bytes(8)
+		return err
+	case op == 0xfc:
+		return r.miscImmediates()
+	case op == 0xfd:
+		return r.vectorImmediates()
+	case op <= 0x01, op == 0x05, op == 0x0b, op == 0x0f, op == 0x1a, op == 0x1b,
+		op >= 0x45 && op <= 0xc4, op == 0xd1:
+		return nil
+	default:
+		return fmt.Errorf("unsupported instruction 0x%02x", op)
+	}
+}
+
+func (r *wasmReader) memarg() error {
+	align, err := r.u32()
+	if err != nil {
+		return err
+	}
+	if align&0x40 != 0 { // memory index follows with multiple memories
+		if err := r.leb(); err != nil {
+			return err
+		}
+	}
+	return r.leb()
+}
+
+func (r *wasmReader) miscImmediates() error {
+	sub, err := r.u32()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case sub <= 7: // saturating truncations
+		return nil
+	case sub == 8: // memory.init: data index and memory
+		if err := r.leb(); err != nil {
+			return err
+		}
+		_, err := r.byte()
+		return err
+	case sub == 9 || sub == 13 || sub == 15 || sub == 16 || sub == 17: // one index
+		return r.leb()
+	case sub == 10 || sub == 12 || sub == 14: // two indices
+		if err := r.leb(); err != nil {
+			return err
+		}
+		return r.leb()
+	case sub == 11: // memory.fill
+		_, err := r.byte()
+		return err
+	default:
+		return fmt.Errorf("unsupported instruction 0xfc %d", sub)
+	}
+}
+
+func (r *wasmReader) vectorImmediates() error {
+	sub, err := r.u32()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case sub <= 11 || sub == 92 || sub == 93: // loads and stores
+		return r.memarg()
+	case sub == 12 || sub == 13: // v128.const, i8x16.shuffle
+		_, err := r.bytes(16)
+		return err
+	case sub >= 21 && sub <= 34: // lane extract and replace
+		_, err := r.byte()
+		return err
+	case sub >= 84 && sub <= 91: // lane loads and stores
+		if err := r.memarg(); err != nil {
+			return err
+		}
+		_, err := r.byte()
+		return err
+	case sub <= 255:
+		return nil
+	default:
+		return fmt.Errorf("unsupported instruction 0xfd %d", sub)
+	}
+}
+
+func uleb32(v uint32) []byte {
+	var out []byte
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if v == 0 {
+			return append(out, b)
+		}
+		out = append(out, b|0x80)
+	}
+}
+
+func sleb64(v int64) []byte {
+	var out []byte
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if v == 0 && b&0x40 == 0 || v == -1 && b&0x40 != 0 {
+			return append(out, b)
+		}
+		out = append(out, b|0x80)
+	}
+}
+
+func wasmName(s string) []byte {
+	return append(uleb32(uint32(len(s))), s...)
+}
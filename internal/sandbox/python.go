@@ -0,0 +1,504 @@
+package sandbox
+
+import (
+	"bufio"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/strategy"
+)
+
+//go:embed harness.py
+var harness string
+
+// largest protocol line read back from the interpreter
+const maxMessageSize = 16 << 20
+
+// PythonConfig controls how Python strategies are run. the interpreter runs
+// arbitrary user code with the full standard library, so it only gets what
+// the process around it allows: without a jail that is everything the server
+// user can reach
+type PythonConfig struct {
+	Path        string        // interpreter binary, inside the jail root when jailed
+	CallTimeout time.Duration // limit per init or bar call, zero disables it
+	MemoryLimit int64         // address space limit in bytes, zero disables it
+	Jail        *Jail         // namespaces and user the interpreter runs in, see Jail
+	Unjailed    bool          // allows running without a jail, for local development only
+}
+
+// pythonEnv is the whole environment of the interpreter, the one of the
+// server holds the database credentials
+var pythonEnv = []string{"PATH=/usr/local/bin:/usr/bin:/bin", "LANG=C.UTF-8"}
+
+// PythonError is an exception raised by the user code, with its traceback
+type PythonError struct {
+	Message   string
+	Traceback string
+}
+
+func (e *PythonError) Error() string {
+	if e.Traceback == "" {
+		return e.Message
+	}
+	return e.Message + "\n" + strings.TrimRight(e.Traceback, "\n")
+}
+
+// PythonStrategy runs user code in a Python process that lives for the whole
+// backtest. every bar is streamed to the bundled harness as one JSON line and
+// the signal and orders come back the same way
+type PythonStrategy struct {
+	name    string
+	timeout time.Duration
+
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	lines  chan []byte
+	read   chan struct{} // closed once stdout is drained
+	stderr *tailBuffer
+
+	sent     map[string]domain.Bar // last bar streamed per symbol
+	once     sync.Once
+	waitOnce sync.Once
+}
+
+// NewPythonStrategy starts the interpreter and loads code, which must define
+// on_bar(ctx). params are exposed to the code as ctx.params
+func NewPythonStrategy(config PythonConfig, name, code string, params map[string]any) (*PythonStrategy, error) {
+	// -I keeps the interpreter from reading PYTHON* variables and user site packages
+	cmd := exec.Command(config.Path, "-I", "-c", harness,
+		strconv.FormatInt(config.MemoryLimit, 10), strconv.FormatBool(config.Jail != nil))
+	cmd.Env = pythonEnv
+
+	switch {
+	case config.Jail != nil:
+		if err := config.Jail.apply(cmd); err != nil {
+			return nil, fmt.Errorf("failed to jail python: %w", err)
+		}
+	case !config.Unjailed:
+		return nil, fmt.Errorf("python strategies are disabled, no jail is configured for the interpreter")
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open python stdin: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open python stdout: %w", err)
+	}
+	stderr := &tailBuffer{limit: 8 << 10}
+	cmd.Stderr = stderr
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start python at %s: %w", config.Path, err)
+	}
+
+	s := &PythonStrategy{
+		name:    name,
+		timeout: config.CallTimeout,
+		cmd:     cmd,
+		stdin:   stdin,
+		lines:   make(chan []byte),
+		read:    make(chan struct{}),
+		stderr:  stderr,
+		sent:    make(map[string]domain.Bar),
+	}
+	go s.readLines(stdout)
+
+	if params == nil {
+		params = map[string]any{}
+	}
+	if _, err := s.call(map[string]any{"type": "init", "code": code, "params": params}, "ready"); err != nil {
+		s.Close()
+		return nil, fmt.Errorf("failed to load python strategy: %w", err)
+	}
+
+	return s, nil
+}
+
+func (s *PythonStrategy) Name() string {
+	return s.name
+}
+
+func (s *PythonStrategy) Generate(ctx *strategy.Context) (strategy.Signal, error) {
+	reply, err := s.call(s.barMessage(ctx), "result")
+	if err != nil {
+		return strategy.SignalHold, err
+	}
+
+	if reply.Quantity != nil {
+		ctx.SetQuantity(*reply.Quantity)
+	}
+
+	for _, wire := range reply.Orders {
+		order, err := wire.order()
+		if err != nil {
+			return strategy.SignalHold, err
+		}
+		if _, err := ctx.SubmitOrder(order); err != nil {
+			return strategy.SignalHold, fmt.Errorf("invalid order from python strategy: %w", err)
+		}
+	}
+
+	for _, id := range reply.Cancels {
+		parsed, err := uuid.Parse(id)
+		if err != nil {
+			return strategy.SignalHold, fmt.Errorf("invalid order id %q from python strategy", id)
+		}
+		ctx.CancelOrder(parsed)
+	}
+
+	switch reply.Signal {
+	case "BUY":
+		return strategy.SignalBuy, nil
+	case "SELL":
+		return strategy.SignalSell, nil
+	case "HOLD", "":
+		return strategy.SignalHold, nil
+	default:
+		return strategy.SignalHold, fmt.Errorf("unknown signal %q from python strategy", reply.Signal)
+	}
+}
+
+// Close stops the interpreter, it is safe to call more than once
+func (s *PythonStrategy) Close() error {
+	s.once.Do(func() {
+		// unblock the reader if a reply is still in flight
+		go s.discardLines()
+
+		// the shutdown write blocks too when the harness stopped reading
+		done := make(chan struct{})
+		go func() {
+			fmt.Fprintln(s.stdin, `{"type":"shutdown"}`)
+			s.stdin.Close()
+			s.wait()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			s.cmd.Process.Kill()
+			<-done
+		}
+	})
+	return nil
+}
+
+// readLines forwards every line the harness writes until it exits
+func (s *PythonStrategy) readLines(stdout io.Reader) {
+	defer close(s.read)
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64<<10), maxMessageSize)
+
+	for scanner.Scan() {
+		line := make([]byte, len(scanner.Bytes()))
+		copy(line, scanner.Bytes())
+		s.lines <- line
+	}
+	close(s.lines)
+}
+
+// discardLines drops the lines nobody waits for so the reader reaches the
+// end of stdout
+func (s *PythonStrategy) discardLines() {
+	for range s.lines {
+	}
+}
+
+// wait reaps the process once stdout is drained, os/exec closes the pipe in
+// Wait and reads still in flight would lose output. a descendant holding
+// stdout open only delays it by a second
+func (s *PythonStrategy) wait() {
+	s.waitOnce.Do(func() {
+		select {
+		case <-s.read:
+		case <-time.After(time.Second):
+		}
+		s.cmd.Wait()
+	})
+}
+
+// call sends one message and waits for the reply of the expected type. the
+// process is killed when it takes longer than the call timeout
+func (s *PythonStrategy) call(msg map[string]any, expect string) (*pythonReply, error) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode message for python: %w", err)
+	}
+
+	// the timer covers the write, a harness that stops reading stdin blocks it
+	var timeout <-chan time.Time
+	if s.timeout > 0 {
+		timer := time.NewTimer(s.timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	written := make(chan error, 1)
+	go func() {
+		_, err := s.stdin.Write(append(data, '\n'))
+		written <- err
+	}()
+
+	select {
+	case err := <-written:
+		if err != nil {
+			return nil, s.exited()
+		}
+	case <-timeout:
+		return nil, s.timedOut()
+	}
+
+	select {
+	case line, ok := <-s.lines:
+		if !ok {
+			return nil, s.exited()
+		}
+
+		reply := &pythonReply{}
+		if err := json.Unmarshal(line, reply); err != nil {
+			return nil, fmt.Errorf("invalid reply from python: %w", err)
+		}
+		if reply.Type == "error" {
+			return nil, &PythonError{Message: reply.Error, Traceback: reply.Traceback}
+		}
+		if reply.Type != expect {
+			return nil, fmt.Errorf("expected %s from python, got %s", expect, reply.Type)
+		}
+		return reply, nil
+
+	case <-timeout:
+		return nil, s.timedOut()
+	}
+}
+
+// timedOut kills the harness, which also fails a write blocked on its stdin
+func (s *PythonStrategy) timedOut() error {
+	s.cmd.Process.Kill()
+	return fmt.Errorf("python strategy timed out after %s", s.timeout)
+}
+
+// exited describes a harness that stopped answering, with the tail of its stderr
+func (s *PythonStrategy) exited() error {
+	s.cmd.Process.Kill()
+	go s.discardLines()
+	s.wait()
+
+	if tail := strings.TrimSpace(s.stderr.String()); tail != "" {
+		return fmt.Errorf("python process exited: %s", tail)
+	}
+	return fmt.Errorf("python process exited: %v", s.cmd.ProcessState)
+}
+
+// barMessage encodes the context. bars are streamed one at a time and the
+// harness keeps the history, unless the history changed since the last bar
+// sent, e.g. restated for a split, in which case it is sent again in full
+func (s *PythonStrategy) barMessage(ctx *strategy.Context) map[string]any {
+	msg := map[string]any{
+		"type":           "bar",
+		"symbol":         ctx.Symbol,
+		"timestamp":      ctx.Timestamp,
+		"bar":            wireBar(ctx.CurrentBar),
+		"cash":           ctx.Cash,
+		"equity":         ctx.Equity,
+		"universe":       ctx.Universe,
+		"fills":          wireFills(ctx.Fills),
+		"cancellations":  wireOrders(ctx.Cancellations),
+		"pending_orders": wireOrders(ctx.PendingOrders()),
+	}
+
+	previous := ctx.HistoricalBars
+	last, streamed := s.sent[ctx.Symbol]
+	inSync := !streamed && len(previous) == 0 ||
+		streamed && len(previous) > 0 && previous[len(previous)-1] == last
+	if !inSync {
+		history := make([]map[string]any, len(previous))
+		for i, bar := range previous {
+			history[i] = wireBar(bar)
+		}
+		msg["history"] = history
+	}
+	s.sent[ctx.Symbol] = ctx.CurrentBar
+
+	bars := make(map[string]any, len(ctx.Bars))
+	for symbol, bar := range ctx.Bars {
+		bars[symbol] = wireBar(bar)
+	}
+	msg["bars"] = bars
+
+	positions := make(map[string]any, len(ctx.Positions))
+	for symbol, position := range ctx.Positions {
+		positions[symbol] = map[string]any{
+			"symbol":      symbol,
+			"shares":      position.Shares,
+			"entry_price": position.EntryPrice,
+		}
+	}
+	msg["positions"] = positions
+
+	return msg
+}
+
+type pythonReply struct {
+	Type      string      `json:"type"`
+	Error     string      `json:"error"`
+	Traceback string      `json:"traceback"`
+	Signal    string      `json:"signal"`
+	Quantity  *float64    `json:"quantity"`
+	Orders    []wireOrder `json:"orders"`
+	Cancels   []string    `json:"cancels"`
+}
+
+type wireOrder struct {
+	ID           string  `json:"id"`
+	Symbol       string  `json:"symbol"`
+	Side         string  `json:"side"`
+	Type         string  `json:"type"`
+	Quantity     float64 `json:"quantity"`
+	LimitPrice   float64 `json:"limit_price"`
+	StopPrice    float64 `json:"stop_price"`
+	TrailAmount  float64 `json:"trail_amount"`
+	TrailPercent float64 `json:"trail_percent"`
+	TimeInForce  string  `json:"time_in_force"`
+	Status       string  `json:"status,omitempty"`
+	Reason       string  `json:"reason,omitempty"`
+}
+
+// order maps an order submitted by user code, keeping the id it generated so
+// fills and cancellations can be matched back
+func (w wireOrder) order() (strategy.Order, error) {
+	order := strategy.Order{
+		Symbol:       w.Symbol,
+		Quantity:     w.Quantity,
+		LimitPrice:   w.LimitPrice,
+		StopPrice:    w.StopPrice,
+		TrailAmount:  w.TrailAmount,
+		TrailPercent: w.TrailPercent,
+	}
+
+	id, err := uuid.Parse(w.ID)
+	if err != nil {
+		return order, fmt.Errorf("invalid order id %q from strategy", w.ID)
+	}
+	order.ID = id
+
+	switch w.Side {
+	case "BUY":
+		order.Side = strategy.OrderSideBuy
+	case "SELL":
+		order.Side = strategy.OrderSideSell
+	default:
+		return order, fmt.Errorf("unknown order side %q from strategy", w.Side)
+	}
+
+	switch w.Type {
+	case "MARKET", "":
+		order.Type = strategy.OrderTypeMarket
+	case "LIMIT":
+		order.Type = strategy.OrderTypeLimit
+	case "STOP":
+		order.Type = strategy.OrderTypeStop
+	case "STOP_LIMIT":
+		order.Type = strategy.OrderTypeStopLimit
+	case "TRAILING_STOP":
+		order.Type = strategy.OrderTypeTrailingStop
+	default:
+		return order, fmt.Errorf("unknown order type %q from strategy", w.Type)
+	}
+
+	switch w.TimeInForce {
+	case "DAY", "":
+		order.TimeInForce = strategy.TimeInForceDay
+	case "GTC":
+		order.TimeInForce = strategy.TimeInForceGTC
+	case "IOC":
+		order.TimeInForce = strategy.TimeInForceIOC
+	default:
+		return order, fmt.Errorf("unknown time in force %q from strategy", w.TimeInForce)
+	}
+
+	return order, nil
+}
+
+func wireBar(bar domain.Bar) map[string]any {
+	return map[string]any{
+		"timestamp": bar.Timestamp,
+		"open":      bar.Open,
+		"high":      bar.High,
+		"low":       bar.Low,
+		"close":     bar.Close,
+		"volume":    bar.Volume,
+	}
+}
+
+func wireOrders(orders []strategy.Order) []wireOrder {
+	wire := make([]wireOrder, len(orders))
+	for i, order := range orders {
+		wire[i] = wireOrder{
+			ID:           order.ID.String(),
+			Symbol:       order.Symbol,
+			Side:         order.Side.String(),
+			Type:         order.Type.String(),
+			Quantity:     order.Quantity,
+			LimitPrice:   order.LimitPrice,
+			StopPrice:    order.StopPrice,
+			TrailAmount:  order.TrailAmount,
+			TrailPercent: order.TrailPercent,
+			TimeInForce:  order.TimeInForce.String(),
+			Status:       order.Status.String(),
+			Reason:       order.Reason,
+		}
+	}
+	return wire
+}
+
+func wireFills(fills []strategy.Fill) []map[string]any {
+	wire := make([]map[string]any, len(fills))
+	for i, fill := range fills {
+		wire[i] = map[string]any{
+			"order_id":   fill.OrderID.String(),
+			"symbol":     fill.Symbol,
+			"side":       fill.Side.String(),
+			"quantity":   fill.Quantity,
+			"price":      fill.Price,
+			"commission": fill.Commission,
+			"timestamp":  fill.Timestamp,
+		}
+	}
+	return wire
+}
+
+// tailBuffer keeps the last limit bytes written to it
+type tailBuffer struct {
+	limit int
+	data  []byte
+	mu    sync.Mutex
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.data = append(b.data, p...)
+	if len(b.data) > b.limit {
+		b.data = b.data[len(b.data)-b.limit:]
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.data)
+}
@@ -0,0 +1,255 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/strategy"
+)
+
+// barProvider serves a fixed set of bars without touching the filesystem
+type barProvider struct {
+	bars []domain.Bar
+}
+
+func (p *barProvider) GetBars(ctx context.Context, symbol string, start, end time.Time) ([]domain.Bar, error) {
+	return p.bars, nil
+}
+
+func (p *barProvider) GetLatestBar(ctx context.Context, symbol string) (domain.Bar, error) {
+	return p.bars[len(p.bars)-1], nil
+}
+
+func (p *barProvider) ListSymbols(ctx context.Context) ([]string, error) {
+	return []string{"TEST"}, nil
+}
+
+// newBarProvider builds daily bars opening at the given prices and closing one higher
+func newBarProvider(opens ...float64) *barProvider {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	bars := make([]domain.Bar, len(opens))
+	for i, open := range opens {
+		bars[i] = domain.Bar{
+			Symbol:    "TEST",
+			Timestamp: start.AddDate(0, 0, i),
+			Open:      open,
+			High:      open + 2,
+			Low:       open - 1,
+			Close:     open + 1,
+			Volume:    1000,
+		}
+	}
+	return &barProvider{bars: bars}
+}
+
+func pythonConfig(t *testing.T) PythonConfig {
+	path, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 not installed")
+	}
+	return PythonConfig{Path: path, CallTimeout: 5 * time.Second, MemoryLimit: 512 << 20, Unjailed: true}
+}
+
+func runBacktest(strat strategy.Strategy) ([]domain.Trade, error) {
+	provider := newBarProvider(100, 102, 104, 106, 108)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	return strategy.NewExecutor(strat, provider, 10000.0).Run(context.Background(), "TEST", start, end)
+}
+
+func runPython(t *testing.T, config PythonConfig, code string, params map[string]any) ([]domain.Trade, error) {
+	t.Helper()
+
+	strat, err := NewPythonStrategy(config, "test", code, params)
+	if err != nil {
+		return nil, err
+	}
+	defer strat.Close()
+
+	return runBacktest(strat)
+}
+
+func TestPythonStrategySignals(t *testing.T) {
+	config := pythonConfig(t)
+
+	code := `
+def initialize(ctx):
+    ctx.state["seen"] = 0
+
+def on_bar(ctx):
+    print("noise on stdout must not break the protocol")
+    ctx.state["seen"] += 1
+    if len(ctx.history) != ctx.state["seen"]:
+        raise AssertionError("history out of sync")
+    if not ctx.position and ctx.state["seen"] == 1:
+        ctx.set_quantity(ctx.params["shares"])
+        return "BUY"
+    if ctx.position and ctx.state["seen"] == ctx.params["exit_bar"]:
+        return "sell"
+`
+
+	trades, err := runPython(t, config, code, map[string]any{"shares": 10, "exit_bar": 3})
+	if err != nil {
+		t.Fatalf("Backtest failed: %v", err)
+	}
+
+	if len(trades) != 2 {
+		t.Fatalf("Expected 2 trades, got %d", len(trades))
+	}
+	if trades[0].Quantity != 10 || trades[0].Price != 102 {
+		t.Errorf("Expected buy of 10 at 102, got %.0f at %.2f", trades[0].Quantity, trades[0].Price)
+	}
+	if trades[1].Direction != domain.TradeDirectionSell || trades[1].Price != 106 {
+		t.Errorf("Expected sell at 106, got direction %d at %.2f", trades[1].Direction, trades[1].Price)
+	}
+}
+
+func TestPythonStrategyOrders(t *testing.T) {
+	config := pythonConfig(t)
+
+	// a limit buy below the market is cancelled once it has been seen pending,
+	// then a market buy fills on the next open
+	code := `
+def on_bar(ctx):
+    n = len(ctx.history)
+    if n == 1:
+        ctx.state["limit"] = ctx.buy(5, type="limit", limit_price=50, time_in_force="gtc")
+    elif n == 2:
+        if [o["id"] for o in ctx.pending_orders] != [ctx.state["limit"]]:
+            raise AssertionError("limit order not pending: %r" % ctx.pending_orders)
+        ctx.cancel_order(ctx.state["limit"])
+        ctx.buy(3)
+    elif n == 4:
+        if len(ctx.fills) != 0 or not ctx.position or ctx.position.shares != 3:
+            raise AssertionError("expected a 3 share position")
+`
+
+	trades, err := runPython(t, config, code, nil)
+	if err != nil {
+		t.Fatalf("Backtest failed: %v", err)
+	}
+
+	if len(trades) != 1 {
+		t.Fatalf("Expected 1 trade, got %d", len(trades))
+	}
+	if trades[0].Quantity != 3 || trades[0].Price != 104 {
+		t.Errorf("Expected buy of 3 at 104, got %.0f at %.2f", trades[0].Quantity, trades[0].Price)
+	}
+}
+
+func TestPythonStrategyErrors(t *testing.T) {
+	config := pythonConfig(t)
+
+	tests := []struct {
+		name    string
+		code    string
+		timeout time.Duration
+		want    []string
+	}{
+		{
+			name: "traceback",
+			code: "def helper(bar):\n    return bar.close / 0\n\ndef on_bar(ctx):\n    return helper(ctx.bar)\n",
+			want: []string{"ZeroDivisionError", "Traceback", "line 2, in helper"},
+		},
+		{
+			name: "syntax error",
+			code: "def on_bar(ctx)\n    return 'BUY'\n",
+			want: []string{"failed to load python strategy", "SyntaxError"},
+		},
+		{
+			name: "missing on_bar",
+			code: "x = 1\n",
+			want: []string{"strategy must define on_bar(ctx)"},
+		},
+		{
+			name: "unknown signal",
+			code: "def on_bar(ctx):\n    return 'MAYBE'\n",
+			want: []string{`unknown signal "MAYBE"`},
+		},
+		{
+			name:    "timeout",
+			code:    "def on_bar(ctx):\n    while True:\n        pass\n",
+			timeout: 200 * time.Millisecond,
+			want:    []string{"timed out after 200ms"},
+		},
+		{
+			name: "memory limit",
+			code: "def on_bar(ctx):\n    ctx.state['big'] = bytearray(1 << 30)\n",
+			want: []string{"MemoryError"},
+		},
+		{
+			name: "process exit",
+			code: "import os\n\ndef on_bar(ctx):\n    print('bye')\n    os._exit(3)\n",
+			want: []string{"python process exited", "bye"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config
+			if tt.timeout > 0 {
+				cfg.CallTimeout = tt.timeout
+			}
+
+			_, err := runPython(t, cfg, tt.code, nil)
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Expected error to contain %q, got: %v", want, err)
+				}
+			}
+		})
+	}
+
+	// exceptions from user code keep their traceback through the executor
+	_, err := runPython(t, config, "def on_bar(ctx):\n    raise ValueError('boom')\n", nil)
+	var pyErr *PythonError
+	if !errors.As(err, &pyErr) {
+		t.Fatalf("Expected a PythonError, got %v", err)
+	}
+	if pyErr.Message != "ValueError: boom" {
+		t.Errorf("Expected message 'ValueError: boom', got %q", pyErr.Message)
+	}
+}
+
+func TestPythonStrategyStopsReading(t *testing.T) {
+	config := pythonConfig(t)
+	config.CallTimeout = 300 * time.Millisecond
+
+	// answers the first bar itself and never reads stdin again
+	code := "import os, time\n\ndef on_bar(ctx):\n    os.write(1, b'{\"type\": \"result\", \"signal\": \"HOLD\"}\\n')\n    time.sleep(3600)\n"
+	strat, err := NewPythonStrategy(config, "test", code, nil)
+	if err != nil {
+		t.Fatalf("Failed to load strategy: %v", err)
+	}
+	defer strat.Close()
+
+	bars := newBarProvider(make([]float64, 5000)...).bars
+	if _, err := strat.Generate(&strategy.Context{Symbol: "TEST", CurrentBar: bars[0]}); err != nil {
+		t.Fatalf("Expected the first bar to be answered, got %v", err)
+	}
+
+	// the full history no longer fits in the pipe, the write blocks
+	done := make(chan error, 1)
+	go func() {
+		_, err := strat.Generate(&strategy.Context{Symbol: "TEST", CurrentBar: bars[len(bars)-1], HistoricalBars: bars[:len(bars)-1]})
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "timed out after 300ms") {
+			t.Errorf("Expected a timeout, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the blocked write to time out")
+	}
+}
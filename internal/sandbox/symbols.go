@@ -0,0 +1,149 @@
+package sandbox
+
+import (
+	"maps"
+	"path"
+	"reflect"
+
+	"github.com/google/uuid"
+	"github.com/traefik/yaegi/stdlib"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/strategy"
+)
+
+const (
+	strategyImport = "github.com/wreckitral/distributed-backtesting-platform/internal/strategy"
+	domainImport   = "github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+	uuidImport     = "github.com/google/uuid"
+)
+
+// goImports is the allow-list of packages Go strategies may import. nothing
+// in it reaches the filesystem, the network or other processes
+var goImports = []string{
+	"errors",
+	"fmt",
+	"math",
+	"math/rand",
+	"slices",
+	"sort",
+	"strconv",
+	"strings",
+	"time",
+	strategyImport,
+	domainImport,
+	uuidImport,
+}
+
+// timeCallbacks are the time functions that run code, or keep a runtime
+// timer, outside the guarded call. they are left out of the exports
+var timeCallbacks = []string{"AfterFunc", "Tick", "NewTicker", "NewTimer"}
+
+// goSymbols builds the interpreter exports for the allow-list. the platform
+// packages only expose what a strategy needs to read the market and trade
+func goSymbols() map[string]map[string]reflect.Value {
+	symbols := map[string]map[string]reflect.Value{
+		".": stdlib.Symbols["."],
+
+		strategyImport + "/strategy": {
+			"Strategy":  reflect.ValueOf((*strategy.Strategy)(nil)),
+			"_Strategy": reflect.ValueOf((*_strategy_Strategy)(nil)),
+			"Context":   reflect.ValueOf((*strategy.Context)(nil)),
+			"Position":  reflect.ValueOf((*strategy.Position)(nil)),
+			"Signal":    reflect.ValueOf((*strategy.Signal)(nil)),
+			"Order":     reflect.ValueOf((*strategy.Order)(nil)),
+			"Fill":      reflect.ValueOf((*strategy.Fill)(nil)),
+
+			"OrderSide":   reflect.ValueOf((*strategy.OrderSide)(nil)),
+			"OrderType":   reflect.ValueOf((*strategy.OrderType)(nil)),
+			"OrderStatus": reflect.ValueOf((*strategy.OrderStatus)(nil)),
+			"TimeInForce": reflect.ValueOf((*strategy.TimeInForce)(nil)),
+
+			"SignalHold": reflect.ValueOf(strategy.SignalHold),
+			"SignalBuy":  reflect.ValueOf(strategy.SignalBuy),
+			"SignalSell": reflect.ValueOf(strategy.SignalSell),
+
+			"OrderSideBuy":          reflect.ValueOf(strategy.OrderSideBuy),
+			"OrderSideSell":         reflect.ValueOf(strategy.OrderSideSell),
+			"OrderTypeMarket":       reflect.ValueOf(strategy.OrderTypeMarket),
+			"OrderTypeLimit":        reflect.ValueOf(strategy.OrderTypeLimit),
+			"OrderTypeStop":         reflect.ValueOf(strategy.OrderTypeStop),
+			"OrderTypeStopLimit":    reflect.ValueOf(strategy.OrderTypeStopLimit),
+			"OrderTypeTrailingStop": reflect.ValueOf(strategy.OrderTypeTrailingStop),
+			"OrderStatusPending":    reflect.ValueOf(strategy.OrderStatusPending),
+			"OrderStatusFilled":     reflect.ValueOf(strategy.OrderStatusFilled),
+			"OrderStatusCancelled":  reflect.ValueOf(strategy.OrderStatusCancelled),
+			"TimeInForceDay":        reflect.ValueOf(strategy.TimeInForceDay),
+			"TimeInForceGTC":        reflect.ValueOf(strategy.TimeInForceGTC),
+			"TimeInForceIOC":        reflect.ValueOf(strategy.TimeInForceIOC),
+
+			"NewMarketOrder":       reflect.ValueOf(strategy.NewMarketOrder),
+			"NewLimitOrder":        reflect.ValueOf(strategy.NewLimitOrder),
+			"NewStopOrder":         reflect.ValueOf(strategy.NewStopOrder),
+			"NewStopLimitOrder":    reflect.ValueOf(strategy.NewStopLimitOrder),
+			"NewTrailingStopOrder": reflect.ValueOf(strategy.NewTrailingStopOrder),
+
+			"Channel":          reflect.ValueOf((*strategy.Channel)(nil)),
+			"MACDValue":        reflect.ValueOf((*strategy.MACDValue)(nil)),
+			"DirectionalIndex": reflect.ValueOf((*strategy.DirectionalIndex)(nil)),
+			"StochasticValue":  reflect.ValueOf((*strategy.StochasticValue)(nil)),
+
+			"SMA":            reflect.ValueOf(strategy.SMA),
+			"EMA":            reflect.ValueOf(strategy.EMA),
+			"WMA":            reflect.ValueOf(strategy.WMA),
+			"RSI":            reflect.ValueOf(strategy.RSI),
+			"MACD":           reflect.ValueOf(strategy.MACD),
+			"BollingerBands": reflect.ValueOf(strategy.BollingerBands),
+			"ATR":            reflect.ValueOf(strategy.ATR),
+			"ADX":            reflect.ValueOf(strategy.ADX),
+			"Stochastic":     reflect.ValueOf(strategy.Stochastic),
+			"CCI":            reflect.ValueOf(strategy.CCI),
+			"OBV":            reflect.ValueOf(strategy.OBV),
+			"VWAP":           reflect.ValueOf(strategy.VWAP),
+			"Donchian":       reflect.ValueOf(strategy.Donchian),
+			"Keltner":        reflect.ValueOf(strategy.Keltner),
+			"StdDev":         reflect.ValueOf(strategy.StdDev),
+			"ZScore":         reflect.ValueOf(strategy.ZScore),
+		},
+
+		domainImport + "/domain": {
+			"Bar": reflect.ValueOf((*domain.Bar)(nil)),
+		},
+
+		uuidImport + "/uuid": {
+			"UUID": reflect.ValueOf((*uuid.UUID)(nil)),
+			"Nil":  reflect.ValueOf(uuid.Nil),
+		},
+	}
+
+	for _, pkg := range goImports {
+		key := pkg + "/" + path.Base(pkg)
+		exports, ok := stdlib.Symbols[key]
+		if !ok {
+			continue
+		}
+		if pkg == "time" {
+			exports = maps.Clone(exports)
+			for _, name := range timeCallbacks {
+				delete(exports, name)
+			}
+		}
+		symbols[key] = exports
+	}
+
+	return symbols
+}
+
+// _strategy_Strategy lets values of interpreted types satisfy strategy.Strategy
+type _strategy_Strategy struct {
+	IValue    interface{}
+	WGenerate func(ctx *strategy.Context) (strategy.Signal, error)
+	WName     func() string
+}
+
+func (W _strategy_Strategy) Generate(ctx *strategy.Context) (strategy.Signal, error) {
+	return W.WGenerate(ctx)
+}
+
+func (W _strategy_Strategy) Name() string {
+	return W.WName()
+}
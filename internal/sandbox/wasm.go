@@ -0,0 +1,344 @@
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/strategy"
+)
+
+// size of the bar record written to guest memory for every on_bar call
+const wasmBarSize = 80
+
+var wasmMagic = []byte{0x00, 'a', 's', 'm'}
+
+// id of the section naming the function run at instantiation
+const wasmStartSection = 8
+
+// WasmConfig controls how WebAssembly strategies are run. modules are
+// instrumented to count the instructions they execute, a call that runs out
+// of fuel traps. the time budget bounds host calls and the engine overhead
+// on top of that
+type WasmConfig struct {
+	CallTimeout time.Duration // budget per init or on_bar call, zero disables it
+	Fuel        int64         // instructions per init or on_bar call, zero disables metering
+	MemoryLimit uint32        // linear memory cap in bytes, zero leaves the engine default
+}
+
+// WasmStrategy runs a WebAssembly module instantiated for one backtest. the
+// module talks to the executor through this ABI:
+//
+// exports, all i32 unless noted
+//
+//	memory                   linear memory the host writes records into
+//	alloc(size) ptr          returns a buffer of size bytes, called once per record kind
+//	on_bar(ptr, len) signal  0 HOLD, 1 BUY, 2 SELL
+//	init(ptr, len) status    optional, receives the parameters as JSON, non zero fails the load
+//	_initialize()            optional, run first for WASI reactors, a start section is refused
+//
+// imports from module "env"
+//
+//	set_quantity(f64)        trade an explicit number of shares on the returned signal
+//	log(ptr, len)            append a message to the strategy output shown on errors
+//
+// WASI preview1 is available without filesystem, network, env or args, its
+// stdout and stderr land in the strategy output as well. metering adds a
+// global after the ones of the module and exports it as __strategy_fuel.
+//
+// the bar record is little endian:
+//
+//	0  i64 timestamp, unix milliseconds
+//	8  f64 open, 16 high, 24 low, 32 close, 40 volume
+//	48 f64 shares held in the symbol, negative when short
+//	56 f64 cash
+//	64 f64 equity
+//	72 i32 index of the symbol in the backtest universe
+//	76 i32 number of bars seen for the symbol, including this one
+type WasmStrategy struct {
+	name    string
+	timeout time.Duration
+	budget  int64             // fuel per call
+	fuel    api.MutableGlobal // remaining fuel of the current call, nil when not metered
+
+	runtime wazero.Runtime
+	module  api.Module
+	onBar   api.Function
+	barPtr  uint32
+	output  *tailBuffer
+
+	quantity *float64 // set by the guest during a call
+}
+
+// DecodeWasm decodes a module stored as base64 and checks its header and
+// sections. a start section is refused: it would run while instantiating,
+// outside the fuel and time budget of a call
+func DecodeWasm(code string) ([]byte, error) {
+	wasm, err := base64.StdEncoding.DecodeString(strings.TrimSpace(code))
+	if err != nil {
+		return nil, fmt.Errorf("wasm module must be base64 encoded: %w", err)
+	}
+	if !bytes.HasPrefix(wasm, wasmMagic) {
+		return nil, fmt.Errorf("not a wasm module")
+	}
+
+	sections, err := readSections(wasm)
+	if err != nil {
+		return nil, fmt.Errorf("invalid wasm module: %w", err)
+	}
+	for _, section := range sections {
+		if section.id == wasmStartSection {
+			return nil, fmt.Errorf("wasm modules must not declare a start function, export _initialize instead")
+		}
+	}
+	return wasm, nil
+}
+
+// NewWasmStrategy compiles and instantiates the base64 encoded module in
+// code and passes params to its init export
+func NewWasmStrategy(config WasmConfig, name, code string, params map[string]any) (*WasmStrategy, error) {
+	wasm, err := DecodeWasm(code)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	runtimeConfig := wazero.NewRuntimeConfig().WithCloseOnContextDone(true)
+	if pages := config.MemoryLimit >> 16; pages > 0 {
+		runtimeConfig = runtimeConfig.WithMemoryLimitPages(pages)
+	}
+
+	if config.Fuel > 0 {
+		if wasm, err = meter(wasm); err != nil {
+			return nil, fmt.Errorf("failed to load wasm strategy: %w", err)
+		}
+	}
+
+	s := &WasmStrategy{
+		name:    name,
+		timeout: config.CallTimeout,
+		budget:  config.Fuel,
+		runtime: wazero.NewRuntimeWithConfig(ctx, runtimeConfig),
+		output:  &tailBuffer{limit: 8 << 10},
+	}
+
+	if err := s.instantiate(ctx, wasm, params); err != nil {
+		s.Close()
+		return nil, fmt.Errorf("failed to load wasm strategy: %w", err)
+	}
+
+	return s, nil
+}
+
+func (s *WasmStrategy) instantiate(ctx context.Context, wasm []byte, params map[string]any) error {
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, s.runtime); err != nil {
+		return err
+	}
+
+	_, err := s.runtime.NewHostModuleBuilder("env").
+		NewFunctionBuilder().
+		WithFunc(func(shares float64) {
+			shares = math.Max(shares, 0)
+			s.quantity = &shares
+		}).
+		Export("set_quantity").
+		NewFunctionBuilder().
+		WithFunc(func(ctx context.Context, m api.Module, ptr, size uint32) {
+			if msg, ok := m.Memory().Read(ptr, size); ok {
+				s.output.Write(append(msg, '\n'))
+			}
+		}).
+		Export("log").
+		Instantiate(ctx)
+	if err != nil {
+		return err
+	}
+
+	compiled, err := s.runtime.CompileModule(ctx, wasm)
+	if err != nil {
+		return err
+	}
+
+	// _start of WASI commands is not run, DecodeWasm refused a start section
+	// and _initialize is called below with the call budget
+	s.module, err = s.runtime.InstantiateModule(ctx, compiled, wazero.NewModuleConfig().
+		WithName("strategy").
+		WithStartFunctions().
+		WithStdout(s.output).
+		WithStderr(s.output))
+	if err != nil {
+		return err
+	}
+
+	if s.module.Memory() == nil {
+		return fmt.Errorf("module must export its memory")
+	}
+	if s.budget > 0 {
+		s.fuel = s.module.ExportedGlobal(fuelExport).(api.MutableGlobal)
+	}
+	s.onBar = s.module.ExportedFunction("on_bar")
+	if s.onBar == nil {
+		return fmt.Errorf("module must export on_bar(ptr, len)")
+	}
+
+	if initialize := s.module.ExportedFunction("_initialize"); initialize != nil {
+		if _, err := s.call(initialize); err != nil {
+			return err
+		}
+	}
+
+	if s.barPtr, err = s.alloc(wasmBarSize); err != nil {
+		return err
+	}
+
+	if init := s.module.ExportedFunction("init"); init != nil {
+		if params == nil {
+			params = map[string]any{}
+		}
+		data, err := json.Marshal(params)
+		if err != nil {
+			return fmt.Errorf("failed to encode parameters: %w", err)
+		}
+
+		ptr, err := s.alloc(uint32(len(data)))
+		if err != nil {
+			return err
+		}
+		s.module.Memory().Write(ptr, data)
+
+		status, err := s.call(init, uint64(ptr), uint64(len(data)))
+		if err != nil {
+			return err
+		}
+		if int32(status) != 0 {
+			return s.failed(fmt.Errorf("init returned status %d", int32(status)))
+		}
+	}
+
+	return nil
+}
+
+func (s *WasmStrategy) Name() string {
+	return s.name
+}
+
+func (s *WasmStrategy) Generate(ctx *strategy.Context) (strategy.Signal, error) {
+	s.module.Memory().Write(s.barPtr, encodeWasmBar(ctx))
+	s.quantity = nil
+
+	result, err := s.call(s.onBar, uint64(s.barPtr), wasmBarSize)
+	if err != nil {
+		return strategy.SignalHold, err
+	}
+
+	if s.quantity != nil {
+		ctx.SetQuantity(*s.quantity)
+	}
+
+	switch signal := int32(result); signal {
+	case 0:
+		return strategy.SignalHold, nil
+	case 1:
+		return strategy.SignalBuy, nil
+	case 2:
+		return strategy.SignalSell, nil
+	default:
+		return strategy.SignalHold, s.failed(fmt.Errorf("on_bar returned unknown signal %d", signal))
+	}
+}
+
+// Close releases the runtime and everything instantiated in it
+func (s *WasmStrategy) Close() error {
+	return s.runtime.Close(context.Background())
+}
+
+func (s *WasmStrategy) alloc(size uint32) (uint32, error) {
+	alloc := s.module.ExportedFunction("alloc")
+	if alloc == nil {
+		return 0, fmt.Errorf("module must export alloc(size)")
+	}
+
+	ptr, err := s.call(alloc, uint64(size))
+	if err != nil {
+		return 0, err
+	}
+	if _, ok := s.module.Memory().Read(uint32(ptr), size); !ok {
+		return 0, fmt.Errorf("alloc(%d) returned %d, outside of memory", size, uint32(ptr))
+	}
+	return uint32(ptr), nil
+}
+
+// call runs an export within the call budget and returns its first result
+func (s *WasmStrategy) call(fn api.Function, params ...uint64) (uint64, error) {
+	ctx := context.Background()
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	if s.fuel != nil {
+		s.fuel.Set(uint64(s.budget))
+	}
+
+	results, err := fn.Call(ctx, params...)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return 0, s.failed(fmt.Errorf("wasm strategy exceeded its budget of %s", s.timeout))
+	}
+	if err != nil && s.fuel != nil && int64(s.fuel.Get()) < 0 {
+		return 0, s.failed(fmt.Errorf("wasm strategy ran out of fuel after %d instructions", s.budget))
+	}
+	if err != nil {
+		return 0, s.failed(err)
+	}
+
+	if len(results) == 0 {
+		return 0, nil
+	}
+	return results[0], nil
+}
+
+// failed adds the tail of the strategy output to err
+func (s *WasmStrategy) failed(err error) error {
+	if tail := strings.TrimSpace(s.output.String()); tail != "" {
+		return fmt.Errorf("%w\n%s", err, tail)
+	}
+	return err
+}
+
+func encodeWasmBar(ctx *strategy.Context) []byte {
+	record := make([]byte, wasmBarSize)
+	bar := ctx.CurrentBar
+
+	var shares float64
+	if ctx.CurrentPosition != nil {
+		shares = ctx.CurrentPosition.Shares
+	}
+
+	index := -1
+	for i, symbol := range ctx.Universe {
+		if symbol == ctx.Symbol {
+			index = i
+			break
+		}
+	}
+
+	binary.LittleEndian.PutUint64(record[0:], uint64(ctx.Timestamp.UnixMilli()))
+	for i, value := range []float64{bar.Open, bar.High, bar.Low, bar.Close, float64(bar.Volume), shares, ctx.Cash, ctx.Equity} {
+		binary.LittleEndian.PutUint64(record[8+8*i:], math.Float64bits(value))
+	}
+	binary.LittleEndian.PutUint32(record[72:], uint32(int32(index)))
+	binary.LittleEndian.PutUint32(record[76:], uint32(ctx.BarCount()))
+
+	return record
+}
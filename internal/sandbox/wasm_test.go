@@ -0,0 +1,292 @@
+package sandbox
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/strategy"
+)
+
+// wasm opcodes used by the test modules
+const (
+	opUnreachable = 0x00
+	opLoop        = 0x03
+	opIf          = 0x04
+	opElse        = 0x05
+	opEnd         = 0x0b
+	opBr          = 0x0c
+	opCall        = 0x10
+	opLocalGet    = 0x20
+	opI32Load     = 0x28
+	opI32Const    = 0x41
+	opF64Const    = 0x44
+	opI32Eq       = 0x46
+)
+
+// testModule assembles a module importing env.set_quantity (func 0) and
+// env.log (func 1) and exporting memory, alloc (func 2), on_bar (func 3)
+// and, when given, init (func 4). "hello" is stored at address 0
+type testModule struct {
+	memoryPages byte
+	onBar       []byte // body of on_bar(ptr, len) i32, without the final end
+	init        []byte // body of init(ptr, len) i32, without the final end
+	start       bool   // declares an empty function as start function, last
+}
+
+func (m testModule) encode() string {
+	section := func(id byte, items ...[]byte) []byte {
+		content := []byte{byte(len(items))}
+		for _, item := range items {
+			content = append(content, item...)
+		}
+		return append([]byte{id}, append(uleb(uint32(len(content))), content...)...)
+	}
+	name := func(s string) []byte {
+		return append([]byte{byte(len(s))}, s...)
+	}
+	code := func(body []byte) []byte {
+		fn := append([]byte{0}, body...) // no locals
+		fn = append(fn, opEnd)
+		return append(uleb(uint32(len(fn))), fn...)
+	}
+
+	const i32, f64 = 0x7f, 0x7c
+	types := [][]byte{
+		{0x60, 1, f64, 0},           // (f64)
+		{0x60, 2, i32, i32, 0},      // (i32, i32)
+		{0x60, 1, i32, 1, i32},      // (i32) i32
+		{0x60, 2, i32, i32, 1, i32}, // (i32, i32) i32
+		{0x60, 0, 0},                // ()
+	}
+	imports := [][]byte{
+		append(append(name("env"), name("set_quantity")...), 0x00, 0),
+		append(append(name("env"), name("log")...), 0x00, 1),
+	}
+	functions := [][]byte{{2}, {3}}
+	exports := [][]byte{
+		append(name("memory"), 0x02, 0),
+		append(name("alloc"), 0x00, 2),
+		append(name("on_bar"), 0x00, 3),
+	}
+	codes := [][]byte{
+		code([]byte{opI32Const, 0x80, 0x08}), // alloc returns 1024
+		code(m.onBar),
+	}
+	if m.init != nil {
+		functions = append(functions, []byte{3})
+		exports = append(exports, append(name("init"), 0x00, 4))
+		codes = append(codes, code(m.init))
+	}
+	start := byte(2 + len(codes))
+	if m.start {
+		functions = append(functions, []byte{4})
+		codes = append(codes, code(nil))
+	}
+
+	pages := m.memoryPages
+	if pages == 0 {
+		pages = 1
+	}
+
+	wasm := []byte{0x00, 'a', 's', 'm', 1, 0, 0, 0}
+	wasm = append(wasm, section(1, types...)...)
+	wasm = append(wasm, section(2, imports...)...)
+	wasm = append(wasm, section(3, functions...)...)
+	wasm = append(wasm, section(5, []byte{0x00, pages})...)
+	wasm = append(wasm, section(7, exports...)...)
+	if m.start {
+		wasm = append(wasm, 8, 1, start)
+	}
+	wasm = append(wasm, section(10, codes...)...)
+	wasm = append(wasm, section(11, append([]byte{0x00, opI32Const, 0, opEnd}, name("hello")...))...)
+
+	return base64.StdEncoding.EncodeToString(wasm)
+}
+
+func uleb(v uint32) []byte {
+	var out []byte
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if v == 0 {
+			return append(out, b)
+		}
+		out = append(out, b|0x80)
+	}
+}
+
+func f64Const(v float64) []byte {
+	out := []byte{opF64Const}
+	return binary.LittleEndian.AppendUint64(out, math.Float64bits(v))
+}
+
+// barCountIs pushes whether the bar count field of the record equals n
+func barCountIs(n byte) []byte {
+	return []byte{opLocalGet, 0, opI32Load, 2, 76, opI32Const, n, opI32Eq}
+}
+
+func TestWasmStrategySignals(t *testing.T) {
+	// buy 10 shares on the first bar and sell on the third
+	onBar := barCountIs(1)
+	onBar = append(onBar, opIf, 0x7f)
+	onBar = append(onBar, f64Const(10)...)
+	onBar = append(onBar, opCall, 0, opI32Const, 1, opElse)
+	onBar = append(onBar, barCountIs(3)...)
+	onBar = append(onBar, opIf, 0x7f, opI32Const, 2, opElse, opI32Const, 0, opEnd, opEnd)
+
+	strat, err := NewWasmStrategy(WasmConfig{CallTimeout: time.Second, Fuel: 1000, MemoryLimit: 1 << 20}, "wasm", testModule{onBar: onBar}.encode(), nil)
+	if err != nil {
+		t.Fatalf("Failed to load strategy: %v", err)
+	}
+	defer strat.Close()
+
+	trades, err := runBacktest(strat)
+	if err != nil {
+		t.Fatalf("Backtest failed: %v", err)
+	}
+
+	if len(trades) != 2 {
+		t.Fatalf("Expected 2 trades, got %d", len(trades))
+	}
+	if trades[0].Quantity != 10 || trades[0].Price != 102 {
+		t.Errorf("Expected buy of 10 at 102, got %.0f at %.2f", trades[0].Quantity, trades[0].Price)
+	}
+	if trades[1].Direction != domain.TradeDirectionSell || trades[1].Price != 106 {
+		t.Errorf("Expected sell at 106, got direction %d at %.2f", trades[1].Direction, trades[1].Price)
+	}
+}
+
+func TestWasmStrategyErrors(t *testing.T) {
+	hold := []byte{opI32Const, 0}
+
+	tests := []struct {
+		name   string
+		module testModule
+		config WasmConfig
+		want   []string
+	}{
+		{
+			name:   "init status with log output",
+			module: testModule{onBar: hold, init: []byte{opI32Const, 0, opI32Const, 5, opCall, 1, opI32Const, 7}},
+			want:   []string{"failed to load wasm strategy", "init returned status 7", "hello"},
+		},
+		{
+			name:   "trap",
+			module: testModule{onBar: []byte{opUnreachable}},
+			want:   []string{"unreachable"},
+		},
+		{
+			name:   "unknown signal",
+			module: testModule{onBar: []byte{opI32Const, 9}},
+			want:   []string{"on_bar returned unknown signal 9"},
+		},
+		{
+			name:   "budget",
+			module: testModule{onBar: []byte{opLoop, 0x40, opBr, 0, opEnd, opI32Const, 0}},
+			config: WasmConfig{CallTimeout: 100 * time.Millisecond},
+			want:   []string{"exceeded its budget of 100ms"},
+		},
+		{
+			name:   "fuel",
+			module: testModule{onBar: []byte{opLoop, 0x40, opBr, 0, opEnd, opI32Const, 0}},
+			config: WasmConfig{Fuel: 10000},
+			want:   []string{"ran out of fuel after 10000 instructions"},
+		},
+		{
+			name:   "fuel global touched",
+			module: testModule{onBar: []byte{0x42, 0x7f, 0x24, 0, opI32Const, 0}},
+			config: WasmConfig{Fuel: 10000},
+			want:   []string{"failed to load wasm strategy", "global 0 is reserved for fuel"},
+		},
+		{
+			name:   "memory cap",
+			module: testModule{onBar: hold, memoryPages: 4},
+			config: WasmConfig{MemoryLimit: 2 << 16},
+			want:   []string{"failed to load wasm strategy", "memory"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strat, err := NewWasmStrategy(tt.config, "wasm", tt.module.encode(), nil)
+			if err == nil {
+				defer strat.Close()
+				_, err = runBacktest(strat)
+			}
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Expected error to contain %q, got: %v", want, err)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeWasm(t *testing.T) {
+	if _, err := DecodeWasm("not base64!"); err == nil {
+		t.Error("Expected an error for invalid base64")
+	}
+	if _, err := DecodeWasm(base64.StdEncoding.EncodeToString([]byte("def on_bar(ctx): pass"))); err == nil {
+		t.Error("Expected an error for a missing wasm header")
+	}
+	if _, err := DecodeWasm(testModule{onBar: []byte{opI32Const, 0}}.encode()); err != nil {
+		t.Errorf("Expected the test module to decode, got %v", err)
+	}
+
+	start := testModule{onBar: []byte{opI32Const, 0}, start: true}.encode()
+	if _, err := DecodeWasm(start); err == nil || !strings.Contains(err.Error(), "start function") {
+		t.Errorf("Expected a start section to be refused, got %v", err)
+	}
+	for _, fuel := range []int64{0, 1000} {
+		if _, err := NewWasmStrategy(WasmConfig{Fuel: fuel}, "wasm", start, nil); err == nil {
+			t.Errorf("Expected the module with a start function to fail to load with fuel %d", fuel)
+		}
+	}
+
+	truncated := base64.StdEncoding.EncodeToString([]byte{0x00, 'a', 's', 'm', 1, 0, 0, 0, 1, 10})
+	if _, err := DecodeWasm(truncated); err == nil || !strings.Contains(err.Error(), "invalid wasm module") {
+		t.Errorf("Expected a truncated section to be refused, got %v", err)
+	}
+}
+
+func TestEncodeWasmBar(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	ctx := &strategy.Context{
+		Symbol:          "BBB",
+		Timestamp:       ts,
+		CurrentBar:      domain.Bar{Open: 1, High: 2, Low: 0.5, Close: 1.5, Volume: 300},
+		HistoricalBars:  make([]domain.Bar, 4),
+		CurrentPosition: &strategy.Position{Shares: -20},
+		Cash:            1000,
+		Equity:          970,
+		Universe:        []string{"AAA", "BBB"},
+	}
+
+	record := encodeWasmBar(ctx)
+	if len(record) != wasmBarSize {
+		t.Fatalf("Expected a %d byte record, got %d", wasmBarSize, len(record))
+	}
+
+	if got := int64(binary.LittleEndian.Uint64(record[0:])); got != ts.UnixMilli() {
+		t.Errorf("Expected timestamp %d, got %d", ts.UnixMilli(), got)
+	}
+	for i, want := range []float64{1, 2, 0.5, 1.5, 300, -20, 1000, 970} {
+		if got := math.Float64frombits(binary.LittleEndian.Uint64(record[8+8*i:])); got != want {
+			t.Errorf("Expected field at offset %d to be %.2f, got %.2f", 8+8*i, want, got)
+		}
+	}
+	if got := binary.LittleEndian.Uint32(record[72:]); got != 1 {
+		t.Errorf("Expected symbol index 1, got %d", got)
+	}
+	if got := binary.LittleEndian.Uint32(record[76:]); got != 5 {
+		t.Errorf("Expected bar count 5, got %d", got)
+	}
+}
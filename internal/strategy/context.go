@@ -1,10 +1,21 @@
 package strategy
 
-import "github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+import (
+	"fmt"
+	"math"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+)
+
+// Context is what a strategy sees of the market and the account. weight
+// strategies get one context per rebalance with an empty Symbol and CurrentBar
 type Context struct {
 	Symbol string
 
+	Timestamp time.Time
+
 	CurrentBar domain.Bar
 
 	HistoricalBars []domain.Bar
@@ -12,6 +23,26 @@ type Context struct {
 	CurrentPosition *Position
 
 	Cash float64
+
+	// account value with every position marked at its last close
+	Equity float64
+
+	// every symbol of the backtest with its latest bar and open position,
+	// symbols without data yet are missing from Bars
+	Universe  []string
+	Bars      map[string]domain.Bar
+	Positions map[string]*Position
+
+	// order events since the previous bar
+	Fills         []Fill
+	Cancellations []Order
+
+	pending    []Order
+	submitted  []Order
+	cancels    []uuid.UUID
+	quantity   float64
+	histories  map[string][]domain.Bar
+	indicators indicatorSet
 }
 
 func (c *Context) BarCount() int {
@@ -19,12 +50,111 @@ func (c *Context) BarCount() int {
 }
 
 func (c *Context) HasPosition() bool {
-	return c.CurrentPosition != nil && c.CurrentPosition.Shares > 0
+	return c.CurrentPosition != nil && c.CurrentPosition.IsOpen()
+}
+
+func (c *Context) IsLong() bool {
+	return c.CurrentPosition != nil && c.CurrentPosition.IsLong()
 }
 
+func (c *Context) IsShort() bool {
+	return c.CurrentPosition != nil && c.CurrentPosition.IsShort()
+}
+
+// AllBars returns a copy of the bars of the context symbol, History and
+// Lookback give the same bars without copying
 func (c *Context) AllBars() []domain.Bar {
 	all := make([]domain.Bar, len(c.HistoricalBars)+1)
 	copy(all, c.HistoricalBars)
 	all[len(all)-1] = c.CurrentBar
 	return all
 }
+
+// History returns the bars of symbol up to and including its latest bar. the
+// slice is shared with the executor and must not be modified
+func (c *Context) History(symbol string) []domain.Bar {
+	history, ok := c.histories[symbol]
+	if symbol == c.Symbol && !ok {
+		return c.AllBars()
+	}
+
+	return history[:len(history):len(history)]
+}
+
+// Lookback returns up to the last n bars of the context symbol, the current
+// bar included, without copying
+func (c *Context) Lookback(n int) []domain.Bar {
+	history := c.History(c.Symbol)
+	if n < len(history) {
+		history = history[len(history)-max(n, 0):]
+	}
+	return history
+}
+
+// Indicator returns the indicator registered under key for the context
+// symbol. the first call builds it and replays the history, after that the
+// executor updates it with every bar so each call is O(1). contexts built
+// outside an executor rebuild the indicator on every call
+func (c *Context) Indicator(key string, build func() (Indicator, error)) (Indicator, error) {
+	if c.Symbol == "" {
+		return nil, fmt.Errorf("indicator %s needs a symbol context", key)
+	}
+
+	if indicator, ok := c.indicators[c.Symbol][key]; ok {
+		return indicator, nil
+	}
+
+	indicator, err := build()
+	if err != nil {
+		return nil, fmt.Errorf("indicator %s: %w", key, err)
+	}
+	for _, bar := range c.History(c.Symbol) {
+		indicator.Update(bar)
+	}
+
+	if c.indicators != nil {
+		if c.indicators[c.Symbol] == nil {
+			c.indicators[c.Symbol] = make(map[string]Indicator)
+		}
+		c.indicators[c.Symbol][key] = indicator
+	}
+	return indicator, nil
+}
+
+// SetQuantity trades an explicit number of shares on the signal returned for
+// this bar instead of leaving it to the executor sizer
+func (c *Context) SetQuantity(shares float64) {
+	c.quantity = math.Max(shares, 0)
+}
+
+// SubmitOrder queues an order, it is matched starting with the next bar.
+// the symbol defaults to the context symbol and an order without an ID gets one
+func (c *Context) SubmitOrder(order Order) (uuid.UUID, error) {
+	if err := order.Validate(); err != nil {
+		return uuid.Nil, err
+	}
+
+	if order.ID == uuid.Nil {
+		order.ID = uuid.New()
+	}
+	order.Status = OrderStatusPending
+	order.SubmittedAt = c.Timestamp
+	if order.Symbol == "" {
+		order.Symbol = c.Symbol
+	}
+
+	c.submitted = append(c.submitted, order)
+	return order.ID, nil
+}
+
+// CancelOrder cancels a pending order before the next bar is matched
+func (c *Context) CancelOrder(id uuid.UUID) {
+	c.cancels = append(c.cancels, id)
+}
+
+// PendingOrders returns the orders still waiting to be filled
+func (c *Context) PendingOrders() []Order {
+	pending := make([]Order, len(c.pending))
+	copy(pending, c.pending)
+	return pending
+}
@@ -0,0 +1,125 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/marketdata"
+)
+
+// WithCorporateActions splits open positions and pays dividends in cash on
+// their ex-dates. with reinvest, dividends on long positions buy more shares
+// at the open. use it with raw prices, a back-adjusted provider already
+// accounts for the actions
+func WithCorporateActions(source marketdata.CorporateActionSource, reinvest bool) ExecutorOption {
+	return func(e *Executor) {
+		e.actions = source
+		e.reinvest = reinvest
+	}
+}
+
+// loadCorporateActions fetches the actions of every symbol going ex inside the run
+func (e *Executor) loadCorporateActions(ctx context.Context, state *runState, start, end time.Time) error {
+	if e.actions == nil {
+		return nil
+	}
+
+	state.actions = make(map[string][]domain.CorporateAction, len(state.symbols))
+	for _, symbol := range state.symbols {
+		actions, err := e.actions.GetActions(ctx, symbol, start, end)
+		if err != nil {
+			return fmt.Errorf("failed to get corporate actions for %s: %w", symbol, err)
+		}
+		state.actions[symbol] = actions
+	}
+
+	return nil
+}
+
+// applyCorporateActions applies every action whose ex-date was reached by the
+// bar of its symbol
+func (e *Executor) applyCorporateActions(state *runState, slice marketdata.TimeSlice) {
+	if e.actions == nil {
+		return
+	}
+
+	for _, symbol := range state.symbols {
+		bar, ok := slice.Bars[symbol]
+		if !ok {
+			continue
+		}
+
+		pending := state.actions[symbol]
+		for len(pending) > 0 && marketdata.ExDateReached(bar.Timestamp, pending[0].ExDate) {
+			switch action := pending[0]; action.Type {
+			case domain.CorporateActionSplit:
+				state.split(symbol, action)
+			case domain.CorporateActionDividend:
+				e.payDividend(state, symbol, bar, action.Amount)
+			}
+			pending = pending[1:]
+		}
+		state.actions[symbol] = pending
+	}
+}
+
+// split restates the position, working orders, last close and earlier bars of
+// symbol in post-split shares so nothing jumps at the ex-date
+func (s *runState) split(symbol string, action domain.CorporateAction) {
+	ratio := action.Ratio
+
+	if position := s.positions[symbol]; position != nil {
+		position.Shares *= ratio
+		position.EntryPrice /= ratio
+	}
+
+	if mark, ok := s.marks[symbol]; ok {
+		s.marks[symbol] = mark / ratio
+	}
+
+	for _, order := range s.orders {
+		if order.Symbol != symbol || !order.IsOpen() {
+			continue
+		}
+		order.Quantity *= ratio
+		order.LimitPrice /= ratio
+		order.StopPrice /= ratio
+		order.TrailAmount /= ratio
+		order.extreme /= ratio
+	}
+
+	history := s.histories[symbol]
+	if len(history) > 1 {
+		earlier := marketdata.AdjustBars(history[:len(history)-1], []domain.CorporateAction{action})
+		s.histories[symbol] = append(earlier, history[len(history)-1])
+		s.indicators.reset(symbol)
+	}
+}
+
+// payDividend credits, or charges a short, the dividend of the position in
+// symbol and reinvests it at the open of bar when enabled
+func (e *Executor) payDividend(state *runState, symbol string, bar domain.Bar, amount float64) {
+	position := state.positions[symbol]
+	if position == nil {
+		return
+	}
+
+	cash := position.Shares * amount
+	position.Dividends += cash
+	state.cash += cash
+
+	if !e.reinvest || cash <= 0 || bar.Open <= 0 {
+		return
+	}
+
+	order := NewMarketOrder(OrderSideBuy, cash/bar.Open)
+	order.ID = uuid.New()
+	order.Symbol = symbol
+	order.SubmittedAt = bar.Timestamp
+	order.Reason = "dividend reinvestment"
+
+	e.fill(state, &order, bar.Open)
+}
@@ -0,0 +1,132 @@
+package strategy
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+)
+
+// memoryActions serves a fixed set of corporate actions
+type memoryActions map[string][]domain.CorporateAction
+
+func (m memoryActions) GetActions(ctx context.Context, symbol string, start, end time.Time) ([]domain.CorporateAction, error) {
+	filtered := []domain.CorporateAction{}
+	for _, action := range m[symbol] {
+		if !action.ExDate.Before(start) && action.ExDate.Before(end) {
+			filtered = append(filtered, action)
+		}
+	}
+	return filtered, nil
+}
+
+func TestExecutorSplit(t *testing.T) {
+	provider := newMemoryProvider("TEST", [][4]float64{
+		{100, 100, 100, 100},
+		{100, 100, 100, 100},
+		{50, 50, 50, 50},
+		{50, 50, 50, 50},
+	})
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	actions := memoryActions{"TEST": {
+		{Symbol: "TEST", ExDate: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), Type: domain.CorporateActionSplit, Ratio: 2},
+	}}
+
+	strat := &scriptedStrategy{signals: map[int]Signal{0: SignalBuy, 2: SignalSell}}
+	result, err := NewExecutor(strat, provider, 10000.0, WithCorporateActions(actions, false)).
+		Simulate(context.Background(), "TEST", start, end)
+	if err != nil {
+		t.Fatalf("Executor failed: %v", err)
+	}
+	if len(result.Trades) != 2 {
+		t.Fatalf("Expected 2 trades, got %d", len(result.Trades))
+	}
+
+	sell := result.Trades[1]
+	if math.Abs(sell.Quantity-200) > 1e-9 {
+		t.Errorf("Expected 200 shares after the split, got %.4f", sell.Quantity)
+	}
+	if math.Abs(sell.PnL) > 1e-6 {
+		t.Errorf("Expected no P&L across the split, got %.4f", sell.PnL)
+	}
+
+	for _, point := range result.EquityCurve {
+		if math.Abs(point.Equity-10000) > 1e-6 {
+			t.Errorf("Expected flat equity across the split, got %.2f on %s", point.Equity, point.Timestamp)
+		}
+	}
+
+	// without corporate actions the split looks like a 50% loss
+	trades, err := NewExecutor(strat, provider, 10000.0).Run(context.Background(), "TEST", start, end)
+	if err != nil {
+		t.Fatalf("Executor failed: %v", err)
+	}
+	if math.Abs(trades[1].PnL+5000) > 1e-6 {
+		t.Errorf("Expected a 5000 loss on raw prices, got %.4f", trades[1].PnL)
+	}
+}
+
+func TestExecutorDividends(t *testing.T) {
+	provider := newMemoryProvider("TEST", [][4]float64{
+		{100, 100, 100, 100},
+		{100, 100, 100, 100},
+		{100, 100, 100, 100},
+		{100, 100, 100, 100},
+	})
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	actions := memoryActions{"TEST": {
+		{Symbol: "TEST", ExDate: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), Type: domain.CorporateActionDividend, Amount: 1},
+	}}
+
+	tests := []struct {
+		name      string
+		signals   map[int]Signal
+		short     bool
+		reinvest  bool
+		trades    int
+		closed    float64
+		dividends float64
+	}{
+		{"long paid in cash", map[int]Signal{0: SignalBuy, 2: SignalSell}, false, false, 2, 100, 100},
+		{"long reinvested", map[int]Signal{0: SignalBuy, 2: SignalSell}, false, true, 3, 101, 100},
+		{"short pays the dividend", map[int]Signal{0: SignalSell, 2: SignalBuy}, true, false, 2, 100, -100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := []ExecutorOption{WithCorporateActions(actions, tt.reinvest)}
+			if tt.short {
+				opts = append(opts, WithShortSelling(MarginConfig{InitialMargin: 0.5, MaintenanceMargin: 0.3}))
+			}
+
+			result, err := NewExecutor(&scriptedStrategy{signals: tt.signals}, provider, 10000.0, opts...).
+				Simulate(context.Background(), "TEST", start, end)
+			if err != nil {
+				t.Fatalf("Executor failed: %v", err)
+			}
+			if len(result.Trades) != tt.trades {
+				t.Fatalf("Expected %d trades, got %d", tt.trades, len(result.Trades))
+			}
+
+			closing := result.Trades[len(result.Trades)-1]
+			if math.Abs(closing.Quantity-tt.closed) > 1e-6 {
+				t.Errorf("Expected to close %.0f shares, got %.4f", tt.closed, closing.Quantity)
+			}
+			if math.Abs(closing.Dividends-tt.dividends) > 1e-6 {
+				t.Errorf("Expected dividends of %.0f, got %.4f", tt.dividends, closing.Dividends)
+			}
+			if math.Abs(closing.PnL-tt.dividends) > 1e-6 {
+				t.Errorf("Expected P&L of %.0f from dividends, got %.4f", tt.dividends, closing.PnL)
+			}
+
+			last := result.EquityCurve[len(result.EquityCurve)-1]
+			if math.Abs(last.Equity-(10000+tt.dividends)) > 1e-6 {
+				t.Errorf("Expected equity %.0f, got %.2f", 10000+tt.dividends, last.Equity)
+			}
+		})
+	}
+}
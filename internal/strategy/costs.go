@@ -0,0 +1,214 @@
+package strategy
+
+import (
+	"math"
+
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+)
+
+// CommissionModel computes the broker fee charged for a single fill
+type CommissionModel interface {
+	Commission(quantity, price float64) float64
+}
+
+// SlippageModel moves a fill price against the trader.
+// history holds the bars up to and including the fill bar
+type SlippageModel interface {
+	Apply(direction domain.TradeDirection, price float64, history []domain.Bar) float64
+}
+
+// NoCommission charges nothing
+type NoCommission struct{}
+
+func (NoCommission) Commission(quantity, price float64) float64 {
+	return 0
+}
+
+// FixedCommission charges a flat fee per trade
+type FixedCommission struct {
+	PerTrade float64
+}
+
+func (c FixedCommission) Commission(quantity, price float64) float64 {
+	return c.PerTrade
+}
+
+// PerShareCommission charges a fee per share with an optional minimum per trade
+type PerShareCommission struct {
+	PerShare float64
+	Minimum  float64
+}
+
+func (c PerShareCommission) Commission(quantity, price float64) float64 {
+	return math.Max(math.Abs(quantity)*c.PerShare, c.Minimum)
+}
+
+// BpsCommission charges basis points of the traded notional
+type BpsCommission struct {
+	Bps float64
+}
+
+func (c BpsCommission) Commission(quantity, price float64) float64 {
+	return math.Abs(quantity*price) * c.Bps / 10000
+}
+
+// CommissionTier is one band of a tiered schedule, shares up to UpTo
+// (exclusive of the previous band) are charged PerShare. zero UpTo means unbounded
+type CommissionTier struct {
+	UpTo     float64
+	PerShare float64
+}
+
+// TieredCommission charges a per share rate that drops as the order gets larger,
+// each band only applies to the shares that fall inside it
+type TieredCommission struct {
+	Tiers   []CommissionTier
+	Minimum float64
+	Maximum float64 // cap as a fraction of notional, zero disables it
+}
+
+// DefaultTieredCommission is a typical retail broker tiered schedule
+func DefaultTieredCommission() TieredCommission {
+	return TieredCommission{
+		Tiers: []CommissionTier{
+			{UpTo: 300, PerShare: 0.0035},
+			{UpTo: 3000, PerShare: 0.0020},
+			{UpTo: 20000, PerShare: 0.0015},
+			{UpTo: 100000, PerShare: 0.0010},
+			{UpTo: 0, PerShare: 0.0005},
+		},
+		Minimum: 0.35,
+		Maximum: 0.01,
+	}
+}
+
+func (c TieredCommission) Commission(quantity, price float64) float64 {
+	remaining := math.Abs(quantity)
+	lower := 0.0
+	fee := 0.0
+
+	for _, tier := range c.Tiers {
+		if remaining <= 0 {
+			break
+		}
+
+		band := remaining
+		if tier.UpTo > 0 {
+			band = math.Min(remaining, tier.UpTo-lower)
+			lower = tier.UpTo
+		}
+
+		fee += band * tier.PerShare
+		remaining -= band
+	}
+
+	fee = math.Max(fee, c.Minimum)
+
+	if c.Maximum > 0 {
+		fee = math.Min(fee, math.Abs(quantity*price)*c.Maximum)
+	}
+
+	return fee
+}
+
+// NoSlippage fills at the model price
+type NoSlippage struct{}
+
+func (NoSlippage) Apply(direction domain.TradeDirection, price float64, history []domain.Bar) float64 {
+	return price
+}
+
+// FixedBpsSlippage moves every fill a fixed number of basis points
+type FixedBpsSlippage struct {
+	Bps float64
+}
+
+func (s FixedBpsSlippage) Apply(direction domain.TradeDirection, price float64, history []domain.Bar) float64 {
+	return adverse(direction, price, s.Bps/10000)
+}
+
+// VolatilitySlippage scales slippage with the standard deviation of recent
+// close-to-close returns, so fills get worse in turbulent markets
+type VolatilitySlippage struct {
+	Multiplier float64
+	Lookback   int
+}
+
+func (s VolatilitySlippage) Apply(direction domain.TradeDirection, price float64, history []domain.Bar) float64 {
+	lookback := s.Lookback
+	if lookback <= 0 {
+		lookback = 20
+	}
+
+	return adverse(direction, price, s.Multiplier*realizedVolatility(history, lookback))
+}
+
+// adverse moves the price up for buys and down for sells by fraction
+func adverse(direction domain.TradeDirection, price, fraction float64) float64 {
+	if direction == domain.TradeDirectionBuy || direction == domain.TradeDirectionCover {
+		return price * (1 + fraction)
+	}
+	return price * (1 - fraction)
+}
+
+// realizedVolatility is the standard deviation of the last lookback bar to bar returns
+func realizedVolatility(bars []domain.Bar, lookback int) float64 {
+	if len(bars) > lookback+1 {
+		bars = bars[len(bars)-lookback-1:]
+	}
+	if len(bars) < 3 {
+		return 0
+	}
+
+	returns := make([]float64, 0, len(bars)-1)
+	for i := 1; i < len(bars); i++ {
+		if bars[i-1].Close > 0 {
+			returns = append(returns, bars[i].Close/bars[i-1].Close-1)
+		}
+	}
+	if len(returns) < 2 {
+		return 0
+	}
+
+	mean := 0.0
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+
+	variance := 0.0
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	variance /= float64(len(returns) - 1)
+
+	return math.Sqrt(variance)
+}
+
+// NewCommissionModel maps the commission settings of a backtest to a model
+func NewCommissionModel(model domain.CommissionModel, rate float64) CommissionModel {
+	switch model {
+	case domain.CommissionModelFixed:
+		return FixedCommission{PerTrade: rate}
+	case domain.CommissionModelPerShare:
+		return PerShareCommission{PerShare: rate}
+	case domain.CommissionModelBps:
+		return BpsCommission{Bps: rate}
+	case domain.CommissionModelTiered:
+		return DefaultTieredCommission()
+	default:
+		return NoCommission{}
+	}
+}
+
+// NewSlippageModel maps the slippage settings of a backtest to a model
+func NewSlippageModel(model domain.SlippageModel, rate float64) SlippageModel {
+	switch model {
+	case domain.SlippageModelFixedBps:
+		return FixedBpsSlippage{Bps: rate}
+	case domain.SlippageModelVolatility:
+		return VolatilitySlippage{Multiplier: rate}
+	default:
+		return NoSlippage{}
+	}
+}
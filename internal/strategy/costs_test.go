@@ -0,0 +1,68 @@
+package strategy
+
+import (
+	"math"
+	"testing"
+
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+)
+
+func TestCommissionModels(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    CommissionModel
+		quantity float64
+		price    float64
+		expected float64
+	}{
+		{"none", NoCommission{}, 100, 50, 0},
+		{"fixed", FixedCommission{PerTrade: 4.95}, 100, 50, 4.95},
+		{"per share", PerShareCommission{PerShare: 0.005}, 1000, 50, 5},
+		{"per share minimum", PerShareCommission{PerShare: 0.005, Minimum: 1}, 10, 50, 1},
+		{"bps", BpsCommission{Bps: 10}, 100, 50, 5},
+		{"tiered first band", DefaultTieredCommission(), 200, 50, 0.7},
+		{"tiered across bands", DefaultTieredCommission(), 1000, 50, 300*0.0035 + 700*0.0020},
+		{"tiered minimum", DefaultTieredCommission(), 10, 50, 0.35},
+		{"tiered capped", DefaultTieredCommission(), 10, 1, 0.1},
+	}
+
+	for _, tt := range tests {
+		got := tt.model.Commission(tt.quantity, tt.price)
+		if math.Abs(got-tt.expected) > 1e-9 {
+			t.Errorf("%s: expected commission %.4f, got %.4f", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestFixedBpsSlippage(t *testing.T) {
+	slippage := FixedBpsSlippage{Bps: 50}
+
+	if got := slippage.Apply(domain.TradeDirectionBuy, 100, nil); math.Abs(got-100.5) > 1e-9 {
+		t.Errorf("Expected buy fill at 100.50, got %.4f", got)
+	}
+	if got := slippage.Apply(domain.TradeDirectionSell, 100, nil); math.Abs(got-99.5) > 1e-9 {
+		t.Errorf("Expected sell fill at 99.50, got %.4f", got)
+	}
+}
+
+func TestVolatilitySlippage(t *testing.T) {
+	calm := []domain.Bar{{Close: 100}, {Close: 100.1}, {Close: 100}, {Close: 100.1}}
+	wild := []domain.Bar{{Close: 100}, {Close: 110}, {Close: 95}, {Close: 108}}
+
+	slippage := VolatilitySlippage{Multiplier: 0.5, Lookback: 10}
+
+	calmFill := slippage.Apply(domain.TradeDirectionBuy, 100, calm)
+	wildFill := slippage.Apply(domain.TradeDirectionBuy, 100, wild)
+
+	if calmFill <= 100 || wildFill <= calmFill {
+		t.Errorf("Expected slippage to grow with volatility, calm=%.4f wild=%.4f", calmFill, wildFill)
+	}
+
+	if got := slippage.Apply(domain.TradeDirectionSell, 100, wild); got >= 100 {
+		t.Errorf("Expected sell slippage below 100, got %.4f", got)
+	}
+
+	if got := slippage.Apply(domain.TradeDirectionBuy, 100, calm[:2]); got != 100 {
+		t.Errorf("Expected no slippage without enough history, got %.4f", got)
+	}
+}
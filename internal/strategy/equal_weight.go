@@ -0,0 +1,30 @@
+package strategy
+
+// EqualWeight holds every symbol of the universe with the same weight
+type EqualWeight struct{}
+
+func NewEqualWeight() *EqualWeight {
+	return &EqualWeight{}
+}
+
+func (s *EqualWeight) Name() string {
+	return "Equal Weight"
+}
+
+func (s *EqualWeight) Weights(ctx *Context) (map[string]float64, error) {
+	weights := make(map[string]float64, len(ctx.Universe))
+
+	// symbols without data yet can't be priced, spread over the rest
+	var tradable []string
+	for _, symbol := range ctx.Universe {
+		if _, ok := ctx.Bars[symbol]; ok {
+			tradable = append(tradable, symbol)
+		}
+	}
+
+	for _, symbol := range tradable {
+		weights[symbol] = 1 / float64(len(tradable))
+	}
+
+	return weights, nil
+}
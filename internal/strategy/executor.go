@@ -3,6 +3,8 @@ package strategy
 import (
 	"context"
 	"fmt"
+	"math"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,110 +12,807 @@ import (
 	"github.com/wreckitral/distributed-backtesting-platform/internal/marketdata"
 )
 
+// quantities below this are treated as zero to absorb float rounding
+const shareEpsilon = 1e-9
+
+// bars per year used to turn annual rates into per bar rates
+const tradingDaysPerYear = 252
+
 type Executor struct {
 	strategy    Strategy
+	weights     WeightStrategy
+	rebalance   RebalanceConfig
 	provider    marketdata.Provider
 	initialCash float64
+	fillModel   FillModel
+	commission  CommissionModel
+	slippage    SlippageModel
+	sizer       Sizer
+	margin      *MarginConfig
+	actions     marketdata.CorporateActionSource
+	reinvest    bool
+}
+
+// MarginConfig enables short selling. margins are fractions of the short
+// notional and BorrowRate is the annual stock borrow fee
+type MarginConfig struct {
+	InitialMargin     float64
+	MaintenanceMargin float64
+	BorrowRate        float64
+}
+
+// ExecutorOption customizes how the executor simulates a backtest
+type ExecutorOption func(*Executor)
+
+// WithFillModel sets the fill model, defaults to NextBarOpen
+func WithFillModel(model FillModel) ExecutorOption {
+	return func(e *Executor) {
+		if model != nil {
+			e.fillModel = model
+		}
+	}
+}
+
+// WithCommission sets the commission model, defaults to no commission
+func WithCommission(model CommissionModel) ExecutorOption {
+	return func(e *Executor) {
+		if model != nil {
+			e.commission = model
+		}
+	}
+}
+
+// WithSlippage sets the slippage model, defaults to no slippage
+func WithSlippage(model SlippageModel) ExecutorOption {
+	return func(e *Executor) {
+		if model != nil {
+			e.slippage = model
+		}
+	}
+}
+
+// WithSizer sets how unsized orders are sized, defaults to AllIn
+func WithSizer(sizer Sizer) ExecutorOption {
+	return func(e *Executor) {
+		if sizer != nil {
+			e.sizer = sizer
+		}
+	}
 }
 
-func NewExecutor(strategy Strategy, provider marketdata.Provider, initialCash float64) *Executor {
-	return &Executor{
+// WithShortSelling allows positions to go short under the given margin rules,
+// without it sells never take a position below flat
+func WithShortSelling(margin MarginConfig) ExecutorOption {
+	return func(e *Executor) {
+		e.margin = &margin
+	}
+}
+
+func NewExecutor(strategy Strategy, provider marketdata.Provider, initialCash float64, opts ...ExecutorOption) *Executor {
+	e := &Executor{
 		strategy:    strategy,
 		provider:    provider,
 		initialCash: initialCash,
+		fillModel:   NextBarOpen(),
+		commission:  NoCommission{},
+		slippage:    NoSlippage{},
+		sizer:       AllIn{},
+	}
+
+	for _, opt := range opts {
+		opt(e)
 	}
+
+	return e
+}
+
+// Result is everything a run produces
+type Result struct {
+	Trades      []domain.Trade
+	EquityCurve []domain.EquityCurve    // one point per bar
+	Bars        map[string][]domain.Bar // every bar traded, per symbol in time order
+	Positions   map[string]*Position    // still open at the end of the run
+}
+
+// Liquidation closes the positions still open at the end of the run at their
+// last close without commission, in symbol order. the trades realize the P&L
+// the last equity point already holds, for callers that cut a run into
+// pieces and need the trades to add up to the curve
+func (r *Result) Liquidation() []domain.Trade {
+	if len(r.EquityCurve) == 0 {
+		return nil
+	}
+	timestamp := r.EquityCurve[len(r.EquityCurve)-1].Timestamp
+
+	symbols := make([]string, 0, len(r.Positions))
+	for symbol, position := range r.Positions {
+		if position.IsOpen() && len(r.Bars[symbol]) > 0 {
+			symbols = append(symbols, symbol)
+		}
+	}
+	sort.Strings(symbols)
+
+	trades := make([]domain.Trade, 0, len(symbols))
+	for _, symbol := range symbols {
+		position := r.Positions[symbol]
+		bars := r.Bars[symbol]
+		price := bars[len(bars)-1].Close
+
+		trade := domain.Trade{
+			ID:        uuid.New(),
+			Symbol:    symbol,
+			Quantity:  math.Abs(position.Shares),
+			Price:     price,
+			BorrowFee: position.BorrowFees,
+			Dividends: position.Dividends,
+			Timestamp: timestamp,
+			PnL:       position.ProfitLoss(price) - position.EntryCommission - position.BorrowFees + position.Dividends,
+		}
+		trade.Direction = domain.TradeDirectionSell
+		if position.IsShort() {
+			trade.Direction = domain.TradeDirectionCover
+		}
+		trades = append(trades, trade)
+	}
+	return trades
+}
+
+// runState is the account being simulated during a single run, every symbol
+// of the universe trades against the same cash
+type runState struct {
+	symbols    []string
+	positions  map[string]*Position
+	histories  map[string][]domain.Bar // bars seen so far per symbol
+	indicators indicatorSet            // registered by the strategy, fed every bar
+	marks      map[string]float64      // last close used to value each position
+	cash       float64
+	trades     []domain.Trade
+	curve      []domain.EquityCurve
+	actions    map[string][]domain.CorporateAction // splits and dividends not yet applied
+
+	// order book and the events not yet reported to the strategy
+	orders    []*Order
+	fills     []Fill
+	cancelled []Order
 }
 
 func (e *Executor) Run(ctx context.Context, symbol string, start, end time.Time) ([]domain.Trade, error) {
-	bars, err := e.provider.GetBars(ctx, symbol, start, end)
+	result, err := e.Simulate(ctx, symbol, start, end)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get bars: %w", err)
+		return nil, err
 	}
+	return result.Trades, nil
+}
 
-	if len(bars) == 0 {
-		return nil, fmt.Errorf("no bars found for %s between %s and %s", symbol, start, end)
+// Simulate runs the strategy like Run and also marks the account to market
+// at the close of every bar
+func (e *Executor) Simulate(ctx context.Context, symbol string, start, end time.Time) (*Result, error) {
+	return e.SimulatePortfolio(ctx, []string{symbol}, start, end)
+}
+
+// SimulatePortfolio runs the strategy over a universe of symbols sharing one
+// cash account. bars are aligned on time and at every timestamp the strategy
+// is called once per symbol that has a bar, in universe order
+func (e *Executor) SimulatePortfolio(ctx context.Context, symbols []string, start, end time.Time) (*Result, error) {
+	if len(symbols) == 0 {
+		return nil, fmt.Errorf("no symbols to backtest")
+	}
+
+	slices, err := marketdata.GetAlignedBars(ctx, e.provider, symbols, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bars: %w", err)
 	}
 
 	// initialize tracking variables
-	var position *Position = nil
-	cash := e.initialCash
-	trades := []domain.Trade{}
-
-	for i, bar := range bars {
-		strategyCtx := &Context{
-			Symbol:          symbol,
-			CurrentBar:      bar,
-			HistoricalBars:  bars[0:i], // all bars before today
-			CurrentPosition: position,
-			Cash:            cash,
-		}
-
-		signal, err := e.strategy.Generate(strategyCtx)
-		if err != nil {
-			return nil, fmt.Errorf("strategy error on %s: %w", bar.Timestamp, err)
-		}
-
-		switch signal {
-		case SignalBuy:
-			if position == nil || !position.IsOpen() {
-				if cash >= bar.Close {
-					shares := cash / bar.Close
-
-					if shares > 0 {
-						trade := domain.Trade{
-							ID:            uuid.New(),
-							BacktestID:    uuid.Nil,
-							Symbol:        symbol,
-							Direction:     domain.TradeDirectionBuy,
-							Quantity:      shares,
-							Price:         bar.Close,
-							Commission:    0,
-							Timestamp:     bar.Timestamp,
-							PnL:           0,
-							CumulativePnL: 0,
-						}
-						trades = append(trades, trade)
-
-						position = &Position{
-							Symbol:     symbol,
-							Shares:     shares,
-							EntryPrice: bar.Close,
-							EntryTime:  bar.Timestamp,
-						}
-
-						cash -= shares * bar.Close
-					}
+	state := &runState{
+		symbols:    symbols,
+		positions:  make(map[string]*Position),
+		histories:  make(map[string][]domain.Bar, len(symbols)),
+		indicators: make(indicatorSet),
+		marks:      make(map[string]float64, len(symbols)),
+		cash:       e.initialCash,
+		trades:     []domain.Trade{},
+		curve:      make([]domain.EquityCurve, 0, len(slices)),
+	}
+
+	if err := e.loadCorporateActions(ctx, state, start, end); err != nil {
+		return nil, err
+	}
+
+	var prev time.Time
+	for _, slice := range slices {
+		for symbol, bar := range slice.Bars {
+			state.histories[symbol] = append(state.histories[symbol], bar)
+			state.indicators.update(symbol, bar)
+		}
+
+		// splits and dividends take effect at the open, before any order trades
+		e.applyCorporateActions(state, slice)
+
+		// orders placed on earlier bars trade on this one
+		e.matchOrders(state, slice)
+
+		for symbol, bar := range slice.Bars {
+			state.marks[symbol] = bar.Close
+		}
+
+		e.accrueBorrowFees(state, slice)
+		e.checkMargin(state, slice)
+
+		if e.weights != nil {
+			if e.rebalance.due(prev, slice.Timestamp) {
+				if err := e.rebalanceTo(state, slice); err != nil {
+					return nil, fmt.Errorf("strategy error on %s: %w", slice.Timestamp, err)
 				}
 			}
 
-		case SignalSell:
-			if position != nil && position.IsOpen() {
-				sellValue := position.Shares * bar.Close
-				buyValue := position.CostBasis()
-				pnl := sellValue - buyValue
-
-				trade := domain.Trade{
-					ID:            uuid.New(),
-					BacktestID:    uuid.Nil,
-					Symbol:        symbol,
-					Direction:     domain.TradeDirectionSell,
-					Quantity:      position.Shares,
-					Price:         bar.Close,
-					Commission:    0,
-					Timestamp:     bar.Timestamp,
-					PnL:           pnl,
-					CumulativePnL: 0,
+			state.mark(slice.Timestamp)
+			prev = slice.Timestamp
+			continue
+		}
+
+		// the views of the account are shared by the symbols of the slice
+		bars := state.latestBars()
+		var view *accountView
+
+		for _, symbol := range symbols {
+			bar, ok := slice.Bars[symbol]
+			if !ok {
+				continue
+			}
+
+			history := state.histories[symbol]
+			position := state.positions[symbol]
+			fills, cancelled := state.takeEvents(symbol)
+			if view == nil {
+				view = state.view()
+			}
+
+			strategyCtx := &Context{
+				Symbol:          symbol,
+				Timestamp:       bar.Timestamp,
+				CurrentBar:      bar,
+				HistoricalBars:  history[:len(history)-1], // all bars before today
+				CurrentPosition: view.positions[symbol],
+				Cash:            view.cash,
+				Equity:          view.equity,
+				Universe:        symbols,
+				Bars:            bars,
+				Positions:       view.positions,
+				Fills:           fills,
+				Cancellations:   cancelled,
+				pending:         view.orders,
+				histories:       state.histories,
+				indicators:      state.indicators,
+			}
+
+			signal, err := e.strategy.Generate(strategyCtx)
+			if err != nil {
+				return nil, fmt.Errorf("strategy error on %s for %s: %w", bar.Timestamp, symbol, err)
+			}
+
+			if order := signalOrder(signal, position, e.margin != nil, strategyCtx.quantity); order != nil {
+				if _, err := strategyCtx.SubmitOrder(*order); err != nil {
+					return nil, fmt.Errorf("invalid order on %s for %s: %w", bar.Timestamp, symbol, err)
 				}
-				trades = append(trades, trade)
+			}
+
+			e.acceptOrders(state, strategyCtx, slice)
+			if len(strategyCtx.submitted) > 0 || len(strategyCtx.cancels) > 0 {
+				view = nil // the book changed, orders may have filled
+			}
+		}
+
+		state.mark(slice.Timestamp)
+		prev = slice.Timestamp
+	}
+
+	return &Result{Trades: state.trades, EquityCurve: state.curve, Bars: state.histories, Positions: state.openPositions()}, nil
+}
+
+// signalOrder turns a signal into a market order for quantity shares, zero
+// leaves sizing to the executor. a buy covers a short or opens a long, a sell
+// closes a long or opens a short when shorting is allowed
+func signalOrder(signal Signal, position *Position, allowShort bool, quantity float64) *Order {
+	long := position != nil && position.IsLong()
+	short := position != nil && position.IsShort()
+
+	switch signal {
+	case SignalBuy:
+		if !long {
+			order := NewMarketOrder(OrderSideBuy, quantity)
+			return &order
+		}
+	case SignalSell:
+		if long || !short && allowShort {
+			order := NewMarketOrder(OrderSideSell, quantity)
+			return &order
+		}
+	}
+
+	return nil
+}
 
-				cash += position.Shares * bar.Close
-				position = nil
+// acceptOrders moves the orders submitted through the context onto the book and
+// applies cancellations. with a same bar fill model market orders fill right away
+func (e *Executor) acceptOrders(state *runState, strategyCtx *Context, slice marketdata.TimeSlice) {
+	for _, submitted := range strategyCtx.submitted {
+		order := submitted
+		if _, ok := state.histories[order.Symbol]; !ok {
+			state.cancel(&order, "symbol not in universe")
+			continue
+		}
+
+		if order.Type == OrderTypeTrailingStop {
+			order.extreme = state.marks[order.Symbol]
+		}
+		state.orders = append(state.orders, &order)
+	}
+
+	for _, id := range strategyCtx.cancels {
+		for _, order := range state.orders {
+			if order.ID == id && order.IsOpen() {
+				state.cancel(order, "cancelled by strategy")
 			}
+		}
+	}
 
-		case SignalHold:
-			// do nothing
+	if !e.fillModel.NextBar() {
+		for _, order := range state.orders {
+			bar, ok := slice.Bars[order.Symbol]
+			if ok && order.IsOpen() && order.Type == OrderTypeMarket {
+				e.fill(state, order, e.fillModel.Price(bar))
+			}
 		}
 	}
 
-	return trades, nil
+	state.prune()
+}
+
+// matchOrders runs every open order against the bar of its symbol, orders on
+// symbols without a bar at this timestamp wait
+func (e *Executor) matchOrders(state *runState, slice marketdata.TimeSlice) {
+	for _, order := range state.orders {
+		bar, ok := slice.Bars[order.Symbol]
+		if !ok || !order.IsOpen() {
+			continue
+		}
+
+		if order.Type == OrderTypeMarket {
+			e.fill(state, order, e.fillModel.Price(bar))
+			continue
+		}
+
+		if price, ok := order.match(bar); ok {
+			e.fill(state, order, price)
+			continue
+		}
+
+		switch order.TimeInForce {
+		case TimeInForceDay:
+			state.cancel(order, "expired at end of day")
+		case TimeInForceIOC:
+			state.cancel(order, "not immediately fillable")
+		}
+	}
+
+	state.prune()
+}
+
+// fill executes an order at price on the latest bar of its symbol, applying
+// slippage and commission. an order that crosses flat is booked as a closing
+// and an opening trade. orders that can't be executed are cancelled with the reason
+func (e *Executor) fill(state *runState, order *Order, price float64) {
+	history := state.histories[order.Symbol]
+	bar := history[len(history)-1]
+
+	var direction domain.TradeDirection = domain.TradeDirectionBuy
+	if order.Side == OrderSideSell {
+		direction = domain.TradeDirectionSell
+	}
+
+	fillPrice := price
+	if order.slips() {
+		fillPrice = e.slippage.Apply(direction, price, history)
+	}
+
+	held := 0.0
+	if position := state.positions[order.Symbol]; position != nil {
+		held = position.Shares
+	}
+
+	// shares that reduce the current position and shares that open a new one,
+	// a zero quantity closes what is held or else asks the sizer
+	closing, opening := 0.0, order.Quantity
+	if order.Side == OrderSideBuy && held < 0 || order.Side == OrderSideSell && held > 0 {
+		closing = math.Abs(held)
+		opening = 0
+		if order.Quantity > 0 {
+			closing = math.Min(order.Quantity, closing)
+			opening = order.Quantity - closing
+		}
+	} else if opening == 0 {
+		opening = e.sizer.Size(SizingInput{
+			Price:   fillPrice,
+			Equity:  state.equityAt(order.Symbol, fillPrice),
+			History: history,
+			Trades:  state.trades,
+		})
+
+		if opening <= shareEpsilon || math.IsNaN(opening) {
+			state.cancel(order, "sized to zero shares")
+			return
+		}
+	}
+
+	filled, commission := 0.0, 0.0
+
+	if closing > 0 {
+		commission += e.closePosition(state, order, closing, fillPrice, price, bar.Timestamp)
+		filled += closing
+	}
+
+	if opening > 0 {
+		shares, fee := e.openPosition(state, order, opening, fillPrice, price, bar.Timestamp)
+		commission += fee
+		filled += shares
+	}
+
+	if filled <= shareEpsilon {
+		switch {
+		case order.Side == OrderSideBuy:
+			state.cancel(order, "insufficient cash")
+		case e.margin == nil:
+			state.cancel(order, "no position to sell")
+		default:
+			state.cancel(order, "insufficient margin")
+		}
+		return
+	}
+
+	state.complete(order, filled, fillPrice, commission, bar.Timestamp)
+}
+
+// closePosition realizes P&L on shares of the position in the order symbol and
+// returns the commission paid. P&L is net of commission on both legs and borrow
+// fees and includes dividends
+func (e *Executor) closePosition(state *runState, order *Order, shares, fillPrice, price float64, timestamp time.Time) float64 {
+	position := state.positions[order.Symbol]
+	commission := e.commission.Commission(shares, fillPrice)
+	entryCommission, borrowFees, dividends := position.reduce(shares)
+
+	trade := domain.Trade{
+		ID:            uuid.New(),
+		BacktestID:    uuid.Nil,
+		Symbol:        position.Symbol,
+		Quantity:      shares,
+		Price:         fillPrice,
+		Commission:    commission,
+		Slippage:      shares * math.Abs(fillPrice-price),
+		BorrowFee:     borrowFees,
+		Dividends:     dividends,
+		Timestamp:     timestamp,
+		CumulativePnL: 0,
+	}
+
+	if order.Side == OrderSideSell {
+		trade.Direction = domain.TradeDirectionSell
+		trade.PnL = shares*(fillPrice-position.EntryPrice) - entryCommission - commission + dividends
+		state.cash += shares*fillPrice - commission
+	} else {
+		trade.Direction = domain.TradeDirectionCover
+		trade.PnL = shares*(position.EntryPrice-fillPrice) - entryCommission - commission - borrowFees + dividends
+		state.cash -= shares*fillPrice + commission
+	}
+	state.trades = append(state.trades, trade)
+
+	if math.Abs(position.Shares) <= shareEpsilon {
+		delete(state.positions, order.Symbol)
+	}
+
+	return commission
+}
+
+// openPosition opens or adds to a position with up to shares, limited by cash
+// for longs and by the initial margin for shorts. returns shares and commission
+func (e *Executor) openPosition(state *runState, order *Order, shares, fillPrice, price float64, timestamp time.Time) (float64, float64) {
+	var direction domain.TradeDirection
+	var signed float64
+
+	if order.Side == OrderSideBuy {
+		direction = domain.TradeDirectionBuy
+		shares = math.Min(shares, e.affordableShares(state.cash, fillPrice))
+		signed = shares
+	} else {
+		if e.margin == nil {
+			return 0, 0
+		}
+		direction = domain.TradeDirectionShort
+		shares = math.Min(shares, e.shortableShares(state, order.Symbol, fillPrice))
+		signed = -shares
+	}
+
+	if shares <= shareEpsilon {
+		return 0, 0
+	}
+
+	commission := e.commission.Commission(shares, fillPrice)
+	state.trades = append(state.trades, domain.Trade{
+		ID:            uuid.New(),
+		BacktestID:    uuid.Nil,
+		Symbol:        order.Symbol,
+		Direction:     direction,
+		Quantity:      shares,
+		Price:         fillPrice,
+		Commission:    commission,
+		Slippage:      shares * math.Abs(fillPrice-price),
+		Timestamp:     timestamp,
+		PnL:           0,
+		CumulativePnL: 0,
+	})
+
+	position := state.positions[order.Symbol]
+	if position == nil {
+		position = &Position{
+			Symbol:    order.Symbol,
+			EntryTime: timestamp,
+		}
+		state.positions[order.Symbol] = position
+	}
+	position.add(signed, fillPrice, commission)
+	state.cash -= signed*fillPrice + commission
+
+	return shares, commission
+}
+
+// shortableShares is how many more shares of symbol can be sold short at
+// price. an unsized short uses the account equity as notional, never more
+// than the initial margin allows across every short position
+func (e *Executor) shortableShares(state *runState, symbol string, price float64) float64 {
+	if price <= 0 {
+		return 0
+	}
+
+	equity := state.equityAt(symbol, price)
+	shortNotional := 0.0
+	for held, position := range state.positions {
+		if !position.IsShort() {
+			continue
+		}
+		mark := state.marks[held]
+		if held == symbol {
+			mark = price
+		}
+		shortNotional -= position.Value(mark)
+	}
+
+	limit := equity - shortNotional
+	if e.margin.InitialMargin > 0 {
+		limit = math.Min(limit, equity/e.margin.InitialMargin-shortNotional)
+	}
+
+	return math.Max(limit/price, 0)
+}
+
+// accrueBorrowFees charges one bar of borrow fees on short positions with a bar
+func (e *Executor) accrueBorrowFees(state *runState, slice marketdata.TimeSlice) {
+	if e.margin == nil {
+		return
+	}
+
+	for symbol, bar := range slice.Bars {
+		position := state.positions[symbol]
+		if position == nil || !position.IsShort() {
+			continue
+		}
+
+		fee := -position.Value(bar.Close) * e.margin.BorrowRate / tradingDaysPerYear
+		position.BorrowFees += fee
+		state.cash -= fee
+	}
+}
+
+// checkMargin liquidates short positions at the close when equity falls below
+// the maintenance margin of all shorts. only symbols with a bar can be covered
+func (e *Executor) checkMargin(state *runState, slice marketdata.TimeSlice) {
+	if e.margin == nil {
+		return
+	}
+
+	shortNotional := 0.0
+	for symbol, position := range state.positions {
+		if position.IsShort() {
+			shortNotional -= position.Value(state.marks[symbol])
+		}
+	}
+
+	if shortNotional == 0 || state.equity() >= shortNotional*e.margin.MaintenanceMargin {
+		return
+	}
+
+	for _, symbol := range state.symbols {
+		bar, ok := slice.Bars[symbol]
+		position := state.positions[symbol]
+		if !ok || position == nil || !position.IsShort() {
+			continue
+		}
+
+		order := NewMarketOrder(OrderSideBuy, -position.Shares)
+		order.ID = uuid.New()
+		order.Symbol = symbol
+		order.SubmittedAt = bar.Timestamp
+		order.Reason = "margin call"
+
+		e.fill(state, &order, bar.Close)
+	}
+}
+
+// affordableShares is the largest quantity whose cost plus commission fits in cash
+func (e *Executor) affordableShares(cash, price float64) float64 {
+	if price <= 0 || cash < price {
+		return 0
+	}
+
+	shares := cash / price
+	for i := 0; i < 10; i++ {
+		commission := e.commission.Commission(shares, price)
+		if shares*price+commission <= cash {
+			return shares
+		}
+		shares = (cash - commission) / price
+	}
+
+	// commission schedules with big jumps may not converge, shave the rest
+	for shares > 0 && shares*price+e.commission.Commission(shares, price) > cash {
+		shares *= 0.999
+	}
+
+	return math.Max(shares, 0)
+}
+
+// equity marks the account to market at the last known closes
+func (s *runState) equity() float64 {
+	return s.equityAt("", 0)
+}
+
+// equityAt marks the account to market valuing symbol at price
+func (s *runState) equityAt(symbol string, price float64) float64 {
+	total := s.cash
+	for held, position := range s.positions {
+		mark := s.marks[held]
+		if held == symbol {
+			mark = price
+		}
+		total += position.Value(mark)
+	}
+	return total
+}
+
+// mark records the account value at the close of timestamp
+func (s *runState) mark(timestamp time.Time) {
+	point := domain.EquityCurve{
+		Timestamp: timestamp,
+		Equity:    s.equity(),
+		Cash:      s.cash,
+	}
+
+	gross := 0.0
+	for symbol, position := range s.positions {
+		value := position.Value(s.marks[symbol])
+		point.PositionValue += value
+		gross += math.Abs(value)
+	}
+
+	if point.Equity != 0 {
+		point.GrossExposure = gross / point.Equity
+		point.NetExposure = point.PositionValue / point.Equity
+	}
+
+	s.curve = append(s.curve, point)
+}
+
+// accountView is the account as the strategy sees it between two changes
+// of the book. building it is O(universe), so it is built once per slice
+// and rebuilt only after a strategy call submitted or cancelled orders,
+// instead of once per symbol
+type accountView struct {
+	cash      float64
+	equity    float64
+	positions map[string]*Position
+	orders    []Order
+}
+
+func (s *runState) view() *accountView {
+	return &accountView{
+		cash:      s.cash,
+		equity:    s.equity(),
+		positions: s.openPositions(),
+		orders:    s.openOrders(),
+	}
+}
+
+// latestBars is the most recent bar of every symbol seen so far
+func (s *runState) latestBars() map[string]domain.Bar {
+	bars := make(map[string]domain.Bar, len(s.histories))
+	for symbol, history := range s.histories {
+		bars[symbol] = history[len(history)-1]
+	}
+	return bars
+}
+
+// openPositions copies the open positions, strategies get the copies so
+// they cannot change the positions the account is kept with
+func (s *runState) openPositions() map[string]*Position {
+	positions := make(map[string]*Position, len(s.positions))
+	for symbol, position := range s.positions {
+		positions[symbol] = position.copy()
+	}
+	return positions
+}
+
+// takeEvents removes and returns the fills and cancellations of symbol, or of
+// every symbol when it is empty. orders rejected for a symbol outside the
+// universe go to the first caller
+func (s *runState) takeEvents(symbol string) ([]Fill, []Order) {
+	var fills []Fill
+	keptFills := s.fills[:0]
+	for _, fill := range s.fills {
+		if symbol == "" || fill.Symbol == symbol {
+			fills = append(fills, fill)
+		} else {
+			keptFills = append(keptFills, fill)
+		}
+	}
+	s.fills = keptFills
+
+	var cancelled []Order
+	keptCancelled := s.cancelled[:0]
+	for _, order := range s.cancelled {
+		if _, known := s.histories[order.Symbol]; symbol == "" || order.Symbol == symbol || !known {
+			cancelled = append(cancelled, order)
+		} else {
+			keptCancelled = append(keptCancelled, order)
+		}
+	}
+	s.cancelled = keptCancelled
+
+	return fills, cancelled
+}
+
+func (s *runState) complete(order *Order, shares, price, commission float64, timestamp time.Time) {
+	order.Status = OrderStatusFilled
+	s.fills = append(s.fills, Fill{
+		OrderID:    order.ID,
+		Symbol:     order.Symbol,
+		Side:       order.Side,
+		Quantity:   shares,
+		Price:      price,
+		Commission: commission,
+		Timestamp:  timestamp,
+	})
+}
+
+func (s *runState) cancel(order *Order, reason string) {
+	order.Status = OrderStatusCancelled
+	order.Reason = reason
+	s.cancelled = append(s.cancelled, *order)
+}
+
+// prune drops filled and cancelled orders from the book
+func (s *runState) prune() {
+	open := s.orders[:0]
+	for _, order := range s.orders {
+		if order.IsOpen() {
+			open = append(open, order)
+		}
+	}
+	s.orders = open
+}
+
+func (s *runState) openOrders() []Order {
+	orders := make([]Order, 0, len(s.orders))
+	for _, order := range s.orders {
+		orders = append(orders, *order)
+	}
+	return orders
 }
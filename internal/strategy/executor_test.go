@@ -2,6 +2,9 @@ package strategy
 
 import (
 	"context"
+	"fmt"
+	"math"
+	"reflect"
 	"testing"
 	"time"
 
@@ -54,6 +57,42 @@ func TestExecutorBuyAndHold(t *testing.T) {
 	}
 }
 
+func TestResultLiquidation(t *testing.T) {
+	provider, err := marketdata.NewCSVProvider("../../data/sample")
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	executor := NewExecutor(NewBuyHold(), provider, 10000.0, WithCommission(PerShareCommission{PerShare: 0.01}))
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	result, err := executor.Simulate(context.Background(), "AAPL", start, end)
+	if err != nil {
+		t.Fatalf("Executor failed: %v", err)
+	}
+
+	exits := result.Liquidation()
+	if len(exits) != 1 {
+		t.Fatalf("Expected one closing trade for the held position, got %d", len(exits))
+	}
+
+	bars := result.Bars["AAPL"]
+	last := result.EquityCurve[len(result.EquityCurve)-1]
+	exit := exits[0]
+	if exit.Direction != domain.TradeDirectionSell || exit.Price != bars[len(bars)-1].Close || !exit.Timestamp.Equal(last.Timestamp) {
+		t.Errorf("Expected a sell at the last close on %s, got %+v", last.Timestamp, exit)
+	}
+	if exit.Quantity != result.Trades[0].Quantity || exit.Commission != 0 {
+		t.Errorf("Expected the %v held shares without commission, got %+v", result.Trades[0].Quantity, exit)
+	}
+
+	// the realized P&L is what the curve shows at the end
+	if gain := last.Equity - 10000.0; math.Abs(exit.PnL-gain) > 1e-6 {
+		t.Errorf("Expected the exit to realize %v, got %v", gain, exit.PnL)
+	}
+}
+
 func TestExecutorSMACrossover(t *testing.T) {
 	provider, err := marketdata.NewCSVProvider("../../data/sample")
 	if err != nil {
@@ -92,3 +131,578 @@ func TestExecutorSMACrossover(t *testing.T) {
 	}
 	t.Logf("Total P&L: $%.2f", totalPnL)
 }
+
+// memoryProvider serves a fixed set of bars without touching the filesystem
+type memoryProvider struct {
+	bars map[string][]domain.Bar
+}
+
+func (p *memoryProvider) GetBars(ctx context.Context, symbol string, start, end time.Time) ([]domain.Bar, error) {
+	filtered := []domain.Bar{}
+	for _, bar := range p.bars[symbol] {
+		if !bar.Timestamp.Before(start) && bar.Timestamp.Before(end) {
+			filtered = append(filtered, bar)
+		}
+	}
+	return filtered, nil
+}
+
+func (p *memoryProvider) GetLatestBar(ctx context.Context, symbol string) (domain.Bar, error) {
+	bars := p.bars[symbol]
+	if len(bars) == 0 {
+		return domain.Bar{}, fmt.Errorf("no bars found for symbol %s", symbol)
+	}
+	return bars[len(bars)-1], nil
+}
+
+func (p *memoryProvider) ListSymbols(ctx context.Context) ([]string, error) {
+	symbols := make([]string, 0, len(p.bars))
+	for symbol := range p.bars {
+		symbols = append(symbols, symbol)
+	}
+	return symbols, nil
+}
+
+// newMemoryProvider builds daily bars from (open, high, low, close) rows
+func newMemoryProvider(symbol string, rows [][4]float64) *memoryProvider {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	bars := make([]domain.Bar, len(rows))
+	for i, r := range rows {
+		bars[i] = domain.Bar{
+			Symbol:    symbol,
+			Timestamp: start.AddDate(0, 0, i),
+			Open:      r[0],
+			High:      r[1],
+			Low:       r[2],
+			Close:     r[3],
+			Volume:    1000,
+		}
+	}
+	return &memoryProvider{bars: map[string][]domain.Bar{symbol: bars}}
+}
+
+// scriptedStrategy replays a fixed signal per bar index
+type scriptedStrategy struct {
+	signals map[int]Signal
+}
+
+func (s *scriptedStrategy) Name() string {
+	return "Scripted"
+}
+
+func (s *scriptedStrategy) Generate(ctx *Context) (Signal, error) {
+	return s.signals[ctx.BarCount()-1], nil
+}
+
+func TestExecutorFillModels(t *testing.T) {
+	rows := [][4]float64{
+		{100, 102, 98, 101},
+		{102, 106, 100, 104},
+		{105, 110, 103, 108},
+		{107, 109, 101, 102},
+	}
+	provider := newMemoryProvider("TEST", rows)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		model      FillModel
+		buyPrice   float64
+		sellPrice  float64
+		buyBarDay  int
+		sellBarDay int
+	}{
+		{"same bar close", SameBarClose(), 101, 108, 1, 3},
+		{"next bar open", NextBarOpen(), 102, 107, 2, 4},
+		{"next bar vwap", NextBarVWAP(), 103, 104.75, 2, 4},
+		{"next bar close", NextBarClose(), 104, 102, 2, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strat := &scriptedStrategy{signals: map[int]Signal{0: SignalBuy, 2: SignalSell}}
+			executor := NewExecutor(strat, provider, 10000.0, WithFillModel(tt.model))
+
+			trades, err := executor.Run(context.Background(), "TEST", start, end)
+			if err != nil {
+				t.Fatalf("Executor failed: %v", err)
+			}
+
+			if len(trades) != 2 {
+				t.Fatalf("Expected 2 trades, got %d", len(trades))
+			}
+
+			if trades[0].Price != tt.buyPrice {
+				t.Errorf("Expected buy at %.2f, got %.2f", tt.buyPrice, trades[0].Price)
+			}
+			if trades[1].Price != tt.sellPrice {
+				t.Errorf("Expected sell at %.2f, got %.2f", tt.sellPrice, trades[1].Price)
+			}
+			if trades[0].Timestamp.Day() != tt.buyBarDay {
+				t.Errorf("Expected buy on day %d, got %d", tt.buyBarDay, trades[0].Timestamp.Day())
+			}
+			if trades[1].Timestamp.Day() != tt.sellBarDay {
+				t.Errorf("Expected sell on day %d, got %d", tt.sellBarDay, trades[1].Timestamp.Day())
+			}
+		})
+	}
+}
+
+func TestExecutorDropsSignalOnLastBar(t *testing.T) {
+	provider := newMemoryProvider("TEST", [][4]float64{
+		{100, 102, 98, 101},
+		{102, 106, 100, 104},
+	})
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	strat := &scriptedStrategy{signals: map[int]Signal{1: SignalBuy}}
+	trades, err := NewExecutor(strat, provider, 10000.0).Run(context.Background(), "TEST", start, end)
+	if err != nil {
+		t.Fatalf("Executor failed: %v", err)
+	}
+
+	if len(trades) != 0 {
+		t.Errorf("Expected no trades for a signal on the last bar, got %d", len(trades))
+	}
+}
+
+func TestExecutorAppliesCosts(t *testing.T) {
+	provider := newMemoryProvider("TEST", [][4]float64{
+		{100, 100, 100, 100},
+		{100, 100, 100, 100},
+		{110, 110, 110, 110},
+	})
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	strat := &scriptedStrategy{signals: map[int]Signal{0: SignalBuy, 1: SignalSell}}
+	executor := NewExecutor(strat, provider, 10000.0,
+		WithCommission(FixedCommission{PerTrade: 10}),
+		WithSlippage(FixedBpsSlippage{Bps: 100}),
+	)
+
+	trades, err := executor.Run(context.Background(), "TEST", start, end)
+	if err != nil {
+		t.Fatalf("Executor failed: %v", err)
+	}
+	if len(trades) != 2 {
+		t.Fatalf("Expected 2 trades, got %d", len(trades))
+	}
+
+	buy, sell := trades[0], trades[1]
+
+	if buy.Price != 101 {
+		t.Errorf("Expected buy fill at 101 after slippage, got %.4f", buy.Price)
+	}
+	if buy.Commission != 10 || sell.Commission != 10 {
+		t.Errorf("Expected 10 commission per fill, got %.2f and %.2f", buy.Commission, sell.Commission)
+	}
+
+	// the buy must leave room for its own commission
+	if buy.TotalCost() > 10000.0+1e-9 {
+		t.Errorf("Buy cost %.4f exceeds available cash", buy.TotalCost())
+	}
+
+	expectedPnL := buy.Quantity*(sell.Price-buy.Price) - 20
+	if math.Abs(sell.PnL-expectedPnL) > 1e-6 {
+		t.Errorf("Expected P&L %.4f net of costs, got %.4f", expectedPnL, sell.PnL)
+	}
+
+	if math.Abs(buy.Slippage-buy.Quantity) > 1e-6 {
+		t.Errorf("Expected buy slippage cost %.4f, got %.4f", buy.Quantity, buy.Slippage)
+	}
+}
+
+func TestExecutorShortSelling(t *testing.T) {
+	provider := newMemoryProvider("TEST", [][4]float64{
+		{100, 100, 100, 100},
+		{100, 101, 99, 100},
+		{95, 96, 94, 95},
+		{90, 91, 89, 90},
+	})
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	margin := MarginConfig{InitialMargin: 0.5, MaintenanceMargin: 0.3}
+
+	strat := &scriptedStrategy{signals: map[int]Signal{0: SignalSell, 2: SignalBuy}}
+	trades, err := NewExecutor(strat, provider, 10000.0, WithShortSelling(margin)).Run(context.Background(), "TEST", start, end)
+	if err != nil {
+		t.Fatalf("Executor failed: %v", err)
+	}
+	if len(trades) != 2 {
+		t.Fatalf("Expected 2 trades, got %d", len(trades))
+	}
+
+	short, cover := trades[0], trades[1]
+	if short.Direction != domain.TradeDirectionShort || cover.Direction != domain.TradeDirectionCover {
+		t.Fatalf("Expected SHORT then COVER, got %s then %s", short.Direction, cover.Direction)
+	}
+
+	if math.Abs(short.Quantity-100) > 1e-9 {
+		t.Errorf("Expected a 100 share short sized to equity, got %.4f", short.Quantity)
+	}
+
+	if math.Abs(cover.PnL-1000) > 1e-6 {
+		t.Errorf("Expected short P&L 1000, got %.4f", cover.PnL)
+	}
+
+	// without short selling the sell signal is ignored and the buy goes long
+	trades, err = NewExecutor(strat, provider, 10000.0).Run(context.Background(), "TEST", start, end)
+	if err != nil {
+		t.Fatalf("Executor failed: %v", err)
+	}
+	if len(trades) != 1 || trades[0].Direction != domain.TradeDirectionBuy {
+		t.Errorf("Expected a single BUY without short selling, got %v", trades)
+	}
+}
+
+func TestExecutorBorrowFees(t *testing.T) {
+	provider := newMemoryProvider("TEST", [][4]float64{
+		{100, 100, 100, 100},
+		{100, 100, 100, 100},
+		{100, 100, 100, 100},
+		{100, 100, 100, 100},
+	})
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	// 25.2% a year is 0.1% of the short notional per bar
+	margin := MarginConfig{InitialMargin: 0.5, MaintenanceMargin: 0.3, BorrowRate: 0.252}
+
+	strat := &scriptedStrategy{signals: map[int]Signal{0: SignalSell, 2: SignalBuy}}
+	trades, err := NewExecutor(strat, provider, 10000.0, WithShortSelling(margin)).Run(context.Background(), "TEST", start, end)
+	if err != nil {
+		t.Fatalf("Executor failed: %v", err)
+	}
+	if len(trades) != 2 {
+		t.Fatalf("Expected 2 trades, got %d", len(trades))
+	}
+
+	// the short is held over the closes of bars 1 and 2
+	cover := trades[1]
+	if math.Abs(cover.BorrowFee-20) > 1e-6 {
+		t.Errorf("Expected borrow fees of 20, got %.4f", cover.BorrowFee)
+	}
+	if math.Abs(cover.PnL+20) > 1e-6 {
+		t.Errorf("Expected P&L of -20 from borrow fees, got %.4f", cover.PnL)
+	}
+}
+
+func TestExecutorMarginCall(t *testing.T) {
+	provider := newMemoryProvider("TEST", [][4]float64{
+		{100, 100, 100, 100},
+		{100, 100, 100, 100},
+		{120, 125, 118, 125},
+		{150, 162, 148, 160},
+		{170, 170, 170, 170},
+	})
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	margin := MarginConfig{InitialMargin: 0.5, MaintenanceMargin: 0.3}
+
+	strat := &scriptedStrategy{signals: map[int]Signal{0: SignalSell}}
+	trades, err := NewExecutor(strat, provider, 10000.0, WithShortSelling(margin)).Run(context.Background(), "TEST", start, end)
+	if err != nil {
+		t.Fatalf("Executor failed: %v", err)
+	}
+	if len(trades) != 2 {
+		t.Fatalf("Expected the short to be liquidated, got %d trades", len(trades))
+	}
+
+	// 20000 cash against 100 shares breaches 30% maintenance above 153.85
+	cover := trades[1]
+	if cover.Direction != domain.TradeDirectionCover || cover.Price != 160 {
+		t.Errorf("Expected a forced COVER at the 160 close, got %s at %.2f", cover.Direction, cover.Price)
+	}
+	if !cover.Timestamp.Equal(start.AddDate(0, 0, 3)) {
+		t.Errorf("Expected liquidation on bar 3, got %s", cover.Timestamp)
+	}
+	if math.Abs(cover.PnL+6000) > 1e-6 {
+		t.Errorf("Expected P&L of -6000, got %.4f", cover.PnL)
+	}
+}
+
+func TestExecutorEquityCurve(t *testing.T) {
+	provider := newMemoryProvider("TEST", [][4]float64{
+		{100, 100, 100, 100},
+		{100, 112, 100, 110},
+		{110, 110, 90, 90},
+		{95, 95, 95, 95},
+	})
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	strat := &scriptedStrategy{signals: map[int]Signal{0: SignalBuy, 2: SignalSell}}
+	result, err := NewExecutor(strat, provider, 10000.0).Simulate(context.Background(), "TEST", start, end)
+	if err != nil {
+		t.Fatalf("Executor failed: %v", err)
+	}
+
+	if len(result.EquityCurve) != 4 {
+		t.Fatalf("Expected one equity point per bar, got %d", len(result.EquityCurve))
+	}
+
+	// flat, long 100 shares marked at 110 and 90, then flat after selling at 95
+	expected := []struct {
+		equity, cash, exposure float64
+	}{
+		{10000, 10000, 0},
+		{11000, 0, 1},
+		{9000, 0, 1},
+		{9500, 9500, 0},
+	}
+
+	for i, want := range expected {
+		point := result.EquityCurve[i]
+		if math.Abs(point.Equity-want.equity) > 1e-6 || math.Abs(point.Cash-want.cash) > 1e-6 {
+			t.Errorf("Bar %d: expected equity %.2f and cash %.2f, got %.2f and %.2f",
+				i, want.equity, want.cash, point.Equity, point.Cash)
+		}
+		if math.Abs(point.GrossExposure-want.exposure) > 1e-9 || math.Abs(point.NetExposure-want.exposure) > 1e-9 {
+			t.Errorf("Bar %d: expected exposure %.2f, got gross %.2f net %.2f",
+				i, want.exposure, point.GrossExposure, point.NetExposure)
+		}
+	}
+}
+
+// universeStrategy buys every symbol on the first bar and records what the
+// context exposes about the rest of the portfolio
+type universeStrategy struct {
+	seen map[string]int // number of symbols in Bars when each symbol was called
+}
+
+func (s *universeStrategy) Name() string {
+	return "Universe"
+}
+
+func (s *universeStrategy) Generate(ctx *Context) (Signal, error) {
+	if ctx.BarCount() == 1 {
+		s.seen[ctx.Symbol] = len(ctx.Bars)
+		return SignalBuy, nil
+	}
+	return SignalHold, nil
+}
+
+func TestExecutorPortfolio(t *testing.T) {
+	provider := newMemoryProvider("AAA", [][4]float64{
+		{100, 100, 100, 100},
+		{100, 100, 100, 100},
+		{110, 110, 110, 110},
+	})
+	provider.bars["BBB"] = newMemoryProvider("BBB", [][4]float64{
+		{50, 50, 50, 50},
+		{50, 50, 50, 50},
+		{40, 40, 40, 40},
+	}).bars["BBB"]
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	strat := &universeStrategy{seen: map[string]int{}}
+	executor := NewExecutor(strat, provider, 10000.0, WithSizer(PercentOfEquity{Percent: 50}))
+
+	result, err := executor.SimulatePortfolio(context.Background(), []string{"AAA", "BBB"}, start, end)
+	if err != nil {
+		t.Fatalf("Executor failed: %v", err)
+	}
+
+	if strat.seen["AAA"] != 2 || strat.seen["BBB"] != 2 {
+		t.Errorf("Expected the context to expose both symbols, got %v", strat.seen)
+	}
+
+	if len(result.Trades) != 2 {
+		t.Fatalf("Expected one buy per symbol, got %d trades", len(result.Trades))
+	}
+
+	quantities := map[string]float64{}
+	for _, trade := range result.Trades {
+		quantities[trade.Symbol] = trade.Quantity
+	}
+	if math.Abs(quantities["AAA"]-50) > 1e-9 || math.Abs(quantities["BBB"]-100) > 1e-9 {
+		t.Errorf("Expected 50 AAA and 100 BBB from half the equity each, got %v", quantities)
+	}
+
+	// AAA gains 500 and BBB loses 1000 on the shared account
+	last := result.EquityCurve[len(result.EquityCurve)-1]
+	if math.Abs(last.Equity-9500) > 1e-6 || math.Abs(last.Cash) > 1e-6 {
+		t.Errorf("Expected equity 9500 fully invested, got equity %.2f cash %.2f", last.Equity, last.Cash)
+	}
+	if math.Abs(last.GrossExposure-1) > 1e-9 {
+		t.Errorf("Expected gross exposure of 1, got %.4f", last.GrossExposure)
+	}
+}
+
+func TestExecutorPortfolioMissingBars(t *testing.T) {
+	provider := newMemoryProvider("AAA", [][4]float64{
+		{100, 100, 100, 100},
+		{100, 100, 100, 100},
+		{100, 100, 100, 100},
+	})
+
+	// BBB only trades from the second day
+	bars := newMemoryProvider("BBB", [][4]float64{
+		{50, 50, 50, 50},
+		{50, 50, 50, 50},
+	}).bars["BBB"]
+	for i := range bars {
+		bars[i].Timestamp = bars[i].Timestamp.AddDate(0, 0, 1)
+	}
+	provider.bars["BBB"] = bars
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	strat := &universeStrategy{seen: map[string]int{}}
+	result, err := NewExecutor(strat, provider, 10000.0, WithSizer(FixedShares{Shares: 10})).
+		SimulatePortfolio(context.Background(), []string{"AAA", "BBB"}, start, end)
+	if err != nil {
+		t.Fatalf("Executor failed: %v", err)
+	}
+
+	if len(result.EquityCurve) != 3 {
+		t.Errorf("Expected one equity point per timestamp, got %d", len(result.EquityCurve))
+	}
+
+	if strat.seen["AAA"] != 1 {
+		t.Errorf("Expected BBB to be missing on the first day, got %d symbols", strat.seen["AAA"])
+	}
+
+	// BBB's first bar is the second day, so its buy fills on the third
+	for _, trade := range result.Trades {
+		if trade.Symbol == "BBB" && !trade.Timestamp.Equal(start.AddDate(0, 0, 2)) {
+			t.Errorf("Expected BBB to fill on its next bar, got %s", trade.Timestamp)
+		}
+	}
+	if len(result.Trades) != 2 {
+		t.Errorf("Expected 2 trades, got %d", len(result.Trades))
+	}
+
+	if _, err := NewExecutor(strat, provider, 10000.0).SimulatePortfolio(context.Background(), []string{"AAA", "CCC"}, start, end); err == nil {
+		t.Error("Expected an error for a symbol without data")
+	}
+}
+
+// tamperingStrategy buys every symbol on the first bar and then scribbles
+// over the positions its context hands out
+type tamperingStrategy struct{}
+
+func (tamperingStrategy) Name() string {
+	return "Tampering"
+}
+
+func (tamperingStrategy) Generate(ctx *Context) (Signal, error) {
+	if ctx.BarCount() == 1 {
+		return SignalBuy, nil
+	}
+	if ctx.CurrentPosition != nil {
+		ctx.CurrentPosition.Shares *= 100
+		ctx.CurrentPosition.EntryPrice = 0
+	}
+	for _, position := range ctx.Positions {
+		position.Shares = -1
+		position.EntryCommission = 1e6
+	}
+	return SignalHold, nil
+}
+
+func TestExecutorPositionsAreCopies(t *testing.T) {
+	provider := newMemoryProvider("AAA", [][4]float64{
+		{100, 100, 100, 100},
+		{100, 100, 100, 100},
+		{110, 110, 110, 110},
+	})
+	provider.bars["BBB"] = newMemoryProvider("BBB", [][4]float64{
+		{50, 50, 50, 50},
+		{50, 50, 50, 50},
+		{40, 40, 40, 40},
+	}).bars["BBB"]
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	executor := NewExecutor(tamperingStrategy{}, provider, 10000.0, WithSizer(PercentOfEquity{Percent: 50}))
+	result, err := executor.SimulatePortfolio(context.Background(), []string{"AAA", "BBB"}, start, end)
+	if err != nil {
+		t.Fatalf("Executor failed: %v", err)
+	}
+
+	// same account as TestExecutorPortfolio, the writes never reach it
+	last := result.EquityCurve[len(result.EquityCurve)-1]
+	if math.Abs(last.Equity-9500) > 1e-6 {
+		t.Errorf("Expected equity 9500, got %.2f", last.Equity)
+	}
+	if aaa := result.Positions["AAA"]; aaa == nil || math.Abs(aaa.Shares-50) > 1e-9 || aaa.EntryPrice != 100 {
+		t.Errorf("Expected 50 AAA held at 100, got %+v", aaa)
+	}
+	if bbb := result.Positions["BBB"]; bbb == nil || math.Abs(bbb.Shares-100) > 1e-9 || bbb.EntryCommission != 0 {
+		t.Errorf("Expected 100 BBB held without commission, got %+v", bbb)
+	}
+}
+
+// viewStrategy buys AAA on the first bar and records, per bar, the cash and
+// the positions map every symbol was shown
+type viewStrategy struct {
+	cash  map[int][]float64
+	views map[int][]map[string]*Position
+}
+
+func (s *viewStrategy) Name() string {
+	return "View"
+}
+
+func (s *viewStrategy) Generate(ctx *Context) (Signal, error) {
+	bar := ctx.BarCount() - 1
+	s.cash[bar] = append(s.cash[bar], ctx.Cash)
+	s.views[bar] = append(s.views[bar], ctx.Positions)
+	if bar == 0 && ctx.Symbol == "AAA" {
+		return SignalBuy, nil
+	}
+	return SignalHold, nil
+}
+
+func TestExecutorSharesViewsAcrossSlice(t *testing.T) {
+	provider := newMemoryProvider("AAA", [][4]float64{{100, 100, 100, 100}, {100, 100, 100, 100}})
+	for _, symbol := range []string{"BBB", "CCC"} {
+		provider.bars[symbol] = newMemoryProvider(symbol, [][4]float64{{50, 50, 50, 50}, {50, 50, 50, 50}}).bars[symbol]
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	strat := &viewStrategy{cash: map[int][]float64{}, views: map[int][]map[string]*Position{}}
+	executor := NewExecutor(strat, provider, 10000.0, WithFillModel(SameBarClose()), WithSizer(FixedShares{Shares: 10}))
+	if _, err := executor.SimulatePortfolio(context.Background(), []string{"AAA", "BBB", "CCC"}, start, end); err != nil {
+		t.Fatalf("Executor failed: %v", err)
+	}
+
+	// the buy of AAA fills at the close, the next symbols see it
+	if cash := strat.cash[0]; cash[0] != 10000 || cash[1] != 9000 || cash[2] != 9000 {
+		t.Errorf("Expected cash 10000, 9000, 9000 on the first bar, got %v", cash)
+	}
+	if views := strat.views[0]; len(views[0]) != 0 || views[1]["AAA"] == nil {
+		t.Errorf("Expected the AAA position from the second symbol on, got %v", views)
+	}
+
+	// nothing trades on the second bar, every symbol shares one view
+	views := strat.views[1]
+	same := func(a, b map[string]*Position) bool {
+		return reflect.ValueOf(a).UnsafePointer() == reflect.ValueOf(b).UnsafePointer()
+	}
+	if !same(views[0], views[1]) || !same(views[1], views[2]) {
+		t.Error("Expected the symbols of a slice without trades to share the positions view")
+	}
+	if same(strat.views[0][0], strat.views[0][1]) {
+		t.Error("Expected the view to be rebuilt after the fill")
+	}
+}
+
+// latestBars is the last bar of every symbol
+func (p *memoryProvider) latestBars() map[string]domain.Bar {
+	bars := make(map[string]domain.Bar, len(p.bars))
+	for symbol, series := range p.bars {
+		bars[symbol] = series[len(series)-1]
+	}
+	return bars
+}
@@ -0,0 +1,74 @@
+package strategy
+
+import "github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+
+// FillModel decides on which bar and at what price a signal is executed
+type FillModel interface {
+	Name() string
+
+	// NextBar reports whether the fill happens on the bar after the signal
+	NextBar() bool
+
+	// Price returns the fill price on the execution bar
+	Price(bar domain.Bar) float64
+}
+
+type sameBarClose struct{}
+
+// SameBarClose fills at the close of the bar that produced the signal.
+// it trades on information that was not available yet, so it is only useful
+// for comparing against older results
+func SameBarClose() FillModel {
+	return sameBarClose{}
+}
+
+func (sameBarClose) Name() string                 { return "Same Bar Close" }
+func (sameBarClose) NextBar() bool                { return false }
+func (sameBarClose) Price(bar domain.Bar) float64 { return bar.Close }
+
+type nextBarOpen struct{}
+
+// NextBarOpen fills at the open of the bar after the signal
+func NextBarOpen() FillModel {
+	return nextBarOpen{}
+}
+
+func (nextBarOpen) Name() string                 { return "Next Bar Open" }
+func (nextBarOpen) NextBar() bool                { return true }
+func (nextBarOpen) Price(bar domain.Bar) float64 { return bar.Open }
+
+type nextBarVWAP struct{}
+
+// NextBarVWAP fills at the OHLC average of the next bar, approximating VWAP
+func NextBarVWAP() FillModel {
+	return nextBarVWAP{}
+}
+
+func (nextBarVWAP) Name() string                 { return "Next Bar VWAP" }
+func (nextBarVWAP) NextBar() bool                { return true }
+func (nextBarVWAP) Price(bar domain.Bar) float64 { return bar.AveragePrice() }
+
+type nextBarClose struct{}
+
+// NextBarClose fills at the close of the bar after the signal
+func NextBarClose() FillModel {
+	return nextBarClose{}
+}
+
+func (nextBarClose) Name() string                 { return "Next Bar Close" }
+func (nextBarClose) NextBar() bool                { return true }
+func (nextBarClose) Price(bar domain.Bar) float64 { return bar.Close }
+
+// NewFillModel maps the fill model stored with a backtest to its implementation
+func NewFillModel(model domain.FillModel) FillModel {
+	switch model {
+	case domain.FillModelSameBarClose:
+		return SameBarClose()
+	case domain.FillModelNextBarVWAP:
+		return NextBarVWAP()
+	case domain.FillModelNextBarClose:
+		return NextBarClose()
+	default:
+		return NextBarOpen()
+	}
+}
@@ -2,10 +2,103 @@ package strategy
 
 import (
 	"fmt"
+	"math"
 
 	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
 )
 
+// every indicator returns its value at the last bar and an error when there
+// aren't enough bars to warm it up, the doc comment of each one says how
+// many bars it needs. values that depend on the start of the series, like
+// the smoothed averages, use all the bars they are given
+
+// Channel is a band around a price, Bollinger, Donchian and Keltner
+type Channel struct {
+	Upper  float64
+	Middle float64
+	Lower  float64
+}
+
+// MACDValue is the MACD line, its signal line and their difference
+type MACDValue struct {
+	MACD      float64
+	Signal    float64
+	Histogram float64
+}
+
+// DirectionalIndex is the ADX with the directional indicators it is built on
+type DirectionalIndex struct {
+	ADX     float64
+	PlusDI  float64
+	MinusDI float64
+}
+
+// StochasticValue is the %K line and its %D average
+type StochasticValue struct {
+	K float64
+	D float64
+}
+
+func checkPeriod(period int) error {
+	if period <= 0 {
+		return fmt.Errorf("period must be positive, got %d", period)
+	}
+	return nil
+}
+
+func checkBars(bars []domain.Bar, need int) error {
+	if len(bars) < need {
+		return fmt.Errorf("not enough bars: need %d, have %d", need, len(bars))
+	}
+	return nil
+}
+
+func closes(bars []domain.Bar) []float64 {
+	values := make([]float64, len(bars))
+	for i, bar := range bars {
+		values[i] = bar.Close
+	}
+	return values
+}
+
+func trueRange(bar domain.Bar, prevClose float64) float64 {
+	return math.Max(bar.High-bar.Low,
+		math.Max(math.Abs(bar.High-prevClose), math.Abs(bar.Low-prevClose)))
+}
+
+func mean(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// stdDev is the population standard deviation
+func stdDev(values []float64) float64 {
+	m := mean(values)
+	sum := 0.0
+	for _, v := range values {
+		sum += (v - m) * (v - m)
+	}
+	return math.Sqrt(sum / float64(len(values)))
+}
+
+// emaSeries seeds the average with the SMA of the first period values, the
+// result starts at values[period-1] so it has len(values)-period+1 entries
+func emaSeries(values []float64, period int) []float64 {
+	alpha := 2 / float64(period+1)
+	out := make([]float64, 0, len(values)-period+1)
+
+	avg := mean(values[:period])
+	out = append(out, avg)
+	for _, v := range values[period:] {
+		avg += alpha * (v - avg)
+		out = append(out, avg)
+	}
+	return out
+}
+
 // SMA calculates the Simple Moving Average over the last N bars
 // returns 0 if not enough bars available
 func SMA(bars []domain.Bar, period int) (float64, error) {
@@ -27,3 +120,368 @@ func SMA(bars []domain.Bar, period int) (float64, error) {
 
 	return sum / float64(period), nil
 }
+
+// EMA calculates the Exponential Moving Average of the closes, seeded with
+// the SMA of the first N bars. needs N bars
+func EMA(bars []domain.Bar, period int) (float64, error) {
+	if err := checkPeriod(period); err != nil {
+		return 0, err
+	}
+	if err := checkBars(bars, period); err != nil {
+		return 0, err
+	}
+
+	ema := emaSeries(closes(bars), period)
+	return ema[len(ema)-1], nil
+}
+
+// WMA calculates the Weighted Moving Average of the closes, the newest bar
+// weighs N and the oldest 1. needs N bars
+func WMA(bars []domain.Bar, period int) (float64, error) {
+	if err := checkPeriod(period); err != nil {
+		return 0, err
+	}
+	if err := checkBars(bars, period); err != nil {
+		return 0, err
+	}
+
+	sum, weights := 0.0, 0.0
+	for i, bar := range bars[len(bars)-period:] {
+		weight := float64(i + 1)
+		sum += weight * bar.Close
+		weights += weight
+	}
+
+	return sum / weights, nil
+}
+
+// RSI calculates the Relative Strength Index with Wilder smoothing of the
+// average gain and loss, 100 when there were no losses. needs N+1 bars
+func RSI(bars []domain.Bar, period int) (float64, error) {
+	if err := checkPeriod(period); err != nil {
+		return 0, err
+	}
+	if err := checkBars(bars, period+1); err != nil {
+		return 0, err
+	}
+
+	gain, loss := 0.0, 0.0
+	for i := 1; i < len(bars); i++ {
+		delta := bars[i].Close - bars[i-1].Close
+		up, down := math.Max(delta, 0), math.Max(-delta, 0)
+
+		if i <= period {
+			gain += up / float64(period)
+			loss += down / float64(period)
+			continue
+		}
+		gain = (gain*float64(period-1) + up) / float64(period)
+		loss = (loss*float64(period-1) + down) / float64(period)
+	}
+
+	if loss == 0 {
+		return 100, nil
+	}
+	return 100 - 100/(1+gain/loss), nil
+}
+
+// MACD calculates the difference of a fast and a slow EMA of the closes and
+// an EMA of that difference as the signal line. needs slow+signal-1 bars
+func MACD(bars []domain.Bar, fast, slow, signal int) (MACDValue, error) {
+	for _, period := range []int{fast, slow, signal} {
+		if err := checkPeriod(period); err != nil {
+			return MACDValue{}, err
+		}
+	}
+	if fast >= slow {
+		return MACDValue{}, fmt.Errorf("fast period %d must be shorter than slow period %d", fast, slow)
+	}
+	if err := checkBars(bars, slow+signal-1); err != nil {
+		return MACDValue{}, err
+	}
+
+	values := closes(bars)
+	fastEMA, slowEMA := emaSeries(values, fast), emaSeries(values, slow)
+
+	// both series end at the last bar, the fast one starts slow-fast earlier
+	line := make([]float64, len(slowEMA))
+	for i := range line {
+		line[i] = fastEMA[i+slow-fast] - slowEMA[i]
+	}
+	signalEMA := emaSeries(line, signal)
+
+	macd, sig := line[len(line)-1], signalEMA[len(signalEMA)-1]
+	return MACDValue{MACD: macd, Signal: sig, Histogram: macd - sig}, nil
+}
+
+// BollingerBands puts bands k population standard deviations around the SMA
+// of the closes. needs N bars
+func BollingerBands(bars []domain.Bar, period int, k float64) (Channel, error) {
+	if err := checkPeriod(period); err != nil {
+		return Channel{}, err
+	}
+	if k <= 0 {
+		return Channel{}, fmt.Errorf("multiplier must be positive, got %v", k)
+	}
+	if err := checkBars(bars, period); err != nil {
+		return Channel{}, err
+	}
+
+	window := closes(bars[len(bars)-period:])
+	middle, width := mean(window), k*stdDev(window)
+	return Channel{Upper: middle + width, Middle: middle, Lower: middle - width}, nil
+}
+
+// ATR calculates the Average True Range over the last N bars, the true range
+// of a bar also covers the gap from the previous close
+func ATR(bars []domain.Bar, period int) (float64, error) {
+	if period <= 0 {
+		return 0, fmt.Errorf("period must be positive, got %d", period)
+	}
+
+	if len(bars) < period+1 {
+		return 0, fmt.Errorf("not enough bars: need %d, have %d", period+1, len(bars))
+	}
+
+	sum := 0.0
+	for i := len(bars) - period; i < len(bars); i++ {
+		sum += trueRange(bars[i], bars[i-1].Close)
+	}
+
+	return sum / float64(period), nil
+}
+
+// ADX calculates Wilder's Average Directional Index, the smoothed spread of
+// the +DI and -DI directional indicators. needs 2N bars
+func ADX(bars []domain.Bar, period int) (DirectionalIndex, error) {
+	if err := checkPeriod(period); err != nil {
+		return DirectionalIndex{}, err
+	}
+	if err := checkBars(bars, 2*period); err != nil {
+		return DirectionalIndex{}, err
+	}
+
+	p := float64(period)
+	var tr, plusDM, minusDM float64
+	var result DirectionalIndex
+
+	for i := 1; i < len(bars); i++ {
+		up := bars[i].High - bars[i-1].High
+		down := bars[i-1].Low - bars[i].Low
+		plus, minus := 0.0, 0.0
+		if up > down && up > 0 {
+			plus = up
+		}
+		if down > up && down > 0 {
+			minus = down
+		}
+
+		// the first N moves are summed, later ones smoothed into the sums
+		if i <= period {
+			tr += trueRange(bars[i], bars[i-1].Close)
+			plusDM += plus
+			minusDM += minus
+		} else {
+			tr += trueRange(bars[i], bars[i-1].Close) - tr/p
+			plusDM += plus - plusDM/p
+			minusDM += minus - minusDM/p
+		}
+		if i < period {
+			continue
+		}
+
+		if tr > 0 {
+			result.PlusDI = 100 * plusDM / tr
+			result.MinusDI = 100 * minusDM / tr
+		} else {
+			result.PlusDI, result.MinusDI = 0, 0
+		}
+		dx := 0.0
+		if sum := result.PlusDI + result.MinusDI; sum > 0 {
+			dx = 100 * math.Abs(result.PlusDI-result.MinusDI) / sum
+		}
+
+		// the ADX starts as the mean of the first N DX values
+		if i < 2*period {
+			result.ADX += dx / p
+		} else {
+			result.ADX = (result.ADX*(p-1) + dx) / p
+		}
+	}
+
+	return result, nil
+}
+
+// Stochastic calculates the %K position of the close in the range of the
+// last kPeriod bars, 50 for a flat range, and its %D SMA over dPeriod
+// values. needs kPeriod+dPeriod-1 bars
+func Stochastic(bars []domain.Bar, kPeriod, dPeriod int) (StochasticValue, error) {
+	for _, period := range []int{kPeriod, dPeriod} {
+		if err := checkPeriod(period); err != nil {
+			return StochasticValue{}, err
+		}
+	}
+	if err := checkBars(bars, kPeriod+dPeriod-1); err != nil {
+		return StochasticValue{}, err
+	}
+
+	k := make([]float64, dPeriod)
+	for j := range k {
+		end := len(bars) - dPeriod + j + 1
+		channel := donchian(bars[end-kPeriod : end])
+		if channel.Upper == channel.Lower {
+			k[j] = 50
+			continue
+		}
+		k[j] = 100 * (bars[end-1].Close - channel.Lower) / (channel.Upper - channel.Lower)
+	}
+
+	return StochasticValue{K: k[len(k)-1], D: mean(k)}, nil
+}
+
+// CCI calculates the Commodity Channel Index of the typical price, its
+// distance from the SMA in units of 0.015 mean deviations, 0 when flat.
+// needs N bars
+func CCI(bars []domain.Bar, period int) (float64, error) {
+	if err := checkPeriod(period); err != nil {
+		return 0, err
+	}
+	if err := checkBars(bars, period); err != nil {
+		return 0, err
+	}
+
+	window := bars[len(bars)-period:]
+	prices := make([]float64, len(window))
+	for i, bar := range window {
+		prices[i] = bar.TypicalPrice()
+	}
+
+	avg := mean(prices)
+	deviation := 0.0
+	for _, price := range prices {
+		deviation += math.Abs(price - avg)
+	}
+	deviation /= float64(period)
+
+	if deviation == 0 {
+		return 0, nil
+	}
+	return (prices[len(prices)-1] - avg) / (0.015 * deviation), nil
+}
+
+// OBV calculates the On Balance Volume, the running total of the volume of
+// up bars minus that of down bars starting from 0 at the first bar. needs 1
+// bar
+func OBV(bars []domain.Bar) (float64, error) {
+	if err := checkBars(bars, 1); err != nil {
+		return 0, err
+	}
+
+	obv := 0.0
+	for i := 1; i < len(bars); i++ {
+		switch {
+		case bars[i].Close > bars[i-1].Close:
+			obv += float64(bars[i].Volume)
+		case bars[i].Close < bars[i-1].Close:
+			obv -= float64(bars[i].Volume)
+		}
+	}
+
+	return obv, nil
+}
+
+// VWAP calculates the volume weighted average of the typical price over the
+// last N bars, pass the bars of a session with N = len(bars) for the session
+// VWAP. needs N bars with some volume
+func VWAP(bars []domain.Bar, period int) (float64, error) {
+	if err := checkPeriod(period); err != nil {
+		return 0, err
+	}
+	if err := checkBars(bars, period); err != nil {
+		return 0, err
+	}
+
+	value, volume := 0.0, 0.0
+	for _, bar := range bars[len(bars)-period:] {
+		value += bar.TypicalPrice() * float64(bar.Volume)
+		volume += float64(bar.Volume)
+	}
+
+	if volume == 0 {
+		return 0, fmt.Errorf("no volume in the last %d bars", period)
+	}
+	return value / volume, nil
+}
+
+// Donchian calculates the highest high and lowest low of the last N bars
+// and their midpoint. needs N bars
+func Donchian(bars []domain.Bar, period int) (Channel, error) {
+	if err := checkPeriod(period); err != nil {
+		return Channel{}, err
+	}
+	if err := checkBars(bars, period); err != nil {
+		return Channel{}, err
+	}
+
+	return donchian(bars[len(bars)-period:]), nil
+}
+
+func donchian(window []domain.Bar) Channel {
+	channel := Channel{Upper: window[0].High, Lower: window[0].Low}
+	for _, bar := range window[1:] {
+		channel.Upper = math.Max(channel.Upper, bar.High)
+		channel.Lower = math.Min(channel.Lower, bar.Low)
+	}
+	channel.Middle = (channel.Upper + channel.Lower) / 2
+	return channel
+}
+
+// Keltner puts bands multiplier ATRs over atrPeriod around the EMA of the
+// closes over period. needs the larger of period and atrPeriod+1 bars
+func Keltner(bars []domain.Bar, period, atrPeriod int, multiplier float64) (Channel, error) {
+	if multiplier <= 0 {
+		return Channel{}, fmt.Errorf("multiplier must be positive, got %v", multiplier)
+	}
+
+	middle, err := EMA(bars, period)
+	if err != nil {
+		return Channel{}, err
+	}
+	atr, err := ATR(bars, atrPeriod)
+	if err != nil {
+		return Channel{}, err
+	}
+
+	return Channel{Upper: middle + multiplier*atr, Middle: middle, Lower: middle - multiplier*atr}, nil
+}
+
+// StdDev calculates the population standard deviation of the last N closes.
+// needs N bars
+func StdDev(bars []domain.Bar, period int) (float64, error) {
+	if err := checkPeriod(period); err != nil {
+		return 0, err
+	}
+	if err := checkBars(bars, period); err != nil {
+		return 0, err
+	}
+
+	return stdDev(closes(bars[len(bars)-period:])), nil
+}
+
+// ZScore calculates how many standard deviations the close is from the mean
+// of the last N closes, 0 when they are all equal. needs N bars
+func ZScore(bars []domain.Bar, period int) (float64, error) {
+	if err := checkPeriod(period); err != nil {
+		return 0, err
+	}
+	if err := checkBars(bars, period); err != nil {
+		return 0, err
+	}
+
+	window := closes(bars[len(bars)-period:])
+	sd := stdDev(window)
+	if sd == 0 {
+		return 0, nil
+	}
+	return (window[len(window)-1] - mean(window)) / sd, nil
+}
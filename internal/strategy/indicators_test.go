@@ -1,6 +1,7 @@
 package strategy
 
 import (
+	"math"
 	"testing"
 	"time"
 
@@ -41,3 +42,206 @@ func TestSMA(t *testing.T) {
 		t.Error("Expected error for insufficient bars, got nil")
 	}
 }
+
+func TestATR(t *testing.T) {
+	bars := []domain.Bar{
+		{High: 101, Low: 99, Close: 100},
+		{High: 103, Low: 100, Close: 102}, // range 3
+		{High: 110, Low: 106, Close: 108}, // gap up, true range 110-102 = 8
+		{High: 109, Low: 104, Close: 105}, // range 5
+	}
+
+	atr, err := ATR(bars, 3)
+	if err != nil {
+		t.Fatalf("ATR failed: %v", err)
+	}
+
+	if math.Abs(atr-16.0/3) > 1e-9 {
+		t.Errorf("Expected ATR of %.4f, got %.4f", 16.0/3, atr)
+	}
+
+	if _, err := ATR(bars, 4); err == nil {
+		t.Error("Expected error for insufficient bars, got nil")
+	}
+}
+
+// referenceBars uses the closes of Wilder's RSI example, the reference
+// values were computed independently of this package
+func referenceBars() []domain.Bar {
+	return []domain.Bar{
+		{High: 44.64, Low: 44.09, Close: 44.34, Volume: 1000},
+		{High: 44.49, Low: 43.79, Close: 44.09, Volume: 1037},
+		{High: 44.65, Low: 43.8, Close: 44.15, Volume: 1148},
+		{High: 43.91, Low: 43.21, Close: 43.61, Volume: 1111},
+		{High: 44.73, Low: 44.08, Close: 44.33, Volume: 1296},
+		{High: 45.33, Low: 44.53, Close: 44.83, Volume: 1185},
+		{High: 45.4, Low: 44.75, Close: 45.1, Volume: 1444},
+		{High: 45.82, Low: 45.02, Close: 45.42, Volume: 1259},
+		{High: 46.34, Low: 45.59, Close: 45.84, Volume: 1592},
+		{High: 46.38, Low: 45.78, Close: 46.08, Volume: 1333},
+		{High: 46.29, Low: 45.54, Close: 45.89, Volume: 1740},
+		{High: 46.53, Low: 45.63, Close: 46.03, Volume: 1407},
+		{High: 45.91, Low: 45.36, Close: 45.61, Volume: 1888},
+		{High: 46.68, Low: 45.98, Close: 46.28, Volume: 1481},
+		{High: 46.78, Low: 45.93, Close: 46.28, Volume: 2036},
+		{High: 46.3, Low: 45.6, Close: 46, Volume: 1555},
+		{High: 46.43, Low: 45.78, Close: 46.03, Volume: 2184},
+		{High: 46.91, Low: 46.11, Close: 46.41, Volume: 1629},
+		{High: 46.52, Low: 45.87, Close: 46.22, Volume: 2332},
+		{High: 46.04, Low: 45.24, Close: 45.64, Volume: 1703},
+		{High: 46.71, Low: 45.96, Close: 46.21, Volume: 2480},
+		{High: 46.55, Low: 45.95, Close: 46.25, Volume: 1777},
+		{High: 46.11, Low: 45.36, Close: 45.71, Volume: 2628},
+		{High: 46.95, Low: 46.05, Close: 46.45, Volume: 1851},
+		{High: 46.08, Low: 45.53, Close: 45.78, Volume: 2776},
+		{High: 45.75, Low: 45.05, Close: 45.35, Volume: 1925},
+		{High: 44.53, Low: 43.68, Close: 44.03, Volume: 2924},
+		{High: 44.48, Low: 43.78, Close: 44.18, Volume: 1999},
+		{High: 44.62, Low: 43.97, Close: 44.22, Volume: 3072},
+		{High: 45.07, Low: 44.27, Close: 44.57, Volume: 2073},
+	}
+}
+
+func TestIndicatorReferenceValues(t *testing.T) {
+	bars := referenceBars()
+
+	value := func(v float64, err error) []float64 {
+		if err != nil {
+			t.Fatalf("Indicator failed: %v", err)
+		}
+		return []float64{v}
+	}
+	channel := func(c Channel, err error) []float64 {
+		if err != nil {
+			t.Fatalf("Indicator failed: %v", err)
+		}
+		return []float64{c.Upper, c.Middle, c.Lower}
+	}
+
+	tests := []struct {
+		name string
+		got  func() []float64
+		want []float64
+	}{
+		{"SMA", func() []float64 { return value(SMA(bars, 10)) }, []float64{45.275}},
+		{"EMA", func() []float64 { return value(EMA(bars, 10)) }, []float64{44.999460890617605}},
+		{"WMA", func() []float64 { return value(WMA(bars, 10)) }, []float64{44.87218181818182}},
+		{"RSI", func() []float64 { return value(RSI(bars, 14)) }, []float64{45.499497238680405}},
+		{"MACD", func() []float64 {
+			m, err := MACD(bars, 5, 12, 4)
+			if err != nil {
+				t.Fatalf("MACD failed: %v", err)
+			}
+			return []float64{m.MACD, m.Signal, m.Histogram}
+		}, []float64{-0.4597994210920149, -0.40089495351378845, -0.05890446757822643}},
+		{"BollingerBands", func() []float64 { return channel(BollingerBands(bars, 20, 2)) },
+			[]float64{47.179275927681964, 45.657, 44.13472407231803}},
+		{"ATR", func() []float64 { return value(ATR(bars, 14)) }, []float64{0.8914285714285712}},
+		{"ADX", func() []float64 {
+			d, err := ADX(bars, 7)
+			if err != nil {
+				t.Fatalf("ADX failed: %v", err)
+			}
+			return []float64{d.ADX, d.PlusDI, d.MinusDI}
+		}, []float64{26.070650980393037, 21.5210221854462, 29.513010037142465}},
+		{"Stochastic", func() []float64 {
+			s, err := Stochastic(bars, 14, 3)
+			if err != nil {
+				t.Fatalf("Stochastic failed: %v", err)
+			}
+			return []float64{s.K, s.D}
+		}, []float64{27.21712538226299, 19.67380224260956}},
+		{"CCI", func() []float64 { return value(CCI(bars, 20)) }, []float64{-116.28811017937531}},
+		{"OBV", func() []float64 { return value(OBV(bars)) }, []float64{7591}},
+		{"VWAP", func() []float64 { return value(VWAP(bars, 10)) }, []float64{45.240028362759695}},
+		{"Donchian", func() []float64 { return channel(Donchian(bars, 20)) }, []float64{46.95, 45.315, 43.68}},
+		{"Keltner", func() []float64 { return channel(Keltner(bars, 20, 10, 2)) },
+			[]float64{47.050781873284386, 45.18678187328439, 43.32278187328439}},
+		{"StdDev", func() []float64 { return value(StdDev(bars, 20)) }, []float64{0.7611379638409848}},
+		{"ZScore", func() []float64 { return value(ZScore(bars, 20)) }, []float64{-1.4281247968694015}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.got()
+			for i := range tt.want {
+				if math.Abs(got[i]-tt.want[i]) > 1e-9 {
+					t.Errorf("Value %d: expected %.10f, got %.10f", i, tt.want[i], got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestIndicatorWarmup(t *testing.T) {
+	bars := referenceBars()
+
+	tests := []struct {
+		name string
+		need int
+		call func(bars []domain.Bar) error
+	}{
+		{"SMA", 10, func(b []domain.Bar) error { _, err := SMA(b, 10); return err }},
+		{"EMA", 10, func(b []domain.Bar) error { _, err := EMA(b, 10); return err }},
+		{"WMA", 10, func(b []domain.Bar) error { _, err := WMA(b, 10); return err }},
+		{"RSI", 15, func(b []domain.Bar) error { _, err := RSI(b, 14); return err }},
+		{"MACD", 15, func(b []domain.Bar) error { _, err := MACD(b, 5, 12, 4); return err }},
+		{"BollingerBands", 20, func(b []domain.Bar) error { _, err := BollingerBands(b, 20, 2); return err }},
+		{"ATR", 15, func(b []domain.Bar) error { _, err := ATR(b, 14); return err }},
+		{"ADX", 14, func(b []domain.Bar) error { _, err := ADX(b, 7); return err }},
+		{"Stochastic", 16, func(b []domain.Bar) error { _, err := Stochastic(b, 14, 3); return err }},
+		{"CCI", 20, func(b []domain.Bar) error { _, err := CCI(b, 20); return err }},
+		{"OBV", 1, func(b []domain.Bar) error { _, err := OBV(b); return err }},
+		{"VWAP", 10, func(b []domain.Bar) error { _, err := VWAP(b, 10); return err }},
+		{"Donchian", 20, func(b []domain.Bar) error { _, err := Donchian(b, 20); return err }},
+		{"Keltner", 20, func(b []domain.Bar) error { _, err := Keltner(b, 20, 10, 2); return err }},
+		{"StdDev", 20, func(b []domain.Bar) error { _, err := StdDev(b, 20); return err }},
+		{"ZScore", 20, func(b []domain.Bar) error { _, err := ZScore(b, 20); return err }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(bars[:tt.need]); err != nil {
+				t.Errorf("Expected a value with %d bars, got: %v", tt.need, err)
+			}
+			if err := tt.call(bars[:tt.need-1]); err == nil {
+				t.Errorf("Expected an error with %d bars", tt.need-1)
+			}
+		})
+	}
+}
+
+func TestIndicatorEdgeCases(t *testing.T) {
+	flat := make([]domain.Bar, 20)
+	for i := range flat {
+		flat[i] = domain.Bar{High: 10, Low: 10, Close: 10}
+	}
+
+	if rsi, _ := RSI(flat, 14); rsi != 100 {
+		t.Errorf("Expected RSI of 100 without losses, got %.2f", rsi)
+	}
+	if s, _ := Stochastic(flat, 5, 3); s.K != 50 || s.D != 50 {
+		t.Errorf("Expected stochastic of 50 in a flat range, got %.2f/%.2f", s.K, s.D)
+	}
+	if cci, _ := CCI(flat, 10); cci != 0 {
+		t.Errorf("Expected CCI of 0 when flat, got %.2f", cci)
+	}
+	if z, _ := ZScore(flat, 10); z != 0 {
+		t.Errorf("Expected z-score of 0 when flat, got %.2f", z)
+	}
+	if adx, _ := ADX(flat, 5); adx.ADX != 0 {
+		t.Errorf("Expected ADX of 0 when flat, got %.2f", adx.ADX)
+	}
+	if _, err := VWAP(flat, 5); err == nil {
+		t.Error("Expected an error for VWAP without volume")
+	}
+	if _, err := MACD(referenceBars(), 12, 5, 4); err == nil {
+		t.Error("Expected an error for a fast period longer than the slow one")
+	}
+	if _, err := BollingerBands(referenceBars(), 20, 0); err == nil {
+		t.Error("Expected an error for a zero multiplier")
+	}
+	if _, err := EMA(referenceBars(), 0); err == nil {
+		t.Error("Expected an error for a zero period")
+	}
+}
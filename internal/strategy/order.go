@@ -0,0 +1,338 @@
+package strategy
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+)
+
+type OrderType int
+
+const (
+	OrderTypeMarket OrderType = iota
+	OrderTypeLimit
+	OrderTypeStop
+	OrderTypeStopLimit
+	OrderTypeTrailingStop
+)
+
+func (t OrderType) String() string {
+	switch t {
+	case OrderTypeMarket:
+		return "MARKET"
+	case OrderTypeLimit:
+		return "LIMIT"
+	case OrderTypeStop:
+		return "STOP"
+	case OrderTypeStopLimit:
+		return "STOP_LIMIT"
+	case OrderTypeTrailingStop:
+		return "TRAILING_STOP"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+type OrderSide int
+
+const (
+	OrderSideBuy OrderSide = iota
+	OrderSideSell
+)
+
+func (s OrderSide) String() string {
+	switch s {
+	case OrderSideBuy:
+		return "BUY"
+	case OrderSideSell:
+		return "SELL"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// TimeInForce controls how long an order stays on the book
+type TimeInForce int
+
+const (
+	TimeInForceDay TimeInForce = iota // live for the first bar it can fill on
+	TimeInForceGTC                    // live until filled or cancelled
+	TimeInForceIOC                    // fills at the open of the next bar or is cancelled
+)
+
+func (t TimeInForce) String() string {
+	switch t {
+	case TimeInForceDay:
+		return "DAY"
+	case TimeInForceGTC:
+		return "GTC"
+	case TimeInForceIOC:
+		return "IOC"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+type OrderStatus int
+
+const (
+	OrderStatusPending OrderStatus = iota
+	OrderStatusFilled
+	OrderStatusCancelled
+)
+
+func (s OrderStatus) String() string {
+	switch s {
+	case OrderStatusPending:
+		return "PENDING"
+	case OrderStatusFilled:
+		return "FILLED"
+	case OrderStatusCancelled:
+		return "CANCELLED"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Order is an instruction submitted by a strategy through the Context.
+// orders are matched against the OHLC range of the bars after submission
+type Order struct {
+	ID           uuid.UUID
+	Symbol       string
+	Side         OrderSide
+	Type         OrderType
+	Quantity     float64 // zero lets the executor size the order
+	LimitPrice   float64
+	StopPrice    float64
+	TrailAmount  float64 // trailing distance in price
+	TrailPercent float64 // trailing distance in percent, takes precedence over TrailAmount
+	TimeInForce  TimeInForce
+	Status       OrderStatus
+	SubmittedAt  time.Time
+	Reason       string // why the order was cancelled
+
+	triggered bool    // stop price of a stop-limit order was hit
+	extreme   float64 // best price seen since a trailing stop was placed
+}
+
+// Fill reports an executed order back to the strategy
+type Fill struct {
+	OrderID    uuid.UUID
+	Symbol     string
+	Side       OrderSide
+	Quantity   float64
+	Price      float64
+	Commission float64
+	Timestamp  time.Time
+}
+
+func NewMarketOrder(side OrderSide, quantity float64) Order {
+	return Order{Side: side, Type: OrderTypeMarket, Quantity: quantity}
+}
+
+func NewLimitOrder(side OrderSide, quantity, limitPrice float64, tif TimeInForce) Order {
+	return Order{Side: side, Type: OrderTypeLimit, Quantity: quantity, LimitPrice: limitPrice, TimeInForce: tif}
+}
+
+func NewStopOrder(side OrderSide, quantity, stopPrice float64, tif TimeInForce) Order {
+	return Order{Side: side, Type: OrderTypeStop, Quantity: quantity, StopPrice: stopPrice, TimeInForce: tif}
+}
+
+func NewStopLimitOrder(side OrderSide, quantity, stopPrice, limitPrice float64, tif TimeInForce) Order {
+	return Order{
+		Side:        side,
+		Type:        OrderTypeStopLimit,
+		Quantity:    quantity,
+		StopPrice:   stopPrice,
+		LimitPrice:  limitPrice,
+		TimeInForce: tif,
+	}
+}
+
+// NewTrailingStopOrder trails the best price by trailPercent percent
+func NewTrailingStopOrder(side OrderSide, quantity, trailPercent float64, tif TimeInForce) Order {
+	return Order{
+		Side:         side,
+		Type:         OrderTypeTrailingStop,
+		Quantity:     quantity,
+		TrailPercent: trailPercent,
+		TimeInForce:  tif,
+	}
+}
+
+func (o Order) Validate() error {
+	if o.Quantity < 0 {
+		return fmt.Errorf("quantity cannot be negative, got %f", o.Quantity)
+	}
+	if math.IsNaN(o.Quantity) || math.IsInf(o.Quantity, 0) {
+		return fmt.Errorf("quantity must be finite, got %f", o.Quantity)
+	}
+
+	switch o.Type {
+	case OrderTypeMarket:
+	case OrderTypeLimit:
+		if o.LimitPrice <= 0 {
+			return fmt.Errorf("limit order needs a positive limit price")
+		}
+	case OrderTypeStop:
+		if o.StopPrice <= 0 {
+			return fmt.Errorf("stop order needs a positive stop price")
+		}
+	case OrderTypeStopLimit:
+		if o.StopPrice <= 0 || o.LimitPrice <= 0 {
+			return fmt.Errorf("stop-limit order needs positive stop and limit prices")
+		}
+	case OrderTypeTrailingStop:
+		if o.TrailAmount <= 0 && o.TrailPercent <= 0 {
+			return fmt.Errorf("trailing stop needs a trail amount or percent")
+		}
+	default:
+		return fmt.Errorf("unknown order type %d", o.Type)
+	}
+
+	return nil
+}
+
+func (o *Order) IsOpen() bool {
+	return o.Status == OrderStatusPending
+}
+
+// slips reports whether the fill is executed as a market order and so
+// subject to slippage, limit prices are guaranteed
+func (o *Order) slips() bool {
+	return o.Type == OrderTypeMarket || o.Type == OrderTypeStop || o.Type == OrderTypeTrailingStop
+}
+
+// trailingStop is the current stop price of a trailing stop order
+func (o *Order) trailingStop() float64 {
+	distance := o.TrailAmount
+	if o.TrailPercent > 0 {
+		distance = o.extreme * o.TrailPercent / 100
+	}
+
+	if o.Side == OrderSideSell {
+		return o.extreme - distance
+	}
+	return o.extreme + distance
+}
+
+// match checks a non-market order against a bar and returns the fill price.
+// gaps through the order price fill at the open, otherwise at the order price
+func (o *Order) match(bar domain.Bar) (float64, bool) {
+	if o.TimeInForce == TimeInForceIOC {
+		return o.matchOpen(bar)
+	}
+
+	switch o.Type {
+	case OrderTypeLimit:
+		return matchLimit(o.Side, o.LimitPrice, bar)
+
+	case OrderTypeStop:
+		return matchStop(o.Side, o.StopPrice, bar)
+
+	case OrderTypeStopLimit:
+		if o.triggered {
+			return matchLimit(o.Side, o.LimitPrice, bar)
+		}
+
+		price, hit := matchStop(o.Side, o.StopPrice, bar)
+		if !hit {
+			return 0, false
+		}
+		o.triggered = true
+
+		// on the trigger bar we only know price traded at the stop, so fill
+		// there if it satisfies the limit and rest as a limit order otherwise
+		if o.marketable(price) {
+			return price, true
+		}
+		return 0, false
+
+	case OrderTypeTrailingStop:
+		// check against the stop from previous bars before trailing this one,
+		// we can't tell whether the high or the low came first
+		if price, hit := matchStop(o.Side, o.trailingStop(), bar); hit {
+			return price, true
+		}
+
+		if o.Side == OrderSideSell {
+			o.extreme = math.Max(o.extreme, bar.High)
+		} else {
+			o.extreme = math.Min(o.extreme, bar.Low)
+		}
+		return 0, false
+	}
+
+	return 0, false
+}
+
+// matchOpen only looks at the opening price, used for immediate-or-cancel orders
+func (o *Order) matchOpen(bar domain.Bar) (float64, bool) {
+	open := domain.Bar{Open: bar.Open, High: bar.Open, Low: bar.Open, Close: bar.Open}
+
+	switch o.Type {
+	case OrderTypeLimit:
+		return matchLimit(o.Side, o.LimitPrice, open)
+	case OrderTypeStop:
+		return matchStop(o.Side, o.StopPrice, open)
+	case OrderTypeStopLimit:
+		if _, hit := matchStop(o.Side, o.StopPrice, open); hit && o.marketable(bar.Open) {
+			return bar.Open, true
+		}
+	case OrderTypeTrailingStop:
+		return matchStop(o.Side, o.trailingStop(), open)
+	}
+
+	return 0, false
+}
+
+// marketable reports whether price satisfies the limit price
+func (o *Order) marketable(price float64) bool {
+	if o.Side == OrderSideBuy {
+		return price <= o.LimitPrice
+	}
+	return price >= o.LimitPrice
+}
+
+func matchLimit(side OrderSide, limit float64, bar domain.Bar) (float64, bool) {
+	if side == OrderSideBuy {
+		if bar.Open <= limit {
+			return bar.Open, true
+		}
+		if bar.Low <= limit {
+			return limit, true
+		}
+		return 0, false
+	}
+
+	if bar.Open >= limit {
+		return bar.Open, true
+	}
+	if bar.High >= limit {
+		return limit, true
+	}
+	return 0, false
+}
+
+func matchStop(side OrderSide, stop float64, bar domain.Bar) (float64, bool) {
+	if side == OrderSideBuy {
+		if bar.Open >= stop {
+			return bar.Open, true
+		}
+		if bar.High >= stop {
+			return stop, true
+		}
+		return 0, false
+	}
+
+	if bar.Open <= stop {
+		return bar.Open, true
+	}
+	if bar.Low <= stop {
+		return stop, true
+	}
+	return 0, false
+}
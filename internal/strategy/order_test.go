@@ -0,0 +1,244 @@
+package strategy
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+)
+
+func TestOrderMatch(t *testing.T) {
+	bar := domain.Bar{Open: 100, High: 105, Low: 95, Close: 102}
+	gapUp := domain.Bar{Open: 110, High: 112, Low: 108, Close: 111}
+
+	tests := []struct {
+		name   string
+		order  Order
+		bar    domain.Bar
+		price  float64
+		filled bool
+	}{
+		{"buy limit touched", NewLimitOrder(OrderSideBuy, 1, 97, TimeInForceGTC), bar, 97, true},
+		{"buy limit not reached", NewLimitOrder(OrderSideBuy, 1, 90, TimeInForceGTC), bar, 0, false},
+		{"buy limit gap below", NewLimitOrder(OrderSideBuy, 1, 101, TimeInForceGTC), bar, 100, true},
+		{"sell limit touched", NewLimitOrder(OrderSideSell, 1, 104, TimeInForceGTC), bar, 104, true},
+		{"sell limit gap above", NewLimitOrder(OrderSideSell, 1, 104, TimeInForceGTC), gapUp, 110, true},
+		{"buy stop triggered", NewStopOrder(OrderSideBuy, 1, 103, TimeInForceGTC), bar, 103, true},
+		{"buy stop gap above", NewStopOrder(OrderSideBuy, 1, 103, TimeInForceGTC), gapUp, 110, true},
+		{"sell stop triggered", NewStopOrder(OrderSideSell, 1, 96, TimeInForceGTC), bar, 96, true},
+		{"sell stop not reached", NewStopOrder(OrderSideSell, 1, 90, TimeInForceGTC), bar, 0, false},
+		{"buy stop-limit within limit", NewStopLimitOrder(OrderSideBuy, 1, 103, 104, TimeInForceGTC), bar, 103, true},
+		{"buy stop-limit gap past limit", NewStopLimitOrder(OrderSideBuy, 1, 103, 104, TimeInForceGTC), gapUp, 0, false},
+		{"ioc buy limit at open", NewLimitOrder(OrderSideBuy, 1, 100, TimeInForceIOC), bar, 100, true},
+		{"ioc buy limit intrabar only", NewLimitOrder(OrderSideBuy, 1, 97, TimeInForceIOC), bar, 0, false},
+	}
+
+	for _, tt := range tests {
+		order := tt.order
+		price, filled := order.match(tt.bar)
+		if filled != tt.filled || price != tt.price {
+			t.Errorf("%s: expected (%.2f, %v), got (%.2f, %v)", tt.name, tt.price, tt.filled, price, filled)
+		}
+	}
+}
+
+func TestStopLimitRestsAfterTrigger(t *testing.T) {
+	order := NewStopLimitOrder(OrderSideBuy, 1, 103, 104, TimeInForceGTC)
+
+	if _, filled := order.match(domain.Bar{Open: 110, High: 112, Low: 108, Close: 111}); filled {
+		t.Fatal("Expected no fill when the gap skips the limit")
+	}
+	if !order.triggered {
+		t.Fatal("Expected the stop to be triggered")
+	}
+
+	price, filled := order.match(domain.Bar{Open: 106, High: 107, Low: 103.5, Close: 104})
+	if !filled || price != 104 {
+		t.Errorf("Expected the resting limit to fill at 104, got (%.2f, %v)", price, filled)
+	}
+}
+
+func TestTrailingStopFollowsPrice(t *testing.T) {
+	order := NewTrailingStopOrder(OrderSideSell, 1, 10, TimeInForceGTC)
+	order.extreme = 100
+
+	// rally moves the stop from 90 to 108
+	if _, filled := order.match(domain.Bar{Open: 101, High: 120, Low: 99, Close: 118}); filled {
+		t.Fatal("Expected no fill while price rallies")
+	}
+	if order.trailingStop() != 108 {
+		t.Errorf("Expected trailing stop at 108, got %.2f", order.trailingStop())
+	}
+
+	price, filled := order.match(domain.Bar{Open: 115, High: 116, Low: 105, Close: 106})
+	if !filled || price != 108 {
+		t.Errorf("Expected fill at the trailed stop 108, got (%.2f, %v)", price, filled)
+	}
+}
+
+func TestOrderValidate(t *testing.T) {
+	invalid := []Order{
+		NewLimitOrder(OrderSideBuy, 1, 0, TimeInForceDay),
+		NewStopOrder(OrderSideSell, 1, -1, TimeInForceDay),
+		NewStopLimitOrder(OrderSideBuy, 1, 10, 0, TimeInForceDay),
+		NewTrailingStopOrder(OrderSideSell, 1, 0, TimeInForceGTC),
+		NewMarketOrder(OrderSideBuy, -5),
+		NewMarketOrder(OrderSideBuy, math.NaN()),
+	}
+
+	for _, order := range invalid {
+		if err := order.Validate(); err == nil {
+			t.Errorf("Expected %s order to be invalid", order.Type)
+		}
+	}
+
+	if err := NewMarketOrder(OrderSideBuy, 0).Validate(); err != nil {
+		t.Errorf("Expected market order to be valid, got %v", err)
+	}
+}
+
+// orderStrategy submits a fixed set of orders per bar index and records what
+// the executor reports back
+type orderStrategy struct {
+	orders        map[int][]Order
+	fills         []Fill
+	cancellations []Order
+}
+
+func (s *orderStrategy) Name() string {
+	return "Orders"
+}
+
+func (s *orderStrategy) Generate(ctx *Context) (Signal, error) {
+	s.fills = append(s.fills, ctx.Fills...)
+	s.cancellations = append(s.cancellations, ctx.Cancellations...)
+
+	for _, order := range s.orders[ctx.BarCount()-1] {
+		if _, err := ctx.SubmitOrder(order); err != nil {
+			return SignalHold, err
+		}
+	}
+	return SignalHold, nil
+}
+
+func TestExecutorOrders(t *testing.T) {
+	provider := newMemoryProvider("TEST", [][4]float64{
+		{100, 101, 99, 100},
+		{100, 102, 98, 101},
+		{101, 103, 96, 97},
+		{97, 99, 94, 98},
+		{98, 112, 97, 110},
+		{110, 111, 108, 109},
+	})
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	strat := &orderStrategy{orders: map[int][]Order{
+		0: {
+			NewLimitOrder(OrderSideBuy, 10, 95, TimeInForceDay),   // expires on bar 1
+			NewLimitOrder(OrderSideBuy, 10, 95, TimeInForceGTC),   // fills on bar 3
+			NewLimitOrder(OrderSideBuy, 10, 99.5, TimeInForceIOC), // open is 100, cancelled
+		},
+		3: {
+			NewLimitOrder(OrderSideSell, 4, 111, TimeInForceGTC), // fills on bar 4
+		},
+	}}
+
+	trades, err := NewExecutor(strat, provider, 10000.0).Run(context.Background(), "TEST", start, end)
+	if err != nil {
+		t.Fatalf("Executor failed: %v", err)
+	}
+
+	if len(trades) != 2 {
+		t.Fatalf("Expected 2 trades, got %d", len(trades))
+	}
+
+	if trades[0].Price != 95 || trades[0].Quantity != 10 {
+		t.Errorf("Expected buy 10 @ 95, got %.2f @ %.2f", trades[0].Quantity, trades[0].Price)
+	}
+
+	if trades[1].Price != 111 || trades[1].Quantity != 4 {
+		t.Errorf("Expected partial sell 4 @ 111, got %.2f @ %.2f", trades[1].Quantity, trades[1].Price)
+	}
+	if trades[1].PnL != 64 {
+		t.Errorf("Expected P&L 64 on the partial exit, got %.2f", trades[1].PnL)
+	}
+
+	if len(strat.fills) != 2 {
+		t.Errorf("Expected 2 fills reported, got %d", len(strat.fills))
+	}
+	if len(strat.cancellations) != 2 {
+		t.Errorf("Expected 2 cancellations reported, got %d", len(strat.cancellations))
+	}
+	for _, cancelled := range strat.cancellations {
+		if cancelled.TimeInForce == TimeInForceGTC {
+			t.Errorf("GTC order should not be cancelled: %s", cancelled.Reason)
+		}
+	}
+}
+
+func TestExecutorRejectsInvalidSignalOrder(t *testing.T) {
+	provider := newMemoryProvider("TEST", [][4]float64{
+		{100, 101, 99, 100},
+		{100, 102, 98, 101},
+	})
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := NewExecutor(&quantityStrategy{shares: math.Inf(1)}, provider, 10000.0).Run(context.Background(), "TEST", start, end)
+	if err == nil {
+		t.Fatal("Expected the invalid signal order to fail the run")
+	}
+}
+
+func TestExecutorCancelOrder(t *testing.T) {
+	provider := newMemoryProvider("TEST", [][4]float64{
+		{100, 101, 99, 100},
+		{100, 101, 99, 100},
+		{100, 101, 90, 95},
+	})
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	strat := &cancellingStrategy{}
+	trades, err := NewExecutor(strat, provider, 10000.0).Run(context.Background(), "TEST", start, end)
+	if err != nil {
+		t.Fatalf("Executor failed: %v", err)
+	}
+
+	if len(trades) != 0 {
+		t.Errorf("Expected cancelled order not to trade, got %d trades", len(trades))
+	}
+	if strat.reason != "cancelled by strategy" {
+		t.Errorf("Expected cancellation to be reported, got %q", strat.reason)
+	}
+}
+
+// cancellingStrategy places a GTC limit order and cancels it on the next bar
+type cancellingStrategy struct {
+	reason string
+}
+
+func (s *cancellingStrategy) Name() string {
+	return "Cancelling"
+}
+
+func (s *cancellingStrategy) Generate(ctx *Context) (Signal, error) {
+	for _, cancelled := range ctx.Cancellations {
+		s.reason = cancelled.Reason
+	}
+
+	switch ctx.BarCount() {
+	case 1:
+		_, err := ctx.SubmitOrder(NewLimitOrder(OrderSideBuy, 10, 92, TimeInForceGTC))
+		return SignalHold, err
+	case 2:
+		for _, order := range ctx.PendingOrders() {
+			ctx.CancelOrder(order.ID)
+		}
+	}
+
+	return SignalHold, nil
+}
@@ -1,18 +1,42 @@
 package strategy
 
-import "time"
+import (
+	"math"
+	"time"
+)
 
+// Position holds the shares of a symbol, Shares is negative for a short position
 type Position struct {
-	Symbol     string
-	Shares     float64
-	EntryPrice float64
-	EntryTime  time.Time
+	Symbol          string
+	Shares          float64
+	EntryPrice      float64
+	EntryTime       time.Time
+	EntryCommission float64
+	BorrowFees      float64 // fees accrued on a short position not yet realized
+	Dividends       float64 // dividends received, or paid when short, not yet realized
+}
+
+// copy returns a copy of the position, nil stays nil
+func (p *Position) copy() *Position {
+	if p == nil {
+		return nil
+	}
+	c := *p
+	return &c
 }
 
 func (p *Position) IsOpen() bool {
+	return p.Shares != 0
+}
+
+func (p *Position) IsLong() bool {
 	return p.Shares > 0
 }
 
+func (p *Position) IsShort() bool {
+	return p.Shares < 0
+}
+
 func (p *Position) CostBasis() float64 {
 	return float64(p.Shares) * p.EntryPrice
 }
@@ -29,5 +53,40 @@ func (p *Position) ProfitLossPercent(currentPrice float64) float64 {
 	if p.CostBasis() == 0 {
 		return 0
 	}
-	return (p.ProfitLoss(currentPrice) / p.CostBasis()) * 100
+	return (p.ProfitLoss(currentPrice) / math.Abs(p.CostBasis())) * 100
+}
+
+// add grows the position in the direction of shares, averaging the entry price
+func (p *Position) add(shares, price, commission float64) {
+	total := p.Shares + shares
+	if total != 0 {
+		p.EntryPrice = (p.Shares*p.EntryPrice + shares*price) / total
+	}
+	p.Shares = total
+	p.EntryCommission += commission
+}
+
+// reduce closes shares of the position towards flat and returns the part of
+// the entry commission, borrow fees and dividends that belongs to the closed shares
+func (p *Position) reduce(shares float64) (float64, float64, float64) {
+	held := math.Abs(p.Shares)
+	if held == 0 {
+		return 0, 0, 0
+	}
+
+	fraction := math.Min(shares/held, 1)
+	commission := p.EntryCommission * fraction
+	borrowFees := p.BorrowFees * fraction
+	dividends := p.Dividends * fraction
+
+	if p.Shares > 0 {
+		p.Shares -= shares
+	} else {
+		p.Shares += shares
+	}
+	p.EntryCommission -= commission
+	p.BorrowFees -= borrowFees
+	p.Dividends -= dividends
+
+	return commission, borrowFees, dividends
 }
@@ -0,0 +1,208 @@
+package strategy
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+	"github.com/wreckitral/distributed-backtesting-platform/internal/marketdata"
+)
+
+// RebalanceFrequency controls when a weight strategy is asked for targets
+type RebalanceFrequency int
+
+const (
+	RebalanceDaily   RebalanceFrequency = iota // every bar
+	RebalanceWeekly                            // first bar of each week
+	RebalanceMonthly                           // first bar of each month
+	RebalanceDrift                             // every bar, trading only past the drift threshold
+)
+
+func (f RebalanceFrequency) String() string {
+	switch f {
+	case RebalanceDaily:
+		return "DAILY"
+	case RebalanceWeekly:
+		return "WEEKLY"
+	case RebalanceMonthly:
+		return "MONTHLY"
+	case RebalanceDrift:
+		return "DRIFT"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// RebalanceConfig describes how targets from a weight strategy turn into trades
+type RebalanceConfig struct {
+	Frequency RebalanceFrequency
+
+	// only rebalance once a weight is this far from its target, zero always trades
+	DriftThreshold float64
+
+	// trades smaller than either minimum are skipped
+	MinShares   float64
+	MinNotional float64
+}
+
+// DefaultRebalanceConfig rebalances monthly without thresholds
+func DefaultRebalanceConfig() RebalanceConfig {
+	return RebalanceConfig{Frequency: RebalanceMonthly}
+}
+
+// NewRebalanceConfig maps the rebalance settings of a backtest to a config
+func NewRebalanceConfig(settings domain.RebalanceSettings) RebalanceConfig {
+	config := RebalanceConfig{
+		Frequency:   RebalanceMonthly,
+		MinShares:   settings.MinShares,
+		MinNotional: settings.MinNotional,
+	}
+
+	switch settings.Frequency {
+	case domain.RebalanceFrequencyDaily:
+		config.Frequency = RebalanceDaily
+	case domain.RebalanceFrequencyWeekly:
+		config.Frequency = RebalanceWeekly
+	case domain.RebalanceFrequencyDrift:
+		config.Frequency = RebalanceDrift
+		config.DriftThreshold = settings.DriftThreshold
+	}
+
+	return config
+}
+
+// WithRebalance sets the rebalance schedule of a weight executor
+func WithRebalance(config RebalanceConfig) ExecutorOption {
+	return func(e *Executor) {
+		e.rebalance = config
+	}
+}
+
+// NewWeightExecutor runs a weight strategy, by default rebalancing monthly
+func NewWeightExecutor(strategy WeightStrategy, provider marketdata.Provider, initialCash float64, opts ...ExecutorOption) *Executor {
+	e := NewExecutor(nil, provider, initialCash, append([]ExecutorOption{WithRebalance(DefaultRebalanceConfig())}, opts...)...)
+	e.weights = strategy
+	return e
+}
+
+// due reports whether the schedule asks for targets at now, prev is the
+// timestamp of the previous slice and zero on the first one
+func (c RebalanceConfig) due(prev, now time.Time) bool {
+	if prev.IsZero() {
+		return true
+	}
+
+	switch c.Frequency {
+	case RebalanceWeekly:
+		prevYear, prevWeek := prev.ISOWeek()
+		year, week := now.ISOWeek()
+		return year != prevYear || week != prevWeek
+	case RebalanceMonthly:
+		return now.Year() != prev.Year() || now.Month() != prev.Month()
+	default:
+		return true
+	}
+}
+
+// rebalanceTo asks the weight strategy for targets and queues the market
+// orders that move the portfolio there at the last closes. sells go first so
+// they free cash before the buys fill
+func (e *Executor) rebalanceTo(state *runState, slice marketdata.TimeSlice) error {
+	fills, cancelled := state.takeEvents("")
+
+	strategyCtx := &Context{
+		Timestamp:     slice.Timestamp,
+		Cash:          state.cash,
+		Equity:        state.equity(),
+		Universe:      state.symbols,
+		Bars:          state.latestBars(),
+		Positions:     state.openPositions(),
+		Fills:         fills,
+		Cancellations: cancelled,
+		pending:       state.openOrders(),
+		histories:     state.histories,
+	}
+
+	weights, err := e.weights.Weights(strategyCtx)
+	if err != nil {
+		return err
+	}
+
+	for symbol, weight := range weights {
+		if !slices.Contains(state.symbols, symbol) {
+			return fmt.Errorf("weight for %s which is not in the universe", symbol)
+		}
+		if weight < 0 && e.margin == nil {
+			return fmt.Errorf("negative weight for %s without short selling", symbol)
+		}
+	}
+
+	equity := state.equity()
+	if equity <= 0 {
+		return nil
+	}
+
+	if e.rebalance.DriftThreshold > 0 && state.drift(weights, equity) < e.rebalance.DriftThreshold {
+		return nil
+	}
+
+	// targets replace whatever the last rebalance left on the book
+	for _, order := range state.orders {
+		if order.IsOpen() && order.Type == OrderTypeMarket {
+			state.cancel(order, "replaced by rebalance")
+		}
+	}
+	state.prune()
+
+	var sells, buys []Order
+	for _, symbol := range state.symbols {
+		price := state.marks[symbol]
+		if price <= 0 {
+			continue
+		}
+
+		held := 0.0
+		if position := state.positions[symbol]; position != nil {
+			held = position.Shares
+		}
+
+		delta := weights[symbol]*equity/price - held
+		shares := math.Abs(delta)
+		if shares <= shareEpsilon || shares < e.rebalance.MinShares || shares*price < e.rebalance.MinNotional {
+			continue
+		}
+
+		order := NewMarketOrder(OrderSideBuy, shares)
+		order.Symbol = symbol
+		if delta < 0 {
+			order.Side = OrderSideSell
+			sells = append(sells, order)
+		} else {
+			buys = append(buys, order)
+		}
+	}
+
+	for _, order := range append(sells, buys...) {
+		if _, err := strategyCtx.SubmitOrder(order); err != nil {
+			return err
+		}
+	}
+
+	e.acceptOrders(state, strategyCtx, slice)
+	return nil
+}
+
+// drift is the largest distance between a current weight and its target
+func (s *runState) drift(weights map[string]float64, equity float64) float64 {
+	drift := 0.0
+	for _, symbol := range s.symbols {
+		current := 0.0
+		if position := s.positions[symbol]; position != nil {
+			current = position.Value(s.marks[symbol]) / equity
+		}
+		drift = math.Max(drift, math.Abs(current-weights[symbol]))
+	}
+	return drift
+}
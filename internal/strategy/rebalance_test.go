@@ -0,0 +1,150 @@
+package strategy
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+// fixedWeights always asks for the same targets
+type fixedWeights struct {
+	weights map[string]float64
+}
+
+func (s *fixedWeights) Name() string {
+	return "Fixed Weights"
+}
+
+func (s *fixedWeights) Weights(ctx *Context) (map[string]float64, error) {
+	return s.weights, nil
+}
+
+func newRebalanceProvider() *memoryProvider {
+	provider := newMemoryProvider("AAA", [][4]float64{
+		{100, 100, 100, 100},
+		{100, 100, 100, 100},
+		{100, 100, 100, 100},
+		{100, 100, 100, 100},
+	})
+	provider.bars["BBB"] = newMemoryProvider("BBB", [][4]float64{
+		{50, 50, 50, 50},
+		{50, 50, 50, 50},
+		{100, 100, 100, 100},
+		{100, 100, 100, 100},
+	}).bars["BBB"]
+	return provider
+}
+
+func TestWeightExecutorRebalances(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		config RebalanceConfig
+		trades int
+	}{
+		// BBB doubling pushes it to 2/3 of the book, daily rebalancing sells 25 BBB for 25 AAA
+		{"daily", RebalanceConfig{Frequency: RebalanceDaily}, 4},
+		{"drift below threshold", RebalanceConfig{Frequency: RebalanceDrift, DriftThreshold: 0.2}, 2},
+		{"drift above threshold", RebalanceConfig{Frequency: RebalanceDrift, DriftThreshold: 0.1}, 4},
+		{"below minimum notional", RebalanceConfig{Frequency: RebalanceDaily, MinNotional: 3000}, 2},
+		{"monthly", RebalanceConfig{Frequency: RebalanceMonthly}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strat := &fixedWeights{weights: map[string]float64{"AAA": 0.5, "BBB": 0.5}}
+			executor := NewWeightExecutor(strat, newRebalanceProvider(), 10000.0, WithRebalance(tt.config))
+
+			result, err := executor.SimulatePortfolio(context.Background(), []string{"AAA", "BBB"}, start, end)
+			if err != nil {
+				t.Fatalf("Executor failed: %v", err)
+			}
+
+			if len(result.Trades) != tt.trades {
+				t.Fatalf("Expected %d trades, got %d", tt.trades, len(result.Trades))
+			}
+
+			held := map[string]float64{}
+			for _, trade := range result.Trades {
+				if trade.IsClosing() {
+					held[trade.Symbol] -= trade.Quantity
+				} else {
+					held[trade.Symbol] += trade.Quantity
+				}
+			}
+
+			expected := map[string]float64{"AAA": 50, "BBB": 100}
+			if tt.trades == 4 {
+				expected = map[string]float64{"AAA": 75, "BBB": 75}
+			}
+			for symbol, shares := range expected {
+				if math.Abs(held[symbol]-shares) > 1e-6 {
+					t.Errorf("Expected %.0f %s, got %.4f", shares, symbol, held[symbol])
+				}
+			}
+
+			last := result.EquityCurve[len(result.EquityCurve)-1]
+			if math.Abs(last.Equity-15000) > 1e-6 {
+				t.Errorf("Expected equity 15000, got %.2f", last.Equity)
+			}
+		})
+	}
+}
+
+func TestWeightExecutorRejectsUnknownSymbol(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	strat := &fixedWeights{weights: map[string]float64{"CCC": 1}}
+	_, err := NewWeightExecutor(strat, newRebalanceProvider(), 10000.0).
+		SimulatePortfolio(context.Background(), []string{"AAA", "BBB"}, start, end)
+	if err == nil {
+		t.Error("Expected an error for a weight outside the universe")
+	}
+
+	strat = &fixedWeights{weights: map[string]float64{"AAA": -0.5}}
+	_, err = NewWeightExecutor(strat, newRebalanceProvider(), 10000.0).
+		SimulatePortfolio(context.Background(), []string{"AAA", "BBB"}, start, end)
+	if err == nil {
+		t.Error("Expected an error for a short weight without short selling")
+	}
+}
+
+func TestRebalanceSchedule(t *testing.T) {
+	friday := time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)
+	monday := time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	tuesday := time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)
+	february := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	weekly := RebalanceConfig{Frequency: RebalanceWeekly}
+	monthly := RebalanceConfig{Frequency: RebalanceMonthly}
+
+	if !weekly.due(time.Time{}, friday) || !monthly.due(time.Time{}, friday) {
+		t.Error("Expected the first bar to always rebalance")
+	}
+	if !weekly.due(friday, monday) || weekly.due(monday, tuesday) {
+		t.Error("Expected weekly rebalancing on the first bar of the week only")
+	}
+	if monthly.due(friday, tuesday) || !monthly.due(tuesday, february) {
+		t.Error("Expected monthly rebalancing on the first bar of the month only")
+	}
+}
+
+func TestEqualWeight(t *testing.T) {
+	ctx := &Context{
+		Universe: []string{"AAA", "BBB", "CCC"},
+		Bars:     newRebalanceProvider().latestBars(),
+	}
+
+	weights, err := NewEqualWeight().Weights(ctx)
+	if err != nil {
+		t.Fatalf("Weights failed: %v", err)
+	}
+
+	if len(weights) != 2 || weights["AAA"] != 0.5 || weights["BBB"] != 0.5 {
+		t.Errorf("Expected half in each symbol with data, got %v", weights)
+	}
+}
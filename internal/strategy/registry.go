@@ -0,0 +1,293 @@
+package strategy
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+)
+
+// ParamType is the type of a strategy parameter
+type ParamType int
+
+const (
+	ParamTypeInt ParamType = iota
+	ParamTypeFloat
+	ParamTypeBool
+)
+
+func (p ParamType) String() string {
+	switch p {
+	case ParamTypeInt:
+		return "INT"
+	case ParamTypeFloat:
+		return "FLOAT"
+	case ParamTypeBool:
+		return "BOOL"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// ParamSpec describes one parameter a strategy accepts. Min and Max bound
+// numeric parameters, nil leaves that side open
+type ParamSpec struct {
+	Name        string
+	Type        ParamType
+	Description string
+	Default     any
+	Min         *float64
+	Max         *float64
+}
+
+// Params holds validated parameter values by name. ints are int, floats are
+// float64 and bools are bool
+type Params map[string]any
+
+func (p Params) Int(name string) int {
+	value, _ := p[name].(int)
+	return value
+}
+
+func (p Params) Float(name string) float64 {
+	switch value := p[name].(type) {
+	case float64:
+		return value
+	case int:
+		return float64(value)
+	default:
+		return 0
+	}
+}
+
+func (p Params) Bool(name string) bool {
+	value, _ := p[name].(bool)
+	return value
+}
+
+// Definition is a registered strategy. exactly one of New and NewWeights is
+// set, depending on whether it trades on signals or target weights
+type Definition struct {
+	ID          string
+	Name        string
+	Description string
+	Params      []ParamSpec
+	New         func(params Params) (Strategy, error)
+	NewWeights  func(params Params) (WeightStrategy, error)
+}
+
+// Kind is SIGNAL or WEIGHTS
+func (d Definition) Kind() string {
+	if d.NewWeights != nil {
+		return "WEIGHTS"
+	}
+	return "SIGNAL"
+}
+
+// Validate checks raw parameter values, as decoded from JSON, against the
+// schema and fills in defaults for the ones left out
+func (d Definition) Validate(raw map[string]any) (Params, error) {
+	known := make(map[string]bool, len(d.Params))
+	params := make(Params, len(d.Params))
+
+	for _, spec := range d.Params {
+		known[spec.Name] = true
+
+		value, ok := raw[spec.Name]
+		if !ok || value == nil {
+			value = spec.Default
+		}
+
+		converted, err := spec.convert(value)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %s: %w", spec.Name, err)
+		}
+		params[spec.Name] = converted
+	}
+
+	for name := range raw {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown parameter %s for strategy %s", name, d.ID)
+		}
+	}
+
+	return params, nil
+}
+
+// Build creates the strategy from validated parameters, one of the returned
+// strategies is nil
+func (d Definition) Build(params Params) (Strategy, WeightStrategy, error) {
+	if d.NewWeights != nil {
+		weights, err := d.NewWeights(params)
+		return nil, weights, err
+	}
+
+	strategy, err := d.New(params)
+	return strategy, nil, err
+}
+
+// convert coerces a JSON value to the parameter type and checks its range
+func (s ParamSpec) convert(value any) (any, error) {
+	if s.Type == ParamTypeBool {
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected a bool, got %v", value)
+		}
+		return b, nil
+	}
+
+	var number float64
+	switch v := value.(type) {
+	case float64:
+		number = v
+	case int:
+		number = float64(v)
+	default:
+		return nil, fmt.Errorf("expected a number, got %v", value)
+	}
+
+	if math.IsNaN(number) || math.IsInf(number, 0) {
+		return nil, fmt.Errorf("expected a finite number, got %v", number)
+	}
+	if s.Min != nil && number < *s.Min {
+		return nil, fmt.Errorf("must be at least %v, got %v", *s.Min, number)
+	}
+	if s.Max != nil && number > *s.Max {
+		return nil, fmt.Errorf("must be at most %v, got %v", *s.Max, number)
+	}
+
+	if s.Type == ParamTypeInt {
+		if number != math.Trunc(number) {
+			return nil, fmt.Errorf("expected an integer, got %v", number)
+		}
+		return int(number), nil
+	}
+
+	return number, nil
+}
+
+// Registry maps strategy ids to their definitions
+type Registry struct {
+	definitions map[string]Definition
+	mu          sync.RWMutex
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		definitions: make(map[string]Definition),
+	}
+}
+
+// Register adds a strategy, its defaults must pass its own schema
+func (r *Registry) Register(def Definition) error {
+	if def.ID == "" {
+		return fmt.Errorf("strategy id is required")
+	}
+	if (def.New == nil) == (def.NewWeights == nil) {
+		return fmt.Errorf("strategy %s needs exactly one factory", def.ID)
+	}
+	if _, err := def.Validate(nil); err != nil {
+		return fmt.Errorf("invalid defaults for strategy %s: %w", def.ID, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.definitions[def.ID]; exists {
+		return fmt.Errorf("strategy %s is already registered", def.ID)
+	}
+	r.definitions[def.ID] = def
+
+	return nil
+}
+
+func (r *Registry) Get(id string) (Definition, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	def, ok := r.definitions[id]
+	return def, ok
+}
+
+// List returns every definition sorted by id
+func (r *Registry) List() []Definition {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	defs := make([]Definition, 0, len(r.definitions))
+	for _, def := range r.definitions {
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool {
+		return defs[i].ID < defs[j].ID
+	})
+
+	return defs
+}
+
+// DefaultRegistry holds the built-in strategies
+func DefaultRegistry() *Registry {
+	r := NewRegistry()
+
+	for _, def := range builtinStrategies() {
+		if err := r.Register(def); err != nil {
+			panic(err)
+		}
+	}
+
+	return r
+}
+
+func builtinStrategies() []Definition {
+	smaParams := func(short, long int) []ParamSpec {
+		return []ParamSpec{
+			{Name: "short_period", Type: ParamTypeInt, Description: "bars in the fast moving average", Default: short, Min: bound(1)},
+			{Name: "long_period", Type: ParamTypeInt, Description: "bars in the slow moving average", Default: long, Min: bound(2)},
+		}
+	}
+
+	newSMA := func(params Params) (Strategy, error) {
+		short, long := params.Int("short_period"), params.Int("long_period")
+		if short >= long {
+			return nil, fmt.Errorf("short_period %d must be below long_period %d", short, long)
+		}
+		return NewSMACrossover(short, long), nil
+	}
+
+	return []Definition{
+		{
+			ID:          "buy_hold",
+			Name:        "Buy and Hold",
+			Description: "Buys on the first bar and holds until the end",
+			New: func(params Params) (Strategy, error) {
+				return NewBuyHold(), nil
+			},
+		},
+		{
+			ID:          "sma_crossover",
+			Name:        "SMA Crossover",
+			Description: "Goes long when the fast moving average crosses above the slow one and exits on the cross below",
+			Params:      smaParams(10, 30),
+			New:         newSMA,
+		},
+		{
+			ID:          "sma_crossover_20_50",
+			Name:        "SMA Crossover 20/50",
+			Description: "SMA crossover preset with 20 and 50 bar averages",
+			Params:      smaParams(20, 50),
+			New:         newSMA,
+		},
+		{
+			ID:          "equal_weight",
+			Name:        "Equal Weight",
+			Description: "Holds every symbol of the universe at the same weight",
+			NewWeights: func(params Params) (WeightStrategy, error) {
+				return NewEqualWeight(), nil
+			},
+		},
+	}
+}
+
+func bound(v float64) *float64 {
+	return &v
+}
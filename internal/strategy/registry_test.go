@@ -0,0 +1,85 @@
+package strategy
+
+import (
+	"testing"
+)
+
+func TestDefinitionValidate(t *testing.T) {
+	def, ok := DefaultRegistry().Get("sma_crossover")
+	if !ok {
+		t.Fatal("Expected sma_crossover to be registered")
+	}
+
+	params, err := def.Validate(nil)
+	if err != nil {
+		t.Fatalf("Expected defaults to validate, got: %v", err)
+	}
+	if params.Int("short_period") != 10 || params.Int("long_period") != 30 {
+		t.Errorf("Expected default periods 10 and 30, got %v", params)
+	}
+
+	// JSON numbers decode as float64
+	params, err = def.Validate(map[string]any{"short_period": 5.0})
+	if err != nil {
+		t.Fatalf("Expected valid parameters, got: %v", err)
+	}
+	if params.Int("short_period") != 5 || params.Int("long_period") != 30 {
+		t.Errorf("Expected periods 5 and 30, got %v", params)
+	}
+
+	invalid := []map[string]any{
+		{"short_period": 5.5},
+		{"short_period": 0.0},
+		{"short_period": "5"},
+		{"fast": 5.0},
+	}
+	for _, raw := range invalid {
+		if _, err := def.Validate(raw); err == nil {
+			t.Errorf("Expected %v to be rejected", raw)
+		}
+	}
+
+	params, _ = def.Validate(map[string]any{"short_period": 30.0})
+	if _, _, err := def.Build(params); err == nil {
+		t.Error("Expected short_period equal to long_period to be rejected")
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	registry := DefaultRegistry()
+
+	defs := registry.List()
+	ids := make([]string, len(defs))
+	for i, def := range defs {
+		ids[i] = def.ID
+	}
+
+	expected := []string{"buy_hold", "equal_weight", "sma_crossover", "sma_crossover_20_50"}
+	if len(ids) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, ids)
+	}
+	for i := range expected {
+		if ids[i] != expected[i] {
+			t.Errorf("Expected %s at %d, got %s", expected[i], i, ids[i])
+		}
+	}
+
+	def, _ := registry.Get("equal_weight")
+	strat, weights, err := def.Build(Params{})
+	if err != nil || strat != nil || weights == nil {
+		t.Errorf("Expected equal_weight to build a weight strategy, got %v %v %v", strat, weights, err)
+	}
+
+	if err := registry.Register(def); err == nil {
+		t.Error("Expected a duplicate id to be rejected")
+	}
+
+	bad := Definition{
+		ID:     "bad_default",
+		Params: []ParamSpec{{Name: "period", Type: ParamTypeInt, Default: 0, Min: bound(1)}},
+		New:    func(params Params) (Strategy, error) { return NewBuyHold(), nil },
+	}
+	if err := registry.Register(bad); err == nil {
+		t.Error("Expected a default outside its range to be rejected")
+	}
+}
@@ -0,0 +1,228 @@
+package strategy
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+)
+
+// SizingInput is what a sizer knows when an unsized order fills.
+// history holds the bars up to and including the fill bar
+type SizingInput struct {
+	Price   float64
+	Equity  float64
+	History []domain.Bar
+	Trades  []domain.Trade // trades executed so far
+}
+
+// Sizer decides how many shares an order without a quantity trades. the
+// executor still caps the result by the cash or margin available
+type Sizer interface {
+	Size(in SizingInput) float64
+}
+
+// AllIn uses everything the account can afford, the default
+type AllIn struct{}
+
+func (AllIn) Size(in SizingInput) float64 {
+	return math.Inf(1)
+}
+
+// FixedShares always trades the same number of shares
+type FixedShares struct {
+	Shares float64
+}
+
+func (s FixedShares) Size(in SizingInput) float64 {
+	return s.Shares
+}
+
+// FixedNotional trades the same dollar amount every time
+type FixedNotional struct {
+	Notional float64
+}
+
+func (s FixedNotional) Size(in SizingInput) float64 {
+	if in.Price <= 0 {
+		return 0
+	}
+	return s.Notional / in.Price
+}
+
+// PercentOfEquity trades a percent of the marked to market account value
+type PercentOfEquity struct {
+	Percent float64
+}
+
+func (s PercentOfEquity) Size(in SizingInput) float64 {
+	if in.Price <= 0 {
+		return 0
+	}
+	return in.Equity * s.Percent / 100 / in.Price
+}
+
+// ATRRisk sizes so a move of one average true range costs RiskPercent of equity
+type ATRRisk struct {
+	RiskPercent float64
+	Period      int
+}
+
+func (s ATRRisk) Size(in SizingInput) float64 {
+	period := s.Period
+	if period <= 0 {
+		period = 14
+	}
+
+	atr, err := ATR(in.History, period)
+	if err != nil || atr <= 0 {
+		return 0
+	}
+	return in.Equity * s.RiskPercent / 100 / atr
+}
+
+// VolatilityTarget sizes the position so its annualized volatility, measured
+// from recent close-to-close returns, matches Target. the returns are
+// annualized by the spacing of the bars. never levers above equity
+type VolatilityTarget struct {
+	Target   float64
+	Lookback int
+}
+
+func (s VolatilityTarget) Size(in SizingInput) float64 {
+	lookback := s.Lookback
+	if lookback <= 0 {
+		lookback = 20
+	}
+
+	vol := realizedVolatility(in.History, lookback) * math.Sqrt(barsPerYear(in.History, lookback))
+	if vol <= 0 || in.Price <= 0 {
+		return 0
+	}
+
+	weight := math.Min(s.Target/vol, 1)
+	return in.Equity * weight / in.Price
+}
+
+// tradingSession is the length of a regular trading day, used to count
+// intraday bars per day
+const tradingSession = 6*time.Hour + 30*time.Minute
+
+// barsPerYear is the annualization factor of the returns between the last
+// lookback bars. like the metrics it follows from the median spacing: 252 for
+// daily bars, 52 weekly, 12 monthly, 4 quarterly and 1 for anything slower.
+// intraday bars count as many per day as fit in a trading session
+func barsPerYear(bars []domain.Bar, lookback int) float64 {
+	if len(bars) > lookback+1 {
+		bars = bars[len(bars)-lookback-1:]
+	}
+	if len(bars) < 2 {
+		return tradingDaysPerYear
+	}
+
+	gaps := make([]time.Duration, 0, len(bars)-1)
+	for i := 1; i < len(bars); i++ {
+		gaps = append(gaps, bars[i].Timestamp.Sub(bars[i-1].Timestamp))
+	}
+	sort.Slice(gaps, func(i, j int) bool { return gaps[i] < gaps[j] })
+	spacing := gaps[len(gaps)/2]
+
+	days := spacing.Hours() / 24
+	switch {
+	case spacing <= 0:
+		return tradingDaysPerYear
+	case spacing < 20*time.Hour:
+		return tradingDaysPerYear * math.Max(float64(tradingSession)/float64(spacing), 1)
+	case days <= 4:
+		return tradingDaysPerYear
+	case days <= 10:
+		return 52
+	case days <= 45:
+		return 12
+	case days <= 120:
+		return 4
+	default:
+		return 1
+	}
+}
+
+// Kelly bets Fraction of the Kelly criterion estimated from the win rate and
+// payoff ratio of the last Lookback closed trades. until MinTrades trades have
+// closed it bets Fraction of equity
+type Kelly struct {
+	Fraction  float64
+	Lookback  int
+	MinTrades int
+}
+
+func (s Kelly) Size(in SizingInput) float64 {
+	if in.Price <= 0 {
+		return 0
+	}
+
+	minTrades := s.MinTrades
+	if minTrades <= 0 {
+		minTrades = 10
+	}
+
+	var pnls []float64
+	for _, trade := range in.Trades {
+		if trade.IsClosing() {
+			pnls = append(pnls, trade.PnL)
+		}
+	}
+	if s.Lookback > 0 && len(pnls) > s.Lookback {
+		pnls = pnls[len(pnls)-s.Lookback:]
+	}
+
+	if len(pnls) < minTrades {
+		return in.Equity * s.Fraction / in.Price
+	}
+
+	wins, losses := 0, 0
+	grossWin, grossLoss := 0.0, 0.0
+	for _, pnl := range pnls {
+		if pnl > 0 {
+			wins++
+			grossWin += pnl
+		} else if pnl < 0 {
+			losses++
+			grossLoss -= pnl
+		}
+	}
+
+	if wins == 0 {
+		return 0
+	}
+	if losses == 0 {
+		return in.Equity * s.Fraction / in.Price
+	}
+	winRate := float64(wins) / float64(wins+losses)
+
+	// f = W - (1 - W) / R with R the average win over the average loss
+	payoff := (grossWin / float64(wins)) / (grossLoss / float64(losses))
+	f := math.Max(math.Min(winRate-(1-winRate)/payoff, 1), 0)
+
+	return in.Equity * s.Fraction * f / in.Price
+}
+
+// NewSizer maps the sizing settings of a backtest to a sizer
+func NewSizer(settings domain.SizingSettings) Sizer {
+	switch settings.Method {
+	case domain.SizingMethodFixedShares:
+		return FixedShares{Shares: settings.Value}
+	case domain.SizingMethodFixedNotional:
+		return FixedNotional{Notional: settings.Value}
+	case domain.SizingMethodPercentEquity:
+		return PercentOfEquity{Percent: settings.Value}
+	case domain.SizingMethodATR:
+		return ATRRisk{RiskPercent: settings.Value, Period: settings.Lookback}
+	case domain.SizingMethodVolatilityTarget:
+		return VolatilityTarget{Target: settings.Value, Lookback: settings.Lookback}
+	case domain.SizingMethodKelly:
+		return Kelly{Fraction: settings.Value, Lookback: settings.Lookback}
+	default:
+		return AllIn{}
+	}
+}
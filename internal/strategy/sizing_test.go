@@ -0,0 +1,172 @@
+package strategy
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+)
+
+func TestSizers(t *testing.T) {
+	// true range of 4 on every bar
+	bars := []domain.Bar{
+		{High: 102, Low: 98, Close: 100},
+		{High: 102, Low: 98, Close: 100},
+		{High: 102, Low: 98, Close: 100},
+	}
+	in := SizingInput{Price: 50, Equity: 10000, History: bars}
+
+	tests := []struct {
+		name     string
+		sizer    Sizer
+		expected float64
+	}{
+		{"fixed shares", FixedShares{Shares: 25}, 25},
+		{"fixed notional", FixedNotional{Notional: 1000}, 20},
+		{"percent of equity", PercentOfEquity{Percent: 50}, 100},
+		{"atr risk", ATRRisk{RiskPercent: 1, Period: 2}, 25},
+		{"kelly without history", Kelly{Fraction: 0.5}, 100},
+	}
+
+	for _, tt := range tests {
+		got := tt.sizer.Size(in)
+		if math.Abs(got-tt.expected) > 1e-9 {
+			t.Errorf("%s: expected %.4f shares, got %.4f", tt.name, tt.expected, got)
+		}
+	}
+
+	if !math.IsInf(AllIn{}.Size(in), 1) {
+		t.Errorf("Expected AllIn to leave sizing to the available cash")
+	}
+}
+
+func TestVolatilityTargetScalesDown(t *testing.T) {
+	calm := []domain.Bar{{Close: 100}, {Close: 100.1}, {Close: 100}, {Close: 100.1}}
+	wild := []domain.Bar{{Close: 100}, {Close: 110}, {Close: 95}, {Close: 108}}
+
+	sizer := VolatilityTarget{Target: 0.2, Lookback: 10}
+
+	calmSize := sizer.Size(SizingInput{Price: 100, Equity: 10000, History: calm})
+	wildSize := sizer.Size(SizingInput{Price: 100, Equity: 10000, History: wild})
+
+	if calmSize != 100 {
+		t.Errorf("Expected a calm market to be capped at equity, got %.4f shares", calmSize)
+	}
+	if wildSize <= 0 || wildSize >= calmSize {
+		t.Errorf("Expected a smaller position in a volatile market, got %.4f shares", wildSize)
+	}
+}
+
+func TestVolatilityTargetAnnualizesBySpacing(t *testing.T) {
+	closes := []float64{100, 101, 100, 101, 100, 101}
+	spaced := func(step time.Duration) []domain.Bar {
+		bars := make([]domain.Bar, len(closes))
+		for i, c := range closes {
+			bars[i] = domain.Bar{Timestamp: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(i) * step), Close: c}
+		}
+		return bars
+	}
+
+	tests := []struct {
+		name string
+		step time.Duration
+		want float64
+	}{
+		{name: "daily", step: 24 * time.Hour, want: 252},
+		{name: "weekly", step: 7 * 24 * time.Hour, want: 52},
+		{name: "monthly", step: 30 * 24 * time.Hour, want: 12},
+		{name: "hourly", step: time.Hour, want: 252 * 6.5},
+		{name: "no timestamps", step: 0, want: 252},
+	}
+
+	sizer := VolatilityTarget{Target: 0.05, Lookback: 10}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bars := spaced(tt.step)
+			if got := barsPerYear(bars, 10); got != tt.want {
+				t.Errorf("Expected %v bars per year, got %v", tt.want, got)
+			}
+
+			vol := realizedVolatility(bars, 10) * math.Sqrt(tt.want)
+			want := 10000 * math.Min(0.05/vol, 1) / 100
+			if got := sizer.Size(SizingInput{Price: 100, Equity: 10000, History: bars}); math.Abs(got-want) > 1e-9 {
+				t.Errorf("Expected %.4f shares, got %.4f", want, got)
+			}
+		})
+	}
+
+	// the same returns are less volatile per year on weekly bars than daily ones
+	daily := sizer.Size(SizingInput{Price: 100, Equity: 10000, History: spaced(24 * time.Hour)})
+	weekly := sizer.Size(SizingInput{Price: 100, Equity: 10000, History: spaced(7 * 24 * time.Hour)})
+	if weekly <= daily {
+		t.Errorf("Expected a larger position on weekly bars, got %.4f against %.4f daily", weekly, daily)
+	}
+}
+
+func TestKellyFromTradeHistory(t *testing.T) {
+	var trades []domain.Trade
+	for i := 0; i < 10; i++ {
+		pnl := 200.0
+		if i%5 == 0 {
+			pnl = -100
+		}
+		trades = append(trades, domain.Trade{Direction: domain.TradeDirectionSell, PnL: pnl})
+	}
+
+	// 80% winners paying 2:1, f = 0.8 - 0.2/2 = 0.7
+	sizer := Kelly{Fraction: 0.5, MinTrades: 10}
+	got := sizer.Size(SizingInput{Price: 100, Equity: 10000, Trades: trades})
+
+	if math.Abs(got-35) > 1e-9 {
+		t.Errorf("Expected half Kelly of 35 shares, got %.4f", got)
+	}
+}
+
+func TestExecutorSizing(t *testing.T) {
+	provider := newMemoryProvider("TEST", [][4]float64{
+		{100, 100, 100, 100},
+		{100, 100, 100, 100},
+		{100, 100, 100, 100},
+	})
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	strat := &scriptedStrategy{signals: map[int]Signal{0: SignalBuy}}
+	trades, err := NewExecutor(strat, provider, 10000.0, WithSizer(PercentOfEquity{Percent: 25})).Run(context.Background(), "TEST", start, end)
+	if err != nil {
+		t.Fatalf("Executor failed: %v", err)
+	}
+	if len(trades) != 1 || math.Abs(trades[0].Quantity-25) > 1e-9 {
+		t.Fatalf("Expected a single 25 share buy, got %v", trades)
+	}
+
+	// an explicit quantity from the strategy wins over the sizer
+	override := &quantityStrategy{bar: 0, shares: 7}
+	trades, err = NewExecutor(override, provider, 10000.0, WithSizer(PercentOfEquity{Percent: 25})).Run(context.Background(), "TEST", start, end)
+	if err != nil {
+		t.Fatalf("Executor failed: %v", err)
+	}
+	if len(trades) != 1 || trades[0].Quantity != 7 {
+		t.Fatalf("Expected a single 7 share buy, got %v", trades)
+	}
+}
+
+// quantityStrategy buys an explicit number of shares on one bar
+type quantityStrategy struct {
+	bar    int
+	shares float64
+}
+
+func (s *quantityStrategy) Name() string {
+	return "Quantity"
+}
+
+func (s *quantityStrategy) Generate(ctx *Context) (Signal, error) {
+	if ctx.BarCount()-1 != s.bar {
+		return SignalHold, nil
+	}
+	ctx.SetQuantity(s.shares)
+	return SignalBuy, nil
+}
@@ -1,5 +1,7 @@
 package strategy
 
+import "fmt"
+
 type SMACrossover struct {
 	ShortPeriod int
 	LongPeriod  int
@@ -17,53 +19,39 @@ func (s *SMACrossover) Name() string {
 }
 
 func (s *SMACrossover) Generate(ctx *Context) (Signal, error) {
-	allBars := ctx.AllBars()
-
-	// need enough data for the long period
-	if len(allBars) < s.LongPeriod {
-		return SignalHold, nil
-	}
-
-	// get the simple moving average for short period
-	shortSMA, err := SMA(allBars, s.ShortPeriod)
+	// the averages are registered once per symbol and updated by the executor
+	short, err := ctx.Indicator(fmt.Sprintf("sma:%d", s.ShortPeriod), func() (Indicator, error) {
+		return NewRollingSMA(s.ShortPeriod)
+	})
 	if err != nil {
 		return SignalHold, err
 	}
 
-	// get the simple moving average for long period
-	longSMA, err := SMA(allBars, s.LongPeriod)
+	long, err := ctx.Indicator(fmt.Sprintf("sma:%d", s.LongPeriod), func() (Indicator, error) {
+		return NewRollingSMA(s.LongPeriod)
+	})
 	if err != nil {
 		return SignalHold, err
 	}
 
-	// need previous SMAs to detect crossover
-	if len(allBars) < s.LongPeriod+1 {
-		return SignalHold, nil
-	}
-
-	// calculate previous days SMA except the current one
-	previousBars := allBars[:len(allBars)-1]
-	prevShortSMA, err := SMA(previousBars, s.ShortPeriod)
-	if err != nil {
-		return SignalHold, err
-	}
-
-	prevLongSMA, err := SMA(previousBars, s.LongPeriod)
-	if err != nil {
-		return SignalHold, err
-	}
+	// need the previous SMAs to detect a crossover, they stay NaN until the
+	// long period has one bar to spare and every comparison below fails
+	shortSMA, longSMA := short.Value(), long.Value()
+	prevShortSMA, prevLongSMA := short.Previous(), long.Previous()
 
 	// detect crossover
 	// golden cross, short crosses above long (bullish, buy signal)
+	// covers a short or opens a long
 	if prevShortSMA <= prevLongSMA && shortSMA > longSMA {
-		if !ctx.HasPosition() {
+		if !ctx.IsLong() {
 			return SignalBuy, nil
 		}
 	}
 
 	// death cross, short crosses below long (bearish, sell signal)
+	// closes a long or opens a short when the executor allows shorting
 	if prevShortSMA >= prevLongSMA && shortSMA < longSMA {
-		if !ctx.HasPosition() {
+		if !ctx.IsShort() {
 			return SignalSell, nil
 		}
 	}
@@ -13,6 +13,15 @@ type Strategy interface {
 	Generate(ctx *Context) (Signal, error)
 }
 
+// WeightStrategy thinks in portfolio weights instead of signals. it is asked
+// for target weights on the rebalance schedule and the executor trades the
+// difference. weights are fractions of equity, negative weights are shorts
+// and symbols left out are sold
+type WeightStrategy interface {
+	Name() string
+	Weights(ctx *Context) (map[string]float64, error)
+}
+
 func (s Signal) String() string {
 	switch s {
 	case SignalHold:
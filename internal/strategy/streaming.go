@@ -0,0 +1,332 @@
+package strategy
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+)
+
+// Indicator is a technical indicator updated one bar at a time. an update is
+// O(1), or amortized O(1) for the rolling extremes, so a strategy querying it
+// every bar stays linear in the length of the backtest. values follow the
+// warmup of the batch functions in indicators.go and are NaN until then
+type Indicator interface {
+	Update(bar domain.Bar)
+	Ready() bool
+	Value() float64
+	// Previous is the value before the last update, for crossovers
+	Previous() float64
+}
+
+// output tracks the current and previous value of an indicator
+type output struct {
+	value    float64
+	previous float64
+}
+
+func newOutput() output {
+	return output{value: math.NaN(), previous: math.NaN()}
+}
+
+func (o *output) set(value float64) {
+	o.previous, o.value = o.value, value
+}
+
+func (o *output) Ready() bool {
+	return !math.IsNaN(o.value)
+}
+
+func (o *output) Value() float64 {
+	return o.value
+}
+
+func (o *output) Previous() float64 {
+	return o.previous
+}
+
+// ring is a fixed size window of the latest values with their running sums.
+// the sums are recomputed every time the window wraps so rounding errors
+// don't build up over long runs
+type ring struct {
+	values []float64
+	next   int
+	count  int
+	sum    float64
+	sumSq  float64
+}
+
+func newRing(size int) *ring {
+	return &ring{values: make([]float64, size)}
+}
+
+func (r *ring) push(v float64) {
+	old := r.values[r.next]
+	r.values[r.next] = v
+	r.next = (r.next + 1) % len(r.values)
+
+	if r.count < len(r.values) {
+		r.count++
+		r.sum += v
+		r.sumSq += v * v
+		return
+	}
+
+	if r.next == 0 {
+		r.sum, r.sumSq = 0, 0
+		for _, value := range r.values {
+			r.sum += value
+			r.sumSq += value * value
+		}
+		return
+	}
+	r.sum += v - old
+	r.sumSq += v*v - old*old
+}
+
+func (r *ring) full() bool {
+	return r.count == len(r.values)
+}
+
+func (r *ring) mean() float64 {
+	return r.sum / float64(r.count)
+}
+
+func validPeriod(period int) error {
+	if period <= 0 {
+		return fmt.Errorf("period must be positive, got %d", period)
+	}
+	return nil
+}
+
+type rollingSMA struct {
+	output
+	window *ring
+}
+
+// NewRollingSMA is the incremental SMA of the closes
+func NewRollingSMA(period int) (Indicator, error) {
+	if err := validPeriod(period); err != nil {
+		return nil, err
+	}
+	return &rollingSMA{output: newOutput(), window: newRing(period)}, nil
+}
+
+func (s *rollingSMA) Update(bar domain.Bar) {
+	s.window.push(bar.Close)
+	if s.window.full() {
+		s.set(s.window.mean())
+	} else {
+		s.set(math.NaN())
+	}
+}
+
+type rollingEMA struct {
+	output
+	period int
+	seed   *ring
+}
+
+// NewRollingEMA is the incremental EMA of the closes, seeded with the SMA of
+// the first period bars like EMA
+func NewRollingEMA(period int) (Indicator, error) {
+	if err := validPeriod(period); err != nil {
+		return nil, err
+	}
+	return &rollingEMA{output: newOutput(), period: period, seed: newRing(period)}, nil
+}
+
+func (s *rollingEMA) Update(bar domain.Bar) {
+	if !s.Ready() {
+		s.seed.push(bar.Close)
+		if s.seed.full() {
+			s.set(s.seed.mean())
+		} else {
+			s.set(math.NaN())
+		}
+		return
+	}
+
+	alpha := 2 / float64(s.period+1)
+	s.set(s.value + alpha*(bar.Close-s.value))
+}
+
+type rollingRSI struct {
+	output
+	period     int
+	prevClose  float64
+	changes    int
+	gain, loss float64
+}
+
+// NewRollingRSI is the incremental RSI of the closes with Wilder smoothing
+// like RSI
+func NewRollingRSI(period int) (Indicator, error) {
+	if err := validPeriod(period); err != nil {
+		return nil, err
+	}
+	return &rollingRSI{output: newOutput(), period: period, prevClose: math.NaN()}, nil
+}
+
+func (s *rollingRSI) Update(bar domain.Bar) {
+	if math.IsNaN(s.prevClose) {
+		s.prevClose = bar.Close
+		s.set(math.NaN())
+		return
+	}
+
+	delta := bar.Close - s.prevClose
+	s.prevClose = bar.Close
+	up, down := math.Max(delta, 0), math.Max(-delta, 0)
+	p := float64(s.period)
+
+	s.changes++
+	if s.changes <= s.period {
+		s.gain += up / p
+		s.loss += down / p
+		if s.changes < s.period {
+			s.set(math.NaN())
+			return
+		}
+	} else {
+		s.gain = (s.gain*(p-1) + up) / p
+		s.loss = (s.loss*(p-1) + down) / p
+	}
+
+	if s.loss == 0 {
+		s.set(100)
+		return
+	}
+	s.set(100 - 100/(1+s.gain/s.loss))
+}
+
+type rollingATR struct {
+	output
+	prevClose float64
+	ranges    *ring
+}
+
+// NewRollingATR is the incremental average true range like ATR
+func NewRollingATR(period int) (Indicator, error) {
+	if err := validPeriod(period); err != nil {
+		return nil, err
+	}
+	return &rollingATR{output: newOutput(), prevClose: math.NaN(), ranges: newRing(period)}, nil
+}
+
+func (s *rollingATR) Update(bar domain.Bar) {
+	if !math.IsNaN(s.prevClose) {
+		s.ranges.push(trueRange(bar, s.prevClose))
+	}
+	s.prevClose = bar.Close
+
+	if s.ranges.full() {
+		s.set(s.ranges.mean())
+	} else {
+		s.set(math.NaN())
+	}
+}
+
+type rollingStdDev struct {
+	output
+	window *ring
+}
+
+// NewRollingStdDev is the incremental population standard deviation of the
+// closes like StdDev
+func NewRollingStdDev(period int) (Indicator, error) {
+	if err := validPeriod(period); err != nil {
+		return nil, err
+	}
+	return &rollingStdDev{output: newOutput(), window: newRing(period)}, nil
+}
+
+func (s *rollingStdDev) Update(bar domain.Bar) {
+	s.window.push(bar.Close)
+	if !s.window.full() {
+		s.set(math.NaN())
+		return
+	}
+
+	mean := s.window.mean()
+	variance := s.window.sumSq/float64(s.window.count) - mean*mean
+	s.set(math.Sqrt(math.Max(variance, 0)))
+}
+
+// rollingExtreme keeps a monotonic queue of the bars that can still become
+// the extreme of the window, each bar enters and leaves it once
+type rollingExtreme struct {
+	output
+	period  int
+	highest bool
+	seen    int
+	queue   []extremeEntry
+}
+
+type extremeEntry struct {
+	index int
+	value float64
+}
+
+// NewRollingHighest is the incremental highest high of the last period bars
+func NewRollingHighest(period int) (Indicator, error) {
+	if err := validPeriod(period); err != nil {
+		return nil, err
+	}
+	return &rollingExtreme{output: newOutput(), period: period, highest: true}, nil
+}
+
+// NewRollingLowest is the incremental lowest low of the last period bars
+func NewRollingLowest(period int) (Indicator, error) {
+	if err := validPeriod(period); err != nil {
+		return nil, err
+	}
+	return &rollingExtreme{output: newOutput(), period: period}, nil
+}
+
+func (s *rollingExtreme) Update(bar domain.Bar) {
+	value := bar.Low
+	if s.highest {
+		value = bar.High
+	}
+
+	for len(s.queue) > 0 && s.dominates(value, s.queue[len(s.queue)-1].value) {
+		s.queue = s.queue[:len(s.queue)-1]
+	}
+	s.queue = append(s.queue, extremeEntry{index: s.seen, value: value})
+	s.seen++
+
+	if s.queue[0].index <= s.seen-1-s.period {
+		s.queue = s.queue[1:]
+	}
+
+	if s.seen < s.period {
+		s.set(math.NaN())
+		return
+	}
+	s.set(s.queue[0].value)
+}
+
+func (s *rollingExtreme) dominates(a, b float64) bool {
+	if s.highest {
+		return a >= b
+	}
+	return a <= b
+}
+
+// indicatorSet holds the indicators registered through contexts, by symbol
+// and key, for the length of a run
+type indicatorSet map[string]map[string]Indicator
+
+// update feeds the new bar of symbol to its indicators
+func (s indicatorSet) update(symbol string, bar domain.Bar) {
+	for _, indicator := range s[symbol] {
+		indicator.Update(bar)
+	}
+}
+
+// reset drops the indicators of symbol after its history was restated, they
+// are rebuilt from the adjusted bars on their next use
+func (s indicatorSet) reset(symbol string) {
+	delete(s, symbol)
+}
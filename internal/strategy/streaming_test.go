@@ -0,0 +1,296 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/wreckitral/distributed-backtesting-platform/internal/domain"
+)
+
+// randomWalk builds n daily bars of a seeded random walk
+func randomWalk(symbol string, n int) []domain.Bar {
+	rng := rand.New(rand.NewSource(42))
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	bars := make([]domain.Bar, n)
+	price := 100.0
+	for i := range bars {
+		open := price
+		price *= 1 + rng.NormFloat64()*0.01
+		bars[i] = domain.Bar{
+			Symbol:    symbol,
+			Timestamp: start.Add(time.Duration(i) * time.Minute),
+			Open:      open,
+			High:      math.Max(open, price) * (1 + rng.Float64()*0.005),
+			Low:       math.Min(open, price) * (1 - rng.Float64()*0.005),
+			Close:     price,
+			Volume:    int64(1000 + rng.Intn(1000)),
+		}
+	}
+	return bars
+}
+
+func TestRollingIndicatorsMatchBatch(t *testing.T) {
+	bars := randomWalk("TEST", 500)
+
+	tests := []struct {
+		name    string
+		rolling func(period int) (Indicator, error)
+		batch   func(bars []domain.Bar, period int) (float64, error)
+	}{
+		{"SMA", NewRollingSMA, SMA},
+		{"EMA", NewRollingEMA, EMA},
+		{"RSI", NewRollingRSI, RSI},
+		{"ATR", NewRollingATR, ATR},
+		{"StdDev", NewRollingStdDev, StdDev},
+		{"Highest", NewRollingHighest, func(bars []domain.Bar, period int) (float64, error) {
+			channel, err := Donchian(bars, period)
+			return channel.Upper, err
+		}},
+		{"Lowest", NewRollingLowest, func(bars []domain.Bar, period int) (float64, error) {
+			channel, err := Donchian(bars, period)
+			return channel.Lower, err
+		}},
+	}
+
+	for _, tt := range tests {
+		for _, period := range []int{1, 14, 50} {
+			t.Run(fmt.Sprintf("%s/%d", tt.name, period), func(t *testing.T) {
+				indicator, err := tt.rolling(period)
+				if err != nil {
+					t.Fatalf("Failed to build indicator: %v", err)
+				}
+
+				previous := math.NaN()
+				for i, bar := range bars {
+					indicator.Update(bar)
+					want, err := tt.batch(bars[:i+1], period)
+
+					if err != nil {
+						if indicator.Ready() {
+							t.Fatalf("Bar %d: expected warmup, got %.6f", i, indicator.Value())
+						}
+						continue
+					}
+					if !indicator.Ready() {
+						t.Fatalf("Bar %d: expected %.6f, still warming up", i, want)
+					}
+					if math.Abs(indicator.Value()-want) > 1e-9*math.Max(1, math.Abs(want)) {
+						t.Fatalf("Bar %d: expected %.12f, got %.12f", i, want, indicator.Value())
+					}
+					if got := indicator.Previous(); !(math.IsNaN(previous) && math.IsNaN(got)) && got != previous {
+						t.Fatalf("Bar %d: expected previous %.6f, got %.6f", i, previous, got)
+					}
+					previous = indicator.Value()
+				}
+			})
+		}
+	}
+
+	if _, err := NewRollingSMA(0); err == nil {
+		t.Error("Expected an error for a zero period")
+	}
+}
+
+// probeStrategy checks the registered SMA against the batch SMA every bar
+type probeStrategy struct {
+	period int
+	builds int
+	err    error
+}
+
+func (p *probeStrategy) Name() string { return "Probe" }
+
+func (p *probeStrategy) Generate(ctx *Context) (Signal, error) {
+	sma, err := ctx.Indicator("sma", func() (Indicator, error) {
+		p.builds++
+		return NewRollingSMA(p.period)
+	})
+	if err != nil {
+		return SignalHold, err
+	}
+
+	want, err := SMA(ctx.AllBars(), p.period)
+	switch {
+	case err != nil && sma.Ready():
+		p.err = fmt.Errorf("bar %d: expected warmup, got %.4f", ctx.BarCount(), sma.Value())
+	case err == nil && math.Abs(sma.Value()-want) > 1e-9:
+		p.err = fmt.Errorf("bar %d: expected %.4f, got %.4f", ctx.BarCount(), want, sma.Value())
+	}
+	if p.err != nil {
+		return SignalHold, p.err
+	}
+
+	if ctx.BarCount() == 1 {
+		return SignalBuy, nil
+	}
+	return SignalHold, nil
+}
+
+func TestContextIndicator(t *testing.T) {
+	provider := newMemoryProvider("TEST", [][4]float64{
+		{100, 100, 100, 100},
+		{102, 102, 102, 102},
+		{104, 104, 104, 104},
+		{53, 53, 53, 53},
+		{54, 54, 54, 54},
+		{55, 55, 55, 55},
+	})
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	probe := &probeStrategy{period: 3}
+	if _, err := NewExecutor(probe, provider, 10000.0).Run(context.Background(), "TEST", start, end); err != nil {
+		t.Fatalf("Executor failed: %v", err)
+	}
+	if probe.builds != 1 {
+		t.Errorf("Expected the indicator to be built once, got %d", probe.builds)
+	}
+
+	// the split restates the earlier bars so the indicator is rebuilt from them
+	actions := memoryActions{"TEST": {
+		{Symbol: "TEST", ExDate: time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC), Type: domain.CorporateActionSplit, Ratio: 2},
+	}}
+	probe = &probeStrategy{period: 3}
+	_, err := NewExecutor(probe, provider, 10000.0, WithCorporateActions(actions, false)).
+		Run(context.Background(), "TEST", start, end)
+	if err != nil {
+		t.Fatalf("Executor failed: %v", err)
+	}
+	if probe.builds != 2 {
+		t.Errorf("Expected the indicator to be rebuilt after the split, got %d builds", probe.builds)
+	}
+
+	// contexts built by hand work without an executor
+	ctx := &Context{Symbol: "TEST", CurrentBar: domain.Bar{Close: 3}, HistoricalBars: []domain.Bar{{Close: 1}, {Close: 2}}}
+	sma, err := ctx.Indicator("sma", func() (Indicator, error) { return NewRollingSMA(3) })
+	if err != nil || sma.Value() != 2 {
+		t.Errorf("Expected SMA of 2, got %.2f (%v)", sma.Value(), err)
+	}
+
+	if _, err := (&Context{}).Indicator("sma", func() (Indicator, error) { return NewRollingSMA(3) }); err == nil {
+		t.Error("Expected an error without a symbol")
+	}
+}
+
+func TestContextLookback(t *testing.T) {
+	history := []domain.Bar{{Close: 1}, {Close: 2}, {Close: 3}}
+	ctx := &Context{
+		Symbol:         "TEST",
+		CurrentBar:     history[2],
+		HistoricalBars: history[:2],
+		histories:      map[string][]domain.Bar{"TEST": history},
+	}
+
+	if got := ctx.Lookback(2); len(got) != 2 || got[0].Close != 2 || got[1].Close != 3 {
+		t.Errorf("Expected the last 2 bars, got %v", got)
+	}
+	if got := ctx.Lookback(10); len(got) != 3 {
+		t.Errorf("Expected all 3 bars, got %d", len(got))
+	}
+	if got := ctx.Lookback(2); &got[0] != &history[1] {
+		t.Error("Expected Lookback to share the history")
+	}
+	if got := ctx.Lookback(0); len(got) != 0 {
+		t.Errorf("Expected no bars, got %d", len(got))
+	}
+
+	// appending to the view must not write into the history
+	view := ctx.History("TEST")
+	_ = append(view, domain.Bar{Close: 99})
+	if cap(view) != len(view) {
+		t.Error("Expected the view to be capped at its length")
+	}
+}
+
+// batchSMACrossover is SMACrossover recomputing both averages from a copy of
+// the whole history on every bar, the way it worked before indicators
+type batchSMACrossover struct {
+	SMACrossover
+}
+
+func (s *batchSMACrossover) Generate(ctx *Context) (Signal, error) {
+	allBars := ctx.AllBars()
+	if len(allBars) < s.LongPeriod+1 {
+		return SignalHold, nil
+	}
+
+	shortSMA, _ := SMA(allBars, s.ShortPeriod)
+	longSMA, _ := SMA(allBars, s.LongPeriod)
+	prevShortSMA, _ := SMA(allBars[:len(allBars)-1], s.ShortPeriod)
+	prevLongSMA, _ := SMA(allBars[:len(allBars)-1], s.LongPeriod)
+
+	if prevShortSMA <= prevLongSMA && shortSMA > longSMA && !ctx.IsLong() {
+		return SignalBuy, nil
+	}
+	if prevShortSMA >= prevLongSMA && shortSMA < longSMA && !ctx.IsShort() {
+		return SignalSell, nil
+	}
+	return SignalHold, nil
+}
+
+func TestSMACrossoverMatchesBatch(t *testing.T) {
+	provider := &memoryProvider{bars: map[string][]domain.Bar{"TEST": randomWalk("TEST", 3000)}}
+	start, end := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	incremental, err := NewExecutor(NewSMACrossover(10, 30), provider, 10000.0).Run(context.Background(), "TEST", start, end)
+	if err != nil {
+		t.Fatalf("Executor failed: %v", err)
+	}
+	batch, err := NewExecutor(&batchSMACrossover{*NewSMACrossover(10, 30)}, provider, 10000.0).Run(context.Background(), "TEST", start, end)
+	if err != nil {
+		t.Fatalf("Executor failed: %v", err)
+	}
+
+	if len(incremental) == 0 || len(incremental) != len(batch) {
+		t.Fatalf("Expected the same trades, got %d and %d", len(incremental), len(batch))
+	}
+	for i := range batch {
+		if !incremental[i].Timestamp.Equal(batch[i].Timestamp) || incremental[i].Direction != batch[i].Direction {
+			t.Errorf("Trade %d differs: %v %s vs %v %s", i,
+				incremental[i].Direction, incremental[i].Timestamp, batch[i].Direction, batch[i].Timestamp)
+		}
+	}
+}
+
+// the incremental crossover stays linear in the number of bars while the
+// batch one grows quadratically, compare with
+//
+//	go test ./internal/strategy -run xxx -bench SMACrossover
+func BenchmarkSMACrossover(b *testing.B) {
+	start, end := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, n := range []int{1000, 10000} {
+		provider := &memoryProvider{bars: map[string][]domain.Bar{"TEST": randomWalk("TEST", n)}}
+
+		strategies := []struct {
+			name     string
+			strategy Strategy
+		}{
+			{"incremental", NewSMACrossover(50, 200)},
+			{"batch", &batchSMACrossover{*NewSMACrossover(50, 200)}},
+		}
+		for _, s := range strategies {
+			b.Run(fmt.Sprintf("%s/%d", s.name, n), func(b *testing.B) {
+				for b.Loop() {
+					if _, err := NewExecutor(s.strategy, provider, 10000.0).Run(context.Background(), "TEST", start, end); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkRollingSMA(b *testing.B) {
+	bars := randomWalk("TEST", 10000)
+	sma, _ := NewRollingSMA(200)
+
+	for i := 0; b.Loop(); i++ {
+		sma.Update(bars[i%len(bars)])
+	}
+}
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE backtests
+    ADD COLUMN fill_model VARCHAR(20) NOT NULL DEFAULT 'NEXT_BAR_OPEN';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE backtests DROP COLUMN IF EXISTS fill_model;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE backtests
+    ADD COLUMN commission_model VARCHAR(20) NOT NULL DEFAULT 'NONE',
+    ADD COLUMN commission_rate DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN slippage_model VARCHAR(20) NOT NULL DEFAULT 'NONE',
+    ADD COLUMN slippage_rate DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+ALTER TABLE trades
+    ADD COLUMN slippage DOUBLE PRECISION NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE trades DROP COLUMN IF EXISTS slippage;
+
+ALTER TABLE backtests
+    DROP COLUMN IF EXISTS slippage_rate,
+    DROP COLUMN IF EXISTS slippage_model,
+    DROP COLUMN IF EXISTS commission_rate,
+    DROP COLUMN IF EXISTS commission_model;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE backtests
+    ADD COLUMN allow_short BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN initial_margin DOUBLE PRECISION NOT NULL DEFAULT 0.5,
+    ADD COLUMN maintenance_margin DOUBLE PRECISION NOT NULL DEFAULT 0.3,
+    ADD COLUMN borrow_rate DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+ALTER TABLE trades
+    ADD COLUMN borrow_fee DOUBLE PRECISION NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE trades DROP COLUMN IF EXISTS borrow_fee;
+
+ALTER TABLE backtests
+    DROP COLUMN IF EXISTS borrow_rate,
+    DROP COLUMN IF EXISTS maintenance_margin,
+    DROP COLUMN IF EXISTS initial_margin,
+    DROP COLUMN IF EXISTS allow_short;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE backtests
+    ADD COLUMN sizing_method VARCHAR(20) NOT NULL DEFAULT 'ALL_IN',
+    ADD COLUMN sizing_value DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN sizing_lookback INTEGER NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE backtests
+    DROP COLUMN IF EXISTS sizing_lookback,
+    DROP COLUMN IF EXISTS sizing_value,
+    DROP COLUMN IF EXISTS sizing_method;
+-- +goose StatementEnd
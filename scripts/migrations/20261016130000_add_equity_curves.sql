@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS equity_curves (
+    backtest_id UUID NOT NULL REFERENCES backtests(id) ON DELETE CASCADE,
+    timestamp TIMESTAMPTZ NOT NULL,
+    equity DOUBLE PRECISION NOT NULL,
+    cash DOUBLE PRECISION NOT NULL,
+    position_value DOUBLE PRECISION NOT NULL,
+    gross_exposure DOUBLE PRECISION NOT NULL,
+    net_exposure DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (backtest_id, timestamp)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS equity_curves;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE backtests ADD COLUMN symbols TEXT[] NOT NULL DEFAULT '{}';
+
+UPDATE backtests SET symbols = ARRAY[symbol];
+
+DROP INDEX IF EXISTS idx_backtests_symbol;
+ALTER TABLE backtests DROP COLUMN symbol;
+
+CREATE INDEX idx_backtests_symbols ON backtests USING GIN (symbols);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_backtests_symbols;
+
+ALTER TABLE backtests ADD COLUMN symbol VARCHAR(10) NOT NULL DEFAULT '';
+
+UPDATE backtests SET symbol = COALESCE(symbols[1], '');
+
+ALTER TABLE backtests ALTER COLUMN symbol DROP DEFAULT;
+ALTER TABLE backtests DROP COLUMN symbols;
+
+CREATE INDEX idx_backtests_symbol ON backtests(symbol);
+-- +goose StatementEnd
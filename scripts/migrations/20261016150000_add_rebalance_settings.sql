@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE backtests
+    ADD COLUMN rebalance_frequency VARCHAR(20) NOT NULL DEFAULT 'MONTHLY',
+    ADD COLUMN rebalance_threshold DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN min_trade_shares DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN min_trade_notional DOUBLE PRECISION NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE backtests
+    DROP COLUMN IF EXISTS min_trade_notional,
+    DROP COLUMN IF EXISTS min_trade_shares,
+    DROP COLUMN IF EXISTS rebalance_threshold,
+    DROP COLUMN IF EXISTS rebalance_frequency;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE backtests
+    ADD COLUMN corporate_actions VARCHAR(20) NOT NULL DEFAULT 'APPLY',
+    ADD COLUMN reinvest_dividends BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE trades
+    ADD COLUMN dividends DOUBLE PRECISION NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE trades DROP COLUMN IF EXISTS dividends;
+
+ALTER TABLE backtests
+    DROP COLUMN IF EXISTS reinvest_dividends,
+    DROP COLUMN IF EXISTS corporate_actions;
+-- +goose StatementEnd
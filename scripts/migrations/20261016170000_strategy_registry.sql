@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- backtests reference strategies from the in-process registry by id
+ALTER TABLE backtests DROP CONSTRAINT IF EXISTS backtests_strategy_id_fkey;
+
+ALTER TABLE backtests
+    ALTER COLUMN strategy_id TYPE VARCHAR(100) USING strategy_id::TEXT,
+    ADD COLUMN parameters JSONB NOT NULL DEFAULT '{}';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- backtests of registry strategies have no strategies row to point back to
+DELETE FROM backtests WHERE strategy_id NOT IN (SELECT id::TEXT FROM strategies);
+
+ALTER TABLE backtests
+    DROP COLUMN IF EXISTS parameters,
+    ALTER COLUMN strategy_id TYPE UUID USING strategy_id::UUID;
+
+ALTER TABLE backtests
+    ADD CONSTRAINT backtests_strategy_id_fkey FOREIGN KEY (strategy_id) REFERENCES strategies(id);
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS optimizations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    strategy_id VARCHAR(100) NOT NULL,
+    symbols TEXT[] NOT NULL DEFAULT '{}',
+    start_date TIMESTAMPTZ NOT NULL,
+    end_date TIMESTAMPTZ NOT NULL,
+    grid JSONB NOT NULL DEFAULT '{}',
+    objective VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    combinations INTEGER NOT NULL,
+    skipped INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ,
+    error_message TEXT
+);
+
+-- child backtests go away with their grid search
+ALTER TABLE backtests ADD COLUMN optimization_id UUID REFERENCES optimizations(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_backtests_optimization_id ON backtests(optimization_id);
+CREATE INDEX idx_optimizations_created_at ON optimizations(created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM backtests WHERE optimization_id IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_backtests_optimization_id;
+ALTER TABLE backtests DROP COLUMN IF EXISTS optimization_id;
+
+DROP TABLE IF EXISTS optimizations;
+-- +goose StatementEnd
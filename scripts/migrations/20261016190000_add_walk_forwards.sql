@@ -0,0 +1,50 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS walk_forwards (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    strategy_id VARCHAR(100) NOT NULL,
+    symbols TEXT[] NOT NULL DEFAULT '{}',
+    start_date TIMESTAMPTZ NOT NULL,
+    end_date TIMESTAMPTZ NOT NULL,
+    in_sample_days INTEGER NOT NULL,
+    out_of_sample_days INTEGER NOT NULL,
+    anchored BOOLEAN NOT NULL DEFAULT FALSE,
+    grid JSONB NOT NULL DEFAULT '{}',
+    objective VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    combinations INTEGER NOT NULL,
+    -- the stitched out-of-sample trades live in a regular backtest
+    backtest_id UUID REFERENCES backtests(id) ON DELETE SET NULL,
+    efficiency DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ,
+    error_message TEXT
+);
+
+CREATE TABLE IF NOT EXISTS walk_forward_windows (
+    walk_forward_id UUID NOT NULL REFERENCES walk_forwards(id) ON DELETE CASCADE,
+    window_index INTEGER NOT NULL,
+    in_sample_start TIMESTAMPTZ NOT NULL,
+    in_sample_end TIMESTAMPTZ NOT NULL,
+    out_of_sample_start TIMESTAMPTZ NOT NULL,
+    out_of_sample_end TIMESTAMPTZ NOT NULL,
+    parameters JSONB NOT NULL DEFAULT '{}',
+    evaluated INTEGER NOT NULL,
+    in_sample_score DOUBLE PRECISION NOT NULL,
+    out_of_sample_score DOUBLE PRECISION NOT NULL,
+    in_sample_return DOUBLE PRECISION NOT NULL,
+    out_of_sample_return DOUBLE PRECISION NOT NULL,
+    out_of_sample_trades INTEGER NOT NULL,
+    efficiency DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (walk_forward_id, window_index)
+);
+
+CREATE INDEX idx_walk_forwards_created_at ON walk_forwards(created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS walk_forward_windows;
+DROP TABLE IF EXISTS walk_forwards;
+-- +goose StatementEnd
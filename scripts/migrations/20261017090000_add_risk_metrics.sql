@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE metrics
+    ADD COLUMN volatility DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN sortino_ratio DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN calmar_ratio DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN omega_ratio DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN skewness DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN kurtosis DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN tail_ratio DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+ALTER TABLE backtests ADD COLUMN risk_free_rate DOUBLE PRECISION NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE backtests DROP COLUMN IF EXISTS risk_free_rate;
+
+ALTER TABLE metrics
+    DROP COLUMN IF EXISTS volatility,
+    DROP COLUMN IF EXISTS sortino_ratio,
+    DROP COLUMN IF EXISTS calmar_ratio,
+    DROP COLUMN IF EXISTS omega_ratio,
+    DROP COLUMN IF EXISTS skewness,
+    DROP COLUMN IF EXISTS kurtosis,
+    DROP COLUMN IF EXISTS tail_ratio;
+-- +goose StatementEnd
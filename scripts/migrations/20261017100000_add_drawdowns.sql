@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS drawdowns (
+    backtest_id UUID NOT NULL REFERENCES backtests(id) ON DELETE CASCADE,
+    drawdown_index INTEGER NOT NULL,
+    peak_date TIMESTAMPTZ NOT NULL,
+    trough_date TIMESTAMPTZ NOT NULL,
+    recovery_date TIMESTAMPTZ,
+    peak_equity DOUBLE PRECISION NOT NULL,
+    trough_equity DOUBLE PRECISION NOT NULL,
+    depth DOUBLE PRECISION NOT NULL,
+    length_days INTEGER NOT NULL,
+    PRIMARY KEY (backtest_id, drawdown_index)
+);
+
+ALTER TABLE metrics
+    ADD COLUMN avg_drawdown DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN time_under_water DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN ulcer_index DOUBLE PRECISION NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE metrics
+    DROP COLUMN IF EXISTS avg_drawdown,
+    DROP COLUMN IF EXISTS time_under_water,
+    DROP COLUMN IF EXISTS ulcer_index;
+
+DROP TABLE IF EXISTS drawdowns;
+-- +goose StatementEnd
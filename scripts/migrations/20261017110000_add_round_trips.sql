@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS round_trips (
+    backtest_id UUID NOT NULL REFERENCES backtests(id) ON DELETE CASCADE,
+    trip_index INTEGER NOT NULL,
+    symbol VARCHAR(10) NOT NULL,
+    direction VARCHAR(10) NOT NULL,
+    entry_time TIMESTAMPTZ NOT NULL,
+    exit_time TIMESTAMPTZ NOT NULL,
+    entry_price DOUBLE PRECISION NOT NULL,
+    exit_price DOUBLE PRECISION NOT NULL,
+    quantity DOUBLE PRECISION NOT NULL,
+    pnl DOUBLE PRECISION NOT NULL,
+    return_pct DOUBLE PRECISION NOT NULL,
+    bars_held INTEGER NOT NULL,
+    mae DOUBLE PRECISION NOT NULL,
+    mfe DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (backtest_id, trip_index)
+);
+
+ALTER TABLE metrics
+    ADD COLUMN avg_bars_held DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN max_win_streak INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN max_loss_streak INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN avg_mae DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN avg_mfe DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN edge_ratio DOUBLE PRECISION NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE metrics
+    DROP COLUMN IF EXISTS avg_bars_held,
+    DROP COLUMN IF EXISTS max_win_streak,
+    DROP COLUMN IF EXISTS max_loss_streak,
+    DROP COLUMN IF EXISTS avg_mae,
+    DROP COLUMN IF EXISTS avg_mfe,
+    DROP COLUMN IF EXISTS edge_ratio;
+
+DROP TABLE IF EXISTS round_trips;
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE metrics
+    ADD COLUMN benchmark_return DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN excess_return DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN alpha DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN beta DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN correlation DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN tracking_error DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN information_ratio DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN up_capture DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN down_capture DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+ALTER TABLE backtests ADD COLUMN benchmark VARCHAR(10) NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE backtests DROP COLUMN IF EXISTS benchmark;
+
+ALTER TABLE metrics
+    DROP COLUMN IF EXISTS benchmark_return,
+    DROP COLUMN IF EXISTS excess_return,
+    DROP COLUMN IF EXISTS alpha,
+    DROP COLUMN IF EXISTS beta,
+    DROP COLUMN IF EXISTS correlation,
+    DROP COLUMN IF EXISTS tracking_error,
+    DROP COLUMN IF EXISTS information_ratio,
+    DROP COLUMN IF EXISTS up_capture,
+    DROP COLUMN IF EXISTS down_capture;
+-- +goose StatementEnd